@@ -0,0 +1,358 @@
+// Package cli implements headless (command-line) mode of DataPower Commander
+// which is used to run file and object operations from scripts without
+// starting dpcmder UI.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/repo/dp"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"github.com/croz-ltd/dpcmder/utils/paths"
+	"github.com/howeyc/gopass"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Exit statuses returned by headless commands.
+const (
+	exitOk         = 0
+	exitError      = 1
+	exitUsageError = 2
+)
+
+// usageError is returned when command is called with wrong arguments.
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// command contains headless command definition, local commands don't need
+// DataPower connection.
+type command struct {
+	args    string
+	minArgs int
+	maxArgs int
+	local   bool
+	run     func(ctx context.Context, args []string) error
+}
+
+// commands contains all available headless commands.
+var commands = map[string]command{
+	"ls":                {args: "[DP_PATH]", minArgs: 0, maxArgs: 1, run: list},
+	"get":               {args: "DP_PATH [LOCAL_PATH]", minArgs: 1, maxArgs: 2, run: get},
+	"put":               {args: "LOCAL_PATH DP_PATH", minArgs: 2, maxArgs: 2, run: put},
+	"rm":                {args: "DP_PATH", minArgs: 1, maxArgs: 1, run: remove},
+	"mkdir":             {args: "DP_PATH", minArgs: 1, maxArgs: 1, run: mkdir},
+	"export-domain":     {args: "[LOCAL_PATH]", minArgs: 0, maxArgs: 1, run: exportDomain},
+	"get-object":        {args: "CLASS NAME", minArgs: 2, maxArgs: 2, run: getObject},
+	"set-object":        {args: "LOCAL_PATH", minArgs: 1, maxArgs: 1, run: setObject},
+	"change-passphrase": {args: "", minArgs: 0, maxArgs: 0, local: true, run: changePassphrase},
+}
+
+// Run runs given headless command (first element of args) with its arguments
+// and returns exit status.
+func Run(args []string) int {
+	logging.LogDebugf("cli/Run(%v)", args)
+	err := runCommand(args)
+	switch err.(type) {
+	case nil:
+		return exitOk
+	case usageError:
+		fmt.Fprintf(os.Stderr, "Usage error: %v\n", err)
+		return exitUsageError
+	default:
+		logging.LogDebugf("cli/Run() - err: %v", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+}
+
+// runCommand validates command arguments, prepares DataPower connection and
+// runs command.
+func runCommand(args []string) error {
+	if len(args) == 0 {
+		return usageError("Command not given.")
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return usageError(fmt.Sprintf("Unknown command '%s'.", args[0]))
+	}
+	cmdArgs := args[1:]
+	if len(cmdArgs) < cmd.minArgs || len(cmdArgs) > cmd.maxArgs {
+		return usageError(fmt.Sprintf("Wrong number of arguments, expected: %s %s", args[0], cmd.args))
+	}
+
+	if !cmd.local {
+		err := initDpConnection()
+		if err != nil {
+			return err
+		}
+	}
+
+	return cmd.run(context.Background(), cmdArgs)
+}
+
+// initDpConnection prepares DataPower connection for the appliance configured
+// with command line flags (or saved configuration).
+func initDpConnection() error {
+	applianceName := config.CurrentApplianceName
+	dpa := config.CurrentAppliance
+	if dpa.DpManagmentInterface() == config.DpInterfaceUnknown {
+		return usageError("DataPower appliance not set, use -c flag (or -r / -s flags).")
+	}
+	if dpa.Domain == "" {
+		return usageError("DataPower domain not set, use -d flag.")
+	}
+
+	if dpa.Password == "" {
+		password := config.DpTransientPasswordMap[applianceName]
+		if password == "" {
+			fmt.Fprint(os.Stderr, "DataPower password: ")
+			pass, err := gopass.GetPasswd()
+			if err != nil {
+				return err
+			}
+			password = string(pass)
+		}
+		dpa.SetDpPlaintextPassword(password)
+		config.DpTransientPasswordMap[applianceName] = password
+	}
+
+	return dp.Repo.InitNetworkSettings(applianceName, dpa)
+}
+
+// domainView returns view of the current DataPower domain.
+func domainView() *model.ItemConfig {
+	return &model.ItemConfig{Type: model.ItemDpDomain,
+		Name:        config.CurrentAppliance.Domain,
+		DpAppliance: config.CurrentApplianceName,
+		DpDomain:    config.CurrentAppliance.Domain,
+		Parent:      &model.ItemConfig{Type: model.ItemNone}}
+}
+
+// splitDpPath splits full DataPower path to parent path and file name.
+func splitDpPath(dpPath string) (parentPath, fileName string) {
+	dpPath = strings.TrimRight(dpPath, "/")
+	lastSeparatorIdx := strings.LastIndex(dpPath, "/")
+	if lastSeparatorIdx == -1 {
+		return "", dpPath
+	}
+	return paths.GetDpPath(dpPath[:lastSeparatorIdx], ""), dpPath[lastSeparatorIdx+1:]
+}
+
+// list prints filestores (or files and directories) at given DataPower path.
+func list(ctx context.Context, args []string) error {
+	dirView := domainView()
+	if len(args) == 1 && args[0] != "" {
+		var err error
+		dirView, err = dp.Repo.GetViewConfigByPath(ctx, dirView, args[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	items, err := dp.Repo.GetList(ctx, dirView)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.Name != ".." {
+			fmt.Println(item.DisplayString())
+		}
+	}
+
+	return nil
+}
+
+// get downloads file from DataPower to local file or stdout.
+func get(ctx context.Context, args []string) error {
+	fileBytes, err := dp.Repo.GetFileByPath(ctx, config.CurrentAppliance.Domain, args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 || args[1] == "-" {
+		_, err = os.Stdout.Write(fileBytes)
+		return err
+	}
+
+	return ioutil.WriteFile(args[1], fileBytes, 0644)
+}
+
+// put uploads local file to DataPower.
+func put(ctx context.Context, args []string) error {
+	fileBytes, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	dpPath := args[1]
+	if strings.HasSuffix(dpPath, "/") || strings.HasSuffix(dpPath, ":") {
+		dpPath = paths.GetDpPath(dpPath, filepath.Base(args[0]))
+	}
+	ok, err := dp.Repo.UpdateFileByPath(ctx, config.CurrentAppliance.Domain, dpPath, fileBytes)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errs.Errorf("File '%s' not uploaded to '%s'.", args[0], dpPath)
+	}
+	fmt.Printf("File '%s' uploaded to '%s'.\n", args[0], dpPath)
+
+	return nil
+}
+
+// remove deletes file or directory from DataPower.
+func remove(ctx context.Context, args []string) error {
+	parentPath, fileName := splitDpPath(args[0])
+	if parentPath == "" {
+		return errs.Errorf("Can't delete DataPower filestore '%s'.", args[0])
+	}
+	parentView, err := dp.Repo.GetViewConfigByPath(ctx, domainView(), parentPath)
+	if err != nil {
+		return err
+	}
+
+	fileType, err := dp.Repo.GetFileType(ctx, parentView, parentPath, fileName)
+	if err != nil {
+		return err
+	}
+	if fileType == model.ItemNone {
+		return errs.Errorf("Can't find '%s'.", args[0])
+	}
+
+	ok, err := dp.Repo.Delete(ctx, parentView, fileType, parentPath, fileName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errs.Errorf("Couldn't delete '%s'.", args[0])
+	}
+	fmt.Printf("Deleted '%s' (%s).\n", args[0], fileType.UserFriendlyString())
+
+	return nil
+}
+
+// mkdir creates directory on DataPower.
+func mkdir(ctx context.Context, args []string) error {
+	parentPath, dirName := splitDpPath(args[0])
+	if parentPath == "" {
+		return errs.Errorf("Can't create DataPower filestore '%s'.", args[0])
+	}
+
+	ok, err := dp.Repo.CreateDirByPath(ctx, config.CurrentAppliance.Domain, parentPath, dirName)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errs.Errorf("Couldn't create directory '%s'.", args[0])
+	}
+	fmt.Printf("Directory '%s' created.\n", args[0])
+
+	return nil
+}
+
+// exportDomain exports current DataPower domain to local zip file.
+func exportDomain(ctx context.Context, args []string) error {
+	domainName := config.CurrentAppliance.Domain
+	exportFileName := config.CurrentApplianceName + "_" + domainName + "_" +
+		time.Now().Format("20060102150405") + ".zip"
+	exportFilePath := exportFileName
+	if len(args) == 1 {
+		exportFilePath = args[0]
+		if fileInfo, err := os.Stat(exportFilePath); err == nil && fileInfo.IsDir() {
+			exportFilePath = paths.GetFilePath(exportFilePath, exportFileName)
+		}
+	}
+
+	exportFileBytes, err := dp.Repo.ExportDomain(ctx, domainName, exportFileName)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(exportFilePath, exportFileBytes, 0644)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Domain '%s' exported to file '%s'.\n", domainName, exportFilePath)
+
+	return nil
+}
+
+// getObject prints DataPower object configuration.
+func getObject(ctx context.Context, args []string) error {
+	objectBytes, err := dp.Repo.GetObject(ctx, config.CurrentAppliance.Domain, args[0], args[1], false)
+	if err != nil {
+		return err
+	}
+	if objectBytes == nil {
+		return errs.Errorf("Can't find DataPower object '%s' of class '%s'.", args[1], args[0])
+	}
+	_, err = os.Stdout.Write(objectBytes)
+
+	return err
+}
+
+// setObject creates or updates DataPower object from local configuration file.
+func setObject(ctx context.Context, args []string) error {
+	objectBytes, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	domainName := config.CurrentAppliance.Domain
+
+	objectClass, objectName, err := dp.Repo.ParseObjectClassAndName(objectBytes)
+	if err != nil {
+		return err
+	}
+	existingObjectBytes, err := dp.Repo.GetObject(ctx, domainName, objectClass, objectName, false)
+	if err != nil {
+		return err
+	}
+	existingObject := existingObjectBytes != nil
+
+	err = dp.Repo.SetObject(ctx, domainName, objectClass, objectName, objectBytes, existingObject)
+	if err != nil {
+		return err
+	}
+	if existingObject {
+		fmt.Printf("DataPower object '%s' of class '%s' updated.\n", objectName, objectClass)
+	} else {
+		fmt.Printf("DataPower object '%s' of class '%s' created.\n", objectName, objectClass)
+	}
+
+	return nil
+}
+
+// changePassphrase changes master passphrase used to encrypt saved DataPower
+// passwords (sets it if credential store is not initialized yet).
+func changePassphrase(ctx context.Context, args []string) error {
+	if !config.CredentialsInitialized() {
+		err := config.UnlockCredentials(config.AskNewPassphrase("New dpcmder master passphrase: "))
+		if err != nil {
+			return err
+		}
+		fmt.Println("Master passphrase set.")
+		return nil
+	}
+	if !config.CredentialsUnlocked() {
+		err := config.UnlockCredentials(config.AskPassphrase())
+		if err != nil {
+			return err
+		}
+	}
+
+	err := config.ChangeMasterPassphrase(config.AskNewPassphrase("New dpcmder master passphrase: "))
+	if err != nil {
+		return err
+	}
+	fmt.Println("Master passphrase changed.")
+	return nil
+}
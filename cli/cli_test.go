@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/repo/dp/dpfake"
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitDpPath(t *testing.T) {
+	testDataMatrix := [][]string{
+		{"local:/dir1/dir2/myfile", "local:/dir1/dir2", "myfile"},
+		{"local:/dir1/dir2/", "local:/dir1", "dir2"},
+		{"local:/myfile", "local:", "myfile"},
+		{"local/dir1/myfile", "local:/dir1", "myfile"},
+		{"local:", "", "local:"},
+	}
+	for _, testCase := range testDataMatrix {
+		parentPath, fileName := splitDpPath(testCase[0])
+		assert.Equals(t, "splitDpPath('"+testCase[0]+"')", parentPath, testCase[1])
+		assert.Equals(t, "splitDpPath('"+testCase[0]+"')", fileName, testCase[2])
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	config.CurrentApplianceName = ""
+	config.CurrentAppliance = config.DataPowerAppliance{}
+
+	testDataMatrix := []struct {
+		args []string
+		err  error
+	}{
+		{[]string{}, usageError("Command not given.")},
+		{[]string{"unknown"}, usageError("Unknown command 'unknown'.")},
+		{[]string{"get"}, usageError("Wrong number of arguments, expected: get DP_PATH [LOCAL_PATH]")},
+		{[]string{"put", "a", "b", "c"}, usageError("Wrong number of arguments, expected: put LOCAL_PATH DP_PATH")},
+		{[]string{"ls"}, usageError("DataPower appliance not set, use -c flag (or -r / -s flags).")},
+	}
+	for _, testCase := range testDataMatrix {
+		assert.Equals(t, "runCommand", runCommand(testCase.args), testCase.err)
+	}
+
+	config.CurrentAppliance = config.DataPowerAppliance{RestUrl: "https://my_dp_host:5554"}
+	assert.Equals(t, "runCommand", runCommand([]string{"ls"}), usageError("DataPower domain not set, use -d flag."))
+	assert.Equals(t, "Run", Run([]string{"unknown"}), exitUsageError)
+}
+
+// newFakeAppliance starts fake DataPower appliance and sets it as current
+// DataPower appliance using given management interface.
+func newFakeAppliance(t *testing.T, managementInterface string) *dpfake.Appliance {
+	a := dpfake.NewAppliance("admin", "secret")
+	a.AddDomain("test")
+	a.SetFile("test", "local:/dir/file.txt", []byte("file content"))
+	url := a.Start()
+
+	dpa := config.DataPowerAppliance{Domain: "test", Username: "admin"}
+	switch managementInterface {
+	case config.DpInterfaceRest:
+		dpa.RestUrl = url
+	case config.DpInterfaceSoma:
+		dpa.SomaUrl = url
+	}
+	dpa.SetDpPlaintextPassword("secret")
+	config.Conf.DataPowerAppliances["fake"] = dpa
+	config.CurrentApplianceName = "fake"
+	config.CurrentAppliance = dpa
+	return a
+}
+
+// runCaptured runs headless command and returns its exit status and output
+// written to stdout.
+func runCaptured(t *testing.T, args ...string) (int, string) {
+	stdoutFile, err := ioutil.TempFile("", "dpcmder-cli-stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(stdoutFile.Name())
+	defer stdoutFile.Close()
+
+	stdout := os.Stdout
+	os.Stdout = stdoutFile
+	exitStatus := Run(args)
+	os.Stdout = stdout
+
+	output, err := ioutil.ReadFile(stdoutFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return exitStatus, string(output)
+}
+
+func TestRunCommands(t *testing.T) {
+	for _, managementInterface := range []string{config.DpInterfaceRest, config.DpInterfaceSoma} {
+		t.Run(managementInterface, func(t *testing.T) {
+			a := newFakeAppliance(t, managementInterface)
+			defer a.Close()
+			tmpDir, err := ioutil.TempDir("", "dpcmder-cli")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(tmpDir)
+
+			exitStatus, output := runCaptured(t, "ls", "local:/dir")
+			assert.Equals(t, "ls exit status", exitStatus, exitOk)
+			assert.Equals(t, "ls output", strings.Contains(output, "file.txt"), true)
+
+			exitStatus, output = runCaptured(t, "get", "local:/dir/file.txt")
+			assert.Equals(t, "get exit status", exitStatus, exitOk)
+			assert.Equals(t, "get output", output, "file content")
+			exitStatus, output = runCaptured(t, "get", "local:/dir/missing.txt")
+			assert.Equals(t, "get missing exit status", exitStatus, exitError)
+			assert.Equals(t, "get missing output", output, "")
+			localPath := filepath.Join(tmpDir, "file.txt")
+			exitStatus, _ = runCaptured(t, "get", "local:/dir/file.txt", localPath)
+			assert.Equals(t, "get to file exit status", exitStatus, exitOk)
+			fileBytes, err := ioutil.ReadFile(localPath)
+			assert.Equals(t, "get to file", err, nil)
+			assert.Equals(t, "get to file", string(fileBytes), "file content")
+			fileInfo, err := os.Stat(localPath)
+			assert.Equals(t, "get to file", err, nil)
+			assert.Equals(t, "get to file permissions", fileInfo.Mode().Perm()&^0022, os.FileMode(0644))
+
+			err = ioutil.WriteFile(localPath, []byte("new content"), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exitStatus, output = runCaptured(t, "put", localPath, "local:/dir/")
+			assert.Equals(t, "put exit status", exitStatus, exitOk)
+			assert.Equals(t, "put output", output,
+				"File '"+localPath+"' uploaded to 'local:/dir/file.txt'.\n")
+			fileBytes, _ = a.File("test", "local:/dir/file.txt")
+			assert.Equals(t, "put", string(fileBytes), "new content")
+			exitStatus, _ = runCaptured(t, "put", filepath.Join(tmpDir, "missing.txt"), "local:/dir/")
+			assert.Equals(t, "put missing exit status", exitStatus, exitError)
+
+			exportPath := filepath.Join(tmpDir, "export.zip")
+			exitStatus, output = runCaptured(t, "export-domain", exportPath)
+			assert.Equals(t, "export-domain exit status", exitStatus, exitOk)
+			assert.Equals(t, "export-domain output", output,
+				"Domain 'test' exported to file '"+exportPath+"'.\n")
+			exportBytes, err := ioutil.ReadFile(exportPath)
+			assert.Equals(t, "export-domain", err, nil)
+			assert.Equals(t, "export-domain zip", strings.HasPrefix(string(exportBytes), "PK"), true)
+
+			exitStatus, _ = runCaptured(t, "export-domain", exportPath, "extra")
+			assert.Equals(t, "export-domain usage exit status", exitStatus, exitUsageError)
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"github.com/croz-ltd/confident"
 	"github.com/croz-ltd/dpcmder/help"
+	"github.com/croz-ltd/dpcmder/ui/keys"
 	"github.com/croz-ltd/dpcmder/utils/logging"
 	"github.com/croz-ltd/dpcmder/utils/paths"
 	"github.com/howeyc/gopass"
@@ -25,6 +26,10 @@ const (
 	// PreviousApplianceName is name of configuration for the last appliance
 	// configured with command-line parameters (without explicitly saving config).
 	PreviousApplianceName = "_PreviousAppliance_"
+	// DemoRestApplianceName & DemoSomaApplianceName are names of configurations
+	// for the fake DataPower appliance used in demo mode.
+	DemoRestApplianceName = "demo-rest"
+	DemoSomaApplianceName = "demo-soma"
 )
 
 // CurrentAppliance stores configuration value of current appliance used.
@@ -38,7 +43,7 @@ var (
 	// LocalFolderPath is a folder where dpcmder starts showing files - set by command flag.
 	LocalFolderPath *string
 	// DebugLogFile/TraceLogFile enables writing of debug/trace messages to
-	// dpcmder.log file in current folder.
+	// dpcmder.log file in current folder (or to log file configured).
 	DebugLogFile *bool
 	TraceLogFile *bool
 	// DataPower connection parameters.
@@ -49,21 +54,35 @@ var (
 	dpDomain     *string
 	proxy        *string
 	dpConfigName *string
+	// DemoMode starts dpcmder connected to fake (in-process) DataPower appliance.
+	DemoMode *bool
 	// Help/Usage/Version flags - shows usage, help or version and exit.
 	helpUsage *bool
 	helpFull  *bool
 	version   *bool
 )
 
+// HeadlessArgs contains command with arguments given after flags - if set,
+// command is executed without starting dpcmder UI (see usage).
+var HeadlessArgs []string
+
 // DpTransientPasswordMap contains passwords entered through dpcmder dialogs,
 // not saved to config during (other) configuration changes.
 var DpTransientPasswordMap = make(map[string]string)
 
 // Config is a structure containing dpcmder configuration (saved to JSON).
+// Version is version of configuration, configuration saved by older dpcmder
+// versions (without Version) is migrated once after it is read.
 type Config struct {
+	Version             int
 	Cmd                 Command
 	Log                 Log
 	Sync                Sync
+	Net                 Net
+	Copy                Copy
+	Keys                map[string][]string
+	Theme               Theme
+	Credentials         Credentials
 	DataPowerAppliances map[string]DataPowerAppliance
 }
 
@@ -74,27 +93,117 @@ type Command struct {
 	Diff   string
 }
 
-// Log is a structure containing dpcmder logging configuration. MaxEntrySize
-// configures how many bytes can each log line contain (rest is removed).
+// Log is a structure containing dpcmder logging configuration. Path is log
+// file path (relative to current dir), Level ("debug" or "trace") enables
+// logging without -debug/-trace flags. MaxEntrySize configures how many bytes
+// can each log line contain (rest is removed). Log file is rotated when it
+// grows over MaxSizeMB megabytes (MaxBackups rotated files are kept). JSON
+// enables writing of log lines as JSON objects.
 type Log struct {
+	Path         string
+	Level        string
 	MaxEntrySize int
+	MaxSizeMB    int
+	MaxBackups   int
+	JSON         bool
 }
 
+// Log levels which can be configured.
+const (
+	LogLevelDebug = "debug"
+	LogLevelTrace = "trace"
+)
+
 // Sync is a structure containing dpcmder synchronization configuration used
-// when syncing local filesystem to datapower is enabled.
+// when syncing local filesystem to datapower is enabled. PropagateDeletes
+// enables deleting DataPower files and directories removed on local filesystem
+// and moving DataPower files renamed on local filesystem. Ignore contains
+// global list of ignore patterns (gitignore syntax) used for sync and copy,
+// together with patterns from .dpcmderignore.
+// Profiles contains named sync profiles used to sync local directory to many
+// DataPower appliances/domains at once.
 type Sync struct {
-	Seconds int
+	Seconds          int
+	PropagateDeletes bool
+	Ignore           []string
+	Profiles         map[string]SyncProfile
+}
+
+// Net is a structure containing dpcmder network configuration used for HTTP
+// client of each DataPower appliance. ConnectSeconds limits time to connect
+// (and TLS handshake), ResponseSeconds limits time to wait for DataPower
+// response, KeepAliveSeconds sets how long idle connections are reused and
+// MaxRetries sets how many times failed GET requests are retried.
+type Net struct {
+	ConnectSeconds   int
+	ResponseSeconds  int
+	KeepAliveSeconds int
+	MaxRetries       int
+}
+
+// Copy is a structure containing dpcmder copy configuration. Workers sets how
+// many files are transferred in parallel while copying.
+type Copy struct {
+	Workers int
+}
+
+// Theme is a structure containing dpcmder color theme configuration. Preset
+// is name of predefined theme (default, dark, light, high-contrast or
+// monochrome), Elements overrides preset style of single UI elements (normal,
+// title, currentTitle, current, selected, currentSelected, modified, down,
+// dialog, dialogSelected, status and built-in pager elements lineNumber,
+// searchMatch, syntaxTag, syntaxAttr, syntaxString, syntaxComment,
+// syntaxKeyword, syntaxNumber and built-in diff viewer elements diffHeader,
+// diffHunk, diffAdded, diffRemoved).
+type Theme struct {
+	Preset   string
+	Elements map[string]ThemeStyle
+}
+
+// ThemeStyle is a structure containing style of UI element. Fg and Bg are
+// color names (like "red" or "navy") or hex values ("#ff8800"), empty color
+// keeps preset color. Attrs (bold, underline, reverse, dim) replaces preset
+// attributes if set.
+type ThemeStyle struct {
+	Fg    string
+	Bg    string
+	Attrs []string
+}
+
+// SyncProfile is a structure containing all DataPower locations (targets) local
+// directory is synced to when sync profile is used.
+type SyncProfile struct {
+	Targets []SyncTarget
+}
+
+// SyncTarget is a structure containing DataPower location local directory is
+// synced to - DataPower appliance configuration name, domain and path.
+type SyncTarget struct {
+	Appliance string
+	Domain    string
+	Path      string
 }
 
 // DataPowerAppliance is a structure containing dpcmder DataPower appliance
-// configuration details required to connect to appliances.
+// configuration details required to connect to appliances. Password is kept
+// base32 encoded in memory and saved encrypted to configuration file (see
+// MarshalJSON). TLS connection to appliance can be configured with CaBundle
+// (PEM file with CA certificates used to verify appliance certificate),
+// ClientCert & ClientKey (PEM files used for mutual TLS), CertFingerprint
+// (SHA-256 fingerprint of appliance certificate) and VerifyHostname flag. If
+// none of them is set appliance certificate is not verified.
 type DataPowerAppliance struct {
-	RestUrl  string
-	SomaUrl  string
-	Username string
-	Password string
-	Domain   string
-	Proxy    string
+	RestUrl         string
+	SomaUrl         string
+	Username        string
+	Password        string
+	Domain          string
+	Proxy           string
+	CaBundle        string
+	ClientCert      string
+	ClientKey       string
+	CertFingerprint string
+	VerifyHostname  bool
 }
 
 // List of DataPower management interfaces - returned by DpManagmentInterface().
@@ -115,13 +224,36 @@ func (dpa *DataPowerAppliance) SetDpPlaintextPassword(password string) {
 func (dpa *DataPowerAppliance) DpPlaintextPassword() string {
 	passBytes, err := base32.StdEncoding.DecodeString(dpa.Password)
 	if err != nil {
-		logging.LogDebugf("config/DataPowerAppliance.DpPlaintextPassword() - Can't decode password: '%s', err: %v",
-			dpa.Password, err)
+		logging.LogDebugf("config/DataPowerAppliance.DpPlaintextPassword() - Can't decode password, err: %v", err)
 		return ""
 	}
 	return string(passBytes)
 }
 
+// String returns DataPowerAppliance as shown in log lines ("%v" and "%+v"
+// formats), password is masked.
+func (dpa DataPowerAppliance) String() string {
+	return fmt.Sprintf("%+v", dpa.masked())
+}
+
+// GoString returns DataPowerAppliance as shown in log lines ("%#v" format),
+// password is masked.
+func (dpa DataPowerAppliance) GoString() string {
+	return strings.Replace(fmt.Sprintf("%#v", dpa.masked()), "config.maskedAppliance", "config.DataPowerAppliance", 1)
+}
+
+// maskedAppliance is DataPowerAppliance without String & GoString methods.
+type maskedAppliance DataPowerAppliance
+
+// masked returns copy of DataPowerAppliance with password masked.
+func (dpa DataPowerAppliance) masked() maskedAppliance {
+	masked := maskedAppliance(dpa)
+	if masked.Password != "" {
+		masked.Password = "***"
+	}
+	return masked
+}
+
 // DpManagmentInterface returns management interface used to manage DataPower.
 func (dpa *DataPowerAppliance) DpManagmentInterface() string {
 	switch {
@@ -139,11 +271,37 @@ func (dpa *DataPowerAppliance) DpManagmentInterface() string {
 // JSON configuration file (if configuration file is found).
 var Conf = Config{
 	Cmd: Command{
-		Viewer: "less", Editor: "vi", Diff: "diff"},
-	Log:                 Log{MaxEntrySize: logging.MaxEntrySize},
+		Editor: "vi"},
+	Log: Log{Path: logging.FilePath, MaxEntrySize: logging.MaxEntrySize,
+		MaxSizeMB: int(logging.MaxFileSize / (1024 * 1024)), MaxBackups: logging.MaxBackups},
 	Sync:                Sync{Seconds: 4},
+	Net:                 Net{ConnectSeconds: 10, ResponseSeconds: 120, KeepAliveSeconds: 90, MaxRetries: 2},
+	Copy:                Copy{Workers: 4},
+	Keys:                help.DefaultKeys(),
+	Theme:               Theme{Preset: "default", Elements: make(map[string]ThemeStyle)},
 	DataPowerAppliances: make(map[string]DataPowerAppliance)}
 
+// configVersion is current version of configuration (see migrateConfig).
+const configVersion = 1
+
+// migrateConfig migrates configuration saved by older dpcmder versions. Older
+// versions saved their default Viewer ("less") and Diff ("diff") commands to
+// configuration, they are removed so built-in pager and diff viewer are used
+// (unless commands are configured again).
+func migrateConfig() {
+	if Conf.Version < 1 {
+		if Conf.Cmd.Viewer == "less" {
+			logging.LogDebug("config/migrateConfig() - Removing old default Viewer command.")
+			Conf.Cmd.Viewer = ""
+		}
+		if Conf.Cmd.Diff == "diff" {
+			logging.LogDebug("config/migrateConfig() - Removing old default Diff command.")
+			Conf.Cmd.Diff = ""
+		}
+	}
+	Conf.Version = configVersion
+}
+
 // k is Confident library configuration instance.
 var k *confident.Confident
 
@@ -159,7 +317,15 @@ func initConfiguration() {
 	k.Permission = os.FileMode(0644)
 	logging.LogDebugf("config/initConfiguration() - Conf before read: %#v", Conf)
 	k.Read()
+	migrateConfig()
+	initLogging()
+	initKeys()
 	logging.LogDebugf("config/initConfiguration() - Conf after read: %#v", Conf)
+	if *DemoMode {
+		// Demo appliances are added after fake DataPower appliance is started.
+		return
+	}
+	initCredentials()
 	if *dpRestURL != "" || *dpSomaURL != "" {
 		if *dpConfigName != "" {
 			validateDpConfigName()
@@ -169,12 +335,59 @@ func initConfiguration() {
 			Conf.DataPowerAppliances[PreviousApplianceName] = DataPowerAppliance{Domain: *dpDomain, Proxy: *proxy, RestUrl: *dpRestURL, SomaUrl: *dpSomaURL, Username: *dpUsername, Password: *dpPassword}
 			CurrentApplianceName = PreviousApplianceName
 		}
-		k.Persist()
+		persist()
 		logging.LogDebugf("config/initConfiguration() - Conf after persist: %#v", Conf)
+	} else if *dpConfigName != "" && len(HeadlessArgs) > 0 {
+		// Headless command uses saved DataPower configuration, domain and
+		// password can be overridden.
+		dpa, ok := Conf.DataPowerAppliances[*dpConfigName]
+		if !ok {
+			fmt.Printf("DataPower appliance configuration with name '%s' doesn't exist.\n\n", *dpConfigName)
+			usage(2)
+		}
+		if *dpDomain != "" {
+			dpa.Domain = *dpDomain
+		}
+		if dpa.Password == "" && *dpPassword != "" {
+			dpa.Password = *dpPassword
+			DpTransientPasswordMap[*dpConfigName] = dpa.DpPlaintextPassword()
+		}
+		CurrentApplianceName = *dpConfigName
+		CurrentAppliance = dpa
+		return
 	}
 	CurrentAppliance = DataPowerAppliance{Domain: *dpDomain, Proxy: *proxy, RestUrl: *dpRestURL, SomaUrl: *dpSomaURL, Username: *dpUsername, Password: *dpPassword}
 }
 
+// initLogging applies logging configuration read from configuration file,
+// -debug/-trace flags enable logging regardless of configured level.
+func initLogging() {
+	if Conf.Log.Path != "" {
+		logging.FilePath = Conf.Log.Path
+	}
+	logging.MaxEntrySize = Conf.Log.MaxEntrySize
+	logging.MaxFileSize = int64(Conf.Log.MaxSizeMB) * 1024 * 1024
+	logging.MaxBackups = Conf.Log.MaxBackups
+	logging.JSONFormat = Conf.Log.JSON
+	switch strings.ToLower(Conf.Log.Level) {
+	case LogLevelDebug:
+		logging.DebugLogFile = true
+	case LogLevelTrace:
+		logging.TraceLogFile = true
+	}
+}
+
+// initKeys generates help from key bindings read from configuration file
+// (actions not configured keep default keys), only keys which can be bound
+// are shown in help.
+func initKeys() {
+	bindings, err := keys.NewBindings(Conf.Keys)
+	if err != nil {
+		logging.LogDebugf("config/initKeys() - %v", err)
+	}
+	help.Help = help.Text(bindings.Keys)
+}
+
 // parseProgramArgs parses program arguments and fill config package variables with flag values.
 func parseProgramArgs() {
 	LocalFolderPath = flag.String("l", ".", "Path to local directory to open, default is '.'")
@@ -184,15 +397,17 @@ func parseProgramArgs() {
 	password := flag.String("p", "", "DataPower user password")
 	dpDomain = flag.String("d", "", "DataPower domain name")
 	proxy = flag.String("x", "", "URL of proxy server for DataPower connection")
-	dpConfigName = flag.String("c", "", "Name of DataPower connection configuration to save with given configuration params")
-	DebugLogFile = flag.Bool("debug", false, "Write debug dpcmder.log file in current dir")
-	TraceLogFile = flag.Bool("trace", false, "Write trace dpcmder.log file in current dir")
+	dpConfigName = flag.String("c", "", "Name of DataPower connection configuration to save with given configuration params (or to use if no URL is given)")
+	DebugLogFile = flag.Bool("debug", false, "Write debug messages to dpcmder.log file in current dir (or to configured log file)")
+	TraceLogFile = flag.Bool("trace", false, "Write trace messages to dpcmder.log file in current dir (or to configured log file)")
 	helpUsage = flag.Bool("h", false, "Show dpcmder usage with examples")
 	helpFull = flag.Bool("help", false, "Show dpcmder in-program help on console")
 	version = flag.Bool("v", false, "Show dpcmder version")
+	DemoMode = flag.Bool("demo", false, "Start dpcmder in demo mode connected to fake DataPower appliance")
 
 	flag.Parse()
 	setDpPasswordPlain(*password)
+	HeadlessArgs = flag.Args()
 }
 
 // Init intializes configuration: parses command line flags and creates config directory.
@@ -313,9 +528,11 @@ func (c *Config) CreateDpApplianceConfig() ([]byte, error) {
 	return json, nil
 }
 
-// GetDpApplianceConfig fetches DataPower appliance JSON configuration as byte array.
+// GetDpApplianceConfig fetches DataPower appliance JSON configuration as byte
+// array (with base32 encoded password, as used in memory).
 func (c *Config) GetDpApplianceConfig(name string) ([]byte, error) {
-	dpAppliance := c.DataPowerAppliances[name]
+	type plainAppliance DataPowerAppliance
+	dpAppliance := plainAppliance(c.DataPowerAppliances[name])
 	json, err := json.MarshalIndent(dpAppliance, "", "  ")
 	if err != nil {
 		logging.LogDebugf("config/GetDpApplianceConfig('%s') - Can't marshal DataPower appliance configuration.", name)
@@ -324,7 +541,9 @@ func (c *Config) GetDpApplianceConfig(name string) ([]byte, error) {
 	return json, nil
 }
 
-// SetDpApplianceConfig sets DataPower appliance JSON configuration as byte array.
+// SetDpApplianceConfig sets DataPower appliance JSON configuration as byte
+// array. Configuration with password can be saved only when credential store
+// is unlocked (ErrCredentialsLocked is returned otherwise).
 func (c *Config) SetDpApplianceConfig(name string, contents []byte) error {
 	dpAppliance := c.DataPowerAppliances[name]
 	err := json.Unmarshal(contents, &dpAppliance)
@@ -332,14 +551,48 @@ func (c *Config) SetDpApplianceConfig(name string, contents []byte) error {
 		logging.LogDebugf("config/SetDpApplianceConfig('%s', ...) - Can't unmarshal DataPower appliance configuration,", name)
 		return err
 	}
+	if dpAppliance.Password != "" && !CredentialsUnlocked() && !*DemoMode {
+		logging.LogDebugf("config/SetDpApplianceConfig('%s', ...) - Credential store is locked.", name)
+		return ErrCredentialsLocked
+	}
 	c.DataPowerAppliances[name] = dpAppliance
-	k.Persist()
+	persist()
 	return nil
 }
 
 // DeleteDpApplianceConfig deletes DataPower appliance JSON configuration.
 func (c *Config) DeleteDpApplianceConfig(name string) {
 	delete(c.DataPowerAppliances, name)
+	persist()
+}
+
+// AddDemoAppliances adds configurations of demo appliances which use REST and
+// SOMA management interfaces of the fake DataPower appliance at given URL. The
+// appliance given with -c flag (or the REST one) is used as current appliance.
+func AddDemoAppliances(url, username, password string) {
+	logging.LogDebugf("config/AddDemoAppliances('%s', '%s', ...)", url, username)
+	restDpa := DataPowerAppliance{RestUrl: url, Username: username}
+	restDpa.SetDpPlaintextPassword(password)
+	somaDpa := DataPowerAppliance{SomaUrl: url, Username: username}
+	somaDpa.SetDpPlaintextPassword(password)
+	Conf.DataPowerAppliances[DemoRestApplianceName] = restDpa
+	Conf.DataPowerAppliances[DemoSomaApplianceName] = somaDpa
+
+	CurrentApplianceName = DemoRestApplianceName
+	if *dpConfigName == DemoSomaApplianceName {
+		CurrentApplianceName = DemoSomaApplianceName
+	}
+	CurrentAppliance = Conf.DataPowerAppliances[CurrentApplianceName]
+	CurrentAppliance.Domain = *dpDomain
+}
+
+// persist saves configuration to dpcmder JSON configuration file (in demo
+// mode configuration is never saved).
+func persist() {
+	if *DemoMode {
+		logging.LogDebug("config/persist() - demo mode, configuration not saved.")
+		return
+	}
 	k.Persist()
 }
 
@@ -353,6 +606,7 @@ func PrintConfig() {
 	fmt.Println("dpDomain: ", *dpDomain)
 	fmt.Println("proxy: ", *proxy)
 	fmt.Println("dpConfigName: ", *dpConfigName)
+	fmt.Println("demoMode: ", *DemoMode)
 	fmt.Println("helpUsage: ", *helpUsage)
 	fmt.Println("helpFull: ", *helpFull)
 	fmt.Println("version: ", *version)
@@ -368,6 +622,7 @@ func LogConfig() {
 	logging.LogDebug("dpDomain: ", *dpDomain)
 	logging.LogDebug("proxy: ", *proxy)
 	logging.LogDebug("dpConfigName: ", *dpConfigName)
+	logging.LogDebug("demoMode: ", *DemoMode)
 	logging.LogDebug("helpUsage: ", *helpUsage)
 	logging.LogDebug("helpFull: ", *helpFull)
 	logging.LogDebug("version: ", *version)
@@ -376,7 +631,8 @@ func LogConfig() {
 // usage prints usage help information with examples to console.
 func usage(exitStatus int) {
 	fmt.Println("Usage:")
-	fmt.Printf(" %s [-l LOCAL_FOLDER_PATH] [-r DATA_POWER_REST_URL | -s DATA_POWER_SOMA_AMP_URL] [-u USERNAME] [-p PASSWORD] [-d DP_DOMAIN] [-x PROXY_SERVER] [-c DP_CONFIG_NAME] [-debug] [-h] [-help]\n", os.Args[0])
+	fmt.Printf(" %s [-l LOCAL_FOLDER_PATH] [-r DATA_POWER_REST_URL | -s DATA_POWER_SOMA_AMP_URL] [-u USERNAME] [-p PASSWORD] [-d DP_DOMAIN] [-x PROXY_SERVER] [-c DP_CONFIG_NAME] [-demo] [-debug] [-h] [-help]\n", os.Args[0])
+	fmt.Printf(" %s [-r DATA_POWER_REST_URL | -s DATA_POWER_SOMA_AMP_URL] [-u USERNAME] [-p PASSWORD] [-d DP_DOMAIN] [-x PROXY_SERVER] [-c DP_CONFIG_NAME] [-debug] COMMAND [ARGS...]\n", os.Args[0])
 	fmt.Println("")
 	fmt.Println(" -l LOCAL_FOLDER_PATH - set path to local folder")
 	fmt.Println(" -r DATA_POWER_REST_URL - set REST management URL for DataPower")
@@ -386,11 +642,25 @@ func usage(exitStatus int) {
 	fmt.Println(" -d DP_DOMAIN - connect to specific DataPower domain (can be neccessary on some security configurations)")
 	fmt.Println(" -x PROXY_SERVER - connect to DataPower through proxy")
 	fmt.Println(" -c DP_CONFIG_NAME - save DataPower configuration under given name")
+	fmt.Println("                     (if DataPower URL is not given command uses saved DataPower configuration)")
+	fmt.Println(" -demo - connect to fake DataPower appliance started by dpcmder ('demo-rest' or 'demo-soma'")
+	fmt.Println("         configuration can be selected with -c flag, configuration is not saved)")
 	fmt.Println(" -debug - turns on creation of dpcmder.log file with debug log messages")
 	fmt.Println(" -h - shows this (usage) help")
 	fmt.Println(" -help - shows dpcmder full help on console")
 	fmt.Println(" -v - shows dpcmder version")
 	fmt.Println("")
+	fmt.Println("Commands (run without starting dpcmder UI, DP_PATH is like 'local:/dir/file.xsl'):")
+	fmt.Println(" ls [DP_PATH] - list filestores or files and directories at DP_PATH")
+	fmt.Println(" get DP_PATH [LOCAL_PATH] - download file (to stdout if LOCAL_PATH is not given)")
+	fmt.Println(" put LOCAL_PATH DP_PATH - upload file")
+	fmt.Println(" rm DP_PATH - delete file or directory")
+	fmt.Println(" mkdir DP_PATH - create directory")
+	fmt.Println(" export-domain [LOCAL_PATH] - export domain to zip file")
+	fmt.Println(" get-object CLASS NAME - print DataPower object configuration (JSON/XML)")
+	fmt.Println(" set-object LOCAL_PATH - create or update DataPower object from configuration file")
+	fmt.Println(" change-passphrase - change master passphrase protecting saved DataPower passwords")
+	fmt.Println("")
 	fmt.Println("")
 	fmt.Println("Example:")
 	fmt.Printf(" %s\n", os.Args[0])
@@ -403,6 +673,13 @@ func usage(exitStatus int) {
 	fmt.Println("   - connect to DataPower using SOMA managment interface and write debug messages to ./dpcmder.log file")
 	fmt.Printf(" %s -s https://localhost:5550 -u admin -p admin -c LocalDp\n", os.Args[0])
 	fmt.Println("   - connect to DataPower using SOMA managment interface and save configuration parameters as LocalDp")
+	fmt.Printf(" %s -c LocalDp -d test put ./transform.xsl local:/transform.xsl\n", os.Args[0])
+	fmt.Println("   - upload file to DataPower test domain using saved LocalDp configuration (without starting UI)")
+	fmt.Printf(" %s -demo -c demo-soma -d demo\n", os.Args[0])
+	fmt.Println("   - try dpcmder on fake DataPower appliance using SOMA managment interface, without real appliance")
+	fmt.Println("")
+	fmt.Println("Saved DataPower passwords are encrypted using master passphrase which is asked once on start")
+	fmt.Println("(or read from DPCMDER_PASSPHRASE environment variable).")
 
 	os.Exit(exitStatus)
 }
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDataPowerApplianceLogged(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "dpcmder-config")
+	assert.Equals(t, "TempDir()", err, nil)
+	defer os.RemoveAll(tmpDir)
+	defer func(filePath string, debug bool) {
+		logging.FilePath, logging.DebugLogFile = filePath, debug
+	}(logging.FilePath, logging.DebugLogFile)
+	logging.FilePath = filepath.Join(tmpDir, "dpcmder.log")
+	logging.DebugLogFile = true
+
+	dpa := DataPowerAppliance{RestUrl: "https://h:5554", Username: "admin"}
+	dpa.SetDpPlaintextPassword("s3cret")
+	appliances := map[string]DataPowerAppliance{"dev": dpa}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		logging.LogDebugf("appliance: "+format, dpa)
+		logging.LogDebugf("appliance pointer: "+format, &dpa)
+		logging.LogDebugf("appliances: "+format, appliances)
+	}
+
+	logBytes, err := ioutil.ReadFile(logging.FilePath)
+	assert.Equals(t, "ReadFile()", err, nil)
+	logLines := strings.Split(strings.TrimSpace(string(logBytes)), "\n")
+	assert.Equals(t, "log lines", len(logLines), 12)
+	for _, line := range logLines {
+		assert.Equals(t, "password masked in '"+line+"'", strings.Contains(line, dpa.Password), false)
+		assert.Equals(t, "appliance logged in '"+line+"'", strings.Contains(line, "https://h:5554"), true)
+		assert.Equals(t, "mask logged in '"+line+"'", strings.Contains(line, "***"), true)
+	}
+	assert.Equals(t, "DataPowerAppliance.String()", dpa.String(),
+		"{RestUrl:https://h:5554 SomaUrl: Username:admin Password:*** Domain: Proxy: CaBundle: "+
+			"ClientCert: ClientKey: CertFingerprint: VerifyHostname:false}")
+	assert.Equals(t, "DataPowerAppliance.GoString()",
+		strings.HasPrefix(dpa.GoString(), `config.DataPowerAppliance{RestUrl:"https://h:5554"`), true)
+	assert.Equals(t, "DataPowerAppliance.Password", dpa.DpPlaintextPassword(), "s3cret")
+}
+
+func TestMigrateConfig(t *testing.T) {
+	defer func(cmd Command, version int) {
+		Conf.Cmd, Conf.Version = cmd, version
+	}(Conf.Cmd, Conf.Version)
+
+	testDataMatrix := []struct {
+		name    string
+		version int
+		cmd     Command
+		want    Command
+	}{
+		{"old default viewer", 0, Command{Viewer: "less", Editor: "vi"}, Command{Editor: "vi"}},
+		{"custom viewer", 0, Command{Viewer: "more", Editor: "vi"}, Command{Viewer: "more", Editor: "vi"}},
+		{"viewer configured after migration", 1, Command{Viewer: "less"}, Command{Viewer: "less"}},
+		{"old default diff", 0, Command{Viewer: "less", Editor: "vi", Diff: "diff"}, Command{Editor: "vi"}},
+		{"custom diff", 0, Command{Diff: "ldiff"}, Command{Diff: "ldiff"}},
+		{"diff configured after migration", 1, Command{Diff: "diff"}, Command{Diff: "diff"}},
+	}
+	for _, testCase := range testDataMatrix {
+		Conf.Cmd, Conf.Version = testCase.cmd, testCase.version
+		migrateConfig()
+		assert.Equals(t, "migrateConfig() "+testCase.name, Conf.Cmd, testCase.want)
+		assert.Equals(t, "migrateConfig() version "+testCase.name, Conf.Version, configVersion)
+	}
+}
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"github.com/croz-ltd/dpcmder/utils/secret"
+	"github.com/howeyc/gopass"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// encryptedPasswordPrefix marks DataPower passwords encrypted with key
+	// derived from master passphrase in dpcmder JSON configuration file.
+	encryptedPasswordPrefix = "enc:"
+	// credentialsCheckValue is encrypted and saved to configuration to verify
+	// master passphrase.
+	credentialsCheckValue = "dpcmder"
+	// passphraseEnvVar is environment variable which can be used to set master
+	// passphrase (to avoid asking for passphrase when running commands).
+	passphraseEnvVar = "DPCMDER_PASSPHRASE"
+	// maxPassphraseAttempts is number of tries to enter master passphrase.
+	maxPassphraseAttempts = 3
+)
+
+// ErrCredentialsLocked is returned when DataPower password should be saved
+// but master passphrase is not entered yet.
+const ErrCredentialsLocked = errs.Error("Credential store is locked, master passphrase required.")
+
+// ErrWrongPassphrase is returned when master passphrase can't decrypt
+// credential store.
+const ErrWrongPassphrase = errs.Error("Wrong master passphrase.")
+
+// Credentials is a structure containing encrypted credential store settings -
+// salt used to derive key from master passphrase and encrypted check value
+// used to verify master passphrase.
+type Credentials struct {
+	Salt  string
+	Check string
+}
+
+// credentialsKey is key derived from master passphrase, cached in memory when
+// credential store is unlocked (once per dpcmder session).
+var credentialsKey []byte
+
+// MarshalJSON saves DataPowerAppliance to JSON with password encrypted using
+// key derived from master passphrase. Password is not saved if credential
+// store is locked (only encrypted passwords are saved).
+func (dpa DataPowerAppliance) MarshalJSON() ([]byte, error) {
+	type plainAppliance DataPowerAppliance
+	plain := plainAppliance(dpa)
+	if dpa.Password != "" && !strings.HasPrefix(dpa.Password, encryptedPasswordPrefix) {
+		if credentialsKey == nil {
+			logging.LogDebug("config/MarshalJSON() - Credential store is locked, password not saved.")
+			plain.Password = ""
+			return json.Marshal(plain)
+		}
+		encrypted, err := secret.Encrypt(credentialsKey, []byte(dpa.DpPlaintextPassword()))
+		if err != nil {
+			return nil, err
+		}
+		plain.Password = encryptedPasswordPrefix + encrypted
+	}
+	return json.Marshal(plain)
+}
+
+// CredentialsInitialized returns true if master passphrase is already set.
+func CredentialsInitialized() bool {
+	return Conf.Credentials.Salt != "" && Conf.Credentials.Check != ""
+}
+
+// CredentialsUnlocked returns true if master passphrase is entered in this
+// dpcmder session.
+func CredentialsUnlocked() bool {
+	return credentialsKey != nil
+}
+
+// UnlockCredentials unlocks credential store with master passphrase, decrypts
+// saved DataPower passwords and migrates old (only base32 encoded) passwords to
+// encrypted ones. If credential store is not initialized yet, passphrase
+// becomes new master passphrase. Credential store stays locked if any saved
+// password can't be decrypted.
+func UnlockCredentials(passphrase string) error {
+	logging.LogDebug("config/UnlockCredentials(..)")
+	var key []byte
+	credentials := Conf.Credentials
+	persistNeeded := false
+	if CredentialsInitialized() {
+		salt, err := base64.StdEncoding.DecodeString(Conf.Credentials.Salt)
+		if err != nil {
+			logging.LogDebug("config/UnlockCredentials(..) - Can't decode salt: ", err)
+			return err
+		}
+		key, err = secret.DeriveKey(passphrase, salt)
+		if err != nil {
+			return err
+		}
+		check, err := secret.Decrypt(key, Conf.Credentials.Check)
+		if err != nil || string(check) != credentialsCheckValue {
+			return ErrWrongPassphrase
+		}
+	} else {
+		var err error
+		key, credentials, err = newCredentialsKey(passphrase)
+		if err != nil {
+			return err
+		}
+		persistNeeded = true
+	}
+
+	appliances := make(map[string]DataPowerAppliance, len(Conf.DataPowerAppliances))
+	failedNames := make([]string, 0)
+	for name, dpa := range Conf.DataPowerAppliances {
+		appliances[name] = dpa
+		if dpa.Password == "" {
+			continue
+		}
+		if !strings.HasPrefix(dpa.Password, encryptedPasswordPrefix) {
+			logging.LogDebugf("config/UnlockCredentials(..) - migrating password for '%s'.", name)
+			persistNeeded = true
+			continue
+		}
+		password, err := secret.Decrypt(key,
+			strings.TrimPrefix(dpa.Password, encryptedPasswordPrefix))
+		if err != nil {
+			logging.LogDebugf("config/UnlockCredentials(..) - Can't decrypt password for '%s', err: %v", name, err)
+			failedNames = append(failedNames, name)
+			continue
+		}
+		dpa.SetDpPlaintextPassword(string(password))
+		appliances[name] = dpa
+	}
+	if len(failedNames) > 0 {
+		sort.Strings(failedNames)
+		return errs.Errorf("Can't decrypt saved password of DataPower appliance(s): %s.",
+			strings.Join(failedNames, ", "))
+	}
+
+	credentialsKey = key
+	Conf.Credentials = credentials
+	Conf.DataPowerAppliances = appliances
+	if persistNeeded {
+		persist()
+	}
+	return nil
+}
+
+// ChangeMasterPassphrase changes master passphrase and saves DataPower
+// passwords encrypted with new key to configuration file.
+func ChangeMasterPassphrase(newPassphrase string) error {
+	logging.LogDebug("config/ChangeMasterPassphrase(..)")
+	if !CredentialsUnlocked() {
+		return ErrCredentialsLocked
+	}
+	key, credentials, err := newCredentialsKey(newPassphrase)
+	if err != nil {
+		return err
+	}
+	credentialsKey = key
+	Conf.Credentials = credentials
+	persist()
+	return nil
+}
+
+// newCredentialsKey creates new salt, derives key from passphrase and returns
+// it with credential store settings (salt and check value used to verify
+// passphrase).
+func newCredentialsKey(passphrase string) ([]byte, Credentials, error) {
+	salt, err := secret.NewSalt()
+	if err != nil {
+		return nil, Credentials{}, err
+	}
+	key, err := secret.DeriveKey(passphrase, salt)
+	if err != nil {
+		return nil, Credentials{}, err
+	}
+	check, err := secret.Encrypt(key, []byte(credentialsCheckValue))
+	if err != nil {
+		return nil, Credentials{}, err
+	}
+	return key, Credentials{Salt: base64.StdEncoding.EncodeToString(salt), Check: check}, nil
+}
+
+// credentialsRequired returns true if credential store should be unlocked
+// during dpcmder start - if there are saved passwords or password given as
+// command line parameter will be saved.
+func credentialsRequired() bool {
+	if (*dpRestURL != "" || *dpSomaURL != "") && *dpPassword != "" {
+		return true
+	}
+	for _, dpa := range Conf.DataPowerAppliances {
+		if dpa.Password != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// initCredentials unlocks credential store during dpcmder start using master
+// passphrase from environment variable or asks user to enter it.
+func initCredentials() {
+	if !credentialsRequired() {
+		return
+	}
+
+	if passphrase, ok := os.LookupEnv(passphraseEnvVar); ok {
+		err := UnlockCredentials(passphrase)
+		if err != nil {
+			fmt.Printf("Can't unlock credential store using %s: %v\n\n", passphraseEnvVar, err)
+			usage(1)
+		}
+		return
+	}
+
+	if !CredentialsInitialized() {
+		passphrase := AskNewPassphrase("New dpcmder master passphrase (protects saved DataPower passwords): ")
+		err := UnlockCredentials(passphrase)
+		if err != nil {
+			fmt.Println("Can't initialize credential store: ", err)
+			fmt.Println()
+			usage(1)
+		}
+		return
+	}
+
+	for attempt := 1; attempt <= maxPassphraseAttempts; attempt++ {
+		passphrase := AskPassphrase()
+		err := UnlockCredentials(passphrase)
+		if err == nil {
+			return
+		}
+		fmt.Println(err)
+	}
+	fmt.Println("Can't unlock credential store!")
+	fmt.Println()
+	usage(1)
+}
+
+// AskNewPassphrase asks user (on console) to enter new master passphrase twice
+// and shows usage message in case passphrase is empty or not repeated correctly.
+func AskNewPassphrase(question string) string {
+	passphrase := askPassphrase(question)
+	if passphrase == "" {
+		fmt.Println("Master passphrase can't be empty!")
+		fmt.Println()
+		usage(1)
+	}
+	if askPassphrase("Repeat master passphrase: ") != passphrase {
+		fmt.Println("Master passphrases don't match!")
+		fmt.Println()
+		usage(1)
+	}
+	return passphrase
+}
+
+// AskPassphrase asks user (on console) to enter current master passphrase.
+func AskPassphrase() string {
+	return askPassphrase("dpcmder master passphrase: ")
+}
+
+// askPassphrase asks user to enter passphrase on console.
+func askPassphrase(question string) string {
+	fmt.Println(question)
+	pass, err := gopass.GetPasswdMasked()
+	if err != nil {
+		usage(1)
+	}
+	return string(pass)
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"encoding/json"
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"github.com/croz-ltd/dpcmder/utils/secret"
+	"strings"
+	"testing"
+)
+
+// resetCredentials prepares locked credential store with given appliances,
+// configuration is never saved to file (demo mode).
+func resetCredentials(appliances map[string]DataPowerAppliance) {
+	demoMode := true
+	DemoMode = &demoMode
+	credentialsKey = nil
+	Conf.Credentials = Credentials{}
+	Conf.DataPowerAppliances = appliances
+}
+
+// plainAppliance returns appliance with base32 encoded password.
+func plainAppliance(password string) DataPowerAppliance {
+	dpa := DataPowerAppliance{RestUrl: "https://dp:5554", Username: "admin"}
+	dpa.SetDpPlaintextPassword(password)
+	return dpa
+}
+
+// savedPassword returns password as it is saved to JSON configuration file.
+func savedPassword(t *testing.T, dpa DataPowerAppliance) string {
+	dpaJSON, err := json.Marshal(dpa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct{ Password string }
+	err = json.Unmarshal(dpaJSON, &saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return saved.Password
+}
+
+// encryptedAppliances returns appliances with passwords encrypted using given
+// passphrase (as loaded from configuration file) and locks credential store.
+func encryptedAppliances(t *testing.T, passphrase string, passwords map[string]string) map[string]DataPowerAppliance {
+	appliances := make(map[string]DataPowerAppliance)
+	for name, password := range passwords {
+		appliances[name] = plainAppliance(password)
+	}
+	resetCredentials(appliances)
+	err := UnlockCredentials(passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, dpa := range Conf.DataPowerAppliances {
+		dpa.Password = savedPassword(t, dpa)
+		appliances[name] = dpa
+	}
+	credentialsKey = nil
+	return appliances
+}
+
+func TestMarshalJSON(t *testing.T) {
+	resetCredentials(make(map[string]DataPowerAppliance))
+	testDataMatrix := []struct {
+		name     string
+		unlocked bool
+		password string
+		prefix   string
+	}{
+		{"locked, no password", false, "", ""},
+		{"locked, plaintext password not saved", false, "secret", ""},
+		{"locked, encrypted password kept", false, "enc:abc", "enc:abc"},
+		{"unlocked, no password", true, "", ""},
+		{"unlocked, plaintext password encrypted", true, "secret", "enc:"},
+		{"unlocked, encrypted password kept", true, "enc:abc", "enc:abc"},
+	}
+	for _, testCase := range testDataMatrix {
+		credentialsKey = nil
+		if testCase.unlocked {
+			key, _, err := newCredentialsKey("passphrase")
+			if err != nil {
+				t.Fatal(err)
+			}
+			credentialsKey = key
+		}
+		dpa := DataPowerAppliance{Password: testCase.password}
+		if testCase.password == "secret" {
+			dpa = plainAppliance(testCase.password)
+		}
+		saved := savedPassword(t, dpa)
+		assert.Equals(t, "MarshalJSON() "+testCase.name, strings.HasPrefix(saved, testCase.prefix), true)
+		if testCase.prefix == "" {
+			assert.Equals(t, "MarshalJSON() "+testCase.name, saved, "")
+		}
+		if testCase.prefix == "enc:" {
+			password, err := secret.Decrypt(credentialsKey, strings.TrimPrefix(saved, encryptedPasswordPrefix))
+			assert.Equals(t, "MarshalJSON() "+testCase.name, err, nil)
+			assert.Equals(t, "MarshalJSON() "+testCase.name, string(password), "secret")
+		}
+	}
+}
+
+func TestUnlockCredentials(t *testing.T) {
+	appliances := encryptedAppliances(t, "passphrase",
+		map[string]string{"dev": "dev-secret", "test": "test-secret"})
+	credentials := Conf.Credentials
+
+	testDataMatrix := []struct {
+		name       string
+		passphrase string
+		err        error
+		unlocked   bool
+	}{
+		{"wrong passphrase", "wrong", ErrWrongPassphrase, false},
+		{"empty passphrase", "", ErrWrongPassphrase, false},
+		{"correct passphrase", "passphrase", nil, true},
+	}
+	for _, testCase := range testDataMatrix {
+		resetCredentials(copyAppliances(appliances))
+		Conf.Credentials = credentials
+		err := UnlockCredentials(testCase.passphrase)
+		assert.Equals(t, "UnlockCredentials() "+testCase.name, err, testCase.err)
+		assert.Equals(t, "CredentialsUnlocked() "+testCase.name, CredentialsUnlocked(), testCase.unlocked)
+		dpa := Conf.DataPowerAppliances["dev"]
+		if testCase.unlocked {
+			assert.Equals(t, "UnlockCredentials() "+testCase.name, dpa.DpPlaintextPassword(), "dev-secret")
+		} else {
+			assert.Equals(t, "UnlockCredentials() "+testCase.name, dpa.Password, appliances["dev"].Password)
+		}
+	}
+}
+
+func TestUnlockCredentialsBadPassword(t *testing.T) {
+	appliances := encryptedAppliances(t, "passphrase",
+		map[string]string{"dev": "dev-secret", "test": "test-secret", "prod": "prod-secret"})
+	credentials := Conf.Credentials
+	for _, name := range []string{"test", "prod"} {
+		dpa := appliances[name]
+		dpa.Password = encryptedPasswordPrefix + "corrupted"
+		appliances[name] = dpa
+	}
+
+	resetCredentials(copyAppliances(appliances))
+	Conf.Credentials = credentials
+	err := UnlockCredentials("passphrase")
+	assert.Equals(t, "UnlockCredentials()", err.Error(),
+		"Can't decrypt saved password of DataPower appliance(s): prod, test.")
+	assert.Equals(t, "CredentialsUnlocked()", CredentialsUnlocked(), false)
+	assert.DeepEqual(t, "UnlockCredentials() appliances", Conf.DataPowerAppliances, appliances)
+}
+
+func TestUnlockCredentialsMigration(t *testing.T) {
+	resetCredentials(map[string]DataPowerAppliance{
+		"dev": plainAppliance("dev-secret"), "nopass": {RestUrl: "https://dp:5554"}})
+	assert.Equals(t, "CredentialsInitialized()", CredentialsInitialized(), false)
+	assert.Equals(t, "MarshalJSON() locked", savedPassword(t, Conf.DataPowerAppliances["dev"]), "")
+
+	err := UnlockCredentials("passphrase")
+	assert.Equals(t, "UnlockCredentials()", err, nil)
+	assert.Equals(t, "CredentialsInitialized()", CredentialsInitialized(), true)
+	assert.Equals(t, "CredentialsUnlocked()", CredentialsUnlocked(), true)
+	dpa := Conf.DataPowerAppliances["dev"]
+	assert.Equals(t, "UnlockCredentials() password", dpa.DpPlaintextPassword(), "dev-secret")
+	assert.Equals(t, "MarshalJSON() migrated",
+		strings.HasPrefix(savedPassword(t, dpa), encryptedPasswordPrefix), true)
+	assert.Equals(t, "MarshalJSON() no password", savedPassword(t, Conf.DataPowerAppliances["nopass"]), "")
+}
+
+func TestChangeMasterPassphrase(t *testing.T) {
+	resetCredentials(map[string]DataPowerAppliance{"dev": plainAppliance("dev-secret")})
+	assert.Equals(t, "ChangeMasterPassphrase() locked", ChangeMasterPassphrase("new"), ErrCredentialsLocked)
+
+	err := UnlockCredentials("old")
+	assert.Equals(t, "UnlockCredentials()", err, nil)
+	oldCredentials := Conf.Credentials
+	err = ChangeMasterPassphrase("new")
+	assert.Equals(t, "ChangeMasterPassphrase()", err, nil)
+	assert.Equals(t, "ChangeMasterPassphrase() salt changed", Conf.Credentials.Salt != oldCredentials.Salt, true)
+
+	dpa := Conf.DataPowerAppliances["dev"]
+	dpa.Password = savedPassword(t, dpa)
+	newCredentials := Conf.Credentials
+	for _, testCase := range []struct {
+		passphrase string
+		err        error
+	}{{"old", ErrWrongPassphrase}, {"new", nil}} {
+		resetCredentials(map[string]DataPowerAppliance{"dev": dpa})
+		Conf.Credentials = newCredentials
+		err = UnlockCredentials(testCase.passphrase)
+		assert.Equals(t, "UnlockCredentials('"+testCase.passphrase+"')", err, testCase.err)
+	}
+	unlockedDpa := Conf.DataPowerAppliances["dev"]
+	assert.Equals(t, "UnlockCredentials() password", unlockedDpa.DpPlaintextPassword(), "dev-secret")
+}
+
+// copyAppliances returns copy of appliances map.
+func copyAppliances(appliances map[string]DataPowerAppliance) map[string]DataPowerAppliance {
+	result := make(map[string]DataPowerAppliance, len(appliances))
+	for name, dpa := range appliances {
+		result[name] = dpa
+	}
+	return result
+}
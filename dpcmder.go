@@ -2,7 +2,9 @@
 package main
 
 import (
+	"github.com/croz-ltd/dpcmder/cli"
 	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/repo/dp/dpfake"
 	"github.com/croz-ltd/dpcmder/ui"
 	"github.com/croz-ltd/dpcmder/utils/logging"
 	"os"
@@ -12,12 +14,27 @@ import (
 
 func main() {
 	config.Init()
+	os.Exit(run())
+}
+
+// run starts dpcmder UI (or runs headless command) and returns exit status,
+// deferred cleanup is done before main() exits.
+func run() int {
+	if *config.DemoMode {
+		demoAppliance := dpfake.NewDemoAppliance()
+		defer demoAppliance.Close()
+		config.AddDemoAppliances(demoAppliance.Start(), dpfake.DemoUsername, dpfake.DemoPassword)
+	}
+	if len(config.HeadlessArgs) > 0 {
+		return cli.Run(config.HeadlessArgs)
+	}
 	config.PrintConfig()
 
 	setupCloseHandler()
 
 	ui.Start()
-	logging.LogDebug("main/main() - ...dpcmder ending.")
+	logging.LogDebug("main/run() - ...dpcmder ending.")
+	return 0
 }
 
 // setupCloseHandler creates a 'listener' on a new goroutine which will notify the
@@ -1,4 +1,5 @@
-// Package events contains model for events causing screen update.
+// Package events contains model for events causing screen update and
+// background job notifications.
 package events
 
 import (
@@ -32,3 +33,26 @@ type UpdateViewEvent struct {
 	ListSelectionList        []string
 	ListSelectionSelectedIdx int
 }
+
+// JobEventType is used for sending different background job notifications.
+type JobEventType int
+
+// All available background job event types.
+const (
+	JobProgress JobEventType = JobEventType(1)
+	JobFinished JobEventType = JobEventType(2)
+	JobFailed   JobEventType = JobEventType(3)
+	JobCanceled JobEventType = JobEventType(4)
+)
+
+// JobEvent contains information about background job state change.
+type JobEvent struct {
+	Type    JobEventType
+	JobID   int
+	JobName string
+	Message string
+}
+
+// JobEvents is channel used to notify UI about background job progress and
+// completion.
+var JobEvents = make(chan JobEvent, 100)
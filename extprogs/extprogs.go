@@ -1,12 +1,11 @@
 // Package extprogs contains code for calling external application used for
-// file viewing, edditing and comparing. File viewer is also used to display
-// help inside dpcmder application.
+// file viewing, edditing and comparing. External file viewer is optional,
+// built-in pager is used if viewer command is not configured.
 package extprogs
 
 import (
 	"bytes"
 	"github.com/croz-ltd/dpcmder/config"
-	"github.com/croz-ltd/dpcmder/help"
 	"github.com/croz-ltd/dpcmder/ui/out"
 	"github.com/croz-ltd/dpcmder/utils/errs"
 	"github.com/croz-ltd/dpcmder/utils/logging"
@@ -225,8 +224,3 @@ func Diff(leftPath string, rightPath string) error {
 
 	return nil
 }
-
-// ShowHelp shows help in configured external viewer.
-func ShowHelp() error {
-	return View("Help", []byte(help.Help))
-}
@@ -1,9 +1,16 @@
 // Package help contains help message listing all commands available in
 // dpcmder and how to setup external commands. Help message can be shown from
 // command line (if flag "-help" is given) or inside dpcmder application when
-// key "h" or any unrecognized key is pressed.
+// key "h" or any unrecognized key is pressed. Actions listed in help can be
+// bound to different keys so help message is generated from active key
+// bindings.
 package help
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Version (shoud be git tag), Platform & Time contains application information
 // which can be set during build time (using ldflags -X) and is shown
 // when "-v" flag is passed to dpcmder.
@@ -13,76 +20,184 @@ var (
 	BuildTime = ""
 )
 
-// Help contains dpcmder in-program help shown when 'h' or unrecognized key is pressed.
-const Help = `dpcmder Help
-
-ArrowUp / i          - move one item up
-ArrowDown / k        - move one item down
-Shift+ArrowUp / I    - select a current item and move one item up (can use Alt instead of Shift)
-Shift+ArrowDown / K  - select a current item and move one item down (can use Alt instead of Shift)
-PgUp / u             - move a page of items up
-PgDown / o           - move a page of items down
-Shift+PgUp / U       - select a current item and move a page of items up
-Shift+PgDown / O     - select a current item and move a page of items down
-Home / a             - move to the first item
-End / z              - move to the last item
-Shift+Home / A       - move to the first item and select all items from the current one to the first one
-Shift+End / Z        - move to the last item and select all items from the current one to the last one
-ArrowLeft / j        - scroll items left (useful for long names)
-ArrowRight / l       - scroll items right (useful for long names)
-Shift+ArrowLeft / J  - navigate to the previous view for the current side
-Shift+ArrowRight / L - navigate to the next view for the current side
-H                    - show view history list - can jump to any view in the current history
-Space                - select current item
-TAB                  - switch from left to right panel and vice versa
-Return               - enter directory
-F2/2                 - refresh focused pane (reload files/dirs)
-F3/3                 - view current file, DataPower configuration, DataPower
-                       object, DataPower status (or all statuses of same class)
-                       (see "Custom external commands" below)
-F4/4                 - edit file
-                       (see "Custom external commands" below)
-F5/5                 - copy the selected (or current if none selected) directories and files
-                     - if DataPower domain is selected create an export of the domain
-                     - if DataPower configuration is selected create an export of
-                       the whole appliance (SOMA only)
-                     - in DataPower object configuration mode copy DataPower
-                       object to file or copy file with proper object configuration
-                       to DataPower object (XML/JSON, depending on REST/SOMA
-                       management interface used)
-F7/7                 - create directory
-                     - create a new DataPower domain
-F8/8                 - create an empty file
-                     - create a new DataPower configuration
-F9/9                 - clone a current DataPower configuration under a new name
-                     - clone a current DataPower object under new name
-DEL/x                - delete selected (or current if none selected) directories and files
-                     - delete a DataPower configuration
-                     - delete a DataPower object
-d                    - diff current files/directories
-                       (should be "blocking" - see "Custom external commands" below)
-                     - diff changes on modified DataPower object (SOMA only)
-/                    - find string
-n                    - find next string
-N                    - find previous string
-f                    - filter visible items by a given string
-m                    - show all status messages saved in the history
-.                    - enter a location (full path) for the local file system
-s                    - auto-synchronize selected directories (local to DataPower)
-S                    - save running DataPower configuration (SOMA only)
-0                    - cycle between different DataPower view modes
-                       filestore mode view / object mode view / status mode view
-                     - when using SOMA access changed objects are marked and object
-                       changes can be shown using diff (d key)
-?                    - show status information for the current DataPower object,
-                       local directory or local file
-P                    - show DataPower policy for the current DataPower object
-                       (can be used only on service, policy, matches,rules & actions)
-                       exports the current DataPower object, analyzes it and
-                       shows service, policy, matches, rules and actions for
-                       the object
-h                    - show help
-q                    - quit
+// Action is dpcmder action which can be bound to keys ("Keys" configuration)
+// with its default keys and description lines shown in help.
+type Action struct {
+	Name        string
+	Keys        []string
+	Description []string
+}
+
+// Actions contains all actions which can be bound to keys (in order shown in
+// help).
+var Actions = []Action{
+	{Name: "up", Keys: []string{"ArrowUp", "i"},
+		Description: []string{"move one item up"}},
+	{Name: "down", Keys: []string{"ArrowDown", "k"},
+		Description: []string{"move one item down"}},
+	{Name: "selectUp", Keys: []string{"Shift+ArrowUp", "I"},
+		Description: []string{"select a current item and move one item up (can use Alt instead of Shift)"}},
+	{Name: "selectDown", Keys: []string{"Shift+ArrowDown", "K"},
+		Description: []string{"select a current item and move one item down (can use Alt instead of Shift)"}},
+	{Name: "pageUp", Keys: []string{"PgUp", "u"},
+		Description: []string{"move a page of items up"}},
+	{Name: "pageDown", Keys: []string{"PgDown", "o"},
+		Description: []string{"move a page of items down"}},
+	{Name: "selectPageUp", Keys: []string{"Shift+PgUp", "U"},
+		Description: []string{"select a current item and move a page of items up"}},
+	{Name: "selectPageDown", Keys: []string{"Shift+PgDown", "O"},
+		Description: []string{"select a current item and move a page of items down"}},
+	{Name: "top", Keys: []string{"Home", "a"},
+		Description: []string{"move to the first item"}},
+	{Name: "bottom", Keys: []string{"End", "z"},
+		Description: []string{"move to the last item"}},
+	{Name: "selectTop", Keys: []string{"Shift+Home", "A"},
+		Description: []string{"move to the first item and select all items from the current one to the first one"}},
+	{Name: "selectBottom", Keys: []string{"Shift+End", "Z"},
+		Description: []string{"move to the last item and select all items from the current one to the last one"}},
+	{Name: "scrollLeft", Keys: []string{"ArrowLeft", "j"},
+		Description: []string{"scroll items left (useful for long names)"}},
+	{Name: "scrollRight", Keys: []string{"ArrowRight", "l"},
+		Description: []string{"scroll items right (useful for long names)"}},
+	{Name: "prevView", Keys: []string{"Shift+ArrowLeft", "J"},
+		Description: []string{"navigate to the previous view for the current side"}},
+	{Name: "nextView", Keys: []string{"Shift+ArrowRight", "L"},
+		Description: []string{"navigate to the next view for the current side"}},
+	{Name: "viewHistory", Keys: []string{"H"},
+		Description: []string{"show view history list - can jump to any view in the current history"}},
+	{Name: "select", Keys: []string{"Space"},
+		Description: []string{"select current item"}},
+	{Name: "switchSide", Keys: []string{"Tab"},
+		Description: []string{"switch from left to right panel and vice versa"}},
+	{Name: "enter", Keys: []string{"Return"},
+		Description: []string{"enter directory"}},
+	{Name: "refresh", Keys: []string{"F2", "2"},
+		Description: []string{"refresh focused pane (reload files/dirs)"}},
+	{Name: "view", Keys: []string{"F3", "3"},
+		Description: []string{"view current file, DataPower configuration, DataPower",
+			"  object, DataPower status (or all statuses of same class)",
+			"  (see \"Built-in pager\" below)"}},
+	{Name: "edit", Keys: []string{"F4", "4"},
+		Description: []string{"edit file",
+			"  (see \"Custom external commands\" below)"}},
+	{Name: "copy", Keys: []string{"F5", "5"},
+		Description: []string{"copy the selected (or current if none selected) directories and files",
+			"- entries matching .dpcmderignore patterns (from the copied",
+			"  local directory) are skipped when copying directories",
+			"- files are copied in parallel (\"Copy.Workers\" configuration),",
+			"  copy continues if some file can't be copied and failures",
+			"  are listed in status messages at the end",
+			"- if file or object already exists conflict dialog is shown:",
+			"  overwrite (only if newer or only if content is different,",
+			"  compared byte by byte), skip, overwrite all (only if newer or",
+			"  only if content is different), skip all, rename target,",
+			"  show diff of source and target (before deciding) or abort copy",
+			"- if DataPower domain is selected create an export of the domain",
+			"- if DataPower configuration is selected create an export of",
+			"  the whole appliance (SOMA only)",
+			"- domain and appliance exports run as background jobs",
+			"- if local zip file is selected and DataPower domain is",
+			"  current item in DataPower view import domain export",
+			"- if local zip file is selected and DataPower configuration",
+			"  is current item in DataPower view import (restore) the",
+			"  whole appliance backup (SOMA only)",
+			"- in DataPower object configuration mode copy DataPower",
+			"  object to file or copy file with proper object configuration",
+			"  to DataPower object (XML/JSON, depending on REST/SOMA",
+			"  management interface used)"}},
+	{Name: "createDir", Keys: []string{"F7", "7"},
+		Description: []string{"create directory",
+			"- create a new DataPower domain"}},
+	{Name: "createFile", Keys: []string{"F8", "8"},
+		Description: []string{"create an empty file",
+			"- create a new DataPower configuration",
+			"- in DataPower object configuration mode create a new",
+			"  DataPower object of any class supported by the appliance",
+			"  (object skeleton with required properties is opened in editor)"}},
+	{Name: "clone", Keys: []string{"F9", "9"},
+		Description: []string{"clone a current DataPower configuration under a new name",
+			"- clone a current DataPower object under new name"}},
+	{Name: "delete", Keys: []string{"Del", "x"},
+		Description: []string{"delete selected (or current if none selected) directories and files",
+			"- delete a DataPower configuration",
+			"- delete a DataPower domain (domain name has to be entered",
+			"  to confirm deletion, 'default' domain can't be deleted)",
+			"- delete a DataPower object"}},
+	{Name: "diff", Keys: []string{"d"},
+		Description: []string{"diff current files/directories (in built-in diff viewer",
+			"  or \"blocking\" external diff - see \"Built-in diff viewer\" below)",
+			"- diff changes on modified DataPower object (SOMA only)"}},
+	{Name: "compare", Keys: []string{"="},
+		Description: []string{"compare directories shown in both panes (subdirectories",
+			"  recursively, files by size and content), mark items which",
+			"  exist only on one side (+) or are different (~) and select them",
+			"  so copy (F5) copies only the differences (files found the same",
+			"  inside different directories are skipped)",
+			"- compare DataPower object with the same object in other domain",
+			"  or on other DataPower appliance (in DataPower object mode)"}},
+	{Name: "search", Keys: []string{"/"},
+		Description: []string{"find string"}},
+	{Name: "searchNext", Keys: []string{"n"},
+		Description: []string{"find next string"}},
+	{Name: "searchPrev", Keys: []string{"N"},
+		Description: []string{"find previous string"}},
+	{Name: "filter", Keys: []string{"f"},
+		Description: []string{"filter visible items by a given string"}},
+	{Name: "messages", Keys: []string{"m"},
+		Description: []string{"show all status messages saved in the history"}},
+	{Name: "enterPath", Keys: []string{"."},
+		Description: []string{"enter a location (full path) for the local file system"}},
+	{Name: "sync", Keys: []string{"s"},
+		Description: []string{"auto-synchronize selected directories (local to DataPower)",
+			"- bidirectional sync mode also downloads files changed on",
+			"  DataPower and asks what to do with files changed on both sides",
+			"- with \"PropagateDeletes\" sync configuration set to true files",
+			"  and directories removed locally are also deleted from",
+			"  DataPower and files renamed locally are moved on DataPower",
+			"  (local to DataPower sync only)",
+			"- files matching .dpcmderignore patterns are not synced",
+			"- when sync profiles are configured local directory can be",
+			"  synced to all DataPower targets from selected profile at once"}},
+	{Name: "saveConfig", Keys: []string{"S"},
+		Description: []string{"save running DataPower configuration (SOMA only)"}},
+	{Name: "toggleMode", Keys: []string{"0"},
+		Description: []string{"cycle between different DataPower view modes",
+			"  filestore mode view / object mode view / status mode view",
+			"- when using SOMA access changed objects are marked and object",
+			"  changes can be shown using diff (d key)"}},
+	{Name: "info", Keys: []string{"?"},
+		Description: []string{"show status information for the current DataPower object,",
+			"  local directory or local file"}},
+	{Name: "policy", Keys: []string{"P"},
+		Description: []string{"show DataPower policy for the current DataPower object",
+			"  (can be used only on service, policy, matches,rules & actions)",
+			"  exports the current DataPower object, analyzes it and",
+			"  shows service, policy, matches, rules and actions for",
+			"  the object (analysis runs as background job, select finished",
+			"  job in jobs panel to view the result)"}},
+	{Name: "jobs", Keys: []string{"b"},
+		Description: []string{"show background jobs panel (running, finished and failed jobs)",
+			"- Enter opens finished job output (or job log) in viewer",
+			"- x cancels running job or removes finished job from the list"}},
+	{Name: "copyInBackground", Keys: []string{"B"},
+		Description: []string{"copy the selected (or current if none selected) directories and",
+			"  files as background job (how existing files are handled -",
+			"  skip, overwrite, overwrite only if newer or only if content",
+			"  is different - is chosen once before job is started)"}},
+	{Name: "help", Keys: []string{"h"},
+		Description: []string{"show help"}},
+	{Name: "quit", Keys: []string{"q", "Ctrl+C"},
+		Description: []string{"quit"}},
+}
+
+// keysColumnWidth is width of help column showing keys bound to action.
+const keysColumnWidth = 21
+
+// helpHeader and helpFooter are shown before and after actions in help.
+const helpHeader = `dpcmder Help
+
+`
+const helpFooter = `Esc                  - cancel running operation (while progress dialog is shown)
 any-other-char       - show help (+ hex value of the key pressed visible in the status bar)
 
 Navigational keys (except Left/Right can be used in combination with Shift for selections):
@@ -90,11 +205,19 @@ PgUp  Up  PgDn
 Left Down Right
 Home      End
 
-Alternative keys:
+Alternative keys (default key bindings):
 u i o
 j k l
 a   z
 
+Mouse:
+Click moves cursor (and focus) to the item clicked, double-click enters
+directory (domain, filestore, ...) or views file (object, status), Ctrl-click
+selects/deselects item, Shift-click selects items from the current item to the
+item clicked, mouse wheel scrolls items. In dialogs click selects list item or
+moves cursor, double-click accepts selection (answer) and click outside of the
+dialog cancels it.
+
 DataPower view mode (filestore / object / status):
 You can cycle though 3 available DataPower view mode (when focus is on the
 DataPower view).
@@ -102,10 +225,40 @@ DataPower view).
 - object view mode: view DataPower objects and make quick configuration changes.
 - status view mode: view DataPower statuses and flush xsl & document caches.
 
+Key bindings:
+Keys used for each action can be changed in "Keys" section of dpcmder
+configuration (~/.dpcmder/config.json) which maps action names to keys, for
+example:
+  "Keys": {"copy": ["F5", "c"], "diff": ["d", "Ctrl+D"], "toggleMode": ["0", "F6"]}
+Keys can be characters (like "c" or "?") or key names (ArrowUp, ArrowDown,
+ArrowLeft, ArrowRight, PgUp, PgDown, Home, End, Insert, Del, Return, Tab,
+Backspace, Space, F1-F12) with optional "Shift+", "Ctrl+" or "Alt+" modifiers.
+Actions (with default keys): %s.
+Esc, Return and arrow keys used in dialogs can't be changed.
+
+Built-in pager:
+Files, DataPower objects, statuses, help and other texts are shown in built-in
+pager if Viewer command is not configured. Navigational keys scroll the text,
+search keys (/, n, N) search for text, "#" shows line numbers, "w" wraps long
+lines, "p" pretty prints XML or JSON, "q" or Esc closes the pager. XML, XSLT,
+JSON and JavaScript (GatewayScript) syntax is highlighted.
+
+Built-in diff viewer:
+Files, directories (compared recursively) and changes of DataPower objects
+(saved vs running configuration or the same object in other domain or on other
+appliance) are compared in built-in diff viewer if Diff
+command is not configured. Changes are shown in colored unified view, "s"
+switches between unified and side-by-side view, search next/previous keys
+(n, N) jump between changes, "q" or Esc closes the diff viewer.
+
 Custom external commands (Viewer/Editor/Diff):
 dpcmder configuration is saved to ~/.dpcmder/config.json where commands used for
-calling external commands are set. By default, these are "less", "vi" and "diff"
-but could be any commands. All of those commands should be started in the
+calling external commands are set. By default, Viewer and Diff are not set
+(built-in pager and diff viewer are used) and Editor is "vi" but could be any commands.
+Older dpcmder versions saved Viewer "less" and Diff "diff" to configuration,
+they are removed once when such configuration is read (set them again to keep
+using those commands). All
+of those commands should be started in the
 foreground and should wait for the user's input to complete. For example for
 viewers "less" or "more" can be used while "cat" will not work. For file
 comparison, normal "diff" command can be used as a workaround but "blocking" diff
@@ -124,13 +277,69 @@ Some of the DataPower information is not available using REST interface.
 Some new features added are SOMA-only. For example, with REST you can't compare
 persisted DataPower object configuration to saved configuration.
 
-TODO:
-- add domain/appliance import?
-- add creation of new DataPower objects
-  (should be able to show/create all classes of objects, even ones without
-  object instances)
-- add deletion of a domain (with maybe 2 confirmation dialogs: are you sure?;
-  are you really sure?)
-
 (to show dpcmder usage help use "-h" flag instead of "-help" flag)
 `
+
+// Help contains dpcmder in-program help shown when 'h' or unrecognized key is
+// pressed (generated again when key bindings are read from configuration).
+var Help = Text(DefaultKeys())
+
+// DefaultKeys returns default key bindings for all actions.
+func DefaultKeys() map[string][]string {
+	keys := make(map[string][]string, len(Actions))
+	for _, action := range Actions {
+		keys[action.Name] = append([]string(nil), action.Keys...)
+	}
+	return keys
+}
+
+// FindAction returns action with given name.
+func FindAction(name string) (Action, bool) {
+	for _, action := range Actions {
+		if action.Name == name {
+			return action, true
+		}
+	}
+	return Action{}, false
+}
+
+// Text generates help text showing keys bound to each action.
+func Text(keys map[string][]string) string {
+	var sb strings.Builder
+	sb.WriteString(helpHeader)
+	actionNames := make([]string, len(Actions))
+	for idx, action := range Actions {
+		actionKeys := strings.Join(keys[action.Name], " / ")
+		if actionKeys == "" {
+			actionKeys = "(not bound)"
+		}
+		sb.WriteString(fmt.Sprintf("%-*s- %s\n", keysColumnWidth, actionKeys+" ", action.Description[0]))
+		for _, line := range action.Description[1:] {
+			sb.WriteString(strings.Repeat(" ", keysColumnWidth) + line + "\n")
+		}
+		actionNames[idx] = fmt.Sprintf("%s (%s)", action.Name, strings.Join(action.Keys, ", "))
+	}
+	sb.WriteString(fmt.Sprintf(helpFooter, wrapText(strings.Join(actionNames, ", "), 80, len("Actions (with default keys): "))))
+	return sb.String()
+}
+
+// wrapText wraps text to lines of given width (first line is shorter by
+// given prefix length).
+func wrapText(text string, width, prefixLen int) string {
+	var sb strings.Builder
+	lineLen := prefixLen
+	for idx, word := range strings.Fields(text) {
+		if idx > 0 {
+			if lineLen+1+len(word) > width {
+				sb.WriteString("\n")
+				lineLen = 0
+			} else {
+				sb.WriteString(" ")
+				lineLen++
+			}
+		}
+		sb.WriteString(word)
+		lineLen += len(word)
+	}
+	return sb.String()
+}
@@ -0,0 +1,48 @@
+package help
+
+import (
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	text := Text(map[string][]string{
+		"up":   {"Ctrl+P", "w"},
+		"copy": {"F5"},
+		"quit": {"Alt+F4 is too long for keys column"},
+	})
+
+	testDataMatrix := []struct {
+		line  string
+		found bool
+	}{
+		{"Ctrl+P / w           - move one item up", true},
+		{"ArrowUp / i          - move one item up", false},
+		{"F5                   - copy the selected (or current if none selected)", true},
+		{"(not bound)          - move one item down", true},
+		{"Alt+F4 is too long for keys column - quit", true},
+		{"Actions (with default keys): up (ArrowUp, i), down (ArrowDown, k),", true},
+	}
+	lines := strings.Split(text, "\n")
+	for _, testCase := range testDataMatrix {
+		found := false
+		for _, line := range lines {
+			if strings.HasPrefix(line, testCase.line) {
+				found = true
+				break
+			}
+		}
+		assert.Equals(t, "Text() line '"+testCase.line+"'", found, testCase.found)
+	}
+	assert.Equals(t, "Text() header", strings.HasPrefix(text, helpHeader), true)
+}
+
+func TestTextDefaultKeys(t *testing.T) {
+	text := Text(DefaultKeys())
+	for _, action := range Actions {
+		assert.Equals(t, "Text() "+action.Name,
+			strings.Contains(text, strings.Join(action.Keys, " / ")), true)
+	}
+	assert.Equals(t, "Text() not bound", strings.Contains(text, "(not bound)"), false)
+}
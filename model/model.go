@@ -84,9 +84,31 @@ type Item struct {
 	Size     string
 	Modified string
 	Selected bool
+	Compared ItemCompareResult
 	Config   *ItemConfig
 }
 
+// ItemCompareResult is result of comparing item to the item with the same name
+// shown on the other side (compare panes).
+type ItemCompareResult byte
+
+// Available compare results, items which are the same on both sides are not
+// marked (NotCompared).
+const (
+	NotCompared      = ItemCompareResult(0)
+	CompareOnlyHere  = ItemCompareResult('+')
+	CompareDifferent = ItemCompareResult('~')
+)
+
+// Marker returns single character string shown next to item type for compare
+// result.
+func (r ItemCompareResult) Marker() string {
+	if r == NotCompared {
+		return " "
+	}
+	return string(r)
+}
+
 // ItemConfig contains information about File, Directory, DataPower filestore,
 // DataPower domain or DataPower configuration which is required to uniquely
 // identify Item.
@@ -130,7 +152,9 @@ type Model struct {
 	HorizScroll         int
 	SearchBy            string
 	SyncModeOn          bool
+	SyncProfile         string
 	SyncInitial         bool
+	SyncBidirectional   bool
 	SyncDpDomain        string
 	SyncDirDp           string
 	SyncDirLocal        string
@@ -203,8 +227,21 @@ func (item Item) String() string {
 
 // DisplayString method returns formatted string representing how item will be shown.
 func (item Item) DisplayString() string {
-	return fmt.Sprintf("%s %10s %19s %s",
-		item.GetDisplayableType(), item.Size, item.Modified, item.Name)
+	return fmt.Sprintf("%s%s%10s %19s %s",
+		item.GetDisplayableType(), item.Compared.Marker(), item.Size, item.Modified, item.Name)
+}
+
+// IsModified returns true if item is DataPower object (or domain) with changes
+// which are not saved to persisted configuration.
+func (item Item) IsModified() bool {
+	return item.Modified == "*" ||
+		(item.Config != nil && item.Config.Type == ItemDpObject && item.Modified != "")
+}
+
+// IsDown returns true if item is DataPower object (or domain) which is down.
+func (item Item) IsDown() bool {
+	return item.Size == "down" ||
+		(item.Config != nil && item.Config.DpObjectState.OpState == "down")
 }
 
 // GetDisplayableType retuns single character string representation of Item type.
@@ -570,6 +607,42 @@ func (m *Model) ToggleSide() {
 	}
 }
 
+// SetCurrSide sets side used.
+func (m *Model) SetCurrSide(side Side) {
+	m.currSide = side
+}
+
+// NavToRow moves cursor of given side to item shown in given row, returns
+// false if no item is shown in that row.
+func (m *Model) NavToRow(side Side, rowIdx int) bool {
+	if rowIdx < 0 || rowIdx >= m.GetVisibleItemCount(side) {
+		return false
+	}
+	m.currItemIdx[side] = m.currFirstRowItemIdx[side] + rowIdx
+	return true
+}
+
+// ScrollView scrolls items of given side by given number of rows (up if move
+// is negative) and moves cursor only if it would not be visible any more.
+func (m *Model) ScrollView(side Side, move int) {
+	maxRows := m.GetVisibleItemCount(side)
+	firstIdx := m.currFirstRowItemIdx[side] + move
+	if firstIdx > len(m.items[side])-maxRows {
+		firstIdx = len(m.items[side]) - maxRows
+	}
+	if firstIdx < 0 {
+		firstIdx = 0
+	}
+	m.currFirstRowItemIdx[side] = firstIdx
+
+	switch {
+	case m.currItemIdx[side] < firstIdx:
+		m.currItemIdx[side] = firstIdx
+	case m.currItemIdx[side] > firstIdx+maxRows-1 && maxRows > 0:
+		m.currItemIdx[side] = firstIdx + maxRows - 1
+	}
+}
+
 // NavUp moves cursor one item up if possible.
 func (m *Model) NavUp() {
 	m.navUpDown(m.currSide, -1)
@@ -647,6 +720,19 @@ func (m *Model) SelToBottom() {
 	m.selRange(firstIdx, lastIdx)
 }
 
+// SelToRow selects all items from current one to item shown in given row.
+func (m *Model) SelToRow(rowIdx int) {
+	if rowIdx < 0 || rowIdx >= m.GetVisibleItemCount(m.currSide) {
+		return
+	}
+	firstIdx := m.currItemIdx[m.currSide]
+	lastIdx := m.currFirstRowItemIdx[m.currSide] + rowIdx
+	if firstIdx > lastIdx {
+		firstIdx, lastIdx = lastIdx, firstIdx
+	}
+	m.selRange(firstIdx, lastIdx)
+}
+
 func (m *Model) navUpDown(side Side, move int) {
 	newCurr := m.currItemIdx[side] + move
 	logging.LogDebugf(
@@ -679,6 +765,17 @@ func (m *Model) SortSide(side Side) {
 	sort.Sort(m.items[side])
 }
 
+// MarkCompared sets compare results of items for given side (items not found
+// in results are the same as items on the other side) and selects all
+// different items.
+func (m *Model) MarkCompared(side Side, results map[string]ItemCompareResult) {
+	for idx := range m.items[side] {
+		item := &m.items[side][idx]
+		item.Compared = results[item.Name]
+		item.Selected = item.Compared != NotCompared && item.Name != ".."
+	}
+}
+
 // GetSelectedItems returns all selected items for given side.
 // It skips parent directories since we don't want to perform any
 // actions (except navigation) on parent directory.
@@ -89,6 +89,29 @@ func TestItemDisplayString(t *testing.T) {
 	got := item.DisplayString()
 	want := "f       3000 2019-02-06 14:06:10 master"
 	assert.DeepEqual(t, "Item.GetDisplayableType()", got, want)
+
+	item.Compared = CompareDifferent
+	got = item.DisplayString()
+	want = "f~      3000 2019-02-06 14:06:10 master"
+	assert.DeepEqual(t, "Item.GetDisplayableType() compared", got, want)
+}
+func TestItemIsModifiedIsDown(t *testing.T) {
+	testDataMatrix := []struct {
+		item     Item
+		modified bool
+		down     bool
+	}{
+		{Item{Config: &ItemConfig{Type: ItemFile}, Size: "3000", Modified: "2019-02-06 14:06:10"}, false, false},
+		{Item{Config: &ItemConfig{Type: ItemDpDomain}, Size: "down", Modified: "*"}, true, true},
+		{Item{Config: &ItemConfig{Type: ItemDpDomain}}, false, false},
+		{Item{Config: &ItemConfig{Type: ItemDpObject, DpObjectState: ItemDpObjectState{OpState: "down"}},
+			Size: "down", Modified: "modified"}, true, true},
+		{Item{Config: &ItemConfig{Type: ItemDpObject, DpObjectState: ItemDpObjectState{OpState: "up"}}}, false, false},
+	}
+	for _, testCase := range testDataMatrix {
+		assert.DeepEqual(t, "Item.IsModified()", testCase.item.IsModified(), testCase.modified)
+		assert.DeepEqual(t, "Item.IsDown()", testCase.item.IsDown(), testCase.down)
+	}
 }
 func TestItemGetDisplayableType(t *testing.T) {
 	itemList := prepareAllTypesItemList()
@@ -703,6 +726,49 @@ func TestModelSelect(t *testing.T) {
 
 }
 
+func TestModelNavToRowAndScroll(t *testing.T) {
+	model := Model{}
+	side := model.CurrSide()
+
+	model.ItemMaxRows = 4
+	items := prepareItemList()
+	model.SetItems(side, items)
+	model.SetItems(Right, items[:2])
+
+	assert.DeepEqual(t, "NavToRow(2)", model.NavToRow(side, 2), true)
+	checkCurrItem(t, model, items[2], "NavToRow(2)")
+	assert.DeepEqual(t, "NavToRow(4)", model.NavToRow(side, 4), false)
+	assert.DeepEqual(t, "NavToRow(-1)", model.NavToRow(side, -1), false)
+	checkCurrItem(t, model, items[2], "NavToRow(4)")
+
+	model.ScrollView(side, 3)
+	checkCurrItem(t, model, items[3], "ScrollView(3)")
+	checkCurrentRow(t, model, side, 0, true)
+	model.ScrollView(side, 10)
+	checkCurrItem(t, model, items[6], "ScrollView(10)")
+	checkCurrentRow(t, model, side, 0, true)
+	assert.DeepEqual(t, "NavToRow(3)", model.NavToRow(side, 3), true)
+	checkCurrItem(t, model, items[9], "NavToRow(3) after scroll")
+	model.ScrollView(side, -3)
+	checkCurrItem(t, model, items[6], "ScrollView(-3)")
+	checkCurrentRow(t, model, side, 3, true)
+	model.ScrollView(side, -10)
+	checkCurrItem(t, model, items[3], "ScrollView(-10)")
+	checkCurrentRow(t, model, side, 3, true)
+
+	model.NavToRow(side, 1)
+	model.SelToRow(3)
+	assert.DeepEqual(t, "SelToRow(3)", len(model.GetSelectedItems(side)), 3)
+	model.SelToRow(0)
+	assert.DeepEqual(t, "SelToRow(0)", len(model.GetSelectedItems(side)), 2)
+
+	model.SetCurrSide(Right)
+	assert.DeepEqual(t, "SetCurrSide(Right)", model.CurrSide(), Right)
+	assert.DeepEqual(t, "NavToRow(Right, 2)", model.NavToRow(Right, 2), false)
+	model.ScrollView(Right, 3)
+	checkCurrItem(t, model, items[0], "ScrollView(Right, 3)")
+}
+
 func checkCurrentRow(t *testing.T, model Model, side Side, row int, want bool) {
 	t.Helper()
 	got := model.IsCurrentRow(side, row)
@@ -882,3 +948,20 @@ func TestModelStatusHandling(t *testing.T) {
 	assert.DeepEqual(t, "LastStatus()", fmt.Sprintf("Status event new no %d", maxStatusCount-1), model.LastStatus())
 	assert.DeepEqual(t, "Statuses() size", maxStatusCount, len(model.Statuses()))
 }
+
+func TestModelMarkCompared(t *testing.T) {
+	model := Model{}
+	model.ItemMaxRows = 20
+	model.SetItems(Left, prepareItemList())
+	model.ToggleCurrItem()
+
+	model.MarkCompared(Left, map[string]ItemCompareResult{
+		"Micro": CompareOnlyHere, "Blob": CompareDifferent, "..": CompareDifferent})
+	selected := model.GetSelectedItems(Left)
+	assert.DeepEqual(t, "MarkCompared() selected", len(selected), 2)
+	assert.DeepEqual(t, "MarkCompared() selected 0", selected[0].Name, "Micro")
+	assert.DeepEqual(t, "MarkCompared() selected 0 result", selected[0].Compared, CompareOnlyHere)
+	assert.DeepEqual(t, "MarkCompared() selected 1", selected[1].Name, "Blob")
+	assert.DeepEqual(t, "MarkCompared() selected 1 result", selected[1].Compared, CompareDifferent)
+	assert.DeepEqual(t, "MarkCompared() same item", model.GetVisibleItem(Left, 0).Compared, NotCompared)
+}
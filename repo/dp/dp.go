@@ -4,7 +4,7 @@ package dp
 import (
 	"archive/zip"
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -13,14 +13,15 @@ import (
 	"github.com/clbanning/mxj"
 	"github.com/croz-ltd/dpcmder/config"
 	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/repo/localfs"
 	"github.com/croz-ltd/dpcmder/utils/errs"
 	"github.com/croz-ltd/dpcmder/utils/logging"
 	"github.com/croz-ltd/dpcmder/utils/paths"
 	"github.com/savaki/jq"
+	"html"
 	"io"
 	"io/ioutil"
 	"net/http"
-	"net/url"
 	"regexp"
 	"sort"
 	"strings"
@@ -35,8 +36,20 @@ type dpApplicance struct {
 	config.DataPowerAppliance
 }
 
-// dpRepo contains basic DataPower repo information and implements Repo interface.
-type dpRepo struct {
+// String returns DataPower appliance as shown in log lines (password is
+// masked by config.DataPowerAppliance).
+func (dpa dpApplicance) String() string {
+	return fmt.Sprintf("{name:%s DataPowerAppliance:%v}", dpa.name, dpa.DataPowerAppliance)
+}
+
+// GoString returns DataPower appliance as shown in log lines using "%#v"
+// format (password is masked by config.DataPowerAppliance).
+func (dpa dpApplicance) GoString() string {
+	return fmt.Sprintf("dp.dpApplicance{name:%q, DataPowerAppliance:%#v}", dpa.name, dpa.DataPowerAppliance)
+}
+
+// DataPowerRepo contains basic DataPower repo information and implements Repo interface.
+type DataPowerRepo struct {
 	name               string
 	dpFilestoreXmls    map[string]string
 	invalidateCache    bool
@@ -47,14 +60,30 @@ type dpRepo struct {
 
 // Repo is instance or DataPower repo/Repo interface implementation used for all
 // operations on DataPower except syncing local filesystem to DataPower.
-var Repo = dpRepo{name: "DataPower", dpFilestoreXmls: make(map[string]string),
+var Repo = DataPowerRepo{name: "DataPower", dpFilestoreXmls: make(map[string]string),
 	DpViewMode: model.DpFilestoreMode, req: netRequester{}}
 
 // SyncRepo is instance or DataPower repo/Repo interface implementation used for
 // syncing local directory to DataPower directory.
-var SyncRepo = dpRepo{name: "SyncDataPower", dpFilestoreXmls: make(map[string]string),
+var SyncRepo = DataPowerRepo{name: "SyncDataPower", dpFilestoreXmls: make(map[string]string),
 	DpViewMode: model.DpFilestoreMode, req: netRequester{}}
 
+// NewRepo creates new instance of DataPower repo with its own connection,
+// used for syncing local directory to one of many DataPower locations or to
+// access other DataPower appliance (or domain) while current one is shown.
+func NewRepo(name string, viewMode model.DpViewMode) *DataPowerRepo {
+	return &DataPowerRepo{name: name, dpFilestoreXmls: make(map[string]string),
+		DpViewMode: viewMode, req: netRequester{}}
+}
+
+// Clone creates new instance of DataPower repo connected to the same DataPower
+// appliance and using the same view mode, used for background jobs so user can
+// keep browsing (and switch appliances) while job is running.
+func (r *DataPowerRepo) Clone() *DataPowerRepo {
+	return &DataPowerRepo{name: r.name, dpFilestoreXmls: make(map[string]string),
+		dataPowerAppliance: r.dataPowerAppliance, DpViewMode: r.DpViewMode, req: r.req}
+}
+
 // dpDomainInfo contains domain name and basic state
 type dpDomainInfo struct {
 	name       string
@@ -68,11 +97,11 @@ const (
 	objectStatusExternal = "external"
 )
 
-func (r *dpRepo) String() string {
+func (r *DataPowerRepo) String() string {
 	return r.name
 }
 
-func (r *dpRepo) GetInitialItem() (model.Item, error) {
+func (r *DataPowerRepo) GetInitialItem(ctx context.Context) (model.Item, error) {
 	logging.LogDebugf("repo/dp/GetInitialItem(), dataPowerAppliance: %#v", r.dataPowerAppliance)
 	var initialConfig model.ItemConfig
 	initialViewName := "List appliance configurations"
@@ -101,7 +130,7 @@ func (r *dpRepo) GetInitialItem() (model.Item, error) {
 	return initialItem, nil
 }
 
-func (r *dpRepo) GetTitle(itemToShow *model.ItemConfig) string {
+func (r *DataPowerRepo) GetTitle(itemToShow *model.ItemConfig) string {
 	logging.LogDebugf("repo/dp/GetTitle(%v)", itemToShow)
 	dpConfigName := itemToShow.DpAppliance
 	dpDomain := itemToShow.DpDomain
@@ -140,7 +169,7 @@ func getDpAppliance(itemToShow *model.ItemConfig) dpApplicance {
 		return dpApplicance{}
 	}
 }
-func (r *dpRepo) GetList(itemToShow *model.ItemConfig) (model.ItemList, error) {
+func (r *DataPowerRepo) GetList(ctx context.Context, itemToShow *model.ItemConfig) (model.ItemList, error) {
 	logging.LogDebugf("repo/dp/GetList(%v), r.DpViewMode: %s", itemToShow, r.DpViewMode)
 
 	switch r.DpViewMode {
@@ -158,10 +187,10 @@ func (r *dpRepo) GetList(itemToShow *model.ItemConfig) (model.ItemList, error) {
 		switch itemToShow.Type {
 		case model.ItemDpObjectClassList:
 			r.dataPowerAppliance = getDpAppliance(itemToShow)
-			return r.listObjectClasses(itemToShow)
+			return r.listObjectClasses(ctx, itemToShow)
 		case model.ItemDpObjectClass:
 			r.dataPowerAppliance = getDpAppliance(itemToShow)
-			return r.listObjects(itemToShow)
+			return r.listObjects(ctx, itemToShow)
 		default:
 			logging.LogDebugf("repo/dp/GetList(%v) - can't get children or item for DpViewMode: %s.",
 				itemToShow, r.DpViewMode)
@@ -182,10 +211,10 @@ func (r *dpRepo) GetList(itemToShow *model.ItemConfig) (model.ItemList, error) {
 		switch itemToShow.Type {
 		case model.ItemDpStatusClassList:
 			r.dataPowerAppliance = getDpAppliance(itemToShow)
-			return r.listStatusClasses(itemToShow)
+			return r.listStatusClasses(ctx, itemToShow)
 		case model.ItemDpStatusClass:
 			r.dataPowerAppliance = getDpAppliance(itemToShow)
-			return r.listStatuses(itemToShow)
+			return r.listStatuses(ctx, itemToShow)
 		default:
 			wrongView := r.DpViewMode
 			logging.LogDebugf("repo/dp/GetList(%v) - can't get children or item for DpViewMode: %s.",
@@ -204,18 +233,18 @@ func (r *dpRepo) GetList(itemToShow *model.ItemConfig) (model.ItemList, error) {
 		case model.ItemDpConfiguration:
 			r.dataPowerAppliance = getDpAppliance(itemToShow)
 			if itemToShow.DpDomain != "" {
-				return r.listFilestores(itemToShow)
+				return r.listFilestores(ctx, itemToShow)
 			}
-			return r.listDomains(itemToShow)
+			return r.listDomains(ctx, itemToShow)
 		case model.ItemDpDomain:
 			r.dataPowerAppliance = getDpAppliance(itemToShow)
-			return r.listFilestores(itemToShow)
+			return r.listFilestores(ctx, itemToShow)
 		case model.ItemDpFilestore:
 			r.dataPowerAppliance = getDpAppliance(itemToShow)
-			return r.listDpDir(itemToShow)
+			return r.listDpDir(ctx, itemToShow)
 		case model.ItemDirectory:
 			r.dataPowerAppliance = getDpAppliance(itemToShow)
-			return r.listDpDir(itemToShow)
+			return r.listDpDir(ctx, itemToShow)
 		default:
 			logging.LogDebugf("repo/dp/GetList(%v) - can't get children or item for DpViewMode: %s.",
 				itemToShow, r.DpViewMode)
@@ -226,31 +255,31 @@ func (r *dpRepo) GetList(itemToShow *model.ItemConfig) (model.ItemList, error) {
 	}
 }
 
-func (r *dpRepo) InvalidateCache() {
+func (r *DataPowerRepo) InvalidateCache() {
 	logging.LogDebugf("repo/dp/InvalidateCache()")
 	if r.dataPowerAppliance.SomaUrl != "" {
 		r.invalidateCache = true
 	}
 }
 
-func (r *dpRepo) GetFile(currentView *model.ItemConfig, fileName string) ([]byte, error) {
+func (r *DataPowerRepo) GetFile(ctx context.Context, currentView *model.ItemConfig, fileName string) ([]byte, error) {
 	logging.LogDebugf("repo/dp/GetFile(%v, '%s')", currentView, fileName)
 	parentPath := currentView.Path
 	filePath := paths.GetDpPath(parentPath, fileName)
 	r.dataPowerAppliance = getDpAppliance(currentView)
 
-	return r.GetFileByPath(currentView.DpDomain, filePath)
+	return r.GetFileByPath(ctx, currentView.DpDomain, filePath)
 }
 
 // GetFileByPath fetches file from DataPower by it's domain and path.
-func (r *dpRepo) GetFileByPath(dpDomain, filePath string) ([]byte, error) {
+func (r *DataPowerRepo) GetFileByPath(ctx context.Context, dpDomain, filePath string) ([]byte, error) {
 	logging.LogDebugf("repo/dp/GetFile('%s', '%s')", dpDomain, filePath)
 
 	switch r.dataPowerAppliance.DpManagmentInterface() {
 	case config.DpInterfaceRest:
 		restPath := makeRestPath(dpDomain, filePath)
 
-		fileB64, _, err := r.restGetForOneResult(restPath, "/file")
+		fileB64, _, err := r.restGetForOneResult(ctx, restPath, "/file")
 		if err != nil {
 			return nil, err
 		}
@@ -270,7 +299,7 @@ func (r *dpRepo) GetFileByPath(dpDomain, filePath string) ([]byte, error) {
 		</man:request>
 	</soapenv:Body>
 </soapenv:Envelope>`, dpDomain, filePath)
-		somaResponse, err := r.soma(somaRequest)
+		somaResponse, err := r.soma(ctx, somaRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -300,16 +329,16 @@ func (r *dpRepo) GetFileByPath(dpDomain, filePath string) ([]byte, error) {
 	}
 }
 
-func (r *dpRepo) UpdateFile(currentView *model.ItemConfig, fileName string, newFileContent []byte) (bool, error) {
+func (r *DataPowerRepo) UpdateFile(ctx context.Context, currentView *model.ItemConfig, fileName string, newFileContent []byte) (bool, error) {
 	logging.LogDebugf("repo/dp/UpdateFile(%s, '%s', ...)\n", currentView, fileName)
 	parentPath := currentView.Path
 	filePath := paths.GetDpPath(parentPath, fileName)
 	r.dataPowerAppliance = getDpAppliance(currentView)
-	return r.UpdateFileByPath(currentView.DpDomain, filePath, newFileContent)
+	return r.UpdateFileByPath(ctx, currentView.DpDomain, filePath, newFileContent)
 }
-func (r *dpRepo) UpdateFileByPath(dpDomain, filePath string, newFileContent []byte) (bool, error) {
+func (r *DataPowerRepo) UpdateFileByPath(ctx context.Context, dpDomain, filePath string, newFileContent []byte) (bool, error) {
 	logging.LogDebugf("repo/dp/UpdateFileByPath('%s', '%s', ...)", dpDomain, filePath)
-	fileType, err := r.GetFileTypeByPath(dpDomain, filePath, ".")
+	fileType, err := r.GetFileTypeByPath(ctx, dpDomain, filePath, ".")
 	logging.LogDebugf("repo/dp/UpdateFileByPath() fileType: %s", fileType)
 	if err != nil {
 		return false, err
@@ -340,7 +369,7 @@ func (r *dpRepo) UpdateFileByPath(dpDomain, filePath string, newFileContent []by
 		requestBody := "{\"file\":{\"name\":\"" + fileName + "\",\"content\":\"" + base64.StdEncoding.EncodeToString(newFileContent) + "\"}}"
 
 		restPath := makeRestPath(dpDomain, updateFilePath)
-		jsonString, err := r.rest(restPath, restMethod, requestBody)
+		jsonString, err := r.rest(ctx, restPath, restMethod, requestBody)
 		if err != nil {
 			return false, err
 		}
@@ -369,7 +398,7 @@ func (r *dpRepo) UpdateFileByPath(dpDomain, filePath string, newFileContent []by
 		</man:request>
 	</soapenv:Body>
 </soapenv:Envelope>`, dpDomain, filePath, base64.StdEncoding.EncodeToString(newFileContent))
-			somaResponse, err := r.soma(somaRequest)
+			somaResponse, err := r.soma(ctx, somaRequest)
 			if err != nil {
 				return false, err
 			}
@@ -379,7 +408,7 @@ func (r *dpRepo) UpdateFileByPath(dpDomain, filePath string, newFileContent []by
 				return false, err
 			}
 			parentPath := paths.GetDpPath(filePath, "..")
-			err = r.refreshSomaFilesByPath(dpDomain, parentPath)
+			err = r.refreshSomaFilesByPath(ctx, dpDomain, parentPath)
 			if err != nil {
 				logging.LogDebugf("repo/dp/UpdateFileByPath() - Error refresing soma files by path '%s': err: %v", parentPath, err)
 				return false, err
@@ -412,22 +441,22 @@ func (r *dpRepo) UpdateFileByPath(dpDomain, filePath string, newFileContent []by
 	}
 }
 
-func (r *dpRepo) GetFileType(viewConfig *model.ItemConfig, parentPath, fileName string) (model.ItemType, error) {
+func (r *DataPowerRepo) GetFileType(ctx context.Context, viewConfig *model.ItemConfig, parentPath, fileName string) (model.ItemType, error) {
 	logging.LogDebug(fmt.Sprintf("repo/dp/getFileType(%v, '%s', '%s')\n", viewConfig, parentPath, fileName))
 	dpDomain := viewConfig.DpDomain
 	r.dataPowerAppliance = getDpAppliance(viewConfig)
 
-	return r.GetFileTypeByPath(dpDomain, parentPath, fileName)
+	return r.GetFileTypeByPath(ctx, dpDomain, parentPath, fileName)
 }
 
-func (r *dpRepo) GetFileTypeByPath(dpDomain, parentPath, fileName string) (model.ItemType, error) {
+func (r *DataPowerRepo) GetFileTypeByPath(ctx context.Context, dpDomain, parentPath, fileName string) (model.ItemType, error) {
 	logging.LogDebug(fmt.Sprintf("repo/dp/GetFileTypeByPath('%s', '%s', '%s')\n", dpDomain, parentPath, fileName))
 	filePath := paths.GetDpPath(parentPath, fileName)
 
 	switch r.dataPowerAppliance.DpManagmentInterface() {
 	case config.DpInterfaceRest:
 		restPath := makeRestPath(dpDomain, filePath)
-		jsonString, err := r.restGet(restPath)
+		jsonString, err := r.restGet(ctx, restPath)
 		if err != nil {
 			unexErr, ok := err.(errs.UnexpectedHTTPResponse)
 			if ok && unexErr.StatusCode == 404 {
@@ -515,18 +544,18 @@ func (r *dpRepo) GetFileTypeByPath(dpDomain, parentPath, fileName string) (model
 	}
 }
 
-func (r *dpRepo) GetFilePath(parentPath, fileName string) string {
+func (r *DataPowerRepo) GetFilePath(parentPath, fileName string) string {
 	logging.LogDebugf("repo/dp/GetFilePath('%s', '%s')", parentPath, fileName)
 	return paths.GetDpPath(parentPath, fileName)
 }
 
-func (r *dpRepo) CreateDir(viewConfig *model.ItemConfig, parentPath, dirName string) (bool, error) {
+func (r *DataPowerRepo) CreateDir(ctx context.Context, viewConfig *model.ItemConfig, parentPath, dirName string) (bool, error) {
 	logging.LogDebugf("repo/dp/CreateDir(%v, '%s', '%s')", viewConfig, parentPath, dirName)
-	return r.CreateDirByPath(viewConfig.DpDomain, parentPath, dirName)
+	return r.CreateDirByPath(ctx, viewConfig.DpDomain, parentPath, dirName)
 }
-func (r *dpRepo) CreateDirByPath(dpDomain, parentPath, dirName string) (bool, error) {
+func (r *DataPowerRepo) CreateDirByPath(ctx context.Context, dpDomain, parentPath, dirName string) (bool, error) {
 	logging.LogDebugf("repo/dp/CreateDirByPath('%s', '%s', '%s')", dpDomain, parentPath, dirName)
-	fileType, err := r.GetFileTypeByPath(dpDomain, parentPath, dirName)
+	fileType, err := r.GetFileTypeByPath(ctx, dpDomain, parentPath, dirName)
 	if err != nil {
 		return false, err
 	}
@@ -537,7 +566,7 @@ func (r *dpRepo) CreateDirByPath(dpDomain, parentPath, dirName string) (bool, er
 		case config.DpInterfaceRest:
 			requestBody := "{\"directory\":{\"name\":\"" + dirName + "\"}}"
 			restPath := makeRestPath(dpDomain, parentPath)
-			jsonString, err := r.rest(restPath, "POST", requestBody)
+			jsonString, err := r.rest(ctx, restPath, "POST", requestBody)
 			if err != nil {
 				return false, err
 			}
@@ -565,7 +594,7 @@ func (r *dpRepo) CreateDirByPath(dpDomain, parentPath, dirName string) (bool, er
 		</man:request>
 	</soapenv:Body>
 </soapenv:Envelope>`, dpDomain, dirPath)
-			somaResponse, err := r.soma(somaRequest)
+			somaResponse, err := r.soma(ctx, somaRequest)
 			if err != nil {
 				return false, err
 			}
@@ -574,7 +603,7 @@ func (r *dpRepo) CreateDirByPath(dpDomain, parentPath, dirName string) (bool, er
 				logging.LogDebug("Error parsing response SOAP.", err)
 				return false, err
 			}
-			r.refreshSomaFilesByPath(dpDomain, dirPath)
+			r.refreshSomaFilesByPath(ctx, dpDomain, dirPath)
 			resultNode := xmlquery.FindOne(doc, "//*[local-name()='response']/*[local-name()='result']")
 			if resultNode != nil {
 				resultText := strings.Trim(resultNode.InnerText(), " \n\r\t")
@@ -598,7 +627,7 @@ func (r *dpRepo) CreateDirByPath(dpDomain, parentPath, dirName string) (bool, er
 	}
 }
 
-func (r *dpRepo) Delete(currentView *model.ItemConfig, itemType model.ItemType, parentPath, fileName string) (bool, error) {
+func (r *DataPowerRepo) Delete(ctx context.Context, currentView *model.ItemConfig, itemType model.ItemType, parentPath, fileName string) (bool, error) {
 	logging.LogDebugf("repo/dp/Delete(%v, '%s', '%s' (%s))", currentView, parentPath, fileName, itemType)
 
 	switch itemType {
@@ -612,7 +641,7 @@ func (r *dpRepo) Delete(currentView *model.ItemConfig, itemType model.ItemType,
 		switch r.dataPowerAppliance.DpManagmentInterface() {
 		case config.DpInterfaceRest:
 			restPath := makeRestPath(currentView.DpDomain, filePath)
-			jsonString, err := r.rest(restPath, "DELETE", "")
+			jsonString, err := r.rest(ctx, restPath, "DELETE", "")
 			if err != nil {
 				return false, err
 			}
@@ -629,7 +658,7 @@ func (r *dpRepo) Delete(currentView *model.ItemConfig, itemType model.ItemType,
 				return true, nil
 			}
 		case config.DpInterfaceSoma:
-			fileType, err := r.GetFileType(currentView, parentPath, fileName)
+			fileType, err := r.GetFileType(ctx, currentView, parentPath, fileName)
 			if err != nil {
 				return false, err
 			}
@@ -652,7 +681,7 @@ func (r *dpRepo) Delete(currentView *model.ItemConfig, itemType model.ItemType,
 	</soapenv:Body>
 </soapenv:Envelope>`, currentView.DpDomain, filePath)
 			}
-			somaResponse, err := r.soma(somaRequest)
+			somaResponse, err := r.soma(ctx, somaRequest)
 			if err != nil {
 				return false, err
 			}
@@ -661,7 +690,7 @@ func (r *dpRepo) Delete(currentView *model.ItemConfig, itemType model.ItemType,
 				logging.LogDebug("Error parsing response SOAP.", err)
 				return false, err
 			}
-			r.refreshSomaFiles(currentView)
+			r.refreshSomaFiles(ctx, currentView)
 			resultNode := xmlquery.FindOne(doc, "//*[local-name()='response']/*[local-name()='result']")
 			if resultNode != nil {
 				resultText := strings.Trim(resultNode.InnerText(), " \n\r\t")
@@ -673,12 +702,21 @@ func (r *dpRepo) Delete(currentView *model.ItemConfig, itemType model.ItemType,
 			logging.LogDebug("repo/dp/Delete(), using neither REST neither SOMA.")
 			return false, errs.Error("DataPower management interface not set.")
 		}
+	case model.ItemDpDomain:
+		// deleting DataPower domain - Domain object in the default domain
+		if fileName == "default" {
+			logging.LogDebug("repo/dp/Delete(), won't delete default domain.")
+			return false, errs.Error("Can't delete DataPower 'default' domain.")
+		}
+		domainsView := model.ItemConfig{Type: model.ItemDpObjectClass,
+			DpAppliance: currentView.DpAppliance, DpDomain: "default", Path: "Domain"}
+		return r.Delete(ctx, &domainsView, model.ItemDpObject, "Domain", fileName)
 	case model.ItemDpObject:
 		switch r.dataPowerAppliance.DpManagmentInterface() {
 		case config.DpInterfaceRest:
 			restPath := fmt.Sprintf("/mgmt/config/%s/%s/%s", currentView.DpDomain, parentPath, fileName)
 			logging.LogDebugf("repo/dp/Delete(), restPath: '%s'", restPath)
-			jsonString, err := r.rest(restPath, "DELETE", "")
+			jsonString, err := r.rest(ctx, restPath, "DELETE", "")
 			if err != nil {
 				return false, err
 			}
@@ -699,7 +737,7 @@ func (r *dpRepo) Delete(currentView *model.ItemConfig, itemType model.ItemType,
 	</soapenv:Body>
 </soapenv:Envelope>`,
 				currentView.DpDomain, parentPath, fileName)
-			somaResponse, err := r.soma(somaRequest)
+			somaResponse, err := r.soma(ctx, somaRequest)
 			if err != nil {
 				return false, err
 			}
@@ -723,7 +761,58 @@ func (r *dpRepo) Delete(currentView *model.ItemConfig, itemType model.ItemType,
 	return false, errs.Errorf("Can't delete '%s' (%s) at path '%s'.", fileName, itemType.UserFriendlyString(), parentPath)
 }
 
-func (r *dpRepo) GetViewConfigByPath(currentView *model.ItemConfig, dirPath string) (*model.ItemConfig, error) {
+// MoveFileByPath moves (renames) DataPower file to new path (overwriting
+// existing file), parent directory of the new path has to exist.
+func (r *DataPowerRepo) MoveFileByPath(ctx context.Context, dpDomain, filePath, newFilePath string) (bool, error) {
+	logging.LogDebugf("repo/dp/MoveFileByPath('%s', '%s', '%s')", dpDomain, filePath, newFilePath)
+
+	switch r.dataPowerAppliance.DpManagmentInterface() {
+	case config.DpInterfaceRest:
+		restActionPath := fmt.Sprintf("/mgmt/actionqueue/%s", dpDomain)
+		moveRequestJSON := fmt.Sprintf(`{"MoveFile":{"sURL":"%s","dURL":"%s","Overwrite":"on"}}`,
+			filePath, newFilePath)
+		jsonResponseString, err := r.rest(ctx, restActionPath, "POST", moveRequestJSON)
+		if err != nil {
+			return false, err
+		}
+		logging.LogDebugf("repo/dp/MoveFileByPath(), jsonResponseString: '%s'", jsonResponseString)
+		resultMsg, err := parseJSONFindOne(jsonResponseString, "/MoveFile")
+		if err != nil {
+			return false, err
+		}
+		if resultMsg == "Operation completed." {
+			return true, nil
+		}
+		return false, errs.Errorf("Error moving file '%s' to '%s': '%s'", filePath, newFilePath, resultMsg)
+	case config.DpInterfaceSoma:
+		somaRequest := fmt.Sprintf(`<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/">
+	<soapenv:Body>
+		<man:request xmlns:man="http://www.datapower.com/schemas/management" domain="%s">
+			<man:do-action><MoveFile><sURL>%s</sURL><dURL>%s</dURL><Overwrite>on</Overwrite></MoveFile></man:do-action>
+		</man:request>
+	</soapenv:Body>
+</soapenv:Envelope>`, dpDomain, filePath, newFilePath)
+		somaResponse, err := r.soma(ctx, somaRequest)
+		if err != nil {
+			return false, err
+		}
+		resultMsg, err := parseSOMAFindOne(somaResponse, "//*[local-name()='response']/*[local-name()='result']")
+		if err != nil {
+			logging.LogDebug("Error parsing response SOAP.", err)
+			return false, err
+		}
+		r.refreshSomaFilesByPath(ctx, dpDomain, filePath)
+		if strings.TrimSpace(resultMsg) == "OK" {
+			return true, nil
+		}
+		return false, errs.Errorf("Error moving file '%s' to '%s': '%s'", filePath, newFilePath, resultMsg)
+	default:
+		logging.LogDebug("repo/dp/MoveFileByPath(), using neither REST neither SOMA.")
+		return false, errs.Error("DataPower management interface not set.")
+	}
+}
+
+func (r *DataPowerRepo) GetViewConfigByPath(ctx context.Context, currentView *model.ItemConfig, dirPath string) (*model.ItemConfig, error) {
 	logging.LogDebugf("repo/dp/GetViewConfigByPath('%s')", dirPath)
 	if currentView.DpDomain == "" {
 		return nil, errs.Errorf("Can't get view for path '%s' if DataPower domain is not selected.", dirPath)
@@ -759,9 +848,46 @@ func (r *dpRepo) GetViewConfigByPath(currentView *model.ItemConfig, dirPath stri
 	return resultView, nil
 }
 
+// LoadTree loads DataPower directory hierarchy information into Tree object.
+// Modification time of each file is taken from DataPower filestore listing.
+func (r *DataPowerRepo) LoadTree(ctx context.Context, dpDomain, pathFromRoot, dirPath string) (localfs.Tree, error) {
+	logging.LogDebugf("repo/dp/LoadTree('%s', '%s', '%s')", dpDomain, pathFromRoot, dirPath)
+	if pathFromRoot == "" {
+		r.InvalidateCache()
+	}
+
+	_, dirName := splitOnLast(strings.TrimRight(dirPath, "/"), "/")
+	tree := localfs.Tree{Dir: true, Name: dirName, Path: dirPath, PathFromRoot: pathFromRoot}
+	dirView := model.ItemConfig{Type: model.ItemDirectory, DpDomain: dpDomain, Path: dirPath}
+	items, err := r.listFiles(ctx, &dirView)
+	if err != nil {
+		return tree, err
+	}
+
+	tree.Children = make([]localfs.Tree, 0, len(items))
+	for _, item := range items {
+		childPathFromRoot := paths.GetFilePath(pathFromRoot, item.Name)
+		var child localfs.Tree
+		switch item.Config.Type {
+		case model.ItemDirectory:
+			child, err = r.LoadTree(ctx, dpDomain, childPathFromRoot, item.Config.Path)
+			if err != nil {
+				return tree, err
+			}
+		default:
+			modTime, _ := time.Parse("2006-01-02 15:04:05", item.Modified)
+			child = localfs.Tree{Name: item.Name, Path: item.Config.Path,
+				PathFromRoot: childPathFromRoot, ModTime: modTime}
+		}
+		tree.Children = append(tree.Children, child)
+	}
+
+	return tree, nil
+}
+
 // ExportAppliance creates export of whole DataPower appliance and returns
 // base64 encoded exported zip file.
-func (r *dpRepo) ExportAppliance(applianceConfigName, exportFileName string) ([]byte, error) {
+func (r *DataPowerRepo) ExportAppliance(ctx context.Context, applianceConfigName, exportFileName string) ([]byte, error) {
 	logging.LogDebugf("repo/dp/ExportAppliance('%s', '%s')", applianceConfigName, exportFileName)
 
 	// 0. Prepare DataPower connection configuration.
@@ -786,7 +912,7 @@ func (r *dpRepo) ExportAppliance(applianceConfigName, exportFileName string) ([]
 	case config.DpInterfaceSoma:
 		// 1. Fetch export (backup) of all domains
 		//    Backup contains all domains export zip + export info and dp-aux files
-		domains, err := r.fetchDpDomains()
+		domains, err := r.fetchDpDomains(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -808,7 +934,7 @@ func (r *dpRepo) ExportAppliance(applianceConfigName, exportFileName string) ([]
 		</man:request>
 	</soapenv:Body>
 </soapenv:Envelope>`, exportFileName, backupRequestSomaDomains)
-		backupResponseSoma, err := r.soma(backupRequestSoma)
+		backupResponseSoma, err := r.soma(ctx, backupRequestSoma)
 		if err != nil {
 			return nil, err
 		}
@@ -831,7 +957,7 @@ func (r *dpRepo) ExportAppliance(applianceConfigName, exportFileName string) ([]
 
 // ExportDomain creates export of given domain and returns base64 encoded
 // exported zip file.
-func (r *dpRepo) ExportDomain(domainName, exportFileName string) ([]byte, error) {
+func (r *DataPowerRepo) ExportDomain(ctx context.Context, domainName, exportFileName string) ([]byte, error) {
 	logging.LogDebugf("repo/dp/ExportDomain('%s', '%s')", domainName, exportFileName)
 	switch r.dataPowerAppliance.DpManagmentInterface() {
 	case config.DpInterfaceRest:
@@ -845,7 +971,7 @@ func (r *dpRepo) ExportDomain(domainName, exportFileName string) ([]byte, error)
 		    "IncludeInternalFiles":"off"
 		  }
 		}`, exportFileName)
-		locationURL, _, err := r.restPostForResult(
+		locationURL, _, err := r.restPostForResult(ctx,
 			"/mgmt/actionqueue/"+domainName,
 			exportRequestJSON,
 			"/Export/status",
@@ -855,35 +981,19 @@ func (r *dpRepo) ExportDomain(domainName, exportFileName string) ([]byte, error)
 			return nil, err
 		}
 
-		timeStart := time.Now()
-		for {
-			// 2. Check for current status of export request
-			status, exportResponseJSON, err := r.restGetForOneResult(locationURL, "/status")
-			logging.LogDebugf("repo/dp/ExportDomain() status: '%s'", status)
-			if err != nil {
-				return nil, err
-			}
+		// 2. Wait for export to complete
+		exportResponseJSON, err := r.restWaitForActionCompleted(ctx, "Export", locationURL)
+		if err != nil {
+			return nil, err
+		}
 
-			switch status {
-			case "started":
-				if time.Since(timeStart) > 120*time.Second {
-					logging.LogDebugf("repo/dp/ExportDomain() waiting for export since %v, giving up.\n last exportResponseJSON: '%s'", timeStart, exportResponseJSON)
-					return nil, errs.Errorf("Export didn't finish since %v, giving up.", timeStart)
-				}
-				time.Sleep(1 * time.Second)
-			case "completed":
-				// 3. When export is completed get base64 result file from it
-				logging.LogDebugf("repo/dp/ExportDomain() export fetched after %d.", time.Since(timeStart))
-				fileB64, err := parseJSONFindOne(exportResponseJSON, "/result/file")
-				if err != nil {
-					return nil, err
-				}
-				fileBytes, err := base64.StdEncoding.DecodeString(fileB64)
-				return fileBytes, err
-			default:
-				return nil, errs.Errorf("Unexpected response from server ('%s').", status)
-			}
+		// 3. When export is completed get base64 result file from it
+		fileB64, err := parseJSONFindOne(exportResponseJSON, "/result/file")
+		if err != nil {
+			return nil, err
 		}
+		fileBytes, err := base64.StdEncoding.DecodeString(fileB64)
+		return fileBytes, err
 	case config.DpInterfaceSoma:
 		// 1. Fetch export (backup) of domain
 		//    Backup contains domain export zip + export info and dp-aux files
@@ -899,7 +1009,7 @@ func (r *dpRepo) ExportDomain(domainName, exportFileName string) ([]byte, error)
 		</man:request>
 	</soapenv:Body>
 </soapenv:Envelope>`, exportFileName, domainName)
-		backupResponseSoma, err := r.soma(backupRequestSoma)
+		backupResponseSoma, err := r.soma(ctx, backupRequestSoma)
 		if err != nil {
 			return nil, err
 		}
@@ -953,9 +1063,239 @@ func (r *dpRepo) ExportDomain(domainName, exportFileName string) ([]byte, error)
 	}
 }
 
+// ImportDomain imports given zip file (domain export) to the given domain
+// and returns import results (imported objects and files with their statuses).
+func (r *DataPowerRepo) ImportDomain(ctx context.Context, domainName, importFileName string, importFileBytes []byte) ([]byte, error) {
+	logging.LogDebugf("repo/dp/ImportDomain('%s', '%s', ...)", domainName, importFileName)
+	importFileB64 := base64.StdEncoding.EncodeToString(importFileBytes)
+
+	switch r.dataPowerAppliance.DpManagmentInterface() {
+	case config.DpInterfaceRest:
+		// 1. Start import (send import request)
+		importRequestJSON := fmt.Sprintf(`{"Import":
+		  {
+		    "Format":"ZIP",
+		    "InputFile":"%s",
+		    "OverwriteFiles":"on",
+		    "OverwriteObjects":"on",
+		    "DryRun":"off"
+		  }
+		}`, importFileB64)
+		locationURL, _, err := r.restPostForResult(ctx,
+			"/mgmt/actionqueue/"+domainName,
+			importRequestJSON,
+			"/Import/status",
+			"Action request accepted.",
+			"/_links/location/href")
+		if err != nil {
+			return nil, err
+		}
+
+		// 2. Wait for import to complete and parse results
+		importResponseJSON, err := r.restWaitForActionCompleted(ctx, "Import", locationURL)
+		if err != nil {
+			return nil, err
+		}
+
+		return parseImportResultsFromJSON(importResponseJSON)
+	case config.DpInterfaceSoma:
+		importRequestSoma := fmt.Sprintf(`<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"
+	xmlns:man="http://www.datapower.com/schemas/management">
+	<soapenv:Header/>
+	<soapenv:Body>
+		<man:request domain="%s">
+			<man:do-import source-type="ZIP" overwrite-files="true" overwrite-objects="true" dry-run="false">
+				<man:input-file>%s</man:input-file>
+			</man:do-import>
+		</man:request>
+	</soapenv:Body>
+</soapenv:Envelope>`, domainName, importFileB64)
+		importResponseSoma, err := r.soma(ctx, importRequestSoma)
+		if err != nil {
+			return nil, err
+		}
+
+		return parseImportResultsFromSOMA(importResponseSoma)
+	default:
+		return nil, errs.Errorf("DataPower management interface %s not supported.", r.dataPowerAppliance.DpManagmentInterface())
+	}
+}
+
+// ImportAppliance restores whole DataPower appliance from the given zip file
+// (appliance backup) and returns import results (imported objects and files
+// with their statuses).
+func (r *DataPowerRepo) ImportAppliance(ctx context.Context, applianceConfigName, importFileName string, importFileBytes []byte) ([]byte, error) {
+	logging.LogDebugf("repo/dp/ImportAppliance('%s', '%s', ...)", applianceConfigName, importFileName)
+
+	// 0. Prepare DataPower connection configuration.
+	oldDataPowerAppliance := r.dataPowerAppliance
+	r.dataPowerAppliance = dpApplicance{name: applianceConfigName,
+		DataPowerAppliance: config.Conf.DataPowerAppliances[applianceConfigName]}
+	clearCurrentConfig := func() {
+		r.dataPowerAppliance = oldDataPowerAppliance
+	}
+	defer clearCurrentConfig()
+	if r.dataPowerAppliance.Password == "" {
+		r.dataPowerAppliance.SetDpPlaintextPassword(config.DpTransientPasswordMap[applianceConfigName])
+	}
+
+	switch r.dataPowerAppliance.DpManagmentInterface() {
+	case config.DpInterfaceRest:
+		// Don't know how to restore multiple domains using REST.
+		return nil,
+			errs.Errorf("DataPower management interface %s not supported for appliance import.",
+				r.dataPowerAppliance.DpManagmentInterface())
+	case config.DpInterfaceSoma:
+		// 1. Find all domains contained in backup (each domain is one zip file)
+		domainNames, err := getDomainNamesFromBackup(importFileBytes)
+		if err != nil {
+			return nil, err
+		}
+		logging.LogDebugf("repo/dp/ImportAppliance(), domainNames: %v", domainNames)
+
+		restoreRequestSomaDomains := ""
+		for _, domainName := range domainNames {
+			restoreRequestSomaDomains = restoreRequestSomaDomains +
+				fmt.Sprintf(`<man:domain name="%s" import-domain="true" reset-domain="false"/>`, domainName)
+		}
+		restoreRequestSoma := fmt.Sprintf(`<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/"
+  xmlns:man="http://www.datapower.com/schemas/management">
+	<soapenv:Header/>
+	<soapenv:Body>
+		<man:request>
+			<man:do-restore source-type="ZIP" rewrite-local-ip="false" overwrite-files="true" overwrite-objects="true" dry-run="false">
+				<man:input-file>%s</man:input-file>
+%s
+			</man:do-restore>
+		</man:request>
+	</soapenv:Body>
+</soapenv:Envelope>`, base64.StdEncoding.EncodeToString(importFileBytes), restoreRequestSomaDomains)
+		restoreResponseSoma, err := r.soma(ctx, restoreRequestSoma)
+		if err != nil {
+			return nil, err
+		}
+
+		return parseImportResultsFromSOMA(restoreResponseSoma)
+	default:
+		return nil, errs.Errorf("DataPower management interface %s not supported.", r.dataPowerAppliance.DpManagmentInterface())
+	}
+}
+
+// getDomainNamesFromBackup returns names of all domains contained in the
+// DataPower appliance backup zip file.
+func getDomainNamesFromBackup(backupBytes []byte) ([]string, error) {
+	backupZipReader, err := zip.NewReader(bytes.NewReader(backupBytes), int64(len(backupBytes)))
+	if err != nil {
+		logging.LogDebug("repo/dp/getDomainNamesFromBackup() - Error unzipping backup archive.", err)
+		return nil, err
+	}
+
+	domainNames := make([]string, 0)
+	for _, file := range backupZipReader.File {
+		if !strings.Contains(file.Name, "/") && strings.HasSuffix(file.Name, ".zip") {
+			domainNames = append(domainNames, strings.TrimSuffix(file.Name, ".zip"))
+		}
+	}
+	if len(domainNames) == 0 {
+		return nil, errs.Error("Can't find any domain in DataPower backup.")
+	}
+
+	return domainNames, nil
+}
+
+// parseImportResultsFromJSON returns import results from the REST import
+// action response as a human readable text.
+func parseImportResultsFromJSON(importResponseJSON string) ([]byte, error) {
+	doc, err := jsonquery.Parse(strings.NewReader(importResponseJSON))
+	if err != nil {
+		logging.LogDebug("Error parsing JSON.", err)
+		return nil, err
+	}
+
+	innerTexts := func(query string) []string {
+		nodes := jsonquery.Find(doc, query)
+		result := make([]string, len(nodes))
+		for idx, node := range nodes {
+			result[idx] = node.InnerText()
+		}
+		return result
+	}
+
+	objectClasses := innerTexts("//imported-objects//class")
+	objectNames := innerTexts("//imported-objects//name")
+	objectStatuses := innerTexts("//imported-objects//status")
+	fileNames := innerTexts("//imported-files//name")
+	fileStatuses := innerTexts("//imported-files//status")
+
+	if len(objectNames) != len(objectClasses) || len(objectNames) != len(objectStatuses) ||
+		len(fileNames) != len(fileStatuses) {
+		logging.LogDebugf("repo/dp/parseImportResultsFromJSON() unexpected import results:\n'%s'", importResponseJSON)
+		return nil, errs.Error("Unexpected JSON, can't parse import results.")
+	}
+
+	return formatImportResults(objectClasses, objectNames, objectStatuses, fileNames, fileStatuses), nil
+}
+
+// parseImportResultsFromSOMA returns import results from the SOMA do-import
+// (or do-restore) response as a human readable text.
+func parseImportResultsFromSOMA(importResponseSoma string) ([]byte, error) {
+	doc, err := xmlquery.Parse(strings.NewReader(importResponseSoma))
+	if err != nil {
+		logging.LogDebug("Error parsing response SOAP.", err)
+		return nil, err
+	}
+
+	errorNode := xmlquery.FindOne(doc, "//*[local-name()='response']/*[local-name()='result']")
+	if errorNode != nil && strings.TrimSpace(errorNode.InnerText()) != "OK" {
+		return nil, errs.Errorf("Unexpected result of SOMA import: '%s'.", strings.TrimSpace(errorNode.InnerText()))
+	}
+
+	objectNodes := xmlquery.Find(doc, "//*[local-name()='imported-objects']/*[local-name()='object']")
+	fileNodes := xmlquery.Find(doc, "//*[local-name()='imported-files']/*[local-name()='file']")
+	if objectNodes == nil && fileNodes == nil &&
+		xmlquery.FindOne(doc, "//*[local-name()='import-results']") == nil {
+		logging.LogDebugf("repo/dp/parseImportResultsFromSOMA() unexpected import results:\n'%s'", importResponseSoma)
+		return nil, errs.Error("Unexpected SOMA, can't find import results.")
+	}
+
+	objectClasses := make([]string, len(objectNodes))
+	objectNames := make([]string, len(objectNodes))
+	objectStatuses := make([]string, len(objectNodes))
+	for idx, node := range objectNodes {
+		objectClasses[idx] = node.SelectAttr("class")
+		objectNames[idx] = node.SelectAttr("name")
+		objectStatuses[idx] = node.SelectAttr("status")
+	}
+	fileNames := make([]string, len(fileNodes))
+	fileStatuses := make([]string, len(fileNodes))
+	for idx, node := range fileNodes {
+		fileNames[idx] = node.SelectAttr("name")
+		fileStatuses[idx] = node.SelectAttr("status")
+	}
+
+	return formatImportResults(objectClasses, objectNames, objectStatuses, fileNames, fileStatuses), nil
+}
+
+// formatImportResults formats import results - one line for each imported
+// object and file.
+func formatImportResults(objectClasses, objectNames, objectStatuses, fileNames, fileStatuses []string) []byte {
+	var result bytes.Buffer
+	result.WriteString(fmt.Sprintf("Imported objects (%d):\n", len(objectNames)))
+	for idx := range objectNames {
+		result.WriteString(fmt.Sprintf("  %-12s %s '%s'\n",
+			objectStatuses[idx], objectClasses[idx], objectNames[idx]))
+	}
+	result.WriteString(fmt.Sprintf("\nImported files (%d):\n", len(fileNames)))
+	for idx := range fileNames {
+		result.WriteString(fmt.Sprintf("  %-12s %s\n", fileStatuses[idx], fileNames[idx]))
+	}
+
+	return result.Bytes()
+}
+
 // GetObjectDetails parses DataPower export to show service policy
 // with all rules, matches & actions.
-func (r *dpRepo) GetObjectDetails(domainName, objectClassName, objectName string) ([]byte, error) {
+func (r *DataPowerRepo) GetObjectDetails(ctx context.Context, domainName, objectClassName, objectName string) ([]byte, error) {
 	logging.LogDebugf("repo/dp/GetObjectDetails('%s', '%s', '%s')",
 		domainName, objectClassName, objectName)
 	switch r.dataPowerAppliance.DpManagmentInterface() {
@@ -980,7 +1320,7 @@ func (r *dpRepo) GetObjectDetails(domainName, objectClassName, objectName string
 		      ]
 		  }
 		}`, objectClassName, objectName)
-		locationURL, _, err := r.restPostForResult(
+		locationURL, _, err := r.restPostForResult(ctx,
 			"/mgmt/actionqueue/"+domainName,
 			exportRequestJSON,
 			"/Export/status",
@@ -993,7 +1333,7 @@ func (r *dpRepo) GetObjectDetails(domainName, objectClassName, objectName string
 		timeStart := time.Now()
 		for {
 			// 2. Check for current status of export request
-			status, exportResponseJSON, err := r.restGetForOneResult(locationURL, "/status")
+			status, exportResponseJSON, err := r.restGetForOneResult(ctx, locationURL, "/status")
 			logging.LogDebugf("repo/dp/GetObjectDetails() status: '%s'", status)
 			if err != nil {
 				return nil, err
@@ -1005,7 +1345,10 @@ func (r *dpRepo) GetObjectDetails(domainName, objectClassName, objectName string
 					logging.LogDebugf("repo/dp/GetObjectDetails() waiting for export since %v, giving up.\n last exportResponseJSON: '%s'", timeStart, exportResponseJSON)
 					return nil, errs.Errorf("Export didn't finish since %v, giving up.", timeStart)
 				}
-				time.Sleep(1 * time.Second)
+				err = waitContext(ctx, 1*time.Second)
+				if err != nil {
+					return nil, err
+				}
 			case "completed":
 				// 3. When export is completed get base64 result file from it
 				logging.LogDebugf("repo/dp/GetObjectDetails() export fetched after %d.", time.Since(timeStart))
@@ -1077,7 +1420,7 @@ func (r *dpRepo) GetObjectDetails(domainName, objectClassName, objectName string
       </man:request>
    </soapenv:Body>
 </soapenv:Envelope>`, domainName, objectClassName, objectName)
-		exportResponseSoma, err := r.soma(exportRequestSoma)
+		exportResponseSoma, err := r.soma(ctx, exportRequestSoma)
 		if err != nil {
 			return nil, err
 		}
@@ -1348,7 +1691,7 @@ func getObjectDetailsFromExportXML(exportXMLBytes []byte, objectClassName, objec
 
 // GetObject fetches DataPower object configuration. If persisted flag is true
 // fetch persisted object, otherwise fetch current object from memory.
-func (r *dpRepo) GetObject(dpDomain, objectClass, objectName string, persisted bool) ([]byte, error) {
+func (r *DataPowerRepo) GetObject(ctx context.Context, dpDomain, objectClass, objectName string, persisted bool) ([]byte, error) {
 	logging.LogDebugf("repo/dp/GetObject('%s', '%s', '%s', %t)",
 		dpDomain, objectClass, objectName, persisted)
 
@@ -1359,7 +1702,7 @@ func (r *dpRepo) GetObject(dpDomain, objectClass, objectName string, persisted b
 		}
 		getObjectURL := fmt.Sprintf("/mgmt/config/%s/%s/%s",
 			dpDomain, objectClass, objectName)
-		objectJSON, err := r.restGet(getObjectURL)
+		objectJSON, err := r.restGet(ctx, getObjectURL)
 		if err != nil {
 			if respErr, ok := err.(errs.UnexpectedHTTPResponse); ok && respErr.StatusCode == 404 {
 				return nil, nil
@@ -1384,7 +1727,7 @@ func (r *dpRepo) GetObject(dpDomain, objectClass, objectName string, persisted b
 	</soapenv:Body>
 </soapenv:Envelope>`,
 			dpDomain, objectClass, objectName, persisted)
-		somaResponse, err := r.soma(somaRequest)
+		somaResponse, err := r.soma(ctx, somaRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -1415,7 +1758,7 @@ func (r *dpRepo) GetObject(dpDomain, objectClass, objectName string, persisted b
 }
 
 // SetObject updates or creates DataPower object configuration.
-func (r *dpRepo) SetObject(dpDomain, objectClass, objectName string, objectContent []byte, existingObject bool) error {
+func (r *DataPowerRepo) SetObject(ctx context.Context, dpDomain, objectClass, objectName string, objectContent []byte, existingObject bool) error {
 	logging.LogDebugf("repo/dp/SetObject('%s', '%s', '%s', .., %t)",
 		dpDomain, objectClass, objectName, existingObject)
 
@@ -1433,7 +1776,7 @@ func (r *dpRepo) SetObject(dpDomain, objectClass, objectName string, objectConte
 				dpDomain, objectClass)
 			setObjectMethod = "POST"
 		}
-		resultJSON, err := r.rest(setObjectURL, setObjectMethod, string(objectContent))
+		resultJSON, err := r.rest(ctx, setObjectURL, setObjectMethod, string(objectContent))
 		if err != nil {
 			return err
 		}
@@ -1462,7 +1805,7 @@ xmlns:man="http://www.datapower.com/schemas/management">
 	</soapenv:Body>
 </soapenv:Envelope>`, dpDomain, objectContent)
 		logging.LogDebugf("repo/dp/SetObject(), somaRequest: '%s'", somaRequest)
-		somaResponse, err := r.soma(somaRequest)
+		somaResponse, err := r.soma(ctx, somaRequest)
 		if err != nil {
 			return err
 		}
@@ -1484,7 +1827,7 @@ xmlns:man="http://www.datapower.com/schemas/management">
 }
 
 // RenameObject changes name in DataPower object configuration (JSON or XML).
-func (r *dpRepo) RenameObject(dpObject []byte, objectName string) ([]byte, error) {
+func (r *DataPowerRepo) RenameObject(dpObject []byte, objectName string) ([]byte, error) {
 	logging.LogDebugf("repo/dp/RenameObject(.., '%s')", objectName)
 	switch r.dataPowerAppliance.DpManagmentInterface() {
 	case config.DpInterfaceRest:
@@ -1507,8 +1850,193 @@ func (r *dpRepo) RenameObject(dpObject []byte, objectName string) ([]byte, error
 	}
 }
 
+// dpObjectProperty contains basic metadata of DataPower object class property
+// required to create new object of that class.
+type dpObjectProperty struct {
+	name         string
+	defaultValue string
+	required     bool
+}
+
+// GetObjectClasses returns names of all DataPower object classes supported by
+// the DataPower firmware, including ones without any object instances.
+func (r *DataPowerRepo) GetObjectClasses(ctx context.Context) ([]string, error) {
+	logging.LogDebug("repo/dp/GetObjectClasses()")
+
+	var classNames []string
+	switch r.dataPowerAppliance.DpManagmentInterface() {
+	case config.DpInterfaceRest:
+		doc, err := r.restGetDoc(ctx, "/mgmt/metadata/latest")
+		if err != nil {
+			return nil, err
+		}
+		classNodes := jsonquery.Find(doc, "/_links/*")
+		classNames = make([]string, 0)
+		for _, classNode := range classNodes {
+			if classNode.Data != "self" && classNode.Data != "doc" {
+				classNames = append(classNames, classNode.Data)
+			}
+		}
+	case config.DpInterfaceSoma:
+		doc, err := r.fetchManagementSchema(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Object classes are complex types extending ConfigBase type.
+		classNodes := xmlquery.Find(doc, "//*[local-name()='complexType' and starts-with(@name, 'Config')]")
+		classNames = make([]string, 0)
+		for _, classNode := range classNodes {
+			if xmlquery.FindOne(classNode, ".//*[local-name()='extension' and @base='tns:ConfigBase']") != nil {
+				classNames = append(classNames, strings.TrimPrefix(classNode.SelectAttr("name"), "Config"))
+			}
+		}
+	default:
+		logging.LogDebug("repo/dp/GetObjectClasses(), using neither REST neither SOMA.")
+		return nil, errs.Error("DataPower management interface not set.")
+	}
+
+	if len(classNames) == 0 {
+		return nil, errs.Error("Can't find any DataPower object class.")
+	}
+	sort.Strings(classNames)
+
+	return classNames, nil
+}
+
+// CreateObjectSkeleton creates configuration (JSON/XML, depending on REST/SOMA
+// management interface used) of the new DataPower object of the given class
+// with all required properties (with default values where available).
+func (r *DataPowerRepo) CreateObjectSkeleton(ctx context.Context, objectClass, objectName string) ([]byte, error) {
+	logging.LogDebugf("repo/dp/CreateObjectSkeleton('%s', '%s')", objectClass, objectName)
+
+	properties, err := r.getObjectClassProperties(ctx, objectClass)
+	if err != nil {
+		return nil, err
+	}
+
+	var skeleton bytes.Buffer
+	switch r.dataPowerAppliance.DpManagmentInterface() {
+	case config.DpInterfaceRest:
+		jsonString := func(value string) string {
+			jsonBytes, _ := json.Marshal(value)
+			return string(jsonBytes)
+		}
+		skeleton.WriteString(fmt.Sprintf("{\n  %s: {\n    \"name\": %s",
+			jsonString(objectClass), jsonString(objectName)))
+		for _, property := range properties {
+			if property.required || property.defaultValue != "" {
+				skeleton.WriteString(fmt.Sprintf(",\n    %s: %s",
+					jsonString(property.name), jsonString(property.defaultValue)))
+			}
+		}
+		skeleton.WriteString("\n  }\n}\n")
+	case config.DpInterfaceSoma:
+		skeleton.WriteString(fmt.Sprintf("<%s name=\"%s\">\n", objectClass, html.EscapeString(objectName)))
+		for _, property := range properties {
+			if property.required || property.defaultValue != "" {
+				skeleton.WriteString(fmt.Sprintf("  <%s>%s</%s>\n",
+					property.name, html.EscapeString(property.defaultValue), property.name))
+			}
+		}
+		skeleton.WriteString(fmt.Sprintf("</%s>\n", objectClass))
+	}
+
+	return skeleton.Bytes(), nil
+}
+
+// getObjectClassProperties returns metadata of all properties of the given
+// DataPower object class.
+func (r *DataPowerRepo) getObjectClassProperties(ctx context.Context, objectClass string) ([]dpObjectProperty, error) {
+	logging.LogDebugf("repo/dp/getObjectClassProperties('%s')", objectClass)
+
+	properties := make([]dpObjectProperty, 0)
+	switch r.dataPowerAppliance.DpManagmentInterface() {
+	case config.DpInterfaceRest:
+		doc, err := r.restGetDoc(ctx, "/mgmt/metadata/latest/"+objectClass)
+		if err != nil {
+			return nil, err
+		}
+		propertyNode := jsonquery.FindOne(doc, "/object/properties/property")
+		if propertyNode == nil {
+			return nil, errs.Errorf("Can't find properties for DataPower object class '%s'.", objectClass)
+		}
+		// Single property is not returned as an array.
+		propertyNodes := []*jsonquery.Node{propertyNode}
+		if propertyNode.SelectElement("name") == nil {
+			propertyNodes = jsonquery.Find(propertyNode, "*")
+		}
+		for _, node := range propertyNodes {
+			property := dpObjectProperty{}
+			if nameNode := node.SelectElement("name"); nameNode != nil {
+				property.name = nameNode.InnerText()
+			}
+			if defaultNode := node.SelectElement("default"); defaultNode != nil {
+				property.defaultValue = defaultNode.InnerText()
+			}
+			if requiredNode := node.SelectElement("required"); requiredNode != nil {
+				property.required = requiredNode.InnerText() == "true"
+			}
+			if property.name != "" && property.name != "name" {
+				properties = append(properties, property)
+			}
+		}
+	case config.DpInterfaceSoma:
+		doc, err := r.fetchManagementSchema(ctx)
+		if err != nil {
+			return nil, err
+		}
+		classNode := xmlquery.FindOne(doc,
+			fmt.Sprintf("//*[local-name()='complexType' and @name='Config%s']", objectClass))
+		if classNode == nil {
+			return nil, errs.Errorf("Can't find DataPower object class '%s' in management schema.", objectClass)
+		}
+		// Properties are defined directly in complex type or in referenced groups.
+		propertyNodes := xmlquery.Find(classNode, ".//*[local-name()='element' and @name]")
+		for _, groupNode := range xmlquery.Find(classNode, ".//*[local-name()='group' and @ref]") {
+			_, groupName := splitOnLast(groupNode.SelectAttr("ref"), ":")
+			propertyNodes = append(propertyNodes, xmlquery.Find(doc,
+				fmt.Sprintf("//*[local-name()='group' and @name='%s']//*[local-name()='element' and @name]", groupName))...)
+		}
+		for _, node := range propertyNodes {
+			property := dpObjectProperty{name: node.SelectAttr("name"),
+				defaultValue: node.SelectAttr("default"),
+				required:     node.SelectAttr("minOccurs") != "0"}
+			if property.defaultValue == "" {
+				if defaultNode := xmlquery.FindOne(node, ".//*[local-name()='default']"); defaultNode != nil {
+					property.defaultValue = strings.TrimSpace(defaultNode.InnerText())
+				}
+			}
+			properties = append(properties, property)
+		}
+	default:
+		logging.LogDebug("repo/dp/getObjectClassProperties(), using neither REST neither SOMA.")
+		return nil, errs.Error("DataPower management interface not set.")
+	}
+
+	return properties, nil
+}
+
+// fetchManagementSchema fetches XML management interface schema
+// (store:///xml-mgmt.xsd) which contains definitions of all DataPower object
+// classes supported by the DataPower firmware.
+func (r *DataPowerRepo) fetchManagementSchema(ctx context.Context) (*xmlquery.Node, error) {
+	logging.LogDebug("repo/dp/fetchManagementSchema()")
+	schemaBytes, err := r.GetFileByPath(ctx, "default", "store:/xml-mgmt.xsd")
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := xmlquery.Parse(bytes.NewReader(schemaBytes))
+	if err != nil {
+		logging.LogDebug("repo/dp/fetchManagementSchema() - Error parsing management schema.", err)
+		return nil, err
+	}
+
+	return doc, nil
+}
+
 // GetStatus fetches DataPower status info.
-func (r *dpRepo) GetStatus(dpDomain, statusClass string, statusIdx int) ([]byte, error) {
+func (r *DataPowerRepo) GetStatus(ctx context.Context, dpDomain, statusClass string, statusIdx int) ([]byte, error) {
 	logging.LogDebugf("repo/dp/GetStatus('%s', '%s', %d)",
 		dpDomain, statusClass, statusIdx)
 
@@ -1516,7 +2044,7 @@ func (r *dpRepo) GetStatus(dpDomain, statusClass string, statusIdx int) ([]byte,
 	case config.DpInterfaceRest:
 		getStatusesURL := fmt.Sprintf("/mgmt/status/%s/%s",
 			dpDomain, statusClass)
-		statusesRespJSON, err := r.restGet(getStatusesURL)
+		statusesRespJSON, err := r.restGet(ctx, getStatusesURL)
 		if err != nil {
 			if respErr, ok := err.(errs.UnexpectedHTTPResponse); ok && respErr.StatusCode == 404 {
 				return nil, nil
@@ -1582,7 +2110,7 @@ func (r *dpRepo) GetStatus(dpDomain, statusClass string, statusIdx int) ([]byte,
 	</soapenv:Body>
 </soapenv:Envelope>`,
 			dpDomain, statusClass)
-		somaResponse, err := r.soma(somaStatusRequest)
+		somaResponse, err := r.soma(ctx, somaStatusRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -1614,14 +2142,14 @@ func (r *dpRepo) GetStatus(dpDomain, statusClass string, statusIdx int) ([]byte,
 }
 
 // GetStatuses fetches DataPower status info for all statuses in class.
-func (r *dpRepo) GetStatuses(dpDomain, statusClass string) ([]byte, error) {
+func (r *DataPowerRepo) GetStatuses(ctx context.Context, dpDomain, statusClass string) ([]byte, error) {
 	logging.LogDebugf("repo/dp/GetStatuses('%s', '%s')", dpDomain, statusClass)
 
 	switch r.dataPowerAppliance.DpManagmentInterface() {
 	case config.DpInterfaceRest:
 		getStatusesURL := fmt.Sprintf("/mgmt/status/%s/%s",
 			dpDomain, statusClass)
-		statusesRespJSON, err := r.restGet(getStatusesURL)
+		statusesRespJSON, err := r.restGet(ctx, getStatusesURL)
 		if err != nil {
 			if respErr, ok := err.(errs.UnexpectedHTTPResponse); ok && respErr.StatusCode == 404 {
 				return nil, nil
@@ -1661,7 +2189,7 @@ func (r *dpRepo) GetStatuses(dpDomain, statusClass string) ([]byte, error) {
 	</soapenv:Body>
 </soapenv:Envelope>`,
 			dpDomain, statusClass)
-		somaResponse, err := r.soma(somaStatusRequest)
+		somaResponse, err := r.soma(ctx, somaStatusRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -1692,7 +2220,7 @@ func (r *dpRepo) GetStatuses(dpDomain, statusClass string) ([]byte, error) {
 }
 
 // SaveConfiguration saves current DataPower configuration.
-func (r *dpRepo) SaveConfiguration(itemConfig *model.ItemConfig) error {
+func (r *DataPowerRepo) SaveConfiguration(ctx context.Context, itemConfig *model.ItemConfig) error {
 	logging.LogDebugf("repo/dp/SaveConfiguration(%v)", itemConfig)
 	switch r.dataPowerAppliance.DpManagmentInterface() {
 	case config.DpInterfaceRest:
@@ -1709,7 +2237,7 @@ func (r *dpRepo) SaveConfiguration(itemConfig *model.ItemConfig) error {
 	</soapenv:Body>
 </soapenv:Envelope>`, itemConfig.DpDomain)
 		logging.LogDebugf("repo/dp/SaveConfiguration(), somaRequest: '%s'", somaRequest)
-		somaResponse, err := r.soma(somaRequest)
+		somaResponse, err := r.soma(ctx, somaRequest)
 		if err != nil {
 			return err
 		}
@@ -1732,7 +2260,7 @@ func (r *dpRepo) SaveConfiguration(itemConfig *model.ItemConfig) error {
 }
 
 // CreateDomain creates new domain on DataPower appliance.
-func (r *dpRepo) CreateDomain(domainName string) error {
+func (r *DataPowerRepo) CreateDomain(ctx context.Context, domainName string) error {
 	logging.LogDebugf("repo/dp/CreateDomain('%s')", domainName)
 
 	switch r.dataPowerAppliance.DpManagmentInterface() {
@@ -1753,7 +2281,7 @@ func (r *dpRepo) CreateDomain(domainName string) error {
 	</soapenv:Body>
 </soapenv:Envelope>`, domainName)
 		logging.LogDebugf("repo/dp/CreateDomain(), somaRequest: '%s'", somaRequest)
-		somaResponse, err := r.soma(somaRequest)
+		somaResponse, err := r.soma(ctx, somaRequest)
 		if err != nil {
 			return err
 		}
@@ -1776,7 +2304,7 @@ func (r *dpRepo) CreateDomain(domainName string) error {
 
 // ParseObjectClassAndName parses bytes with XML/JSON definition of object
 // (XML/JSON should be used depending on REST/SOMA interface used).
-func (r *dpRepo) ParseObjectClassAndName(objectBytes []byte) (objectClass, objectName string, err error) {
+func (r *DataPowerRepo) ParseObjectClassAndName(objectBytes []byte) (objectClass, objectName string, err error) {
 	logging.LogDebugf("repo/dp/ParseObjectClassAndName('%s')", objectBytes)
 
 	switch r.dataPowerAppliance.DpManagmentInterface() {
@@ -1825,7 +2353,7 @@ func (r *dpRepo) ParseObjectClassAndName(objectBytes []byte) (objectClass, objec
 }
 
 // GetItemInfo returns information about given item.
-func (r *dpRepo) GetItemInfo(itemConfig *model.ItemConfig) ([]byte, error) {
+func (r *DataPowerRepo) GetItemInfo(ctx context.Context, itemConfig *model.ItemConfig) ([]byte, error) {
 	logging.LogDebugf("repo/dp/GetItemInfo(%v)", itemConfig)
 
 	var itemInfo []byte
@@ -1848,7 +2376,7 @@ func (r *dpRepo) GetItemInfo(itemConfig *model.ItemConfig) ([]byte, error) {
 	return itemInfo, err
 }
 
-func (r *dpRepo) FlushCache(
+func (r *DataPowerRepo) FlushCache(ctx context.Context,
 	domainName, statusClass, statusName string, itemType model.ItemType) (bool, error) {
 	logging.LogDebugf("repo/dp/FlushCache('%s', '%s', '%s' (%s))",
 		domainName, statusClass, statusName, itemType)
@@ -1862,12 +2390,12 @@ func (r *dpRepo) FlushCache(
 				getStatusesURL := fmt.Sprintf("/mgmt/status/%s/%s", domainName, statusClass)
 				getStatusesQuery := fmt.Sprintf("/%s//XMLManager/value", statusClass)
 				statusNames, _, err :=
-					r.restGetForListResult(getStatusesURL, getStatusesQuery)
+					r.restGetForListResult(ctx, getStatusesURL, getStatusesQuery)
 				if err != nil {
 					return false, err
 				}
 				for _, statusName := range statusNames {
-					res, err := r.FlushCache(
+					res, err := r.FlushCache(ctx,
 						domainName, statusClass, statusName, model.ItemDpStatus)
 					if err != nil || !res {
 						return res, err
@@ -1888,7 +2416,7 @@ func (r *dpRepo) FlushCache(
 				</man:request>
 			</soapenv:Body>
 		</soapenv:Envelope>`, domainName, statusClass)
-				doc, err := r.somaGetDoc(somaStatusRequest)
+				doc, err := r.somaGetDoc(ctx, somaStatusRequest)
 				if err != nil {
 					return false, err
 				}
@@ -1902,7 +2430,7 @@ func (r *dpRepo) FlushCache(
 				}
 				for _, node := range nodes {
 					statusName := node.InnerText()
-					res, err := r.FlushCache(
+					res, err := r.FlushCache(ctx,
 						domainName, statusClass, statusName, model.ItemDpStatus)
 					if err != nil || !res {
 						return res, err
@@ -1934,7 +2462,7 @@ func (r *dpRepo) FlushCache(
 			flushRequestJSON :=
 				fmt.Sprintf(`{"%s":{"XMLManager":"%s"}}`, flushCacheOp, statusName)
 
-			jsonResponseString, err := r.rest(restActionPath, "POST", flushRequestJSON)
+			jsonResponseString, err := r.rest(ctx, restActionPath, "POST", flushRequestJSON)
 			if err != nil {
 				return false, err
 			}
@@ -1963,7 +2491,7 @@ func (r *dpRepo) FlushCache(
    </soapenv:Body>
 </soapenv:Envelope>`,
 				domainName, flushCacheOp, statusName, flushCacheOp)
-			somaResponse, err := r.soma(somaRequest)
+			somaResponse, err := r.soma(ctx, somaRequest)
 			if err != nil {
 				return false, err
 			}
@@ -1991,7 +2519,7 @@ func (r *dpRepo) FlushCache(
 }
 
 // GetManagementInterface returns current DataPower management interface used.
-func (r *dpRepo) GetManagementInterface() string {
+func (r *DataPowerRepo) GetManagementInterface() string {
 	return r.dataPowerAppliance.DpManagmentInterface()
 }
 
@@ -2024,10 +2552,25 @@ func listAppliances() (model.ItemList, error) {
 	return items, err
 }
 
+// GetDomainNames returns names of all domains on current DataPower.
+func (r *DataPowerRepo) GetDomainNames(ctx context.Context) ([]string, error) {
+	logging.LogDebug("repo/dp/GetDomainNames()")
+	domains, err := r.fetchDpDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(domains))
+	for idx, domain := range domains {
+		names[idx] = domain.name
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
 // listDomains loads DataPower domains from current DataPower.
-func (r *dpRepo) listDomains(selectedItemConfig *model.ItemConfig) (model.ItemList, error) {
+func (r *DataPowerRepo) listDomains(ctx context.Context, selectedItemConfig *model.ItemConfig) (model.ItemList, error) {
 	logging.LogDebugf("repo/dp/listDomains('%s')", selectedItemConfig)
-	domains, err := r.fetchDpDomains()
+	domains, err := r.fetchDpDomains(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -2059,11 +2602,11 @@ func (r *dpRepo) listDomains(selectedItemConfig *model.ItemConfig) (model.ItemLi
 }
 
 // listFilestores loads DataPower filestores in current domain (cert:, local:,..).
-func (r *dpRepo) listFilestores(selectedItemConfig *model.ItemConfig) (model.ItemList, error) {
+func (r *DataPowerRepo) listFilestores(ctx context.Context, selectedItemConfig *model.ItemConfig) (model.ItemList, error) {
 	logging.LogDebugf("repo/dp/listFilestores('%s')", selectedItemConfig)
 	switch r.dataPowerAppliance.DpManagmentInterface() {
 	case config.DpInterfaceRest:
-		jsonString, err := r.restGet("/mgmt/filestore/" + selectedItemConfig.DpDomain)
+		jsonString, err := r.restGet(ctx, "/mgmt/filestore/"+selectedItemConfig.DpDomain)
 		if err != nil {
 			return nil, err
 		}
@@ -2104,7 +2647,7 @@ func (r *dpRepo) listFilestores(selectedItemConfig *model.ItemConfig) (model.Ite
 		</man:request>
 	</soapenv:Body>
 </soapenv:Envelope>`, selectedItemConfig.DpDomain)
-		dpFilestoresXML, err := r.soma(somaRequest)
+		dpFilestoresXML, err := r.soma(ctx, somaRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -2138,10 +2681,10 @@ func (r *dpRepo) listFilestores(selectedItemConfig *model.ItemConfig) (model.Ite
 }
 
 // listDpDir loads DataPower directory (local:, local:///test,..).
-func (r *dpRepo) listDpDir(selectedItemConfig *model.ItemConfig) (model.ItemList, error) {
+func (r *DataPowerRepo) listDpDir(ctx context.Context, selectedItemConfig *model.ItemConfig) (model.ItemList, error) {
 	logging.LogDebugf("repo/dp/listDpDir('%s')", selectedItemConfig)
 	parentDir := model.Item{Name: "..", Config: selectedItemConfig.Parent}
-	filesDirs, err := r.listFiles(selectedItemConfig)
+	filesDirs, err := r.listFiles(ctx, selectedItemConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -2153,7 +2696,7 @@ func (r *dpRepo) listDpDir(selectedItemConfig *model.ItemConfig) (model.ItemList
 	return itemsWithParentDir, nil
 }
 
-func (r *dpRepo) fetchFilestoreIfNeeded(dpDomain, dpFilestoreLocation string, forceReload bool) error {
+func (r *DataPowerRepo) fetchFilestoreIfNeeded(ctx context.Context, dpDomain, dpFilestoreLocation string, forceReload bool) error {
 	if r.dataPowerAppliance.SomaUrl != "" {
 		// If we open filestore or open file but want to reload - refresh current filestore XML cache.
 		if forceReload || r.invalidateCache || r.dpFilestoreXmls[dpFilestoreLocation] == "" {
@@ -2165,7 +2708,7 @@ func (r *dpRepo) fetchFilestoreIfNeeded(dpDomain, dpFilestoreLocation string, fo
 	</soapenv:Body>
 </soapenv:Envelope>`, dpDomain, dpFilestoreLocation)
 			var err error
-			r.dpFilestoreXmls[dpFilestoreLocation], err = r.soma(somaRequest)
+			r.dpFilestoreXmls[dpFilestoreLocation], err = r.soma(ctx, somaRequest)
 			if err != nil {
 				return err
 			}
@@ -2175,14 +2718,14 @@ func (r *dpRepo) fetchFilestoreIfNeeded(dpDomain, dpFilestoreLocation string, fo
 	return nil
 }
 
-func (r *dpRepo) listFiles(selectedItemConfig *model.ItemConfig) ([]model.Item, error) {
+func (r *DataPowerRepo) listFiles(ctx context.Context, selectedItemConfig *model.ItemConfig) ([]model.Item, error) {
 	logging.LogDebugf("repo/dp/listFiles('%s')", selectedItemConfig)
 
 	switch r.dataPowerAppliance.DpManagmentInterface() {
 	case config.DpInterfaceRest:
 		items := make(model.ItemList, 0)
 		currRestDirPath := strings.Replace(selectedItemConfig.Path, ":", "", 1)
-		jsonString, err := r.restGet("/mgmt/filestore/" + selectedItemConfig.DpDomain + "/" + currRestDirPath)
+		jsonString, err := r.restGet(ctx, "/mgmt/filestore/"+selectedItemConfig.DpDomain+"/"+currRestDirPath)
 		if err != nil {
 			return nil, err
 		}
@@ -2236,7 +2779,7 @@ func (r *dpRepo) listFiles(selectedItemConfig *model.ItemConfig) ([]model.Item,
 		var dpFileNodes []*xmlquery.Node
 
 		// If we open filestore or open file but want to reload - refresh current filestore XML cache.
-		err := r.fetchFilestoreIfNeeded(selectedItemConfig.DpDomain, dpFilestoreLocation, dpFilestoreIsRoot)
+		err := r.fetchFilestoreIfNeeded(ctx, selectedItemConfig.DpDomain, dpFilestoreLocation, dpFilestoreIsRoot)
 		if err != nil {
 			logging.LogDebug("Error parsing response JSON.", err)
 			return nil, err
@@ -2304,7 +2847,7 @@ func (r *dpRepo) listFiles(selectedItemConfig *model.ItemConfig) ([]model.Item,
 }
 
 // listObjectClasses lists all object classes used in current DataPower domain.
-func (r *dpRepo) listObjectClasses(currentView *model.ItemConfig) (model.ItemList, error) {
+func (r *DataPowerRepo) listObjectClasses(ctx context.Context, currentView *model.ItemConfig) (model.ItemList, error) {
 	logging.LogDebugf("repo/dp/listObjectClasses(%v)", currentView)
 
 	if currentView.DpAppliance == "" {
@@ -2325,7 +2868,7 @@ func (r *dpRepo) listObjectClasses(currentView *model.ItemConfig) (model.ItemLis
 	case config.DpInterfaceRest:
 		listObjectStatusesURL := fmt.Sprintf("/mgmt/status/%s/ObjectStatus", currentView.DpDomain)
 		classNamesAndStatusesWithDuplicates, _, err =
-			r.restGetForListsResult(listObjectStatusesURL,
+			r.restGetForListsResult(ctx, listObjectStatusesURL,
 				"/ObjectStatus//Class", "/ObjectStatus//ConfigState")
 
 	case config.DpInterfaceSoma:
@@ -2339,7 +2882,7 @@ func (r *dpRepo) listObjectClasses(currentView *model.ItemConfig) (model.ItemLis
 	</soapenv:Body>
 </soapenv:Envelope>`, currentView.DpDomain)
 		var somaResponse string
-		somaResponse, err = r.soma(somaRequest)
+		somaResponse, err = r.soma(ctx, somaRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -2397,7 +2940,7 @@ func (r *dpRepo) listObjectClasses(currentView *model.ItemConfig) (model.ItemLis
 }
 
 // listObjects lists all objects of selected class in current DataPower domain.
-func (r *dpRepo) listObjects(itemConfig *model.ItemConfig) (model.ItemList, error) {
+func (r *DataPowerRepo) listObjects(ctx context.Context, itemConfig *model.ItemConfig) (model.ItemList, error) {
 	logging.LogDebugf("repo/dp/listObjects(%v)", itemConfig)
 
 	switch itemConfig.Type {
@@ -2418,7 +2961,7 @@ func (r *dpRepo) listObjects(itemConfig *model.ItemConfig) (model.ItemList, erro
 		// example: WebGUI (Name (status): "web-mgmt", name (config): "WebGUI-Settings").
 		listObjectStatusesURL := fmt.Sprintf("/mgmt/status/%s/ObjectStatus", itemConfig.DpDomain)
 		objectNamesAndStatuses, _, err :=
-			r.restGetForListsResult(listObjectStatusesURL,
+			r.restGetForListsResult(ctx, listObjectStatusesURL,
 				fmt.Sprintf("/ObjectStatus//Class[text()='%s']/../Name", objectClassName),
 				fmt.Sprintf("/ObjectStatus//Class[text()='%s']/../ConfigState", objectClassName),
 				fmt.Sprintf("/ObjectStatus//Class[text()='%s']/../OpState", objectClassName),
@@ -2431,7 +2974,7 @@ func (r *dpRepo) listObjects(itemConfig *model.ItemConfig) (model.ItemList, erro
 
 		listObjectsURL := fmt.Sprintf("/mgmt/config/%s/%s", itemConfig.DpDomain, objectClassName)
 		objectNameQuery := fmt.Sprintf("/%s//name", objectClassName)
-		objectNames, _, err := r.restGetForListResult(listObjectsURL, objectNameQuery)
+		objectNames, _, err := r.restGetForListResult(ctx, listObjectsURL, objectNameQuery)
 		if err != nil {
 			return nil, err
 		}
@@ -2502,11 +3045,11 @@ func (r *dpRepo) listObjects(itemConfig *model.ItemConfig) (model.ItemList, erro
 		</man:request>
 	</soapenv:Body>
 </soapenv:Envelope>`, itemConfig.DpDomain, objectClassName)
-		somaConfigResponse, err := r.soma(somaConfigRequest)
+		somaConfigResponse, err := r.soma(ctx, somaConfigRequest)
 		if err != nil {
 			return nil, err
 		}
-		somaStatusResponse, err := r.soma(somaStatusRequest)
+		somaStatusResponse, err := r.soma(ctx, somaStatusRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -2574,7 +3117,7 @@ func (r *dpRepo) listObjects(itemConfig *model.ItemConfig) (model.ItemList, erro
 }
 
 // listStatusClasses lists all status classes used in current DataPower domain.
-func (r *dpRepo) listStatusClasses(currentView *model.ItemConfig) (model.ItemList, error) {
+func (r *DataPowerRepo) listStatusClasses(ctx context.Context, currentView *model.ItemConfig) (model.ItemList, error) {
 	logging.LogDebugf("repo/dp/listStatusClasses(%v)", currentView)
 
 	if currentView.DpAppliance == "" {
@@ -2593,7 +3136,7 @@ func (r *dpRepo) listStatusClasses(currentView *model.ItemConfig) (model.ItemLis
 
 	switch r.dataPowerAppliance.DpManagmentInterface() {
 	case config.DpInterfaceRest:
-		responseJSON, err := r.restGet("/mgmt/status/")
+		responseJSON, err := r.restGet(ctx, "/mgmt/status/")
 		if err != nil {
 			return nil, err
 		}
@@ -2625,7 +3168,7 @@ func (r *dpRepo) listStatusClasses(currentView *model.ItemConfig) (model.ItemLis
 	</soapenv:Body>
 </soapenv:Envelope>`, currentView.DpDomain)
 		var somaResponse string
-		somaResponse, err = r.soma(somaRequest)
+		somaResponse, err = r.soma(ctx, somaRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -2761,7 +3304,7 @@ func parseStatusFromXML(
 }
 
 // listStatuses lists all statuses of selected class in current DataPower domain.
-func (r *dpRepo) listStatuses(itemConfig *model.ItemConfig) (model.ItemList, error) {
+func (r *DataPowerRepo) listStatuses(ctx context.Context, itemConfig *model.ItemConfig) (model.ItemList, error) {
 	logging.LogDebugf("repo/dp/listStatuses(%v)", itemConfig)
 
 	switch itemConfig.Type {
@@ -2776,7 +3319,7 @@ func (r *dpRepo) listStatuses(itemConfig *model.ItemConfig) (model.ItemList, err
 	case config.DpInterfaceRest:
 		getStatusesURL := fmt.Sprintf("/mgmt/status/%s/%s",
 			itemConfig.DpDomain, itemConfig.Path)
-		statusesRespJSON, err := r.restGet(getStatusesURL)
+		statusesRespJSON, err := r.restGet(ctx, getStatusesURL)
 		if err != nil {
 			if respErr, ok := err.(errs.UnexpectedHTTPResponse); ok && respErr.StatusCode == 404 {
 				return nil, nil
@@ -2856,7 +3399,7 @@ func (r *dpRepo) listStatuses(itemConfig *model.ItemConfig) (model.ItemList, err
 		</man:request>
 	</soapenv:Body>
 </soapenv:Envelope>`, itemConfig.DpDomain, itemConfig.Path)
-		doc, err := r.somaGetDoc(somaStatusRequest)
+		doc, err := r.somaGetDoc(ctx, somaStatusRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -2896,10 +3439,10 @@ func (r *dpRepo) listStatuses(itemConfig *model.ItemConfig) (model.ItemList, err
 	}
 }
 
-func (r *dpRepo) refreshSomaFiles(viewConfig *model.ItemConfig) error {
-	return r.refreshSomaFilesByPath(viewConfig.DpDomain, viewConfig.Path)
+func (r *DataPowerRepo) refreshSomaFiles(ctx context.Context, viewConfig *model.ItemConfig) error {
+	return r.refreshSomaFilesByPath(ctx, viewConfig.DpDomain, viewConfig.Path)
 }
-func (r *dpRepo) refreshSomaFilesByPath(dpDomain, path string) error {
+func (r *DataPowerRepo) refreshSomaFilesByPath(ctx context.Context, dpDomain, path string) error {
 	if r.dataPowerAppliance.SomaUrl != "" {
 		filestoreEndIdx := strings.Index(path, ":")
 		if filestoreEndIdx == -1 {
@@ -2907,7 +3450,7 @@ func (r *dpRepo) refreshSomaFilesByPath(dpDomain, path string) error {
 		}
 
 		dpFilestoreLocation := path[:filestoreEndIdx] + ":"
-		err := r.fetchFilestoreIfNeeded(dpDomain, dpFilestoreLocation, true)
+		err := r.fetchFilestoreIfNeeded(ctx, dpDomain, dpFilestoreLocation, true)
 		return err
 	}
 
@@ -2915,7 +3458,7 @@ func (r *dpRepo) refreshSomaFilesByPath(dpDomain, path string) error {
 	return errs.Error("Internal error - refreshSomaFilesByPath() called for non-SOMA.")
 }
 
-func (r *dpRepo) findItemConfigParentDomain(itemConfig *model.ItemConfig) *model.ItemConfig {
+func (r *DataPowerRepo) findItemConfigParentDomain(itemConfig *model.ItemConfig) *model.ItemConfig {
 	if itemConfig.Type == model.ItemDpDomain {
 		return itemConfig
 	}
@@ -2925,21 +3468,21 @@ func (r *dpRepo) findItemConfigParentDomain(itemConfig *model.ItemConfig) *model
 	return r.findItemConfigParentDomain(itemConfig.Parent)
 }
 
-func (r *dpRepo) fetchDpDomains() ([]dpDomainInfo, error) {
+func (r *DataPowerRepo) fetchDpDomains(ctx context.Context) ([]dpDomainInfo, error) {
 	logging.LogDebug("repo/dp/fetchDpDomains()")
 	domains := make([]dpDomainInfo, 0)
 
 	switch r.dataPowerAppliance.DpManagmentInterface() {
 	case config.DpInterfaceRest:
 		// Fetch config of all domains (so we can show if domain is enabled)
-		domainsConfigDoc, err := r.restGetDoc("/mgmt/config/default/Domain")
+		domainsConfigDoc, err := r.restGetDoc(ctx, "/mgmt/config/default/Domain")
 		if err != nil {
 			return nil, err
 		}
 		domainConfigList := jsonquery.Find(domainsConfigDoc, "/Domain/*")
 
 		// Fetch status of all domains (so we can show if domain is saved)
-		domainsStatusDoc, err := r.restGetDoc("/mgmt/status/default/DomainStatus")
+		domainsStatusDoc, err := r.restGetDoc(ctx, "/mgmt/status/default/DomainStatus")
 		if err != nil {
 			return nil, err
 		}
@@ -2968,7 +3511,7 @@ func (r *dpRepo) fetchDpDomains() ([]dpDomainInfo, error) {
 		</man:request>
 	</soapenv:Body>
 </soapenv:Envelope>`
-		domainsConfigDoc, err := r.somaGetDoc(somaConfigRequest)
+		domainsConfigDoc, err := r.somaGetDoc(ctx, somaConfigRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -2984,7 +3527,7 @@ func (r *dpRepo) fetchDpDomains() ([]dpDomainInfo, error) {
 		</man:request>
 	</soapenv:Body>
 </soapenv:Envelope>`
-		domainsStatusDoc, err := r.somaGetDoc(somaStatusRequest)
+		domainsStatusDoc, err := r.somaGetDoc(ctx, somaStatusRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -3014,8 +3557,8 @@ func (r *dpRepo) fetchDpDomains() ([]dpDomainInfo, error) {
 	return domains, nil
 }
 
-func (r *dpRepo) restPostForResult(urlPath, postBody, checkQuery, checkExpected, resultQuery string) (result, responseJSON string, err error) {
-	responseJSON, err = r.rest(urlPath, "POST", postBody)
+func (r *DataPowerRepo) restPostForResult(ctx context.Context, urlPath, postBody, checkQuery, checkExpected, resultQuery string) (result, responseJSON string, err error) {
+	responseJSON, err = r.rest(ctx, urlPath, "POST", postBody)
 	if err != nil {
 		return "", "", err
 	}
@@ -3048,8 +3591,8 @@ func (r *dpRepo) restPostForResult(urlPath, postBody, checkQuery, checkExpected,
 	return result, responseJSON, nil
 }
 
-func (r *dpRepo) restGetForOneResult(urlPath, resultQuery string) (result, responseJSON string, err error) {
-	responseJSON, err = r.restGet(urlPath)
+func (r *DataPowerRepo) restGetForOneResult(ctx context.Context, urlPath, resultQuery string) (result, responseJSON string, err error) {
+	responseJSON, err = r.restGet(ctx, urlPath)
 	if err != nil {
 		return "", "", err
 	}
@@ -3059,6 +3602,37 @@ func (r *dpRepo) restGetForOneResult(urlPath, resultQuery string) (result, respo
 	return result, responseJSON, err
 }
 
+// restWaitForActionCompleted polls status of the asynchronous REST action
+// (from the actionqueue) until it is completed and returns last JSON response.
+func (r *DataPowerRepo) restWaitForActionCompleted(ctx context.Context, actionName, locationURL string) (string, error) {
+	logging.LogDebugf("repo/dp/restWaitForActionCompleted('%s', '%s')", actionName, locationURL)
+	timeStart := time.Now()
+	for {
+		status, responseJSON, err := r.restGetForOneResult(ctx, locationURL, "/status")
+		logging.LogDebugf("repo/dp/restWaitForActionCompleted() status: '%s'", status)
+		if err != nil {
+			return "", err
+		}
+
+		switch status {
+		case "started":
+			if time.Since(timeStart) > 120*time.Second {
+				logging.LogDebugf("repo/dp/restWaitForActionCompleted() waiting for %s since %v, giving up.\n last responseJSON: '%s'", actionName, timeStart, responseJSON)
+				return "", errs.Errorf("%s didn't finish since %v, giving up.", actionName, timeStart)
+			}
+			err = waitContext(ctx, 1*time.Second)
+			if err != nil {
+				return "", err
+			}
+		case "completed":
+			logging.LogDebugf("repo/dp/restWaitForActionCompleted() %s completed after %v.", actionName, time.Since(timeStart))
+			return responseJSON, nil
+		default:
+			return "", errs.Errorf("Unexpected response from server ('%s').", status)
+		}
+	}
+}
+
 // parseJSONFindOne query JSON and returns a string value.
 func parseJSONFindOne(json, query string) (string, error) {
 	doc, err := jsonquery.Parse(strings.NewReader(json))
@@ -3077,8 +3651,8 @@ func parseJSONFindOne(json, query string) (string, error) {
 }
 
 // restGetForListResult makes REST call and parses JSON response.
-func (r *dpRepo) restGetForListResult(urlPath, resultQuery string) (result []string, responseJSON string, err error) {
-	responseJSON, err = r.restGet(urlPath)
+func (r *DataPowerRepo) restGetForListResult(ctx context.Context, urlPath, resultQuery string) (result []string, responseJSON string, err error) {
+	responseJSON, err = r.restGet(ctx, urlPath)
 	if err != nil {
 		return nil, "", err
 	}
@@ -3089,8 +3663,8 @@ func (r *dpRepo) restGetForListResult(urlPath, resultQuery string) (result []str
 }
 
 // restGetForListsResult makes REST call and parses JSON response multiple times.
-func (r *dpRepo) restGetForListsResult(urlPath string, resultQueries ...string) (results [][]string, responseJSON string, err error) {
-	responseJSON, err = r.restGet(urlPath)
+func (r *DataPowerRepo) restGetForListsResult(ctx context.Context, urlPath string, resultQueries ...string) (results [][]string, responseJSON string, err error) {
+	responseJSON, err = r.restGet(ctx, urlPath)
 	if err != nil {
 		return nil, "", err
 	}
@@ -3358,33 +3932,42 @@ func splitOnLast(wholeString string, splitterString string) (string, string) {
 	return prefix, suffix
 }
 
-// InitNetworkSettings initializes DataPower client network configuration.
-func (r *dpRepo) InitNetworkSettings(applianceName string,
+// waitContext waits for given duration or until context is canceled (returns
+// context error in that case).
+func waitContext(ctx context.Context, duration time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(duration):
+		return nil
+	}
+}
+
+// InitNetworkSettings initializes DataPower client network configuration
+// (each appliance uses its own HTTP client with its proxy, TLS and timeout
+// settings).
+func (r *DataPowerRepo) InitNetworkSettings(applianceName string,
 	dpa config.DataPowerAppliance) error {
 	logging.LogDebugf("repo/dp/InitNetworkSettings(%v)", dpa)
 	r.dataPowerAppliance = dpApplicance{name: applianceName, DataPowerAppliance: dpa}
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	if r.dataPowerAppliance.Proxy != "" {
-		proxyURL, err := url.Parse(r.dataPowerAppliance.Proxy)
-		if err != nil {
-			logging.LogDebug("Couldn't initialize network settings to access DataPower.", err)
-			return err
-		}
-		http.DefaultTransport.(*http.Transport).Proxy = http.ProxyURL(proxyURL)
+	_, err := getClient(r.dataPowerAppliance)
+	if err != nil {
+		logging.LogDebug("Couldn't initialize network settings to access DataPower.", err)
+		return err
 	}
 	return nil
 }
 
 // rest makes http request from relative URL path given, method and body.
-func (r *dpRepo) rest(urlPath, method, body string) (string, error) {
+func (r *DataPowerRepo) rest(ctx context.Context, urlPath, method, body string) (string, error) {
 	fullURL := r.dataPowerAppliance.RestUrl + urlPath
-	return r.httpRequest(fullURL, method, body)
+	return r.httpRequest(ctx, fullURL, method, body)
 }
 
 // restGetDoc makes DataPower REST GET request and returns parsed JSON doc.
-func (r *dpRepo) restGetDoc(urlPath string) (*jsonquery.Node, error) {
+func (r *DataPowerRepo) restGetDoc(ctx context.Context, urlPath string) (*jsonquery.Node, error) {
 	logging.LogDebugf("repo/dp/restGetDoc('%s')", urlPath)
-	bodyString, err := r.restGet(urlPath)
+	bodyString, err := r.restGet(ctx, urlPath)
 	if err != nil {
 		return nil, err
 	}
@@ -3398,24 +3981,24 @@ func (r *dpRepo) restGetDoc(urlPath string) (*jsonquery.Node, error) {
 }
 
 // restGet makes DataPower REST GET request.
-func (r *dpRepo) restGet(urlPath string) (string, error) {
-	return r.rest(urlPath, "GET", "")
+func (r *DataPowerRepo) restGet(ctx context.Context, urlPath string) (string, error) {
+	return r.rest(ctx, urlPath, "GET", "")
 }
 
 // amp makes DataPower AMP request.
-func (r *dpRepo) amp(body string) (string, error) {
-	return r.httpRequest(r.dataPowerAppliance.SomaUrl+"/service/mgmt/amp/1.0", "POST", body)
+func (r *DataPowerRepo) amp(ctx context.Context, body string) (string, error) {
+	return r.httpRequest(ctx, r.dataPowerAppliance.SomaUrl+"/service/mgmt/amp/1.0", "POST", body)
 }
 
 // soma makes DataPower SOMA request.
-func (r *dpRepo) soma(body string) (string, error) {
-	return r.httpRequest(r.dataPowerAppliance.SomaUrl+"/service/mgmt/current", "POST", body)
+func (r *DataPowerRepo) soma(ctx context.Context, body string) (string, error) {
+	return r.httpRequest(ctx, r.dataPowerAppliance.SomaUrl+"/service/mgmt/current", "POST", body)
 }
 
 // somaGetDoc makes DataPower SOMA request and returns parsed XML doc.
-func (r *dpRepo) somaGetDoc(body string) (*xmlquery.Node, error) {
+func (r *DataPowerRepo) somaGetDoc(ctx context.Context, body string) (*xmlquery.Node, error) {
 	logging.LogDebugf("repo/dp/somaGetDoc('%s')", body)
-	bodyString, err := r.soma(body)
+	bodyString, err := r.soma(ctx, body)
 	if err != nil {
 		return nil, err
 	}
@@ -3433,20 +4016,47 @@ func (r *dpRepo) somaGetDoc(body string) (*xmlquery.Node, error) {
 }
 
 type requester interface {
-	httpRequest(dpa dpApplicance, urlFullPath, method, body string) (string, error)
+	httpRequest(ctx context.Context, dpa dpApplicance, urlFullPath, method, body string) (string, error)
 }
 
 type netRequester struct{}
 
-func (nr netRequester) httpRequest(dpa dpApplicance, urlFullPath, method, body string) (string, error) {
+// httpRequest makes DataPower HTTP request using appliance HTTP client, GET
+// requests which failed because of network problems are retried (with backoff).
+func (nr netRequester) httpRequest(ctx context.Context, dpa dpApplicance, urlFullPath, method, body string) (string, error) {
 	logging.LogTracef("repo/dp/httpRequest(%s, %s, '%s')", urlFullPath, method, body)
 
-	client := &http.Client{}
+	client, err := getClient(dpa)
+	if err != nil {
+		return "", err
+	}
+	maxRetries := 0
+	if method == "GET" {
+		maxRetries = config.Conf.Net.MaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		result, err := nr.doHTTPRequest(ctx, client, dpa, urlFullPath, method, body)
+		if err == nil || attempt >= maxRetries || ctx.Err() != nil || !retryableError(err) {
+			return result, err
+		}
+		backoff := retryBackoff << uint(attempt)
+		logging.LogDebugf("repo/dp/httpRequest() - Retrying %s call to '%s' in %v (err: %v).",
+			method, urlFullPath, backoff, err)
+		err = waitContext(ctx, backoff)
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// doHTTPRequest makes single DataPower HTTP request.
+func (nr netRequester) doHTTPRequest(ctx context.Context, client *http.Client, dpa dpApplicance, urlFullPath, method, body string) (string, error) {
 	var bodyReader io.Reader
 	if body != "" {
 		bodyReader = strings.NewReader(body)
 	}
-	req, err := http.NewRequest(method, urlFullPath, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, urlFullPath, bodyReader)
 	if err != nil {
 		logging.LogDebug("repo/dp/httpRequest() - Can't prepare request: ", err)
 		return "", err
@@ -3457,7 +4067,7 @@ func (nr netRequester) httpRequest(dpa dpApplicance, urlFullPath, method, body s
 
 	if err != nil {
 		logging.LogDebug("repo/dp/httpRequest() - Can't send request: ", err)
-		return "", err
+		return "", timeoutError(dpa, method, urlFullPath, err)
 		// 2019/10/22 08:39:14 dp Can't send request: Post https://10.123.56.55:5550/service/mgmt/current: dial tcp 10.123.56.55:5550: i/o timeout
 		//exit status 1
 	}
@@ -3468,7 +4078,7 @@ func (nr netRequester) httpRequest(dpa dpApplicance, urlFullPath, method, body s
 		bodyBytes, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
 			logging.LogDebug("repo/dp/httpRequest() - Can't read response: ", err)
-			return "", err
+			return "", timeoutError(dpa, method, urlFullPath, err)
 		}
 		logging.LogTracef("repo/dp/httpRequest() - httpResponse: '%s'", string(bodyBytes))
 		return string(bodyBytes), nil
@@ -3483,8 +4093,8 @@ func (nr netRequester) httpRequest(dpa dpApplicance, urlFullPath, method, body s
 }
 
 // httpRequest makes DataPower HTTP request.
-func (r *dpRepo) httpRequest(urlFullPath, method, body string) (string, error) {
-	return r.req.httpRequest(r.dataPowerAppliance, urlFullPath, method, body)
+func (r *DataPowerRepo) httpRequest(ctx context.Context, urlFullPath, method, body string) (string, error) {
+	return r.req.httpRequest(ctx, r.dataPowerAppliance, urlFullPath, method, body)
 }
 
 // makeRestPath creates DataPower REST path to given domain.
@@ -1,17 +1,23 @@
 package dp
 
 import (
+	"archive/zip"
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"github.com/clbanning/mxj"
 	"github.com/croz-ltd/dpcmder/config"
 	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/repo/localfs"
 	"github.com/croz-ltd/dpcmder/utils/assert"
 	"github.com/croz-ltd/dpcmder/utils/errs"
 	"io/ioutil"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 const (
@@ -19,6 +25,16 @@ const (
 	testSomaURL = "https://my_dp_host:5550"
 )
 
+func TestDpApplianceString(t *testing.T) {
+	dpa := dpApplicance{name: "dev", DataPowerAppliance: config.DataPowerAppliance{RestUrl: testRestURL}}
+	dpa.SetDpPlaintextPassword("s3cret")
+	for _, format := range []string{"%v", "%+v", "%#v"} {
+		logged := fmt.Sprintf(format, dpa)
+		assert.Equals(t, "Sprintf('"+format+"') password masked", strings.Contains(logged, dpa.Password), false)
+		assert.Equals(t, "Sprintf('"+format+"') name", strings.Contains(logged, "dev"), true)
+	}
+}
+
 func clearRepo() {
 	Repo.dpFilestoreXmls = make(map[string]string)
 	Repo.invalidateCache = false
@@ -33,11 +49,55 @@ func TestString(t *testing.T) {
 	assert.Equals(t, "Sync DataPower repo", SyncRepo.String(), "SyncDataPower")
 }
 
+func TestClone(t *testing.T) {
+	clearRepo()
+	Repo.dataPowerAppliance = dpApplicance{name: "MyApplianceName"}
+	Repo.dataPowerAppliance.RestUrl = testRestURL
+	Repo.DpViewMode = model.DpObjectMode
+	Repo.dpFilestoreXmls["test:local:"] = "<filestore/>"
+
+	clone := Repo.Clone()
+	assert.Equals(t, "Clone() name", clone.String(), Repo.String())
+	assert.DeepEqual(t, "Clone() appliance", clone.dataPowerAppliance, Repo.dataPowerAppliance)
+	assert.Equals(t, "Clone() view mode", clone.DpViewMode, model.DpObjectMode)
+	assert.Equals(t, "Clone() cache", len(clone.dpFilestoreXmls), 0)
+
+	Repo.dataPowerAppliance = dpApplicance{name: "OtherApplianceName"}
+	assert.Equals(t, "Clone() appliance unchanged", clone.dataPowerAppliance.name, "MyApplianceName")
+	clearRepo()
+}
+
+func TestNewRepo(t *testing.T) {
+	newRepo := NewRepo("CompareDataPower", model.DpObjectMode)
+	assert.Equals(t, "NewRepo() name", newRepo.String(), "CompareDataPower")
+	assert.Equals(t, "NewRepo() view mode", newRepo.DpViewMode, model.DpObjectMode)
+	assert.Equals(t, "NewRepo() cache", len(newRepo.dpFilestoreXmls), 0)
+}
+
+func TestGetDomainNames(t *testing.T) {
+	clearRepo()
+	Repo.req = mockRequester{}
+
+	_, err := Repo.GetDomainNames(context.Background())
+	assert.Equals(t, "GetDomainNames() without appliance", err,
+		errs.Error("DataPower management interface not set."))
+
+	for _, dpa := range []config.DataPowerAppliance{
+		{RestUrl: testRestURL, Username: "user"},
+		{SomaUrl: testSomaURL, Username: "user"}} {
+		Repo.dataPowerAppliance = dpApplicance{name: "MyApplianceName", DataPowerAppliance: dpa}
+		names, err := Repo.GetDomainNames(context.Background())
+		assert.Equals(t, "GetDomainNames() error", err, nil)
+		assert.DeepEqual(t, "GetDomainNames()", names, []string{"default", "test"})
+	}
+	clearRepo()
+}
+
 func TestGetInitialItem(t *testing.T) {
 	t.Run("Showing list of configurations", func(t *testing.T) {
 		clearRepo()
 
-		ii, err := Repo.GetInitialItem()
+		ii, err := Repo.GetInitialItem(context.Background())
 		assert.Equals(t, "GetInitialItem", err, nil)
 		assert.DeepEqual(t, "GetInitialItem",
 			ii,
@@ -51,7 +111,7 @@ func TestGetInitialItem(t *testing.T) {
 
 		Repo.dataPowerAppliance.RestUrl = testRestURL
 		Repo.dataPowerAppliance.name = "MyApplianceName"
-		ii, err := Repo.GetInitialItem()
+		ii, err := Repo.GetInitialItem(context.Background())
 		assert.Equals(t, "GetInitialItem", err, nil)
 		assert.DeepEqual(t, "GetInitialItem",
 			ii,
@@ -69,7 +129,7 @@ func TestGetInitialItem(t *testing.T) {
 		Repo.dataPowerAppliance.RestUrl = testRestURL
 		Repo.dataPowerAppliance.Domain = "my_domain"
 		Repo.dataPowerAppliance.name = "MyApplianceName"
-		ii, err := Repo.GetInitialItem()
+		ii, err := Repo.GetInitialItem(context.Background())
 		assert.Equals(t, "GetInitialItem", err, nil)
 		assert.DeepEqual(t, "GetInitialItem",
 			ii,
@@ -146,7 +206,7 @@ func TestGetList(t *testing.T) {
 		Repo.req = mockRequester{}
 
 		itemToShow := model.ItemConfig{Type: model.ItemDpObjectClassList}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("Internal error showing object config mode - missing dp appliance."))
@@ -154,7 +214,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("Internal error showing object config mode - missing domain."))
@@ -162,7 +222,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow.DpDomain = "MyDomain"
 		itemToShow.Type = model.ItemDirectory
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("Internal error showing object config mode - wrong view type."))
@@ -186,7 +246,7 @@ func TestGetList(t *testing.T) {
 			DpFilestore: "local:",
 			Path:        "Object classes"}
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 43)
@@ -219,7 +279,7 @@ func TestGetList(t *testing.T) {
 		}
 
 		itemToShow.Type = model.ItemDirectory
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("Internal error showing object config mode - wrong view type."))
@@ -241,7 +301,7 @@ func TestGetList(t *testing.T) {
 			DpAppliance: "MyApplianceName",
 			DpDomain:    "MyDomain", DpFilestore: "local:", Path: "Object classes"}
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 43)
@@ -289,7 +349,7 @@ func TestGetList(t *testing.T) {
 			DpAppliance: "MyApplianceName",
 			DpDomain:    "MyDomain", DpFilestore: "local:", Path: "XMLFirewallService"}
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 9)
@@ -345,7 +405,7 @@ func TestGetList(t *testing.T) {
 			DpAppliance: "MyApplianceName",
 			DpDomain:    "MyDomain", DpFilestore: "local:", Path: "XMLFirewallService"}
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 9)
@@ -398,7 +458,7 @@ func TestGetList(t *testing.T) {
 		Repo.req = mockRequester{}
 
 		itemToShow := model.ItemConfig{Type: model.ItemDpStatusClassList}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("Internal error showing status config mode - missing dp appliance."))
@@ -406,7 +466,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("Internal error showing status config mode - missing domain."))
@@ -414,7 +474,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow.DpDomain = "MyDomain"
 		itemToShow.Type = model.ItemDirectory
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("Internal error showing status config mode - wrong view type: s."))
@@ -439,7 +499,7 @@ func TestGetList(t *testing.T) {
 			Path:        "Status classes"}
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
 
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 147)
@@ -479,7 +539,7 @@ func TestGetList(t *testing.T) {
 		}
 
 		itemToShow.Type = model.ItemDirectory
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("Internal error showing status config mode - wrong view type: s."))
@@ -501,7 +561,7 @@ func TestGetList(t *testing.T) {
 			DpAppliance: "MyApplianceName",
 			DpDomain:    "MyDomain", DpFilestore: "local:", Path: "Status classes"}
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 64)
@@ -577,7 +637,7 @@ func TestGetList(t *testing.T) {
 			DpAppliance: "MyApplianceName",
 			DpDomain:    "MyDomain", DpFilestore: "local:", Path: "StylesheetCachingSummary"}
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 4)
@@ -623,7 +683,7 @@ func TestGetList(t *testing.T) {
 			DpAppliance: "MyApplianceName",
 			DpDomain:    "MyDomain", DpFilestore: "local:", Path: "ActiveUsers"}
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 9)
@@ -663,7 +723,7 @@ func TestGetList(t *testing.T) {
 			DpAppliance: "MyApplianceName",
 			DpDomain:    "MyDomain", DpFilestore: "local:", Path: "StylesheetCachingSummary"}
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 4)
@@ -709,7 +769,7 @@ func TestGetList(t *testing.T) {
 			DpAppliance: "MyApplianceName",
 			DpDomain:    "MyDomain", DpFilestore: "local:", Path: "CryptoEngineStatus2"}
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 2)
@@ -743,7 +803,7 @@ func TestGetList(t *testing.T) {
 			DpAppliance: "MyApplianceName",
 			DpDomain:    "MyDomain", DpFilestore: "local:", Path: "ActiveUsers"}
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 9)
@@ -777,7 +837,7 @@ func TestGetList(t *testing.T) {
 		}
 
 		itemToShow := model.ItemConfig{Type: model.ItemNone}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("No appliances found, have to configure dpcmder with command line params first."))
@@ -795,7 +855,7 @@ func TestGetList(t *testing.T) {
 		config.Conf.DataPowerAppliances["dpa1"] = dpa1
 		config.Conf.DataPowerAppliances["dpa2"] = dpa2
 
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 2)
@@ -824,7 +884,7 @@ func TestGetList(t *testing.T) {
 		Repo.req = mockRequester{}
 
 		itemToShow := model.ItemConfig{Type: model.ItemDpConfiguration}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("DataPower management interface not set."))
@@ -838,7 +898,7 @@ func TestGetList(t *testing.T) {
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
 
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 3)
@@ -871,7 +931,7 @@ func TestGetList(t *testing.T) {
 		Repo.req = mockRequester{}
 
 		itemToShow := model.ItemConfig{Type: model.ItemDpConfiguration}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("DataPower management interface not set."))
@@ -885,7 +945,7 @@ func TestGetList(t *testing.T) {
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
 
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 3)
@@ -919,7 +979,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow := model.ItemConfig{Type: model.ItemDpConfiguration,
 			DpDomain: "test"}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("DataPower management interface not set."))
@@ -933,7 +993,7 @@ func TestGetList(t *testing.T) {
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
 
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 13)
@@ -977,7 +1037,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow := model.ItemConfig{Type: model.ItemDpConfiguration,
 			DpDomain: "test"}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("DataPower management interface not set."))
@@ -991,7 +1051,7 @@ func TestGetList(t *testing.T) {
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
 
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 13)
@@ -1035,7 +1095,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow := model.ItemConfig{Type: model.ItemDpDomain,
 			DpDomain: "test"}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("DataPower management interface not set."))
@@ -1049,7 +1109,7 @@ func TestGetList(t *testing.T) {
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
 
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 13)
@@ -1093,7 +1153,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow := model.ItemConfig{Type: model.ItemDpDomain,
 			DpDomain: "test"}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("DataPower management interface not set."))
@@ -1107,7 +1167,7 @@ func TestGetList(t *testing.T) {
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
 
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 13)
@@ -1151,7 +1211,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow := model.ItemConfig{Type: model.ItemDpFilestore,
 			DpDomain: "test", DpFilestore: "store:", Path: "store:"}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("DataPower management interface not set."))
@@ -1165,7 +1225,7 @@ func TestGetList(t *testing.T) {
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
 
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 189)
@@ -1214,7 +1274,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow := model.ItemConfig{Type: model.ItemDpFilestore,
 			DpDomain: "test", DpFilestore: "store:", Path: "store:"}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("DataPower management interface not set."))
@@ -1228,7 +1288,7 @@ func TestGetList(t *testing.T) {
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
 
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 191)
@@ -1277,7 +1337,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow := model.ItemConfig{Type: model.ItemDirectory,
 			DpDomain: "test", DpFilestore: "store:", Path: "store:/gatewayscript"}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("DataPower management interface not set."))
@@ -1291,7 +1351,7 @@ func TestGetList(t *testing.T) {
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
 
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 30)
@@ -1333,7 +1393,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow := model.ItemConfig{Type: model.ItemDirectory,
 			DpDomain: "test", DpFilestore: "store:", Path: "store:/gatewayscript"}
-		itemList, err := Repo.GetList(&itemToShow)
+		itemList, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("DataPower management interface not set."))
@@ -1347,7 +1407,7 @@ func TestGetList(t *testing.T) {
 		itemToShow.DpAppliance = "MyApplianceName"
 		config.Conf.DataPowerAppliances[itemToShow.DpAppliance] = dpa
 
-		itemList, err = Repo.GetList(&itemToShow)
+		itemList, err = Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err, nil)
 		assert.Equals(t, "GetList", len(itemList), 31)
@@ -1389,7 +1449,7 @@ func TestGetList(t *testing.T) {
 
 		itemToShow := model.ItemConfig{Type: model.ItemDpObjectClassList,
 			DpDomain: "test", DpFilestore: "store:", Path: "store:/gatewayscript"}
-		_, err := Repo.GetList(&itemToShow)
+		_, err := Repo.GetList(context.Background(), &itemToShow)
 
 		assert.Equals(t, "GetList", err,
 			errs.Error("Internal error showing filestore mode - wrong view type."))
@@ -1420,7 +1480,7 @@ func TestGetFile(t *testing.T) {
 		dpa := config.DataPowerAppliance{}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		fileBytesGot, err := Repo.GetFile(&currentView, "non-existing-file.js")
+		fileBytesGot, err := Repo.GetFile(context.Background(), &currentView, "non-existing-file.js")
 		assert.Equals(t, "GetFile", err, errs.Error("DataPower management interface not set."))
 		assert.Nil(t, "GetFile", fileBytesGot)
 	})
@@ -1432,7 +1492,7 @@ func TestGetFile(t *testing.T) {
 		fileBytesWant, err := ioutil.ReadFile("testdata/example-context.js")
 		assert.Nil(t, "GetFile", err)
 		assert.NotNil(t, "GetFile/Setup", fileBytesWant)
-		fileBytesGot, err := Repo.GetFile(&currentView, "example-context.js")
+		fileBytesGot, err := Repo.GetFile(context.Background(), &currentView, "example-context.js")
 		assert.Nil(t, "GetFile", err)
 		assert.Equals(t, "GetFile", fileBytesGot, fileBytesWant)
 	})
@@ -1441,7 +1501,7 @@ func TestGetFile(t *testing.T) {
 		dpa := config.DataPowerAppliance{RestUrl: testRestURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		fileBytesGot, err := Repo.GetFile(&currentView, "non-existing-file.js")
+		fileBytesGot, err := Repo.GetFile(context.Background(), &currentView, "non-existing-file.js")
 		assert.NotNil(t, "GetFile", err)
 		assert.Equals(t, "GetFile", err, errs.Error("Unexpected JSON, can't find '/file'."))
 		assert.Nil(t, "GetFile", fileBytesGot)
@@ -1451,7 +1511,7 @@ func TestGetFile(t *testing.T) {
 		dpa := config.DataPowerAppliance{RestUrl: testRestURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		fileBytesGot, err := Repo.GetFile(&currentView, "b64-err-file.txt")
+		fileBytesGot, err := Repo.GetFile(context.Background(), &currentView, "b64-err-file.txt")
 		assert.NotNil(t, "GetFile", err)
 		assert.DeepEqual(t, "GetFile", err, base64.CorruptInputError(3))
 		assert.Nil(t, "GetFile", fileBytesGot)
@@ -1464,7 +1524,7 @@ func TestGetFile(t *testing.T) {
 		fileBytesWant, err := ioutil.ReadFile("testdata/example-context.js")
 		assert.Nil(t, "GetFile", err)
 		assert.NotNil(t, "GetFile/Setup", fileBytesWant)
-		fileBytesGot, err := Repo.GetFile(&currentView, "example-context.js")
+		fileBytesGot, err := Repo.GetFile(context.Background(), &currentView, "example-context.js")
 		assert.Nil(t, "GetFile", err)
 		assert.Equals(t, "GetFile", fileBytesGot, fileBytesWant)
 	})
@@ -1473,7 +1533,7 @@ func TestGetFile(t *testing.T) {
 		dpa := config.DataPowerAppliance{SomaUrl: testSomaURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		fileBytesGot, err := Repo.GetFile(&currentView, "non-existing-file.js")
+		fileBytesGot, err := Repo.GetFile(context.Background(), &currentView, "non-existing-file.js")
 		assert.NotNil(t, "GetFile", err)
 		assert.Equals(t, "GetFile", err, errs.Error("Can't find file 'store:/gatewayscript/non-existing-file.js' from SOMA response."))
 		assert.Nil(t, "GetFile", fileBytesGot)
@@ -1483,7 +1543,7 @@ func TestGetFile(t *testing.T) {
 		dpa := config.DataPowerAppliance{SomaUrl: testSomaURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		fileBytesGot, err := Repo.GetFile(&currentView, "b64-err-file.txt")
+		fileBytesGot, err := Repo.GetFile(context.Background(), &currentView, "b64-err-file.txt")
 		assert.NotNil(t, "GetFile", err)
 		assert.DeepEqual(t, "GetFile", err, base64.CorruptInputError(3))
 		assert.Nil(t, "GetFile", fileBytesGot)
@@ -1499,7 +1559,7 @@ func TestUpdateFile(t *testing.T) {
 		dpa := config.DataPowerAppliance{}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		res, err := Repo.UpdateFile(&currentView, "test-file.txt", []byte("Hello World!"))
+		res, err := Repo.UpdateFile(context.Background(), &currentView, "test-file.txt", []byte("Hello World!"))
 		assert.Equals(t, "UpdateFile", err, errs.Error("DataPower management interface not set."))
 		assert.False(t, "UpdateFile", res)
 	})
@@ -1508,7 +1568,7 @@ func TestUpdateFile(t *testing.T) {
 		dpa := config.DataPowerAppliance{RestUrl: testRestURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		res, err := Repo.UpdateFile(&currentView, "test-new-file.txt", []byte("Hello World!"))
+		res, err := Repo.UpdateFile(context.Background(), &currentView, "test-new-file.txt", []byte("Hello World!"))
 		assert.Nil(t, "UpdateFile", err)
 		assert.True(t, "UpdateFile", res)
 	})
@@ -1517,7 +1577,7 @@ func TestUpdateFile(t *testing.T) {
 		dpa := config.DataPowerAppliance{RestUrl: testRestURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		res, err := Repo.UpdateFile(&currentView, "test-existing-file.txt", []byte("Hello World!"))
+		res, err := Repo.UpdateFile(context.Background(), &currentView, "test-existing-file.txt", []byte("Hello World!"))
 		assert.Nil(t, "UpdateFile", err)
 		assert.True(t, "UpdateFile", res)
 	})
@@ -1526,7 +1586,7 @@ func TestUpdateFile(t *testing.T) {
 		dpa := config.DataPowerAppliance{RestUrl: testRestURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		res, err := Repo.UpdateFile(&currentView, "test-existing-dir", []byte("Hello World!"))
+		res, err := Repo.UpdateFile(context.Background(), &currentView, "test-existing-dir", []byte("Hello World!"))
 		assert.Equals(t, "UpdateFile", err,
 			errs.Error("Can't upload file 'local:/upload/test-existing-dir', directory with same name exists."))
 		assert.False(t, "UpdateFile", res)
@@ -1536,7 +1596,7 @@ func TestUpdateFile(t *testing.T) {
 		dpa := config.DataPowerAppliance{SomaUrl: testSomaURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		res, err := Repo.UpdateFile(&currentView, "test-new-file.txt", []byte("Hello World!"))
+		res, err := Repo.UpdateFile(context.Background(), &currentView, "test-new-file.txt", []byte("Hello World!"))
 		assert.Nil(t, "UpdateFile", err)
 		assert.True(t, "UpdateFile", res)
 	})
@@ -1545,7 +1605,7 @@ func TestUpdateFile(t *testing.T) {
 		dpa := config.DataPowerAppliance{SomaUrl: testSomaURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		res, err := Repo.UpdateFile(&currentView, "test-existing-dir", []byte("Hello World!"))
+		res, err := Repo.UpdateFile(context.Background(), &currentView, "test-existing-dir", []byte("Hello World!"))
 		assert.Equals(t, "UpdateFile", err,
 			errs.Error("Can't upload file 'local:/upload/test-existing-dir', directory with same name exists."))
 		assert.False(t, "UpdateFile", res)
@@ -1562,7 +1622,7 @@ func TestGetFileType(t *testing.T) {
 		dpa := config.DataPowerAppliance{}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		itemType, err := Repo.GetFileType(&currentView, "local:", "test-file.txt")
+		itemType, err := Repo.GetFileType(context.Background(), &currentView, "local:", "test-file.txt")
 		assert.Equals(t, "GetFileType", err, errs.Error("DataPower management interface not set."))
 		assert.Equals(t, "GetFileType", itemType, model.ItemNone)
 	})
@@ -1571,7 +1631,7 @@ func TestGetFileType(t *testing.T) {
 		dpa := config.DataPowerAppliance{RestUrl: testRestURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		itemType, err := Repo.GetFileType(&currentView, "store:/gatewayscript", "non-existing-file.js")
+		itemType, err := Repo.GetFileType(context.Background(), &currentView, "store:/gatewayscript", "non-existing-file.js")
 		assert.Nil(t, "GetFileType", err)
 		assert.Equals(t, "GetFileType", itemType, model.ItemNone)
 	})
@@ -1580,7 +1640,7 @@ func TestGetFileType(t *testing.T) {
 		dpa := config.DataPowerAppliance{RestUrl: testRestURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		itemType, err := Repo.GetFileType(&currentView, "local:/upload", "test-existing-file.txt")
+		itemType, err := Repo.GetFileType(context.Background(), &currentView, "local:/upload", "test-existing-file.txt")
 		assert.Nil(t, "GetFileType", err)
 		assert.Equals(t, "GetFileType", itemType, model.ItemFile)
 	})
@@ -1589,7 +1649,7 @@ func TestGetFileType(t *testing.T) {
 		dpa := config.DataPowerAppliance{RestUrl: testRestURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		itemType, err := Repo.GetFileType(&currentView, "local:/upload", "test-existing-dir")
+		itemType, err := Repo.GetFileType(context.Background(), &currentView, "local:/upload", "test-existing-dir")
 		assert.Nil(t, "GetFileType", err)
 		assert.Equals(t, "GetFileType", itemType, model.ItemDirectory)
 	})
@@ -1598,7 +1658,7 @@ func TestGetFileType(t *testing.T) {
 		dpa := config.DataPowerAppliance{RestUrl: testRestURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		itemType, err := Repo.GetFileType(&currentView, "", "store:")
+		itemType, err := Repo.GetFileType(context.Background(), &currentView, "", "store:")
 		assert.Nil(t, "GetFileType", err)
 		assert.Equals(t, "GetFileType", itemType, model.ItemDpFilestore)
 	})
@@ -1607,7 +1667,7 @@ func TestGetFileType(t *testing.T) {
 		dpa := config.DataPowerAppliance{RestUrl: testRestURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		itemType, err := Repo.GetFileType(&currentView, "store:/gatewayscript", "non-existing-file-404.js")
+		itemType, err := Repo.GetFileType(context.Background(), &currentView, "store:/gatewayscript", "non-existing-file-404.js")
 		assert.Nil(t, "GetFileType", err)
 		assert.Equals(t, "GetFileType", itemType, model.ItemNone)
 	})
@@ -1616,7 +1676,7 @@ func TestGetFileType(t *testing.T) {
 		dpa := config.DataPowerAppliance{SomaUrl: testSomaURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		itemType, err := Repo.GetFileType(&currentView, "store:/gatewayscript", "non-existing-file.js")
+		itemType, err := Repo.GetFileType(context.Background(), &currentView, "store:/gatewayscript", "non-existing-file.js")
 		assert.Nil(t, "GetFileType", err)
 		assert.Equals(t, "GetFileType", itemType, model.ItemNone)
 	})
@@ -1625,7 +1685,7 @@ func TestGetFileType(t *testing.T) {
 		dpa := config.DataPowerAppliance{SomaUrl: testSomaURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		itemType, err := Repo.GetFileType(&currentView, "local:/upload", "test-existing-file.txt")
+		itemType, err := Repo.GetFileType(context.Background(), &currentView, "local:/upload", "test-existing-file.txt")
 		assert.Nil(t, "GetFileType", err)
 		assert.Equals(t, "GetFileType", itemType, model.ItemFile)
 	})
@@ -1634,7 +1694,7 @@ func TestGetFileType(t *testing.T) {
 		dpa := config.DataPowerAppliance{SomaUrl: testSomaURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		itemType, err := Repo.GetFileType(&currentView, "local:/upload", "test-existing-dir")
+		itemType, err := Repo.GetFileType(context.Background(), &currentView, "local:/upload", "test-existing-dir")
 		assert.Nil(t, "GetFileType", err)
 		assert.Equals(t, "GetFileType", itemType, model.ItemDirectory)
 	})
@@ -1643,7 +1703,7 @@ func TestGetFileType(t *testing.T) {
 		dpa := config.DataPowerAppliance{SomaUrl: testSomaURL}
 		config.Conf.DataPowerAppliances[currentView.DpAppliance] = dpa
 
-		itemType, err := Repo.GetFileType(&currentView, "", "store:")
+		itemType, err := Repo.GetFileType(context.Background(), &currentView, "", "store:")
 		assert.Nil(t, "GetFileType", err)
 		assert.Equals(t, "GetFileType", itemType, model.ItemDpFilestore)
 	})
@@ -1653,7 +1713,7 @@ func TestGetObjectDetails(t *testing.T) {
 	t.Run("GetObjectDetails no REST/SOMA", func(t *testing.T) {
 		clearRepo()
 
-		policyBytes, err := Repo.GetObjectDetails("tmp", "XMLFirewallService", "parse-cert")
+		policyBytes, err := Repo.GetObjectDetails(context.Background(), "tmp", "XMLFirewallService", "parse-cert")
 		assert.Equals(t, "GetObjectDetails", err, errs.Error("DataPower management interface Unknown not supported."))
 		assert.Equals(t, "GetObjectDetails", policyBytes, []byte(nil))
 	})
@@ -1662,7 +1722,7 @@ func TestGetObjectDetails(t *testing.T) {
 		clearRepo()
 		Repo.dataPowerAppliance.RestUrl = testRestURL
 
-		policyBytes, err := Repo.GetObjectDetails("tmp", "XMLFirewallService", "parse-cert")
+		policyBytes, err := Repo.GetObjectDetails(context.Background(), "tmp", "XMLFirewallService", "parse-cert")
 		assert.Nil(t, "GetObjectDetails", err)
 		expectedPolicyBytes, err := ioutil.ReadFile("testdata/details-svc-xmlfw.txt")
 		assert.Nil(t, "GetObjectDetails error reading expected policy info", err)
@@ -1673,7 +1733,7 @@ func TestGetObjectDetails(t *testing.T) {
 		clearRepo()
 		Repo.dataPowerAppliance.SomaUrl = testSomaURL
 
-		policyBytes, err := Repo.GetObjectDetails("tmp", "XMLFirewallService", "parse-cert")
+		policyBytes, err := Repo.GetObjectDetails(context.Background(), "tmp", "XMLFirewallService", "parse-cert")
 		assert.Nil(t, "GetObjectDetails", err)
 		expectedPolicyBytes, err := ioutil.ReadFile("testdata/details-svc-xmlfw.txt")
 		assert.Nil(t, "GetObjectDetails error reading expected policy info", err)
@@ -1740,6 +1800,126 @@ func TestGetObjectDetailsFromExportXML(t *testing.T) {
 	})
 }
 
+func TestImportDomain(t *testing.T) {
+	expectedResults, err := ioutil.ReadFile("testdata/import-results.txt")
+	assert.Nil(t, "ImportDomain error reading expected import results", err)
+
+	t.Run("ImportDomain no REST/SOMA", func(t *testing.T) {
+		clearRepo()
+
+		results, err := Repo.ImportDomain(context.Background(), "test", "test.zip", []byte("zip"))
+		assert.Equals(t, "ImportDomain", err, errs.Error("DataPower management interface Unknown not supported."))
+		assert.Equals(t, "ImportDomain", results, []byte(nil))
+	})
+
+	t.Run("ImportDomain REST", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		Repo.dataPowerAppliance.RestUrl = testRestURL
+
+		results, err := Repo.ImportDomain(context.Background(), "test", "test.zip", []byte("zip"))
+		assert.Nil(t, "ImportDomain", err)
+		assert.Equals(t, "ImportDomain", string(results), string(expectedResults))
+	})
+
+	t.Run("ImportDomain SOMA", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		Repo.dataPowerAppliance.SomaUrl = testSomaURL
+
+		results, err := Repo.ImportDomain(context.Background(), "test", "test.zip", []byte("zip"))
+		assert.Nil(t, "ImportDomain", err)
+		assert.Equals(t, "ImportDomain", string(results), string(expectedResults))
+	})
+}
+
+func TestImportAppliance(t *testing.T) {
+	var backupBuffer bytes.Buffer
+	backupZipWriter := zip.NewWriter(&backupBuffer)
+	for _, fileName := range []string{"default.zip", "test.zip", "export-info.xml", "dp-aux/ap-config.xml"} {
+		_, err := backupZipWriter.Create(fileName)
+		assert.Nil(t, "ImportAppliance creating backup", err)
+	}
+	assert.Nil(t, "ImportAppliance creating backup", backupZipWriter.Close())
+	backupBytes := backupBuffer.Bytes()
+
+	t.Run("getDomainNamesFromBackup", func(t *testing.T) {
+		domainNames, err := getDomainNamesFromBackup(backupBytes)
+		assert.Nil(t, "getDomainNamesFromBackup", err)
+		assert.DeepEqual(t, "getDomainNamesFromBackup", domainNames, []string{"default", "test"})
+
+		domainNames, err = getDomainNamesFromBackup([]byte("no zip"))
+		assert.NotNil(t, "getDomainNamesFromBackup", err)
+		assert.Equals(t, "getDomainNamesFromBackup", len(domainNames), 0)
+	})
+
+	t.Run("ImportAppliance REST", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		config.Conf.DataPowerAppliances["MyApplianceName"] = config.DataPowerAppliance{RestUrl: testRestURL}
+
+		results, err := Repo.ImportAppliance(context.Background(), "MyApplianceName", "backup.zip", backupBytes)
+		assert.Equals(t, "ImportAppliance", err, errs.Error("DataPower management interface REST not supported for appliance import."))
+		assert.Equals(t, "ImportAppliance", results, []byte(nil))
+	})
+
+	t.Run("ImportAppliance SOMA", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		config.Conf.DataPowerAppliances["MyApplianceName"] = config.DataPowerAppliance{SomaUrl: testSomaURL}
+
+		results, err := Repo.ImportAppliance(context.Background(), "MyApplianceName", "backup.zip", backupBytes)
+		assert.Nil(t, "ImportAppliance", err)
+		expectedResults, err := ioutil.ReadFile("testdata/import-results.txt")
+		assert.Nil(t, "ImportAppliance error reading expected import results", err)
+		assert.Equals(t, "ImportAppliance", string(results), string(expectedResults))
+	})
+}
+
+func TestDelete(t *testing.T) {
+	currentView := model.ItemConfig{Type: model.ItemDpConfiguration,
+		DpAppliance: "MyApplianceName"}
+
+	t.Run("Delete/ItemDpDomain default", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		Repo.dataPowerAppliance.RestUrl = testRestURL
+
+		res, err := Repo.Delete(context.Background(), &currentView, model.ItemDpDomain, "", "default")
+		assert.Equals(t, "Delete", err, errs.Error("Can't delete DataPower 'default' domain."))
+		assert.False(t, "Delete", res)
+	})
+
+	t.Run("Delete/ItemDpDomain no REST/SOMA", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+
+		res, err := Repo.Delete(context.Background(), &currentView, model.ItemDpDomain, "", "test")
+		assert.Equals(t, "Delete", err, errs.Error("DataPower management interface not set."))
+		assert.False(t, "Delete", res)
+	})
+
+	t.Run("Delete/ItemDpDomain REST", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		Repo.dataPowerAppliance.RestUrl = testRestURL
+
+		res, err := Repo.Delete(context.Background(), &currentView, model.ItemDpDomain, "", "test")
+		assert.Nil(t, "Delete", err)
+		assert.True(t, "Delete", res)
+	})
+
+	t.Run("Delete/ItemDpDomain SOMA", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		Repo.dataPowerAppliance.SomaUrl = testSomaURL
+
+		res, err := Repo.Delete(context.Background(), &currentView, model.ItemDpDomain, "", "test")
+		assert.Nil(t, "Delete", err)
+		assert.True(t, "Delete", res)
+	})
+}
+
 func TestGetFilePath(t *testing.T) {
 	testDataMatrix := [][]string{
 		{"local:/dir1/dir2", "myfile", "local:/dir1/dir2/myfile"},
@@ -2007,7 +2187,7 @@ func TestGetViewConfigByPath(t *testing.T) {
 	}
 
 	for idx, testCase := range testDataMatrix {
-		newView, err := Repo.GetViewConfigByPath(testCase.currentView, testCase.dirPath)
+		newView, err := Repo.GetViewConfigByPath(context.Background(), testCase.currentView, testCase.dirPath)
 		if !reflect.DeepEqual(newView, testCase.newView) {
 			t.Errorf("[%d] GetViewConfigByPath(%v, '%s') res: got %v, want %v",
 				idx, testCase.currentView, testCase.dirPath, newView, testCase.newView)
@@ -2071,6 +2251,114 @@ func TestRenameObject(t *testing.T) {
 	assert.DeepEqual(t, "XML object configuration rename", string(objectXMLGot), objectXMLExpected)
 }
 
+func TestGetObjectClasses(t *testing.T) {
+	t.Run("GetObjectClasses no REST/SOMA", func(t *testing.T) {
+		clearRepo()
+
+		classNames, err := Repo.GetObjectClasses(context.Background())
+		assert.Equals(t, "GetObjectClasses", err, errs.Error("DataPower management interface not set."))
+		assert.Equals(t, "GetObjectClasses", len(classNames), 0)
+	})
+
+	t.Run("GetObjectClasses REST", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		Repo.dataPowerAppliance.RestUrl = testRestURL
+
+		classNames, err := Repo.GetObjectClasses(context.Background())
+		assert.Nil(t, "GetObjectClasses", err)
+		assert.DeepEqual(t, "GetObjectClasses", classNames,
+			[]string{"AAAPolicy", "HTTPSourceProtocolHandler", "XMLFirewallService"})
+	})
+
+	t.Run("GetObjectClasses SOMA", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		Repo.dataPowerAppliance.SomaUrl = testSomaURL
+
+		classNames, err := Repo.GetObjectClasses(context.Background())
+		assert.Nil(t, "GetObjectClasses", err)
+		assert.DeepEqual(t, "GetObjectClasses", classNames,
+			[]string{"AAAPolicy", "HTTPSourceProtocolHandler", "XMLFirewallService"})
+	})
+}
+
+func TestCreateObjectSkeleton(t *testing.T) {
+	t.Run("CreateObjectSkeleton no REST/SOMA", func(t *testing.T) {
+		clearRepo()
+
+		skeleton, err := Repo.CreateObjectSkeleton(context.Background(), "HTTPSourceProtocolHandler", "new-http-fsh")
+		assert.Equals(t, "CreateObjectSkeleton", err, errs.Error("DataPower management interface not set."))
+		assert.Equals(t, "CreateObjectSkeleton", skeleton, []byte(nil))
+	})
+
+	t.Run("CreateObjectSkeleton REST", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		Repo.dataPowerAppliance.RestUrl = testRestURL
+
+		skeleton, err := Repo.CreateObjectSkeleton(context.Background(), "HTTPSourceProtocolHandler", "new-http-fsh")
+		assert.Nil(t, "CreateObjectSkeleton", err)
+		expectedSkeleton, err := ioutil.ReadFile("testdata/object_skeleton_http_fsh.json")
+		assert.Nil(t, "CreateObjectSkeleton error reading expected skeleton", err)
+		assert.Equals(t, "CreateObjectSkeleton", string(skeleton), string(expectedSkeleton))
+
+		objectClass, objectName, err := Repo.ParseObjectClassAndName(skeleton)
+		assert.Nil(t, "CreateObjectSkeleton", err)
+		assert.Equals(t, "CreateObjectSkeleton", objectClass, "HTTPSourceProtocolHandler")
+		assert.Equals(t, "CreateObjectSkeleton", objectName, "new-http-fsh")
+	})
+
+	t.Run("CreateObjectSkeleton SOMA", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		Repo.dataPowerAppliance.SomaUrl = testSomaURL
+
+		skeleton, err := Repo.CreateObjectSkeleton(context.Background(), "HTTPSourceProtocolHandler", "new-http-fsh")
+		assert.Nil(t, "CreateObjectSkeleton", err)
+		expectedSkeleton, err := ioutil.ReadFile("testdata/object_skeleton_http_fsh.xml")
+		assert.Nil(t, "CreateObjectSkeleton error reading expected skeleton", err)
+		assert.Equals(t, "CreateObjectSkeleton", string(skeleton), string(expectedSkeleton))
+
+		objectClass, objectName, err := Repo.ParseObjectClassAndName(skeleton)
+		assert.Nil(t, "CreateObjectSkeleton", err)
+		assert.Equals(t, "CreateObjectSkeleton", objectClass, "HTTPSourceProtocolHandler")
+		assert.Equals(t, "CreateObjectSkeleton", objectName, "new-http-fsh")
+	})
+
+	t.Run("CreateObjectSkeleton SOMA unknown class", func(t *testing.T) {
+		clearRepo()
+		Repo.req = mockRequester{}
+		Repo.dataPowerAppliance.SomaUrl = testSomaURL
+
+		skeleton, err := Repo.CreateObjectSkeleton(context.Background(), "UnknownClass", "new-object")
+		assert.Equals(t, "CreateObjectSkeleton", err,
+			errs.Error("Can't find DataPower object class 'UnknownClass' in management schema."))
+		assert.Equals(t, "CreateObjectSkeleton", skeleton, []byte(nil))
+	})
+}
+
+func TestLoadTree(t *testing.T) {
+	clearRepo()
+	Repo.req = mockRequester{}
+	Repo.dataPowerAppliance.RestUrl = testRestURL
+
+	tree, err := Repo.LoadTree(context.Background(), "test", "", "store:/gatewayscript")
+	assert.Equals(t, "LoadTree", err, nil)
+	assert.Equals(t, "LoadTree", tree.Dir, true)
+	assert.Equals(t, "LoadTree", tree.Name, "gatewayscript")
+	assert.Equals(t, "LoadTree", len(tree.Children), 29)
+
+	child := tree.FindChild(&localfs.Tree{Name: "example-context.js"})
+	if child == nil {
+		t.Fatalf("LoadTree() child 'example-context.js' not found.")
+	}
+	assert.Equals(t, "LoadTree", child.Path, "store:/gatewayscript/example-context.js")
+	assert.Equals(t, "LoadTree", child.PathFromRoot, "example-context.js")
+	assert.Equals(t, "LoadTree", child.ModTime,
+		time.Date(2019, time.August, 9, 15, 24, 42, 0, time.UTC))
+}
+
 func TestGetStatus(t *testing.T) {
 	t.Run("DpStatusMode/GetStatus * REST", func(t *testing.T) {
 		clearRepo()
@@ -2084,7 +2372,7 @@ func TestGetStatus(t *testing.T) {
 		Repo.req = mockRequester{}
 		Repo.dataPowerAppliance = dpApplicance{"", dpa}
 
-		statusBytes, err := Repo.GetStatus(
+		statusBytes, err := Repo.GetStatus(context.Background(),
 			dpa.Domain, "StylesheetCachingSummary", 1)
 
 		assert.Equals(t, "GetStatus", err, nil)
@@ -2116,7 +2404,7 @@ func TestGetStatus(t *testing.T) {
 		Repo.req = mockRequester{}
 		Repo.dataPowerAppliance = dpApplicance{"", dpa}
 
-		statusBytes, err := Repo.GetStatus(
+		statusBytes, err := Repo.GetStatus(context.Background(),
 			dpa.Domain, "CryptoEngineStatus2", 0)
 
 		assert.Equals(t, "GetStatus", err, nil)
@@ -2143,7 +2431,7 @@ func TestGetStatus(t *testing.T) {
 		Repo.req = mockRequester{}
 		Repo.dataPowerAppliance = dpApplicance{"", dpa}
 
-		statusBytes, err := Repo.GetStatus(
+		statusBytes, err := Repo.GetStatus(context.Background(),
 			dpa.Domain, "StylesheetCachingSummary", 1)
 
 		assert.Equals(t, "GetStatus", err, nil)
@@ -0,0 +1,75 @@
+package dpfake
+
+import (
+	"time"
+)
+
+// Demo appliance credentials.
+const (
+	DemoUsername = "admin"
+	DemoPassword = "admin"
+	DemoDomain   = "demo"
+)
+
+// NewDemoAppliance creates fake DataPower appliance populated with example
+// domain, files, objects and statuses (used in dpcmder demo mode).
+func NewDemoAppliance() *Appliance {
+	a := NewAppliance(DemoUsername, DemoPassword)
+
+	for _, domainName := range []string{DefaultDomain, DemoDomain} {
+		a.SetFile(domainName, "local:/readme.txt",
+			[]byte("This file is served by the fake DataPower appliance used in dpcmder demo mode.\n"))
+		a.SetStatuses(domainName, "StylesheetCachingSummary",
+			Fields{"XMLManager": Ref("XMLManager", "default"), "CacheSize": "256",
+				"CacheCount": "2", "ReadyCount": "2", "PendingCount": "0",
+				"BadCount": "0", "DupCount": "0", "CacheKBCount": "14"})
+		a.SetStatuses(domainName, "DocumentCachingSummary",
+			Fields{"XMLManager": Ref("XMLManager", "default"), "CacheCount": "0",
+				"DocCount": "0", "CacheSize": "0", "ByteCount": "0"})
+		a.SetObject(domainName, "XMLManager", "default",
+			Fields{"mAdminState": "enabled", "UserSummary": "Default XML-Manager", "CacheSize": "256"})
+	}
+
+	a.SetFile(DemoDomain, "local:/xsl/identity.xsl", []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<xsl:stylesheet version="1.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform">
+  <xsl:template match="/">
+    <xsl:copy-of select="."/>
+  </xsl:template>
+</xsl:stylesheet>
+`))
+	a.SetFile(DemoDomain, "local:/gatewayscript/hello.js", []byte(`var hm = require('header-metadata');
+
+session.output.write({ message: 'Hello from dpcmder demo!' });
+hm.response.set('Content-Type', 'application/json');
+`))
+	a.SetFile(DemoDomain, "local:/config/settings.json", []byte(`{
+  "backend": "http://localhost:8080/api",
+  "timeout": 60
+}
+`))
+
+	a.SetObject(DemoDomain, "XMLFirewallService", "demo-xmlfw",
+		Fields{"mAdminState": "enabled", "UserSummary": "Demo XML Firewall",
+			"LocalAddress": "0.0.0.0", "LocalPort": "20001",
+			"XMLManager": Ref("XMLManager", "default"), "Type": "loopback"})
+	a.SetObject(DemoDomain, "HTTPSourceProtocolHandler", "demo-http-fsh",
+		Fields{"mAdminState": "enabled", "LocalAddress": "0.0.0.0", "LocalPort": "20002"})
+	a.SetObject(DemoDomain, "MultiProtocolGateway", "demo-mpgw",
+		Fields{"mAdminState": "disabled", "UserSummary": "Demo Multi-Protocol Gateway",
+			"XMLManager":    Ref("XMLManager", "default"),
+			"FrontProtocol": Ref("HTTPSourceProtocolHandler", "demo-http-fsh"),
+			"BackendUrl":    "http://localhost:8080/api"})
+	a.SaveConfig(DefaultDomain)
+	a.SaveConfig(DemoDomain)
+
+	// Leave one unsaved change to show modified object & domain state.
+	a.SetObject(DemoDomain, "XMLFirewallService", "demo-xmlfw",
+		Fields{"mAdminState": "enabled", "UserSummary": "Demo XML Firewall (modified)",
+			"LocalAddress": "0.0.0.0", "LocalPort": "20001",
+			"XMLManager": Ref("XMLManager", "default"), "Type": "loopback"})
+	a.SetStatuses(DemoDomain, "ActiveUsers",
+		Fields{"session": "1", "name": DemoUsername, "connection": "web-gui",
+			"address": "127.0.0.1", "login": time.Now().Format(time.ANSIC), "domain": DemoDomain})
+
+	return a
+}
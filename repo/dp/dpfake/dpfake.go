@@ -0,0 +1,614 @@
+// Package dpfake implements in-process fake DataPower appliance (built on
+// net/http/httptest) answering REST (/mgmt/...) and SOMA/AMP management
+// requests from in-memory filestore, domains, objects and statuses. It is used
+// for testing DataPower repo and for running dpcmder in demo mode.
+package dpfake
+
+import (
+	"crypto/x509"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fields contains DataPower object (or status) properties. Values can be
+// strings (or other simple values), nested Fields or slices of values (for
+// repeated properties). Reference to other object is Fields containing
+// "value" (referenced object name) and "class" (referenced object class).
+type Fields map[string]interface{}
+
+// Ref creates Fields used as a reference to other DataPower object.
+func Ref(class, name string) Fields {
+	return Fields{"value": name, "class": class}
+}
+
+// Property contains metadata of DataPower object class property.
+type Property struct {
+	Name     string
+	Default  string
+	Required bool
+}
+
+// Appliance is fake DataPower appliance. All exported methods are safe for
+// concurrent use (with requests served at the same time).
+type Appliance struct {
+	// Username & Password are checked for each request (if Username is set).
+	Username string
+	Password string
+	// Classes contains metadata of object classes known to appliance.
+	Classes map[string][]Property
+
+	mutex         sync.Mutex
+	domains       map[string]*domain
+	actions       map[string]string
+	actionCounter int
+	server        *httptest.Server
+}
+
+// domain contains all DataPower domain data.
+type domain struct {
+	files      map[string]*file
+	objects    map[string]map[string]*object
+	statuses   map[string][]Fields
+	saveNeeded bool
+}
+
+// file is DataPower file or directory.
+type file struct {
+	dir      bool
+	content  []byte
+	modified time.Time
+}
+
+// object is DataPower object configuration - current and persisted (saved).
+type object struct {
+	fields    Fields
+	persisted Fields
+	deleted   bool
+}
+
+// Locations contains names of filestore locations available in each domain.
+var Locations = []string{"cert:", "config:", "export:", "local:", "logstore:",
+	"logtemp:", "sharedcert:", "store:", "temporary:"}
+
+// Object configuration states (as shown in ObjectStatus).
+const (
+	configStateSaved    = "saved"
+	configStateModified = "modified"
+	configStateNew      = "new"
+)
+
+// DefaultDomain is name of DataPower domain which always exists.
+const DefaultDomain = "default"
+
+// timeLayout is format of file modification time used by DataPower.
+const timeLayout = "2006-01-02 15:04:05"
+
+// NewAppliance creates fake DataPower appliance containing just the default
+// domain. If username is set only requests using given credentials are served.
+func NewAppliance(username, password string) *Appliance {
+	a := &Appliance{Username: username, Password: password,
+		Classes: make(map[string][]Property),
+		domains: make(map[string]*domain), actions: make(map[string]string)}
+	for class, properties := range defaultClasses {
+		a.Classes[class] = properties
+	}
+	a.AddDomain(DefaultDomain)
+	a.SaveConfig(DefaultDomain)
+	return a
+}
+
+// Start starts HTTPS server serving appliance management requests and returns
+// server URL (the same URL is used for REST and SOMA management interfaces).
+func (a *Appliance) Start() string {
+	a.server = httptest.NewTLSServer(a)
+	logging.LogDebugf("repo/dp/dpfake/Start(), url: '%s'", a.server.URL)
+	return a.server.URL
+}
+
+// Certificate returns certificate of the started appliance HTTPS server.
+func (a *Appliance) Certificate() *x509.Certificate {
+	return a.server.Certificate()
+}
+
+// Close stops appliance HTTPS server.
+func (a *Appliance) Close() {
+	if a.server != nil {
+		a.server.Close()
+		a.server = nil
+	}
+}
+
+// ServeHTTP serves DataPower REST, SOMA & AMP management requests.
+func (a *Appliance) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logging.LogDebugf("repo/dp/dpfake/ServeHTTP(), %s '%s'", r.Method, r.URL.Path)
+	if a.Username != "" {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != a.Username || password != a.Password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="DataPower"`)
+			http.Error(w, "Authentication failure", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/mgmt/"):
+		a.serveRest(w, r.Method, r.URL.Path, body)
+	case r.URL.Path == "/service/mgmt/current" && r.Method == "POST":
+		a.serveSoma(w, body)
+	case strings.HasPrefix(r.URL.Path, "/service/mgmt/amp/") && r.Method == "POST":
+		a.serveAmp(w, body)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// AddDomain creates new empty domain (if it doesn't exist).
+func (a *Appliance) AddDomain(name string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.addDomain(name)
+}
+
+// SetFile creates or updates file (and creates missing parent directories).
+func (a *Appliance) SetFile(domainName, filePath string, content []byte) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	d := a.addDomain(domainName)
+	filePath = normalizePath(filePath)
+	d.mkdirAll(parentPath(filePath))
+	d.files[filePath] = &file{content: content, modified: time.Now()}
+}
+
+// File returns content of the file (and true if file exists).
+func (a *Appliance) File(domainName, filePath string) ([]byte, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	d, ok := a.domains[domainName]
+	if !ok {
+		return nil, false
+	}
+	f, ok := d.files[normalizePath(filePath)]
+	if !ok || f.dir {
+		return nil, false
+	}
+	return f.content, true
+}
+
+// SetObject creates or updates object configuration.
+func (a *Appliance) SetObject(domainName, class, name string, fields Fields) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.setObject(a.addDomain(domainName), domainName, class, name, fields)
+}
+
+// Object returns object configuration (and true if object exists).
+func (a *Appliance) Object(domainName, class, name string) (Fields, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	d, ok := a.domains[domainName]
+	if !ok {
+		return nil, false
+	}
+	o := d.object(class, name)
+	if o == nil {
+		return nil, false
+	}
+	return o.fields, true
+}
+
+// SetStatuses sets all statuses of given status class.
+func (a *Appliance) SetStatuses(domainName, class string, statuses ...Fields) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.addDomain(domainName).statuses[class] = statuses
+}
+
+// SaveConfig saves (persists) current configuration of the domain.
+func (a *Appliance) SaveConfig(domainName string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if d, ok := a.domains[domainName]; ok {
+		d.saveConfig()
+	}
+}
+
+// addDomain creates new domain (if it doesn't exist) and returns it.
+func (a *Appliance) addDomain(name string) *domain {
+	if d, ok := a.domains[name]; ok {
+		return d
+	}
+	d := &domain{files: make(map[string]*file),
+		objects:  make(map[string]map[string]*object),
+		statuses: make(map[string][]Fields)}
+	a.domains[name] = d
+	if name != DefaultDomain {
+		a.setObject(a.domains[DefaultDomain], DefaultDomain, "Domain", name,
+			Fields{"mAdminState": "enabled", "NeighborDomain": Ref("Domain", DefaultDomain)})
+	} else {
+		a.setObject(d, DefaultDomain, "Domain", DefaultDomain,
+			Fields{"mAdminState": "enabled", "UserSummary": "Default System Domain"})
+	}
+	return d
+}
+
+// setObject creates or updates object configuration, Domain objects in the
+// default domain also create domains.
+func (a *Appliance) setObject(d *domain, domainName, class, name string, fields Fields) (created bool) {
+	if d.objects[class] == nil {
+		d.objects[class] = make(map[string]*object)
+	}
+	o := d.objects[class][name]
+	created = o == nil || o.deleted
+	if o == nil {
+		o = &object{}
+		d.objects[class][name] = o
+	}
+	o.fields = fields
+	o.deleted = false
+	d.saveNeeded = true
+	if domainName == DefaultDomain && class == "Domain" {
+		a.addDomain(name)
+	}
+	return created
+}
+
+// deleteObject deletes object configuration, Domain objects in the default
+// domain also delete domains.
+func (a *Appliance) deleteObject(d *domain, domainName, class, name string) bool {
+	o := d.object(class, name)
+	if o == nil {
+		return false
+	}
+	if domainName == DefaultDomain && class == "Domain" {
+		if name == DefaultDomain {
+			return false
+		}
+		delete(a.domains, name)
+	}
+	if o.persisted == nil {
+		delete(d.objects[class], name)
+	} else {
+		o.deleted = true
+	}
+	d.saveNeeded = true
+	return true
+}
+
+// domainNames returns sorted names of all domains.
+func (a *Appliance) domainNames() []string {
+	names := make([]string, 0, len(a.domains))
+	for name := range a.domains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// object returns existing (not deleted) object.
+func (d *domain) object(class, name string) *object {
+	o := d.objects[class][name]
+	if o == nil || o.deleted {
+		return nil
+	}
+	return o
+}
+
+// classNames returns sorted names of all object classes used in domain.
+func (d *domain) classNames() []string {
+	names := make([]string, 0, len(d.objects))
+	for class := range d.objects {
+		if len(d.objectNames(class)) > 0 {
+			names = append(names, class)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// objectNames returns sorted names of all objects of given class.
+func (d *domain) objectNames(class string) []string {
+	names := make([]string, 0, len(d.objects[class]))
+	for name, o := range d.objects[class] {
+		if !o.deleted {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// saveConfig persists current configuration of all objects.
+func (d *domain) saveConfig() {
+	for class, objects := range d.objects {
+		for name, o := range objects {
+			if o.deleted {
+				delete(d.objects[class], name)
+				continue
+			}
+			o.persisted = o.fields
+		}
+	}
+	d.saveNeeded = false
+}
+
+// configState returns configuration state of the object.
+func (o *object) configState() string {
+	switch {
+	case o.persisted == nil:
+		return configStateNew
+	case reflect.DeepEqual(o.fields, o.persisted):
+		return configStateSaved
+	default:
+		return configStateModified
+	}
+}
+
+// objectStatuses returns ObjectStatus statuses for all objects (of the given
+// class if objectClass is set).
+func (d *domain) objectStatuses(objectClass string) []Fields {
+	statuses := make([]Fields, 0)
+	for _, class := range d.classNames() {
+		if objectClass != "" && class != objectClass {
+			continue
+		}
+		for _, name := range d.objectNames(class) {
+			o := d.objects[class][name]
+			adminState := "enabled"
+			if state, ok := o.fields["mAdminState"].(string); ok && state != "" {
+				adminState = state
+			}
+			opState := "up"
+			if adminState != "enabled" {
+				opState = "down"
+			}
+			statuses = append(statuses, Fields{"Class": class, "Name": name,
+				"OpState": opState, "AdminState": adminState,
+				"ConfigState": o.configState(), "EventCode": "0x00000000",
+				"ErrorCode": "", "Details": ""})
+		}
+	}
+	return statuses
+}
+
+// domainStatuses returns DomainStatus statuses for all domains.
+func (a *Appliance) domainStatuses() []Fields {
+	statuses := make([]Fields, 0, len(a.domains))
+	for _, name := range a.domainNames() {
+		saveNeeded := "off"
+		if a.domains[name].saveNeeded {
+			saveNeeded = "on"
+		}
+		statuses = append(statuses, Fields{"Domain": name, "SaveNeeded": saveNeeded,
+			"TraceEnabled": "off", "DebugEnabled": "off", "ProbeEnabled": "off",
+			"DiagEnabled": "off", "CurrentCommand": "", "QuiesceState": "",
+			"InterfaceState": "ok", "FailsafeMode": "none"})
+	}
+	return statuses
+}
+
+// statuses returns all statuses of given class (including computed statuses).
+func (a *Appliance) statuses(d *domain, class string) []Fields {
+	switch class {
+	case "ObjectStatus":
+		return d.objectStatuses("")
+	case "DomainStatus":
+		return a.domainStatuses()
+	default:
+		return d.statuses[class]
+	}
+}
+
+// statusClassNames returns sorted names of all status classes used in domain.
+func (a *Appliance) statusClassNames(d *domain) []string {
+	names := []string{"DomainStatus", "ObjectStatus"}
+	for class, statuses := range d.statuses {
+		if len(statuses) > 0 {
+			names = append(names, class)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// classProperties returns metadata of object class properties (for classes
+// without metadata properties are created from existing objects).
+func (a *Appliance) classProperties(class string) ([]Property, bool) {
+	if properties, ok := a.Classes[class]; ok {
+		return properties, true
+	}
+	propertyNames := make(map[string]bool)
+	for _, d := range a.domains {
+		for _, name := range d.objectNames(class) {
+			for propertyName := range d.objects[class][name].fields {
+				propertyNames[propertyName] = true
+			}
+		}
+	}
+	if len(propertyNames) == 0 {
+		return nil, false
+	}
+	properties := make([]Property, 0, len(propertyNames))
+	for _, name := range sortedKeys(propertyNames) {
+		properties = append(properties, Property{Name: name})
+	}
+	return properties, true
+}
+
+// classNames returns sorted names of all known object classes.
+func (a *Appliance) classNames() []string {
+	names := make(map[string]bool)
+	for class := range a.Classes {
+		names[class] = true
+	}
+	for _, d := range a.domains {
+		for _, class := range d.classNames() {
+			names[class] = true
+		}
+	}
+	return sortedKeys(names)
+}
+
+// mkdirAll creates directory with all missing parent directories.
+func (d *domain) mkdirAll(dirPath string) {
+	if dirPath == "" || isLocation(dirPath) {
+		return
+	}
+	if _, ok := d.files[dirPath]; ok {
+		return
+	}
+	d.mkdirAll(parentPath(dirPath))
+	d.files[dirPath] = &file{dir: true, modified: time.Now()}
+}
+
+// isDir checks if path is existing directory (or filestore location).
+func (d *domain) isDir(dirPath string) bool {
+	if isLocation(dirPath) {
+		return true
+	}
+	f, ok := d.files[dirPath]
+	return ok && f.dir
+}
+
+// children returns sorted paths of all files and directories in directory.
+func (d *domain) children(dirPath string) []string {
+	paths := make([]string, 0)
+	for filePath := range d.files {
+		if parentPath(filePath) == dirPath {
+			paths = append(paths, filePath)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// remove removes file or directory with all its content.
+func (d *domain) remove(filePath string) {
+	for _, childPath := range d.children(filePath) {
+		d.remove(childPath)
+	}
+	delete(d.files, filePath)
+}
+
+// move moves file to new path (overwriting existing file), returns false if
+// source is not a file or parent directory of the new path doesn't exist.
+func (d *domain) move(filePath, newFilePath string) bool {
+	f, ok := d.files[filePath]
+	if !ok || f.dir || !d.isDir(parentPath(newFilePath)) {
+		return false
+	}
+	if existing, ok := d.files[newFilePath]; ok && existing.dir {
+		return false
+	}
+	delete(d.files, filePath)
+	d.files[newFilePath] = f
+	return true
+}
+
+// isLocation checks if path is filestore location (for example "local:").
+func isLocation(filePath string) bool {
+	for _, location := range Locations {
+		if filePath == location {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizePath converts DataPower path to form used as a key in the fake
+// filestore ("local:///dir/file/" -> "local:/dir/file").
+func normalizePath(filePath string) string {
+	location, rest := splitLocation(filePath)
+	rest = strings.Trim(rest, "/")
+	if rest == "" {
+		return location
+	}
+	return location + "/" + rest
+}
+
+// splitLocation splits DataPower path to location and path inside location.
+func splitLocation(filePath string) (location, rest string) {
+	idx := strings.Index(filePath, ":")
+	if idx == -1 {
+		return filePath, ""
+	}
+	return filePath[:idx+1], filePath[idx+1:]
+}
+
+// parentPath returns path of the parent directory.
+func parentPath(filePath string) string {
+	if isLocation(filePath) {
+		return ""
+	}
+	idx := strings.LastIndex(filePath, "/")
+	if idx == -1 {
+		return ""
+	}
+	parent := filePath[:idx]
+	if strings.HasSuffix(parent, ":") {
+		return parent
+	}
+	return strings.TrimRight(parent, "/")
+}
+
+// baseName returns name of the file (last path element).
+func baseName(filePath string) string {
+	return filePath[strings.LastIndex(filePath, "/")+1:]
+}
+
+// sortedKeys returns sorted keys of the map.
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// defaultClasses contains metadata of object classes known to each appliance.
+var defaultClasses = map[string][]Property{
+	"Domain": {
+		{Name: "mAdminState", Default: "enabled"},
+		{Name: "UserSummary"},
+		{Name: "NeighborDomain"}},
+	"HTTPSourceProtocolHandler": {
+		{Name: "mAdminState", Default: "enabled"},
+		{Name: "UserSummary"},
+		{Name: "LocalAddress", Default: "0.0.0.0", Required: true},
+		{Name: "LocalPort", Required: true}},
+	"MultiProtocolGateway": {
+		{Name: "mAdminState", Default: "enabled"},
+		{Name: "UserSummary"},
+		{Name: "StylePolicy", Required: true},
+		{Name: "XMLManager", Default: "default", Required: true},
+		{Name: "FrontProtocol"},
+		{Name: "BackendUrl"}},
+	"XMLFirewallService": {
+		{Name: "mAdminState", Default: "enabled"},
+		{Name: "UserSummary"},
+		{Name: "LocalAddress", Default: "0.0.0.0", Required: true},
+		{Name: "LocalPort", Required: true},
+		{Name: "XMLManager", Default: "default", Required: true},
+		{Name: "StylePolicy"},
+		{Name: "Type", Default: "dynamic-backend"}},
+	"XMLManager": {
+		{Name: "mAdminState", Default: "enabled"},
+		{Name: "UserSummary"},
+		{Name: "CacheSize", Default: "256"}},
+}
@@ -0,0 +1,338 @@
+package dpfake
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"github.com/antchfx/xmlquery"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// exportObject is object requested in export (with or without referenced
+// objects).
+type exportObject struct {
+	class      string
+	name       string
+	refObjects bool
+}
+
+// importResults contains results of import - statuses of imported objects
+// and files.
+type importResults struct {
+	domainName string
+	objects    []importedObject
+	files      []importedFile
+}
+
+// importedObject is status of one imported object.
+type importedObject struct {
+	class, name, status string
+}
+
+// importedFile is status of one imported file.
+type importedFile struct {
+	name, src, status string
+}
+
+// exportDomain creates export zip file containing export.xml with objects
+// configuration (all objects if no object is given) and files (if allFiles is
+// set).
+func (a *Appliance) exportDomain(domainName string, objects []exportObject, allFiles bool) ([]byte, error) {
+	d, ok := a.domains[domainName]
+	if !ok {
+		return nil, fmt.Errorf("domain '%s' not found", domainName)
+	}
+
+	exported := make(map[string]bool)
+	var configBuf bytes.Buffer
+	var exportObjectRecursive func(class, name string, refObjects bool) error
+	exportObjectRecursive = func(class, name string, refObjects bool) error {
+		if exported[class+"/"+name] {
+			return nil
+		}
+		o := d.object(class, name)
+		if o == nil {
+			return fmt.Errorf("object %s '%s' not found", class, name)
+		}
+		exported[class+"/"+name] = true
+		if refObjects {
+			for _, ref := range objectRefs(o.fields) {
+				refClass, _ := ref["class"].(string)
+				if d.object(refClass, fmt.Sprint(ref["value"])) != nil {
+					err := exportObjectRecursive(refClass, fmt.Sprint(ref["value"]), true)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+		objectToXML(&configBuf, class, name, o.fields, "    ")
+		return nil
+	}
+
+	if len(objects) == 0 {
+		for _, class := range d.classNames() {
+			for _, name := range d.objectNames(class) {
+				exportObjectRecursive(class, name, false)
+			}
+		}
+	}
+	for _, o := range objects {
+		err := exportObjectRecursive(o.class, o.name, o.refObjects)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var zipBuf bytes.Buffer
+	zipWriter := zip.NewWriter(&zipBuf)
+	var filesBuf bytes.Buffer
+	if allFiles {
+		filePaths := make(map[string]bool)
+		for filePath, f := range d.files {
+			if !f.dir {
+				filePaths[filePath] = true
+			}
+		}
+		for _, filePath := range sortedKeys(filePaths) {
+			location, rest := splitLocation(filePath)
+			locationName := strings.TrimSuffix(location, ":")
+			src := locationName + rest
+			filesBuf.WriteString(fmt.Sprintf("    <file name=\"%s//%s\" src=\"%s\" location=\"%s\"/>\n",
+				escapeXML(location), escapeXML(rest), escapeXML(src), locationName))
+			fileWriter, err := zipWriter.Create(src)
+			if err != nil {
+				return nil, err
+			}
+			fileWriter.Write(d.files[filePath].content)
+		}
+	}
+
+	exportXML := fmt.Sprintf(`<?xml version="1.0"?>
+<datapower-configuration version="3">
+  <export-details>
+    <description>Exported Configuration</description>
+    <user>%s</user>
+    <domain>%s</domain>
+    <comment>Exported by fake DataPower appliance</comment>
+    <current-date>%s</current-date>
+  </export-details>
+  <configuration domain="%s">
+%s  </configuration>
+  <files>
+%s  </files>
+</datapower-configuration>
+`, escapeXML(a.Username), domainName, time.Now().Format("2006-01-02"),
+		domainName, configBuf.String(), filesBuf.String())
+	exportWriter, err := zipWriter.Create("export.xml")
+	if err != nil {
+		return nil, err
+	}
+	exportWriter.Write([]byte(exportXML))
+
+	err = zipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	return zipBuf.Bytes(), nil
+}
+
+// importDomain imports export zip file (created by exportDomain) to the
+// domain.
+func (a *Appliance) importDomain(domainName string, exportBytes []byte) (*importResults, error) {
+	d, ok := a.domains[domainName]
+	if !ok {
+		return nil, fmt.Errorf("domain '%s' not found", domainName)
+	}
+
+	zipReader, err := zip.NewReader(bytes.NewReader(exportBytes), int64(len(exportBytes)))
+	if err != nil {
+		return nil, err
+	}
+	zipFiles := make(map[string][]byte)
+	for _, zipFile := range zipReader.File {
+		fileReader, err := zipFile.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadAll(fileReader)
+		fileReader.Close()
+		if err != nil {
+			return nil, err
+		}
+		zipFiles[zipFile.Name] = content
+	}
+
+	exportXML, ok := zipFiles["export.xml"]
+	if !ok {
+		return nil, fmt.Errorf("export.xml not found in import file")
+	}
+	doc, err := xmlquery.Parse(bytes.NewReader(exportXML))
+	if err != nil {
+		return nil, err
+	}
+
+	results := &importResults{domainName: domainName}
+	if configNode := xmlquery.FindOne(doc, "/datapower-configuration/configuration"); configNode != nil {
+		for _, objectNode := range childElements(configNode) {
+			class, name := objectNode.Data, objectNode.SelectAttr("name")
+			status := "modified"
+			if a.setObject(d, domainName, class, name, fieldsFromXML(objectNode)) {
+				status = "new"
+			}
+			results.objects = append(results.objects, importedObject{class: class, name: name, status: status})
+		}
+	}
+	for _, fileNode := range xmlquery.Find(doc, "/datapower-configuration/files/file") {
+		name, src := fileNode.SelectAttr("name"), fileNode.SelectAttr("src")
+		content, ok := zipFiles[src]
+		if !ok {
+			results.files = append(results.files, importedFile{name: name, src: src, status: "failed"})
+			continue
+		}
+		filePath := normalizePath(name)
+		status := "created"
+		if _, exists := d.files[filePath]; exists {
+			status = "overwritten"
+		}
+		d.mkdirAll(parentPath(filePath))
+		d.files[filePath] = &file{content: content, modified: time.Now()}
+		results.files = append(results.files, importedFile{name: name, src: src, status: status})
+	}
+
+	return results, nil
+}
+
+// backupDomains creates backup zip file containing export of each domain
+// (as "<domain>.zip" zip file).
+func (a *Appliance) backupDomains(domainNames []string) ([]byte, error) {
+	if len(domainNames) == 0 {
+		domainNames = a.domainNames()
+	}
+
+	var zipBuf bytes.Buffer
+	zipWriter := zip.NewWriter(&zipBuf)
+	for _, domainName := range domainNames {
+		exportBytes, err := a.exportDomain(domainName, nil, true)
+		if err != nil {
+			return nil, err
+		}
+		domainWriter, err := zipWriter.CreateHeader(&zip.FileHeader{Name: domainName + ".zip", Method: zip.Store})
+		if err != nil {
+			return nil, err
+		}
+		domainWriter.Write(exportBytes)
+	}
+	err := zipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+	return zipBuf.Bytes(), nil
+}
+
+// restoreDomains imports given domains from backup zip file (created by
+// backupDomains), missing domains are created.
+func (a *Appliance) restoreDomains(backupBytes []byte, domainNames []string) ([]*importResults, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(backupBytes), int64(len(backupBytes)))
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*importResults, 0)
+	for _, domainName := range domainNames {
+		var domainZip *zip.File
+		for _, zipFile := range zipReader.File {
+			if zipFile.Name == domainName+".zip" {
+				domainZip = zipFile
+			}
+		}
+		if domainZip == nil {
+			return nil, fmt.Errorf("domain '%s' not found in backup", domainName)
+		}
+		fileReader, err := domainZip.Open()
+		if err != nil {
+			return nil, err
+		}
+		exportBytes, err := ioutil.ReadAll(fileReader)
+		fileReader.Close()
+		if err != nil {
+			return nil, err
+		}
+		a.addDomain(domainName)
+		domainResults, err := a.importDomain(domainName, exportBytes)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, domainResults)
+	}
+
+	return results, nil
+}
+
+// objectRefs returns all references to other objects found in fields.
+func objectRefs(value interface{}) []Fields {
+	if ref, ok := isRef(value); ok {
+		return []Fields{ref}
+	}
+	refs := make([]Fields, 0)
+	switch typedValue := value.(type) {
+	case Fields:
+		for _, key := range sortedFieldNames(typedValue) {
+			refs = append(refs, objectRefs(typedValue[key])...)
+		}
+	case []interface{}:
+		for _, item := range typedValue {
+			refs = append(refs, objectRefs(item)...)
+		}
+	}
+	return refs
+}
+
+// sortedFieldNames returns sorted names of all fields.
+func sortedFieldNames(fields Fields) []string {
+	names := make(map[string]bool, len(fields))
+	for name := range fields {
+		names[name] = true
+	}
+	return sortedKeys(names)
+}
+
+// toJSON converts import results to value marshaled to REST JSON response.
+func (r *importResults) toJSON() map[string]interface{} {
+	objects := make([]interface{}, len(r.objects))
+	for idx, o := range r.objects {
+		objects[idx] = map[string]interface{}{"class": o.class, "name": o.name,
+			"status": o.status, "import": "yes"}
+	}
+	files := make([]interface{}, len(r.files))
+	for idx, f := range r.files {
+		files[idx] = map[string]interface{}{"name": f.name, "src": f.src, "status": f.status}
+	}
+
+	result := map[string]interface{}{"domain": r.domainName}
+	if len(objects) > 0 {
+		result["imported-objects"] = map[string]interface{}{"object": oneOrMany(objects)}
+	}
+	if len(files) > 0 {
+		result["imported-files"] = map[string]interface{}{"file": oneOrMany(files)}
+	}
+	return result
+}
+
+// writeXML writes import results as SOMA import-results element.
+func (r *importResults) writeXML(buf *bytes.Buffer) {
+	buf.WriteString(fmt.Sprintf(`<import-results domain="%s">`, escapeXML(r.domainName)))
+	buf.WriteString("<imported-objects>")
+	for _, o := range r.objects {
+		buf.WriteString(fmt.Sprintf(`<object class="%s" name="%s" status="%s" import="yes"/>`,
+			escapeXML(o.class), escapeXML(o.name), o.status))
+	}
+	buf.WriteString("</imported-objects><imported-files>")
+	for _, f := range r.files {
+		buf.WriteString(fmt.Sprintf(`<file name="%s" src="%s" status="%s"/>`,
+			escapeXML(f.name), escapeXML(f.src), f.status))
+	}
+	buf.WriteString("</imported-files></import-results>")
+}
@@ -0,0 +1,230 @@
+package dpfake
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/antchfx/xmlquery"
+	"sort"
+	"strings"
+)
+
+// isRef checks if value is a reference to other object.
+func isRef(value interface{}) (ref Fields, ok bool) {
+	ref, ok = value.(Fields)
+	if !ok {
+		return nil, false
+	}
+	if _, hasValue := ref["value"]; !hasValue {
+		return nil, false
+	}
+	for key := range ref {
+		if key != "value" && key != "class" {
+			return nil, false
+		}
+	}
+	return ref, true
+}
+
+// fieldsToJSON converts fields to value marshaled to REST JSON response.
+func fieldsToJSON(domainName string, value interface{}) interface{} {
+	if ref, ok := isRef(value); ok {
+		result := map[string]interface{}{"value": ref["value"]}
+		if class, ok := ref["class"].(string); ok && class != "" {
+			result["href"] = fmt.Sprintf("/mgmt/config/%s/%s/%v", domainName, class, ref["value"])
+		}
+		return result
+	}
+
+	switch typedValue := value.(type) {
+	case Fields:
+		result := make(map[string]interface{}, len(typedValue))
+		for key, fieldValue := range typedValue {
+			result[key] = fieldsToJSON(domainName, fieldValue)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typedValue))
+		for idx, item := range typedValue {
+			result[idx] = fieldsToJSON(domainName, item)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// fieldsFromJSON converts value unmarshaled from REST JSON request to fields
+// (removing links and converting links to other objects to references).
+func fieldsFromJSON(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		if href, ok := typedValue["href"].(string); ok {
+			if hrefParts := strings.Split(strings.Trim(href, "/"), "/"); len(hrefParts) == 5 &&
+				hrefParts[1] == "config" && typedValue["value"] != nil {
+				return Ref(hrefParts[3], fmt.Sprint(typedValue["value"]))
+			}
+		}
+		result := make(Fields, len(typedValue))
+		for key, fieldValue := range typedValue {
+			if key != "_links" && key != "href" {
+				result[key] = fieldsFromJSON(fieldValue)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typedValue))
+		for idx, item := range typedValue {
+			result[idx] = fieldsFromJSON(item)
+		}
+		return result
+	case json.Number:
+		return typedValue.String()
+	default:
+		return value
+	}
+}
+
+// parseJSONObject parses REST JSON request containing object configuration
+// ({"Class": {"name": "Name", ...}}).
+func parseJSONObject(body []byte) (class, name string, fields Fields, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var request map[string]interface{}
+	err = decoder.Decode(&request)
+	if err != nil {
+		return "", "", nil, err
+	}
+	delete(request, "_links")
+	if len(request) != 1 {
+		return "", "", nil, fmt.Errorf("expected one object, got %d", len(request))
+	}
+	for key, value := range request {
+		class = key
+		objectFields, ok := fieldsFromJSON(value).(Fields)
+		if !ok {
+			return "", "", nil, fmt.Errorf("object '%s' is not a JSON object", class)
+		}
+		name = fmt.Sprint(objectFields["name"])
+		delete(objectFields, "name")
+		fields = objectFields
+	}
+	if name == "" || name == "<nil>" {
+		return "", "", nil, fmt.Errorf("object '%s' has no name", class)
+	}
+	return class, name, fields, nil
+}
+
+// fieldsToXML writes XML element with fields as its content.
+func fieldsToXML(buf *bytes.Buffer, elementName string, value interface{}, indent string) {
+	if ref, ok := isRef(value); ok {
+		buf.WriteString(indent + "<" + elementName)
+		if class, ok := ref["class"].(string); ok && class != "" {
+			buf.WriteString(` class="` + escapeXML(class) + `"`)
+		}
+		buf.WriteString(">" + escapeXML(fmt.Sprint(ref["value"])) + "</" + elementName + ">\n")
+		return
+	}
+
+	switch typedValue := value.(type) {
+	case Fields:
+		buf.WriteString(indent + "<" + elementName + ">\n")
+		writeFieldsXML(buf, typedValue, indent+"  ")
+		buf.WriteString(indent + "</" + elementName + ">\n")
+	case []interface{}:
+		for _, item := range typedValue {
+			fieldsToXML(buf, elementName, item, indent)
+		}
+	default:
+		buf.WriteString(indent + "<" + elementName + ">" + escapeXML(fmt.Sprint(value)) +
+			"</" + elementName + ">\n")
+	}
+}
+
+// writeFieldsXML writes all fields (sorted by name) as XML elements.
+func writeFieldsXML(buf *bytes.Buffer, fields Fields, indent string) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fieldsToXML(buf, key, fields[key], indent)
+	}
+}
+
+// objectToXML writes object configuration XML element.
+func objectToXML(buf *bytes.Buffer, class, name string, fields Fields, indent string) {
+	buf.WriteString(fmt.Sprintf("%s<%s name=\"%s\">\n", indent, class, escapeXML(name)))
+	writeFieldsXML(buf, fields, indent+"  ")
+	buf.WriteString(fmt.Sprintf("%s</%s>\n", indent, class))
+}
+
+// fieldsFromXML converts XML element content to fields.
+func fieldsFromXML(node *xmlquery.Node) Fields {
+	fields := make(Fields)
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != xmlquery.ElementNode {
+			continue
+		}
+		var value interface{}
+		switch {
+		case hasChildElements(child):
+			value = fieldsFromXML(child)
+		case child.SelectAttr("class") != "":
+			value = Ref(child.SelectAttr("class"), strings.TrimSpace(child.InnerText()))
+		default:
+			value = strings.TrimSpace(child.InnerText())
+		}
+
+		switch existingValue := fields[child.Data].(type) {
+		case nil:
+			fields[child.Data] = value
+		case []interface{}:
+			fields[child.Data] = append(existingValue, value)
+		default:
+			fields[child.Data] = []interface{}{existingValue, value}
+		}
+	}
+	return fields
+}
+
+// hasChildElements checks if XML node contains any element.
+func hasChildElements(node *xmlquery.Node) bool {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == xmlquery.ElementNode {
+			return true
+		}
+	}
+	return false
+}
+
+// childElements returns all child elements of XML node.
+func childElements(node *xmlquery.Node) []*xmlquery.Node {
+	elements := make([]*xmlquery.Node, 0)
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == xmlquery.ElementNode {
+			elements = append(elements, child)
+		}
+	}
+	return elements
+}
+
+// childElement returns first child element of XML node with given (local)
+// name.
+func childElement(node *xmlquery.Node, name string) *xmlquery.Node {
+	for _, child := range childElements(node) {
+		if child.Data == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// escapeXML escapes text used in XML content or attribute value.
+func escapeXML(text string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
@@ -0,0 +1,530 @@
+package dpfake
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// serveRest serves DataPower REST management requests.
+func (a *Appliance) serveRest(w http.ResponseWriter, method, urlPath string, body []byte) {
+	pathParts := strings.Split(strings.Trim(strings.TrimPrefix(urlPath, "/mgmt/"), "/"), "/")
+	switch pathParts[0] {
+	case "filestore":
+		a.restFilestore(w, method, urlPath, pathParts[1:], body)
+	case "config":
+		a.restConfig(w, method, urlPath, pathParts[1:], body)
+	case "status":
+		a.restStatus(w, method, urlPath, pathParts[1:])
+	case "metadata":
+		a.restMetadata(w, method, urlPath, pathParts[1:])
+	case "actionqueue":
+		a.restActionQueue(w, method, urlPath, pathParts[1:], body)
+	default:
+		restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+	}
+}
+
+// restFilestore serves filestore requests
+// (/mgmt/filestore/{domain}/{location}/{path}).
+func (a *Appliance) restFilestore(w http.ResponseWriter, method, urlPath string, pathParts []string, body []byte) {
+	if len(pathParts) == 0 || pathParts[0] == "" {
+		restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+		return
+	}
+	domainName := pathParts[0]
+	d, ok := a.domains[domainName]
+	if !ok {
+		restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+		return
+	}
+
+	if len(pathParts) == 1 {
+		if method != "GET" {
+			restError(w, http.StatusMethodNotAllowed, urlPath, "Method not allowed.")
+			return
+		}
+		locations := make([]interface{}, len(Locations))
+		for idx, location := range Locations {
+			locations[idx] = map[string]interface{}{"name": location,
+				"href": fmt.Sprintf("/mgmt/filestore/%s/%s", domainName, strings.TrimSuffix(location, ":"))}
+		}
+		restResponse(w, http.StatusOK, urlPath,
+			map[string]interface{}{"filestore": map[string]interface{}{"location": locations}})
+		return
+	}
+
+	filePath := normalizePath(pathParts[1] + ":/" + strings.Join(pathParts[2:], "/"))
+	if !isLocation(pathParts[1] + ":") {
+		restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+		return
+	}
+
+	switch method {
+	case "GET":
+		if d.isDir(filePath) {
+			restResponse(w, http.StatusOK, urlPath,
+				map[string]interface{}{"filestore": map[string]interface{}{
+					"location": a.restDirListing(d, domainName, filePath)}})
+			return
+		}
+		f, ok := d.files[filePath]
+		if !ok {
+			restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+			return
+		}
+		restResponse(w, http.StatusOK, urlPath,
+			map[string]interface{}{"file": base64.StdEncoding.EncodeToString(f.content)})
+	case "PUT":
+		if d.isDir(filePath) || !d.isDir(parentPath(filePath)) {
+			restError(w, http.StatusBadRequest, urlPath, "Cannot write to the specified file.")
+			return
+		}
+		_, content, err := parseRestFile(body)
+		if err != nil {
+			restError(w, http.StatusBadRequest, urlPath, err.Error())
+			return
+		}
+		_, existing := d.files[filePath]
+		d.files[filePath] = &file{content: content, modified: time.Now()}
+		if existing {
+			restResponse(w, http.StatusOK, urlPath, map[string]interface{}{"result": "File was updated."})
+		} else {
+			restResponse(w, http.StatusCreated, urlPath, map[string]interface{}{"result": "File was created."})
+		}
+	case "POST":
+		if !d.isDir(filePath) {
+			restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+			return
+		}
+		var request struct {
+			File      *json.RawMessage `json:"file"`
+			Directory *struct {
+				Name string `json:"name"`
+			} `json:"directory"`
+		}
+		err := json.Unmarshal(body, &request)
+		if err != nil {
+			restError(w, http.StatusBadRequest, urlPath, err.Error())
+			return
+		}
+		switch {
+		case request.File != nil:
+			name, content, err := parseRestFile(body)
+			if err != nil {
+				restError(w, http.StatusBadRequest, urlPath, err.Error())
+				return
+			}
+			newFilePath := normalizePath(filePath + "/" + name)
+			if _, exists := d.files[newFilePath]; exists {
+				restError(w, http.StatusConflict, urlPath, "Resource already exists.")
+				return
+			}
+			d.files[newFilePath] = &file{content: content, modified: time.Now()}
+			restResponse(w, http.StatusCreated, urlPath, map[string]interface{}{
+				"_links": map[string]interface{}{"location": map[string]interface{}{
+					"href": strings.TrimRight(urlPath, "/") + "/" + name}},
+				"result": "File was created."})
+		case request.Directory != nil && request.Directory.Name != "":
+			newDirPath := normalizePath(filePath + "/" + request.Directory.Name)
+			if _, exists := d.files[newDirPath]; exists {
+				restError(w, http.StatusConflict, urlPath, "Resource already exists.")
+				return
+			}
+			d.files[newDirPath] = &file{dir: true, modified: time.Now()}
+			restResponse(w, http.StatusCreated, urlPath, map[string]interface{}{"result": "Directory was created."})
+		default:
+			restError(w, http.StatusBadRequest, urlPath, "Expected file or directory in request.")
+		}
+	case "DELETE":
+		f, ok := d.files[filePath]
+		if !ok {
+			restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+			return
+		}
+		d.remove(filePath)
+		if f.dir {
+			restResponse(w, http.StatusOK, urlPath, map[string]interface{}{"result": "Directory was deleted."})
+		} else {
+			restResponse(w, http.StatusOK, urlPath, map[string]interface{}{"result": "File was deleted."})
+		}
+	default:
+		restError(w, http.StatusMethodNotAllowed, urlPath, "Method not allowed.")
+	}
+}
+
+// restDirListing creates REST JSON listing of the directory content.
+func (a *Appliance) restDirListing(d *domain, domainName, dirPath string) map[string]interface{} {
+	listing := map[string]interface{}{"name": dirPath,
+		"href": "/mgmt/filestore/" + domainName + "/" + strings.Replace(dirPath, ":", "", 1)}
+	dirs := make([]interface{}, 0)
+	files := make([]interface{}, 0)
+	for _, childPath := range d.children(dirPath) {
+		child := d.files[childPath]
+		href := "/mgmt/filestore/" + domainName + "/" + strings.Replace(childPath, ":", "", 1)
+		if child.dir {
+			dirs = append(dirs, map[string]interface{}{"name": childPath, "href": href})
+		} else {
+			files = append(files, map[string]interface{}{"name": baseName(childPath),
+				"size": len(child.content), "modified": child.modified.Format(timeLayout),
+				"href": href})
+		}
+	}
+	// DataPower returns JSON object instead of array for single entry.
+	if len(dirs) > 0 {
+		listing["directory"] = oneOrMany(dirs)
+	}
+	if len(files) > 0 {
+		listing["file"] = oneOrMany(files)
+	}
+	return listing
+}
+
+// parseRestFile parses REST file upload request ({"file": {"name": "", "content": ""}}).
+func parseRestFile(body []byte) (name string, content []byte, err error) {
+	var request struct {
+		File struct {
+			Name    string `json:"name"`
+			Content string `json:"content"`
+		} `json:"file"`
+	}
+	err = json.Unmarshal(body, &request)
+	if err != nil {
+		return "", nil, err
+	}
+	content, err = base64.StdEncoding.DecodeString(request.File.Content)
+	if err != nil {
+		return "", nil, err
+	}
+	return request.File.Name, content, nil
+}
+
+// restConfig serves object configuration requests
+// (/mgmt/config/{domain}/{class}/{name}).
+func (a *Appliance) restConfig(w http.ResponseWriter, method, urlPath string, pathParts []string, body []byte) {
+	if len(pathParts) < 2 || len(pathParts) > 3 {
+		restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+		return
+	}
+	domainName, class := pathParts[0], pathParts[1]
+	name := ""
+	if len(pathParts) == 3 {
+		name = pathParts[2]
+	}
+	d, ok := a.domains[domainName]
+	if !ok {
+		restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+		return
+	}
+
+	switch {
+	case method == "GET" && name == "":
+		objects := make([]interface{}, 0)
+		for _, objectName := range d.objectNames(class) {
+			objects = append(objects, a.restObject(d, domainName, class, objectName))
+		}
+		response := make(map[string]interface{})
+		if len(objects) > 0 {
+			response[class] = oneOrMany(objects)
+		}
+		restResponse(w, http.StatusOK, urlPath, response)
+	case method == "GET":
+		if d.object(class, name) == nil {
+			restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+			return
+		}
+		restResponse(w, http.StatusOK, urlPath,
+			map[string]interface{}{class: a.restObject(d, domainName, class, name)})
+	case method == "PUT" && name != "", method == "POST" && name == "":
+		requestClass, requestName, fields, err := parseJSONObject(body)
+		if err != nil {
+			restError(w, http.StatusBadRequest, urlPath, err.Error())
+			return
+		}
+		if requestClass != class || (name != "" && requestName != name) {
+			restError(w, http.StatusBadRequest, urlPath,
+				fmt.Sprintf("Object %s '%s' doesn't match URL.", requestClass, requestName))
+			return
+		}
+		if method == "POST" && d.object(class, requestName) != nil {
+			restError(w, http.StatusBadRequest, urlPath,
+				fmt.Sprintf("Object '%s' already exists.", requestName))
+			return
+		}
+		if a.setObject(d, domainName, class, requestName, fields) {
+			restResponse(w, http.StatusCreated, urlPath,
+				map[string]interface{}{requestName: "Configuration was created."})
+		} else {
+			restResponse(w, http.StatusOK, urlPath,
+				map[string]interface{}{requestName: "Configuration was updated."})
+		}
+	case method == "DELETE" && name != "":
+		if !a.deleteObject(d, domainName, class, name) {
+			restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+			return
+		}
+		restResponse(w, http.StatusOK, urlPath,
+			map[string]interface{}{name: "Configuration was deleted."})
+	default:
+		restError(w, http.StatusMethodNotAllowed, urlPath, "Method not allowed.")
+	}
+}
+
+// restObject creates REST JSON object configuration.
+func (a *Appliance) restObject(d *domain, domainName, class, name string) map[string]interface{} {
+	result := fieldsToJSON(domainName, d.objects[class][name].fields).(map[string]interface{})
+	result["name"] = name
+	result["_links"] = map[string]interface{}{
+		"self": map[string]interface{}{"href": fmt.Sprintf("/mgmt/config/%s/%s/%s", domainName, class, name)},
+		"doc":  map[string]interface{}{"href": "/mgmt/docs/config/" + class}}
+	return result
+}
+
+// restStatus serves status requests (/mgmt/status/{domain}/{class}).
+func (a *Appliance) restStatus(w http.ResponseWriter, method, urlPath string, pathParts []string) {
+	if method != "GET" {
+		restError(w, http.StatusMethodNotAllowed, urlPath, "Method not allowed.")
+		return
+	}
+	if len(pathParts) == 1 && pathParts[0] == "" {
+		classNames := make(map[string]bool)
+		for _, d := range a.domains {
+			for _, class := range a.statusClassNames(d) {
+				classNames[class] = true
+			}
+		}
+		links := map[string]interface{}{"self": map[string]interface{}{"href": "/mgmt/status/"}}
+		for _, class := range sortedKeys(classNames) {
+			links[class] = map[string]interface{}{"href": "/mgmt/status/default/" + class}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"_links": links})
+		return
+	}
+	if len(pathParts) != 2 {
+		restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+		return
+	}
+
+	domainName, class := pathParts[0], pathParts[1]
+	d, ok := a.domains[domainName]
+	if !ok {
+		restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+		return
+	}
+	statuses := a.statuses(d, class)
+	response := make(map[string]interface{})
+	if len(statuses) > 0 {
+		statusList := make([]interface{}, len(statuses))
+		for idx, status := range statuses {
+			statusList[idx] = fieldsToJSON(domainName, status)
+		}
+		response[class] = oneOrMany(statusList)
+	}
+	restResponse(w, http.StatusOK, urlPath, response)
+}
+
+// restMetadata serves object class metadata requests
+// (/mgmt/metadata/latest/{class}).
+func (a *Appliance) restMetadata(w http.ResponseWriter, method, urlPath string, pathParts []string) {
+	if method != "GET" || pathParts[0] != "latest" || len(pathParts) > 2 {
+		restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+		return
+	}
+
+	if len(pathParts) == 1 {
+		links := map[string]interface{}{
+			"self": map[string]interface{}{"href": urlPath},
+			"doc":  map[string]interface{}{"href": "/mgmt/docs/metadata"}}
+		for _, class := range a.classNames() {
+			links[class] = map[string]interface{}{"href": "/mgmt/metadata/latest/" + class}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"_links": links})
+		return
+	}
+
+	class := pathParts[1]
+	properties, ok := a.classProperties(class)
+	if !ok {
+		restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+		return
+	}
+	propertyList := make([]interface{}, 0, len(properties))
+	for _, property := range properties {
+		propertyJSON := map[string]interface{}{"name": property.Name}
+		if property.Default != "" {
+			propertyJSON["default"] = property.Default
+		}
+		if property.Required {
+			propertyJSON["required"] = "true"
+		}
+		propertyList = append(propertyList, propertyJSON)
+	}
+	restResponse(w, http.StatusOK, urlPath, map[string]interface{}{
+		"object": map[string]interface{}{"name": class,
+			"properties": map[string]interface{}{"property": oneOrMany(propertyList)}}})
+}
+
+// restActionQueue serves action requests (/mgmt/actionqueue/{domain}), all
+// actions are completed immediately - results are returned by first
+// request for pending action (/mgmt/actionqueue/{domain}/pending/{action}).
+func (a *Appliance) restActionQueue(w http.ResponseWriter, method, urlPath string, pathParts []string, body []byte) {
+	if len(pathParts) == 0 || a.domains[pathParts[0]] == nil {
+		restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+		return
+	}
+	domainName := pathParts[0]
+
+	if method == "GET" {
+		result, ok := a.actions[urlPath]
+		if !ok {
+			restError(w, http.StatusNotFound, urlPath, "Resource not found.")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(result))
+		return
+	}
+	if method != "POST" || len(pathParts) != 1 {
+		restError(w, http.StatusMethodNotAllowed, urlPath, "Method not allowed.")
+		return
+	}
+
+	var request map[string]json.RawMessage
+	err := json.Unmarshal(body, &request)
+	if err != nil {
+		restError(w, http.StatusBadRequest, urlPath, err.Error())
+		return
+	}
+
+	switch {
+	case request["SaveConfig"] != nil:
+		a.domains[domainName].saveConfig()
+		restResponse(w, http.StatusOK, urlPath, map[string]interface{}{"SaveConfig": "Operation completed."})
+	case request["MoveFile"] != nil:
+		var moveRequest struct {
+			SURL string `json:"sURL"`
+			DURL string `json:"dURL"`
+		}
+		err = json.Unmarshal(request["MoveFile"], &moveRequest)
+		if err != nil {
+			restError(w, http.StatusBadRequest, urlPath, err.Error())
+			return
+		}
+		if !a.domains[domainName].move(normalizePath(moveRequest.SURL), normalizePath(moveRequest.DURL)) {
+			restError(w, http.StatusBadRequest, urlPath, "Cannot move the specified file.")
+			return
+		}
+		restResponse(w, http.StatusOK, urlPath, map[string]interface{}{"MoveFile": "Operation completed."})
+	case request["FlushStylesheetCache"] != nil:
+		restResponse(w, http.StatusOK, urlPath, map[string]interface{}{"FlushStylesheetCache": "Operation completed."})
+	case request["FlushDocumentCache"] != nil:
+		restResponse(w, http.StatusOK, urlPath, map[string]interface{}{"FlushDocumentCache": "Operation completed."})
+	case request["Export"] != nil:
+		var export struct {
+			AllFiles string
+			Object   []struct {
+				Class      string `json:"class"`
+				Name       string `json:"name"`
+				RefObjects string `json:"ref-objects"`
+			}
+		}
+		err = json.Unmarshal(request["Export"], &export)
+		if err != nil {
+			restError(w, http.StatusBadRequest, urlPath, err.Error())
+			return
+		}
+		objects := make([]exportObject, len(export.Object))
+		for idx, o := range export.Object {
+			objects[idx] = exportObject{class: o.Class, name: o.Name, refObjects: o.RefObjects == "on"}
+		}
+		exportBytes, err := a.exportDomain(domainName, objects, export.AllFiles == "on")
+		if err != nil {
+			restError(w, http.StatusBadRequest, urlPath, err.Error())
+			return
+		}
+		a.restActionAccepted(w, urlPath, domainName, "Export",
+			map[string]interface{}{"file": base64.StdEncoding.EncodeToString(exportBytes)})
+	case request["Import"] != nil:
+		var importRequest struct {
+			InputFile string
+		}
+		err = json.Unmarshal(request["Import"], &importRequest)
+		if err != nil {
+			restError(w, http.StatusBadRequest, urlPath, err.Error())
+			return
+		}
+		importBytes, err := base64.StdEncoding.DecodeString(importRequest.InputFile)
+		if err != nil {
+			restError(w, http.StatusBadRequest, urlPath, err.Error())
+			return
+		}
+		results, err := a.importDomain(domainName, importBytes)
+		if err != nil {
+			restError(w, http.StatusBadRequest, urlPath, err.Error())
+			return
+		}
+		a.restActionAccepted(w, urlPath, domainName, "Import",
+			map[string]interface{}{"Import": map[string]interface{}{"import-results": results.toJSON()}})
+	default:
+		restError(w, http.StatusBadRequest, urlPath, "Unsupported action.")
+	}
+}
+
+// restActionAccepted saves result of completed action and returns response
+// with location of the pending action.
+func (a *Appliance) restActionAccepted(w http.ResponseWriter, urlPath, domainName, action string, result interface{}) {
+	a.actionCounter++
+	location := fmt.Sprintf("/mgmt/actionqueue/%s/pending/%s-%s-%d",
+		domainName, action, time.Now().Format("20060102T150405Z"), a.actionCounter)
+	resultBytes, _ := json.MarshalIndent(map[string]interface{}{
+		"_links": map[string]interface{}{"self": map[string]interface{}{"href": location}},
+		"status": "completed", "result": result}, "", "  ")
+	a.actions[location] = string(resultBytes)
+
+	restResponse(w, http.StatusAccepted, urlPath, map[string]interface{}{
+		"_links": map[string]interface{}{"location": map[string]interface{}{"href": location}},
+		action:   map[string]interface{}{"status": "Action request accepted."}})
+}
+
+// oneOrMany returns single element instead of list with one element (same as
+// DataPower REST management interface does).
+func oneOrMany(list []interface{}) interface{} {
+	if len(list) == 1 {
+		return list[0]
+	}
+	return list
+}
+
+// restResponse writes REST JSON response adding self link.
+func restResponse(w http.ResponseWriter, statusCode int, urlPath string, response map[string]interface{}) {
+	links, ok := response["_links"].(map[string]interface{})
+	if !ok {
+		links = make(map[string]interface{})
+		response["_links"] = links
+	}
+	links["self"] = map[string]interface{}{"href": urlPath}
+	writeJSON(w, statusCode, response)
+}
+
+// restError writes REST JSON error response.
+func restError(w http.ResponseWriter, statusCode int, urlPath, message string) {
+	logging.LogDebugf("repo/dp/dpfake/restError(), %d '%s': %s", statusCode, urlPath, message)
+	restResponse(w, statusCode, urlPath, map[string]interface{}{"error": []string{message}})
+}
+
+// writeJSON writes JSON response.
+func writeJSON(w http.ResponseWriter, statusCode int, response interface{}) {
+	responseBytes, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(responseBytes)
+}
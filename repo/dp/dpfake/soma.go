@@ -0,0 +1,419 @@
+package dpfake
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"github.com/antchfx/xmlquery"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// schemaPath is path of XML management interface schema (in default domain).
+const schemaPath = "store:/xml-mgmt.xsd"
+
+// serveSoma serves DataPower SOMA management requests.
+func (a *Appliance) serveSoma(w http.ResponseWriter, body []byte) {
+	doc, err := xmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		somaFault(w, err.Error())
+		return
+	}
+	requestNode := xmlquery.FindOne(doc, "//*[local-name()='request']")
+	if requestNode == nil {
+		somaFault(w, "Missing request element.")
+		return
+	}
+	operations := childElements(requestNode)
+	if len(operations) == 0 {
+		somaFault(w, "Missing request operation.")
+		return
+	}
+	domainName := requestNode.SelectAttr("domain")
+	if domainName == "" {
+		domainName = DefaultDomain
+	}
+	operation := operations[0]
+	logging.LogDebugf("repo/dp/dpfake/serveSoma(), operation: '%s', domain: '%s'", operation.Data, domainName)
+
+	d, ok := a.domains[domainName]
+	if !ok {
+		somaResult(w, fmt.Sprintf("Domain '%s' does not exist.", domainName))
+		return
+	}
+
+	switch operation.Data {
+	case "get-filestore":
+		a.somaGetFilestore(w, d, operation)
+	case "get-file":
+		filePath := normalizePath(operation.SelectAttr("name"))
+		f, ok := d.files[filePath]
+		var content []byte
+		switch {
+		case ok && !f.dir:
+			content = f.content
+		case domainName == DefaultDomain && filePath == schemaPath:
+			content = a.managementSchema()
+		default:
+			somaResult(w, "Cannot read the specified file")
+			return
+		}
+		somaResponse(w, fmt.Sprintf(`<dp:file name="%s">%s</dp:file>`,
+			escapeXML(operation.SelectAttr("name")), base64.StdEncoding.EncodeToString(content)))
+	case "set-file":
+		filePath := normalizePath(operation.SelectAttr("name"))
+		content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(operation.InnerText()))
+		if err != nil || d.isDir(filePath) || !d.isDir(parentPath(filePath)) {
+			somaResult(w, "Cannot write to the specified file")
+			return
+		}
+		d.files[filePath] = &file{content: content, modified: time.Now()}
+		somaResult(w, "OK")
+	case "do-action":
+		a.somaDoAction(w, d, operation)
+	case "get-config":
+		a.somaGetConfig(w, d, operation)
+	case "set-config":
+		for _, objectNode := range childElements(operation) {
+			a.setObject(d, domainName, objectNode.Data, objectNode.SelectAttr("name"), fieldsFromXML(objectNode))
+		}
+		somaResult(w, "OK")
+	case "del-config":
+		for _, objectNode := range childElements(operation) {
+			if !a.deleteObject(d, domainName, objectNode.Data, objectNode.SelectAttr("name")) {
+				somaResult(w, fmt.Sprintf("Cannot delete %s '%s'", objectNode.Data, objectNode.SelectAttr("name")))
+				return
+			}
+		}
+		somaResult(w, "OK")
+	case "get-status":
+		a.somaGetStatus(w, d, operation)
+	case "do-export":
+		objects := make([]exportObject, 0)
+		for _, objectNode := range childElements(operation) {
+			if objectNode.Data == "object" {
+				objects = append(objects, exportObject{class: objectNode.SelectAttr("class"),
+					name:       objectNode.SelectAttr("name"),
+					refObjects: objectNode.SelectAttr("ref-objects") == "true"})
+			}
+		}
+		exportBytes, err := a.exportDomain(domainName, objects, operation.SelectAttr("all-files") == "true")
+		if err != nil {
+			somaResult(w, err.Error())
+			return
+		}
+		somaResponse(w, `<dp:file>`+base64.StdEncoding.EncodeToString(exportBytes)+`</dp:file>`)
+	case "do-backup":
+		backupBytes, err := a.backupDomains(somaDomainNames(operation))
+		if err != nil {
+			somaResult(w, err.Error())
+			return
+		}
+		somaResponse(w, `<dp:file>`+base64.StdEncoding.EncodeToString(backupBytes)+`</dp:file>`)
+	case "do-import", "do-restore":
+		inputFile := childElement(operation, "input-file")
+		if inputFile == nil {
+			somaResult(w, "Missing input-file element.")
+			return
+		}
+		inputBytes, err := base64.StdEncoding.DecodeString(strings.TrimSpace(inputFile.InnerText()))
+		if err != nil {
+			somaResult(w, err.Error())
+			return
+		}
+		var results []*importResults
+		if operation.Data == "do-import" {
+			var domainResults *importResults
+			domainResults, err = a.importDomain(domainName, inputBytes)
+			results = []*importResults{domainResults}
+		} else {
+			results, err = a.restoreDomains(inputBytes, somaDomainNames(operation))
+		}
+		if err != nil {
+			somaResult(w, err.Error())
+			return
+		}
+		var buf bytes.Buffer
+		buf.WriteString("<dp:import>")
+		for _, domainResults := range results {
+			domainResults.writeXML(&buf)
+		}
+		buf.WriteString("</dp:import>")
+		somaResponse(w, buf.String())
+	default:
+		somaFault(w, fmt.Sprintf("Unsupported operation '%s'.", operation.Data))
+	}
+}
+
+// somaGetFilestore serves get-filestore SOMA request.
+func (a *Appliance) somaGetFilestore(w http.ResponseWriter, d *domain, operation *xmlquery.Node) {
+	layoutOnly := operation.SelectAttr("layout-only") == "true"
+	noSubdirectories := operation.SelectAttr("no-subdirectories") == "true"
+	requestedLocation := operation.SelectAttr("location")
+
+	var buf bytes.Buffer
+	buf.WriteString("<dp:filestore>\n")
+	for _, location := range Locations {
+		if requestedLocation != "" && normalizePath(requestedLocation) != location {
+			continue
+		}
+		buf.WriteString(fmt.Sprintf(`<location name="%s">`+"\n", location))
+		if layoutOnly {
+			dirCount, fileCount := 0, 0
+			for _, childPath := range d.children(location) {
+				if d.files[childPath].dir {
+					dirCount++
+				} else {
+					fileCount++
+				}
+			}
+			buf.WriteString(fmt.Sprintf("<directories>%d</directories>\n<files>%d</files>\n", dirCount, fileCount))
+		} else {
+			a.writeSomaDir(&buf, d, location, noSubdirectories)
+		}
+		buf.WriteString("</location>\n")
+	}
+	buf.WriteString("</dp:filestore>")
+	somaResponse(w, buf.String())
+}
+
+// writeSomaDir writes SOMA filestore listing of the directory content.
+func (a *Appliance) writeSomaDir(buf *bytes.Buffer, d *domain, dirPath string, noSubdirectories bool) {
+	for _, childPath := range d.children(dirPath) {
+		child := d.files[childPath]
+		if child.dir {
+			buf.WriteString(fmt.Sprintf(`<directory name="%s">`+"\n", escapeXML(childPath)))
+			if !noSubdirectories {
+				a.writeSomaDir(buf, d, childPath, false)
+			}
+			buf.WriteString("</directory>\n")
+		} else {
+			buf.WriteString(fmt.Sprintf("<file name=\"%s\">\n<size>%d</size>\n<modified>%s</modified>\n</file>\n",
+				escapeXML(baseName(childPath)), len(child.content), child.modified.Format(timeLayout)))
+		}
+	}
+}
+
+// somaDoAction serves do-action SOMA request.
+func (a *Appliance) somaDoAction(w http.ResponseWriter, d *domain, operation *xmlquery.Node) {
+	actions := childElements(operation)
+	if len(actions) != 1 {
+		somaFault(w, "Expected one action.")
+		return
+	}
+	action := actions[0]
+	actionParam := func(name string) string {
+		paramNode := action.SelectElement(name)
+		if paramNode == nil {
+			return ""
+		}
+		return normalizePath(strings.TrimSpace(paramNode.InnerText()))
+	}
+
+	switch action.Data {
+	case "CreateDir":
+		dirPath := actionParam("Dir")
+		if _, exists := d.files[dirPath]; exists || isLocation(dirPath) {
+			somaResult(w, "Directory already exists.")
+			return
+		}
+		d.mkdirAll(dirPath)
+	case "RemoveDir":
+		dirPath := actionParam("Dir")
+		if !d.isDir(dirPath) || isLocation(dirPath) {
+			somaResult(w, "Cannot remove the specified directory.")
+			return
+		}
+		d.remove(dirPath)
+	case "DeleteFile":
+		filePath := actionParam("File")
+		if f, ok := d.files[filePath]; !ok || f.dir {
+			somaResult(w, "Cannot delete the specified file.")
+			return
+		}
+		d.remove(filePath)
+	case "MoveFile":
+		if !d.move(actionParam("sURL"), actionParam("dURL")) {
+			somaResult(w, "Cannot move the specified file.")
+			return
+		}
+	case "SaveConfig":
+		d.saveConfig()
+	case "FlushStylesheetCache", "FlushDocumentCache":
+	default:
+		somaResult(w, fmt.Sprintf("Unsupported action '%s'.", action.Data))
+		return
+	}
+	somaResult(w, "OK")
+}
+
+// somaGetConfig serves get-config SOMA request.
+func (a *Appliance) somaGetConfig(w http.ResponseWriter, d *domain, operation *xmlquery.Node) {
+	classes := d.classNames()
+	if class := operation.SelectAttr("class"); class != "" {
+		classes = []string{class}
+	}
+	name := operation.SelectAttr("name")
+	persisted := operation.SelectAttr("persisted") == "true"
+
+	var buf bytes.Buffer
+	buf.WriteString("<dp:config>\n")
+	for _, class := range classes {
+		objectNames := make(map[string]bool)
+		for objectName := range d.objects[class] {
+			objectNames[objectName] = true
+		}
+		for _, objectName := range sortedKeys(objectNames) {
+			if name != "" && objectName != name {
+				continue
+			}
+			o := d.objects[class][objectName]
+			switch {
+			case persisted && o.persisted != nil:
+				objectToXML(&buf, class, objectName, o.persisted, "")
+			case !persisted && !o.deleted:
+				objectToXML(&buf, class, objectName, o.fields, "")
+			}
+		}
+	}
+	buf.WriteString("</dp:config>")
+	somaResponse(w, buf.String())
+}
+
+// somaGetStatus serves get-status SOMA request.
+func (a *Appliance) somaGetStatus(w http.ResponseWriter, d *domain, operation *xmlquery.Node) {
+	classes := a.statusClassNames(d)
+	if class := operation.SelectAttr("class"); class != "" {
+		classes = []string{class}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<dp:status>\n")
+	for _, class := range classes {
+		statuses := a.statuses(d, class)
+		if class == "ObjectStatus" {
+			statuses = d.objectStatuses(operation.SelectAttr("object-class"))
+		}
+		for _, status := range statuses {
+			fieldsToXML(&buf, class, status, "")
+		}
+	}
+	buf.WriteString("</dp:status>")
+	somaResponse(w, buf.String())
+}
+
+// managementSchema generates XML management interface schema containing
+// definitions of all known object classes.
+func (a *Appliance) managementSchema() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<xsd:schema xmlns:xsd="http://www.w3.org/2001/XMLSchema" xmlns:tns="http://www.datapower.com/schemas/management" targetNamespace="http://www.datapower.com/schemas/management">
+  <xsd:complexType name="ConfigBase">
+    <xsd:attribute name="name" type="xsd:string"/>
+  </xsd:complexType>
+`)
+	for _, class := range a.classNames() {
+		properties, _ := a.classProperties(class)
+		buf.WriteString(fmt.Sprintf(`  <xsd:complexType name="Config%s">
+    <xsd:complexContent>
+      <xsd:extension base="tns:ConfigBase">
+        <xsd:sequence>
+`, class))
+		for _, property := range properties {
+			buf.WriteString(fmt.Sprintf(`          <xsd:element name="%s"`, property.Name))
+			if !property.Required {
+				buf.WriteString(` minOccurs="0"`)
+			}
+			if property.Default != "" {
+				buf.WriteString(fmt.Sprintf(` default="%s"`, escapeXML(property.Default)))
+			}
+			buf.WriteString("/>\n")
+		}
+		buf.WriteString(`        </xsd:sequence>
+      </xsd:extension>
+    </xsd:complexContent>
+  </xsd:complexType>
+`)
+	}
+	buf.WriteString("</xsd:schema>\n")
+	return buf.Bytes()
+}
+
+// serveAmp serves DataPower AMP management requests (only domain list and
+// device info requests are supported).
+func (a *Appliance) serveAmp(w http.ResponseWriter, body []byte) {
+	doc, err := xmlquery.Parse(bytes.NewReader(body))
+	if err != nil {
+		somaFault(w, err.Error())
+		return
+	}
+	var requestName string
+	if bodyNode := xmlquery.FindOne(doc, "//*[local-name()='Body']"); bodyNode != nil {
+		if requests := childElements(bodyNode); len(requests) > 0 {
+			requestName = requests[0].Data
+		}
+	}
+	logging.LogDebugf("repo/dp/dpfake/serveAmp(), request: '%s'", requestName)
+
+	var buf bytes.Buffer
+	switch requestName {
+	case "GetDomainListRequest":
+		buf.WriteString(`<amp:GetDomainListResponse xmlns:amp="http://www.datapower.com/schemas/appliance/management/1.0">`)
+		for _, domainName := range a.domainNames() {
+			buf.WriteString("<amp:Domain>" + escapeXML(domainName) + "</amp:Domain>")
+		}
+		buf.WriteString("</amp:GetDomainListResponse>")
+	case "GetDeviceInfoRequest":
+		buf.WriteString(`<amp:GetDeviceInfoResponse xmlns:amp="http://www.datapower.com/schemas/appliance/management/1.0">` +
+			"<amp:DeviceName>dpfake</amp:DeviceName><amp:DeviceSerialNo>0000001</amp:DeviceSerialNo>" +
+			"<amp:DeviceID>dpfake</amp:DeviceID><amp:DeviceType>IDG</amp:DeviceType>" +
+			"<amp:ModelType>IBM DataPower Gateway</amp:ModelType><amp:FirmwareLevel>dpfake</amp:FirmwareLevel>" +
+			"</amp:GetDeviceInfoResponse>")
+	default:
+		somaFault(w, fmt.Sprintf("Unsupported AMP request '%s'.", requestName))
+		return
+	}
+	writeSoap(w, http.StatusOK, buf.String())
+}
+
+// somaDomainNames returns names of domains given in do-backup or do-restore
+// SOMA request.
+func somaDomainNames(operation *xmlquery.Node) []string {
+	domainNames := make([]string, 0)
+	for _, domainNode := range childElements(operation) {
+		if domainNode.Data == "domain" {
+			domainNames = append(domainNames, domainNode.SelectAttr("name"))
+		}
+	}
+	return domainNames
+}
+
+// somaResult writes SOMA response containing just the result message.
+func somaResult(w http.ResponseWriter, result string) {
+	somaResponse(w, "<dp:result>"+escapeXML(result)+"</dp:result>")
+}
+
+// somaResponse writes SOMA response with given content.
+func somaResponse(w http.ResponseWriter, content string) {
+	writeSoap(w, http.StatusOK, `<dp:response xmlns:dp="http://www.datapower.com/schemas/management">`+
+		"<dp:timestamp>"+time.Now().Format(time.RFC3339)+"</dp:timestamp>\n"+
+		content+"</dp:response>")
+}
+
+// somaFault writes SOAP fault response.
+func somaFault(w http.ResponseWriter, message string) {
+	logging.LogDebugf("repo/dp/dpfake/somaFault(), %s", message)
+	writeSoap(w, http.StatusInternalServerError, "<env:Fault><faultcode>env:Client</faultcode><faultstring>"+
+		escapeXML(message)+"</faultstring></env:Fault>")
+}
+
+// writeSoap writes SOAP envelope with given body content.
+func writeSoap(w http.ResponseWriter, statusCode int, content string) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(statusCode)
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<env:Envelope xmlns:env="http://schemas.xmlsoap.org/soap/envelope/"><env:Body>` +
+		content + "</env:Body></env:Envelope>\n"))
+}
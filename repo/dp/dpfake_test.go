@@ -0,0 +1,186 @@
+package dp
+
+import (
+	"context"
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/repo/dp/dpfake"
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"strings"
+	"testing"
+)
+
+// newFakeRepo starts fake DataPower appliance and creates DataPower repo
+// connected to it using given management interface.
+func newFakeRepo(t *testing.T, managementInterface string) (*DataPowerRepo, *dpfake.Appliance) {
+	a := dpfake.NewAppliance("admin", "secret")
+	a.AddDomain("test")
+	url := a.Start()
+
+	dpa := config.DataPowerAppliance{Username: "admin"}
+	switch managementInterface {
+	case config.DpInterfaceRest:
+		dpa.RestUrl = url
+	case config.DpInterfaceSoma:
+		dpa.SomaUrl = url
+	}
+	dpa.SetDpPlaintextPassword("secret")
+	config.Conf.DataPowerAppliances["fake"] = dpa
+
+	r := NewRepo("fake", model.DpFilestoreMode)
+	err := r.InitNetworkSettings("fake", dpa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, a
+}
+
+func TestFakeAppliance(t *testing.T) {
+	for _, managementInterface := range []string{config.DpInterfaceRest, config.DpInterfaceSoma} {
+		t.Run(managementInterface+" domains", func(t *testing.T) {
+			r, a := newFakeRepo(t, managementInterface)
+			defer a.Close()
+
+			domains, err := r.fetchDpDomains(context.Background())
+			assert.DeepEqual(t, "fetchDpDomains()", err, nil)
+			assert.DeepEqual(t, "fetchDpDomains()", domains,
+				[]dpDomainInfo{{name: "default", saveNeeded: true}, {name: "test"}})
+		})
+
+		t.Run(managementInterface+" files", func(t *testing.T) {
+			r, a := newFakeRepo(t, managementInterface)
+			defer a.Close()
+
+			created, err := r.CreateDirByPath(context.Background(), "test", "local:", "dir")
+			assert.DeepEqual(t, "CreateDirByPath()", err, nil)
+			assert.DeepEqual(t, "CreateDirByPath()", created, true)
+			fileType, err := r.GetFileTypeByPath(context.Background(), "test", "local:", "dir")
+			assert.DeepEqual(t, "GetFileTypeByPath()", err, nil)
+			assert.DeepEqual(t, "GetFileTypeByPath()", fileType, model.ItemDirectory)
+
+			for _, content := range []string{"first", "second"} {
+				updated, err := r.UpdateFileByPath(context.Background(), "test", "local:/dir/a.txt", []byte(content))
+				assert.DeepEqual(t, "UpdateFileByPath()", err, nil)
+				assert.DeepEqual(t, "UpdateFileByPath()", updated, true)
+				fileContent, err := r.GetFileByPath(context.Background(), "test", "local:/dir/a.txt")
+				assert.DeepEqual(t, "GetFileByPath()", err, nil)
+				assert.DeepEqual(t, "GetFileByPath()", string(fileContent), content)
+			}
+			fileContent, _ := a.File("test", "local:/dir/a.txt")
+			assert.DeepEqual(t, "File()", string(fileContent), "second")
+
+			dirView := &model.ItemConfig{Type: model.ItemDirectory,
+				DpAppliance: "fake", DpDomain: "test", Path: "local:/dir"}
+			itemList, err := r.GetList(context.Background(), dirView)
+			assert.DeepEqual(t, "GetList()", err, nil)
+			assert.DeepEqual(t, "GetList()", len(itemList), 2)
+			assert.DeepEqual(t, "GetList()", itemList[1].Name, "a.txt")
+			assert.DeepEqual(t, "GetList()", itemList[1].Size, "6")
+
+			moved, err := r.MoveFileByPath(context.Background(), "test", "local:/dir/a.txt", "local:/dir/b.txt")
+			assert.DeepEqual(t, "MoveFileByPath()", err, nil)
+			assert.DeepEqual(t, "MoveFileByPath()", moved, true)
+			fileContent, _ = a.File("test", "local:/dir/b.txt")
+			assert.DeepEqual(t, "File()", string(fileContent), "second")
+			_, ok := a.File("test", "local:/dir/a.txt")
+			assert.DeepEqual(t, "File()", ok, false)
+			moved, err = r.MoveFileByPath(context.Background(), "test", "local:/dir/b.txt", "local:/missing/b.txt")
+			assert.DeepEqual(t, "MoveFileByPath() to missing dir", err != nil, true)
+			assert.DeepEqual(t, "MoveFileByPath() to missing dir", moved, false)
+
+			deleted, err := r.Delete(context.Background(), dirView, model.ItemFile, "local:/dir", "b.txt")
+			assert.DeepEqual(t, "Delete()", err, nil)
+			assert.DeepEqual(t, "Delete()", deleted, true)
+			fileType, err = r.GetFileTypeByPath(context.Background(), "test", "local:/dir", "b.txt")
+			assert.DeepEqual(t, "GetFileTypeByPath()", err, nil)
+			assert.DeepEqual(t, "GetFileTypeByPath()", fileType, model.ItemNone)
+		})
+
+		t.Run(managementInterface+" objects", func(t *testing.T) {
+			r, a := newFakeRepo(t, managementInterface)
+			defer a.Close()
+
+			objectContent := `{"XMLManager":{"name":"xm","CacheSize":"100"}}`
+			if managementInterface == config.DpInterfaceSoma {
+				objectContent = `<XMLManager name="xm"><CacheSize>100</CacheSize></XMLManager>`
+			}
+			err := r.SetObject(context.Background(), "test", "XMLManager", "xm", []byte(objectContent), false)
+			assert.DeepEqual(t, "SetObject()", err, nil)
+			fields, ok := a.Object("test", "XMLManager", "xm")
+			assert.DeepEqual(t, "Object()", ok, true)
+			assert.DeepEqual(t, "Object()", fields, dpfake.Fields{"CacheSize": "100"})
+
+			objectBytes, err := r.GetObject(context.Background(), "test", "XMLManager", "xm", false)
+			assert.DeepEqual(t, "GetObject()", err, nil)
+			assert.DeepEqual(t, "GetObject()", strings.Contains(string(objectBytes), "100"), true)
+			class, name, err := r.ParseObjectClassAndName(objectBytes)
+			assert.DeepEqual(t, "ParseObjectClassAndName()", err, nil)
+			assert.DeepEqual(t, "ParseObjectClassAndName()", class+"/"+name, "XMLManager/xm")
+
+			classView := &model.ItemConfig{Type: model.ItemDpObjectClass,
+				DpAppliance: "fake", DpDomain: "test", Path: "XMLManager"}
+			deleted, err := r.Delete(context.Background(), classView, model.ItemDpObject, "XMLManager", "xm")
+			assert.DeepEqual(t, "Delete()", err, nil)
+			assert.DeepEqual(t, "Delete()", deleted, true)
+			_, ok = a.Object("test", "XMLManager", "xm")
+			assert.DeepEqual(t, "Object()", ok, false)
+		})
+
+		t.Run(managementInterface+" metadata", func(t *testing.T) {
+			r, a := newFakeRepo(t, managementInterface)
+			defer a.Close()
+
+			classNames, err := r.GetObjectClasses(context.Background())
+			assert.DeepEqual(t, "GetObjectClasses()", err, nil)
+			assert.DeepEqual(t, "GetObjectClasses()", classNames,
+				[]string{"Domain", "HTTPSourceProtocolHandler", "MultiProtocolGateway",
+					"XMLFirewallService", "XMLManager"})
+
+			properties, err := r.getObjectClassProperties(context.Background(), "XMLManager")
+			assert.DeepEqual(t, "getObjectClassProperties()", err, nil)
+			assert.DeepEqual(t, "getObjectClassProperties()", properties,
+				[]dpObjectProperty{{name: "mAdminState", defaultValue: "enabled"},
+					{name: "UserSummary"}, {name: "CacheSize", defaultValue: "256"}})
+		})
+
+		t.Run(managementInterface+" statuses", func(t *testing.T) {
+			r, a := newFakeRepo(t, managementInterface)
+			defer a.Close()
+			a.SetStatuses("test", "StylesheetCachingSummary",
+				dpfake.Fields{"XMLManager": dpfake.Ref("XMLManager", "default"), "CacheCount": "3"})
+
+			statusView := &model.ItemConfig{Type: model.ItemDpStatusClass,
+				DpAppliance: "fake", DpDomain: "test", Path: "StylesheetCachingSummary"}
+			r.DpViewMode = model.DpStatusMode
+			itemList, err := r.GetList(context.Background(), statusView)
+			assert.DeepEqual(t, "GetList()", err, nil)
+			assert.DeepEqual(t, "GetList()", len(itemList), 2)
+			assert.DeepEqual(t, "GetList()", itemList[1].Name, "default")
+			assert.DeepEqual(t, "GetList()", itemList[1].Size, "3")
+		})
+
+		t.Run(managementInterface+" export & import", func(t *testing.T) {
+			r, a := newFakeRepo(t, managementInterface)
+			defer a.Close()
+			a.SetFile("test", "local:/xsl/a.xsl", []byte("<xsl/>"))
+			a.SetObject("test", "XMLManager", "exported", dpfake.Fields{"CacheSize": "10"})
+			a.AddDomain("copy")
+
+			exportBytes, err := r.ExportDomain(context.Background(), "test", "test-export")
+			assert.DeepEqual(t, "ExportDomain()", err, nil)
+			results, err := r.ImportDomain(context.Background(), "copy", "test-export", exportBytes)
+			assert.DeepEqual(t, "ImportDomain()", err, nil)
+			assert.DeepEqual(t, "ImportDomain()",
+				strings.Contains(string(results), "new          XMLManager 'exported'"), true)
+			assert.DeepEqual(t, "ImportDomain()",
+				strings.Contains(string(results), "created      local:///xsl/a.xsl"), true)
+
+			fields, ok := a.Object("copy", "XMLManager", "exported")
+			assert.DeepEqual(t, "Object()", ok, true)
+			assert.DeepEqual(t, "Object()", fields, dpfake.Fields{"CacheSize": "10"})
+			fileContent, ok := a.File("copy", "local:/xsl/a.xsl")
+			assert.DeepEqual(t, "File()", ok, true)
+			assert.DeepEqual(t, "File()", string(fileContent), "<xsl/>")
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package dp
 
 import (
+	"context"
 	"fmt"
 	"github.com/croz-ltd/dpcmder/utils/errs"
 	"io/ioutil"
@@ -9,7 +10,7 @@ import (
 
 type mockRequester struct{}
 
-func (nr mockRequester) httpRequest(dpa dpApplicance, urlFullPath, method, body string) (string, error) {
+func (nr mockRequester) httpRequest(ctx context.Context, dpa dpApplicance, urlFullPath, method, body string) (string, error) {
 	// fmt.Printf("%s %s\n", method, urlFullPath)
 	var content []byte
 	var err error
@@ -69,6 +70,26 @@ func (nr mockRequester) httpRequest(dpa dpApplicance, urlFullPath, method, body
 		}
 	case "https://my_dp_host:5554/mgmt/actionqueue/tmp/pending/Export-20200228T061406Z-2":
 		content, err = ioutil.ReadFile("testdata/export-svc-pending-get.json")
+	case "https://my_dp_host:5554/mgmt/actionqueue/test":
+		switch method {
+		case "POST":
+			content, err = ioutil.ReadFile("testdata/import-post-response.json")
+		default:
+			return "", errs.Errorf("dpmock_test: Unrecognized method '%s'", method)
+		}
+	case "https://my_dp_host:5554/mgmt/actionqueue/test/pending/Import-20200310T091015Z-4":
+		content, err = ioutil.ReadFile("testdata/import-pending-get.json")
+	case "https://my_dp_host:5554/mgmt/config/default/Domain/test":
+		switch method {
+		case "DELETE":
+			content, err = ioutil.ReadFile("testdata/delete_domain.json")
+		default:
+			return "", errs.Errorf("dpmock_test: Unrecognized method '%s'", method)
+		}
+	case "https://my_dp_host:5554/mgmt/metadata/latest":
+		content, err = ioutil.ReadFile("testdata/metadata_list.json")
+	case "https://my_dp_host:5554/mgmt/metadata/latest/HTTPSourceProtocolHandler":
+		content, err = ioutil.ReadFile("testdata/metadata_http_fsh.json")
 	case "https://my_dp_host:5554/mgmt/status/":
 		content, err = ioutil.ReadFile("testdata/status_class_list.json")
 	case "https://my_dp_host:5554/mgmt/status/MyDomain/StylesheetCachingSummary":
@@ -83,6 +104,7 @@ func (nr mockRequester) httpRequest(dpa dpApplicance, urlFullPath, method, body
 		var opObjClass string
 		var opLayoutOnly string
 		var opFilePath string
+		var opObjName string
 
 		r := regexp.MustCompile(`.*<man:([^ ]+)( class="([^ ]+)")?( object-class="([^ ]+)")?/>.*`)
 		matches := r.FindStringSubmatch(body)
@@ -111,13 +133,23 @@ func (nr mockRequester) httpRequest(dpa dpApplicance, urlFullPath, method, body
 		}
 
 		if len(matches) == 0 {
-			r = regexp.MustCompile(`.*<man:(do-export) .*`)
+			r = regexp.MustCompile(`.*<man:(do-export|do-import|do-restore) .*`)
 			matches = r.FindStringSubmatch(body)
 			if len(matches) == 2 {
 				opTag = matches[1]
 			}
 		}
 
+		if len(matches) == 0 {
+			r = regexp.MustCompile(`.*<man:(del-config)><([^ ]+) name="([^"]+)"/>.*`)
+			matches = r.FindStringSubmatch(body)
+			if len(matches) == 4 {
+				opTag = matches[1]
+				opClass = matches[2]
+				opObjName = matches[3]
+			}
+		}
+
 		if len(matches) == 0 {
 			fmt.Printf("dpmock_test: Unrecognized body of SOMA request:\n'%s'\n", body)
 			return "", errs.Error("dpmock_test: Unrecognized body of SOMA request")
@@ -151,16 +183,22 @@ func (nr mockRequester) httpRequest(dpa dpApplicance, urlFullPath, method, body
 			content, err = ioutil.ReadFile("testdata/non_existing_resource.xml")
 		case opTag == "get-file" && opFilePath == "store:/gatewayscript/b64-err-file.txt":
 			content, err = ioutil.ReadFile("testdata/get_file_gatewayscript_nonb64.xml")
+		case opTag == "get-file" && opFilePath == "store:/xml-mgmt.xsd":
+			content, err = ioutil.ReadFile("testdata/get_file_xml_mgmt_xsd.xml")
 		case opTag == "set-file" && opFilePath == "local:/upload/test-new-file.txt":
 			content, err = ioutil.ReadFile("testdata/update_file.xml")
 		case opTag == "set-file" && opFilePath == "local:/upload/test-existing-dir":
 			content, err = ioutil.ReadFile("testdata/update_file_existing_dir.xml")
 		case opTag == "do-export":
 			content, err = ioutil.ReadFile("testdata/export.soap")
+		case opTag == "del-config" && opClass == "Domain" && opObjName == "test":
+			content, err = ioutil.ReadFile("testdata/delete_domain.xml")
+		case opTag == "do-import", opTag == "do-restore":
+			content, err = ioutil.ReadFile("testdata/import.soap")
 		default:
 			fmt.Printf("dpmock_test: Unrecognized SOMA request opTag: '%s', "+
-				"opClass: '%s', opObjClass: '%s', opLayoutOnly: '%s', opFilePath: '%s'.\n",
-				opTag, opClass, opObjClass, opLayoutOnly, opFilePath)
+				"opClass: '%s', opObjClass: '%s', opLayoutOnly: '%s', opFilePath: '%s', opObjName: '%s'.\n",
+				opTag, opClass, opObjClass, opLayoutOnly, opFilePath, opObjName)
 		}
 
 	default:
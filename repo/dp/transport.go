@@ -0,0 +1,256 @@
+package dp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// retryBackoff is time to wait before first retry of failed GET request (time
+// is doubled for each next retry).
+var retryBackoff = 500 * time.Millisecond
+
+// transportSettings contains DataPower appliance configuration values (and
+// network configuration) used to create appliance HTTP client.
+type transportSettings struct {
+	proxy           string
+	caBundle        string
+	clientCert      string
+	clientKey       string
+	certFingerprint string
+	verifyHostname  bool
+	connectTimeout  time.Duration
+	responseTimeout time.Duration
+	keepAlive       time.Duration
+}
+
+// applianceClient contains HTTP client created for DataPower appliance and
+// settings used to create it.
+type applianceClient struct {
+	settings transportSettings
+	client   *http.Client
+}
+
+// clients contains HTTP client (with its own transport) for each DataPower
+// appliance (by appliance configuration name), so settings of one appliance
+// don't affect connections to other appliances and connections are reused.
+var clients = make(map[string]applianceClient)
+var clientsMutex sync.Mutex
+
+// getTransportSettings returns settings used to create appliance HTTP client.
+func getTransportSettings(dpa dpApplicance) transportSettings {
+	return transportSettings{
+		proxy:           dpa.Proxy,
+		caBundle:        dpa.CaBundle,
+		clientCert:      dpa.ClientCert,
+		clientKey:       dpa.ClientKey,
+		certFingerprint: dpa.CertFingerprint,
+		verifyHostname:  dpa.VerifyHostname,
+		connectTimeout:  time.Duration(config.Conf.Net.ConnectSeconds) * time.Second,
+		responseTimeout: time.Duration(config.Conf.Net.ResponseSeconds) * time.Second,
+		keepAlive:       time.Duration(config.Conf.Net.KeepAliveSeconds) * time.Second}
+}
+
+// getClient returns HTTP client for DataPower appliance, creating new one if
+// appliance doesn't have it yet or if its settings changed.
+func getClient(dpa dpApplicance) (*http.Client, error) {
+	clientsMutex.Lock()
+	defer clientsMutex.Unlock()
+
+	settings := getTransportSettings(dpa)
+	if ac, ok := clients[dpa.name]; ok && ac.settings == settings {
+		return ac.client, nil
+	}
+
+	logging.LogDebugf("repo/dp/getClient() - creating client for '%s'.", dpa.name)
+	transport, err := newTransport(settings)
+	if err != nil {
+		return nil, err
+	}
+	if ac, ok := clients[dpa.name]; ok {
+		ac.client.CloseIdleConnections()
+	}
+	client := &http.Client{Transport: transport}
+	clients[dpa.name] = applianceClient{settings: settings, client: client}
+	return client, nil
+}
+
+// newTransport creates new HTTP transport using appliance proxy, TLS settings
+// and network timeouts (zero timeout means no limit).
+func newTransport(settings transportSettings) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	dialer := &net.Dialer{Timeout: settings.connectTimeout, KeepAlive: settings.keepAlive}
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = settings.connectTimeout
+	transport.ResponseHeaderTimeout = settings.responseTimeout
+	transport.IdleConnTimeout = settings.keepAlive
+	if settings.proxy != "" {
+		proxyURL, err := url.Parse(settings.proxy)
+		if err != nil {
+			logging.LogDebug("repo/dp/newTransport() - Can't parse proxy URL: ", err)
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := newTLSConfig(settings)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// newTLSConfig creates TLS configuration for DataPower appliance connection.
+// Without CA bundle, fingerprint and hostname verification appliance
+// certificate is not verified (DataPower usually uses self-signed certificate).
+func newTLSConfig(settings transportSettings) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: !settings.verifyHostname}
+
+	var rootCAs *x509.CertPool
+	if settings.caBundle != "" {
+		caBytes, err := ioutil.ReadFile(settings.caBundle)
+		if err != nil {
+			logging.LogDebug("repo/dp/newTLSConfig() - Can't read CA bundle: ", err)
+			return nil, err
+		}
+		rootCAs = x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caBytes) {
+			return nil, errs.Errorf("No PEM certificates found in CA bundle '%s'.", settings.caBundle)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if settings.clientCert != "" || settings.clientKey != "" {
+		keyFile := settings.clientKey
+		if keyFile == "" {
+			keyFile = settings.clientCert
+		}
+		clientCert, err := tls.LoadX509KeyPair(settings.clientCert, keyFile)
+		if err != nil {
+			logging.LogDebug("repo/dp/newTLSConfig() - Can't load client certificate: ", err)
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	var fingerprint []byte
+	if settings.certFingerprint != "" {
+		var err error
+		fingerprint, err = parseFingerprint(settings.certFingerprint)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// When hostname is verified standard TLS verification is done before
+	// VerifyPeerCertificate is called, otherwise we verify certificate chain
+	// (if CA bundle is given) without hostname.
+	verifyChain := rootCAs != nil && !settings.verifyHostname
+	if fingerprint != nil || verifyChain {
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errs.Error("DataPower appliance didn't send certificate.")
+			}
+			if fingerprint != nil {
+				leafFingerprint := sha256.Sum256(rawCerts[0])
+				if !bytes.Equal(leafFingerprint[:], fingerprint) {
+					return errs.Errorf("DataPower appliance certificate fingerprint %s doesn't match pinned fingerprint.",
+						formatFingerprint(leafFingerprint[:]))
+				}
+			}
+			if verifyChain {
+				return verifyCertChain(rawCerts, rootCAs)
+			}
+			return nil
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// verifyCertChain verifies certificate chain sent by appliance using given CA
+// certificates (without verifying hostname).
+func verifyCertChain(rawCerts [][]byte, rootCAs *x509.CertPool) error {
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for idx, rawCert := range rawCerts {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return err
+		}
+		certs[idx] = cert
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{Roots: rootCAs, Intermediates: intermediates})
+	return err
+}
+
+// parseFingerprint parses SHA-256 certificate fingerprint given as hex string
+// (with or without ':' separators).
+func parseFingerprint(fingerprint string) ([]byte, error) {
+	fingerprintHex := strings.Replace(strings.TrimSpace(fingerprint), ":", "", -1)
+	fingerprintBytes, err := hex.DecodeString(fingerprintHex)
+	if err != nil || len(fingerprintBytes) != sha256.Size {
+		return nil, errs.Errorf("Invalid SHA-256 certificate fingerprint '%s'.", fingerprint)
+	}
+	return fingerprintBytes, nil
+}
+
+// formatFingerprint formats certificate fingerprint as upper case hex string
+// with ':' separators.
+func formatFingerprint(fingerprint []byte) string {
+	hexParts := make([]string, len(fingerprint))
+	for idx, b := range fingerprint {
+		hexParts[idx] = strings.ToUpper(hex.EncodeToString([]byte{b}))
+	}
+	return strings.Join(hexParts, ":")
+}
+
+// timeoutError converts network timeout error to error with clear message
+// shown to user, other errors are returned unchanged.
+func timeoutError(dpa dpApplicance, method, urlFullPath string, err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return errs.RequestTimeout{Appliance: dpa.name, Method: method, URL: urlFullPath}
+	}
+	return err
+}
+
+// retryableError returns true if request failed because of network problem
+// (timeout, refused or dropped connection) or DataPower was temporarily
+// unavailable, so request can be retried.
+func retryableError(err error) bool {
+	switch err := err.(type) {
+	case errs.RequestTimeout:
+		return true
+	case errs.UnexpectedHTTPResponse:
+		return err.StatusCode == http.StatusBadGateway ||
+			err.StatusCode == http.StatusServiceUnavailable ||
+			err.StatusCode == http.StatusGatewayTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,189 @@
+package dp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/pem"
+	"errors"
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/repo/dp/dpfake"
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTLSSettings(t *testing.T) {
+	a := dpfake.NewAppliance("admin", "secret")
+	a.AddDomain("test")
+	url := a.Start()
+	defer a.Close()
+
+	dirPath, err := ioutil.TempDir("", "dpcmder-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+	caBundlePath := filepath.Join(dirPath, "ca.pem")
+	caBytes := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: a.Certificate().Raw})
+	err = ioutil.WriteFile(caBundlePath, caBytes, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := sha256.Sum256(a.Certificate().Raw)
+
+	testDataMatrix := []struct {
+		name      string
+		dpa       config.DataPowerAppliance
+		initErr   bool
+		domainErr bool
+	}{
+		{name: "no verification", dpa: config.DataPowerAppliance{}},
+		{name: "pinned fingerprint",
+			dpa: config.DataPowerAppliance{CertFingerprint: formatFingerprint(fingerprint[:])}},
+		{name: "wrong fingerprint", domainErr: true,
+			dpa: config.DataPowerAppliance{CertFingerprint: formatFingerprint(make([]byte, sha256.Size))}},
+		{name: "invalid fingerprint", initErr: true,
+			dpa: config.DataPowerAppliance{CertFingerprint: "AB:CD"}},
+		{name: "CA bundle", dpa: config.DataPowerAppliance{CaBundle: caBundlePath}},
+		{name: "CA bundle & hostname",
+			dpa: config.DataPowerAppliance{CaBundle: caBundlePath, VerifyHostname: true}},
+		{name: "hostname without CA bundle", domainErr: true,
+			dpa: config.DataPowerAppliance{VerifyHostname: true}},
+		{name: "missing CA bundle", initErr: true,
+			dpa: config.DataPowerAppliance{CaBundle: filepath.Join(dirPath, "missing.pem")}},
+		{name: "missing client certificate", initErr: true,
+			dpa: config.DataPowerAppliance{ClientCert: filepath.Join(dirPath, "missing.pem")}},
+	}
+
+	for _, testCase := range testDataMatrix {
+		t.Run(testCase.name, func(t *testing.T) {
+			dpa := testCase.dpa
+			dpa.RestUrl = url
+			dpa.Username = "admin"
+			dpa.SetDpPlaintextPassword("secret")
+
+			r := NewRepo("tls "+testCase.name, model.DpFilestoreMode)
+			err := r.InitNetworkSettings("tls "+testCase.name, dpa)
+			assert.DeepEqual(t, "InitNetworkSettings()", err != nil, testCase.initErr)
+			if testCase.initErr {
+				return
+			}
+			_, err = r.fetchDpDomains(context.Background())
+			assert.DeepEqual(t, "fetchDpDomains()", err != nil, testCase.domainErr)
+		})
+	}
+}
+
+func TestGetClient(t *testing.T) {
+	lab := dpApplicance{name: "lab"}
+	prod := dpApplicance{name: "prod",
+		DataPowerAppliance: config.DataPowerAppliance{VerifyHostname: true}}
+
+	labClient, err := getClient(lab)
+	assert.DeepEqual(t, "getClient(lab)", err, nil)
+	prodClient, err := getClient(prod)
+	assert.DeepEqual(t, "getClient(prod)", err, nil)
+	labTransport := labClient.Transport.(*http.Transport)
+	prodTransport := prodClient.Transport.(*http.Transport)
+	assert.DeepEqual(t, "lab transport insecure", labTransport.TLSClientConfig.InsecureSkipVerify, true)
+	assert.DeepEqual(t, "prod transport insecure", prodTransport.TLSClientConfig.InsecureSkipVerify, false)
+	assert.DeepEqual(t, "lab response timeout", labTransport.ResponseHeaderTimeout,
+		time.Duration(config.Conf.Net.ResponseSeconds)*time.Second)
+
+	sameClient, _ := getClient(lab)
+	assert.DeepEqual(t, "getClient(lab) reused", sameClient == labClient, true)
+	lab.Proxy = "http://proxy:8080"
+	changedClient, _ := getClient(lab)
+	assert.DeepEqual(t, "getClient(lab) changed", changedClient == labClient, false)
+}
+
+func TestRetries(t *testing.T) {
+	oldNet, oldRetryBackoff := config.Conf.Net, retryBackoff
+	defer func() { config.Conf.Net, retryBackoff = oldNet, oldRetryBackoff }()
+	config.Conf.Net.MaxRetries = 2
+	retryBackoff = time.Millisecond
+
+	failures, calls := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		if calls <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	testDataMatrix := []struct {
+		method   string
+		failures int
+		calls    int
+		err      error
+	}{
+		{method: "GET", failures: 0, calls: 1},
+		{method: "GET", failures: 2, calls: 3},
+		{method: "GET", failures: 3, calls: 3,
+			err: errs.UnexpectedHTTPResponse{StatusCode: 503, Status: "503 Service Unavailable"}},
+		{method: "POST", failures: 1, calls: 1,
+			err: errs.UnexpectedHTTPResponse{StatusCode: 503, Status: "503 Service Unavailable"}},
+	}
+	for _, testCase := range testDataMatrix {
+		failures, calls = testCase.failures, 0
+		_, err := netRequester{}.httpRequest(context.Background(), dpApplicance{name: "retry"}, server.URL, testCase.method, "")
+		assert.DeepEqual(t, "httpRequest() err", err, testCase.err)
+		assert.DeepEqual(t, "httpRequest() calls", calls, testCase.calls)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	oldNet := config.Conf.Net
+	defer func() { config.Conf.Net = oldNet }()
+	config.Conf.Net.ResponseSeconds = 1
+	config.Conf.Net.MaxRetries = 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(1500 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	_, err := netRequester{}.httpRequest(context.Background(), dpApplicance{name: "slow"}, server.URL, "GET", "")
+	assert.DeepEqual(t, "httpRequest() err", err,
+		errs.RequestTimeout{Appliance: "slow", Method: "GET", URL: server.URL})
+}
+
+func TestParseFingerprint(t *testing.T) {
+	fingerprint := make([]byte, sha256.Size)
+	fingerprint[0] = 0xab
+	parsed, err := parseFingerprint(formatFingerprint(fingerprint))
+	assert.DeepEqual(t, "parseFingerprint()", err, nil)
+	assert.DeepEqual(t, "parseFingerprint()", parsed, fingerprint)
+	parsed, err = parseFingerprint("ab00000000000000000000000000000000000000000000000000000000000000")
+	assert.DeepEqual(t, "parseFingerprint()", err, nil)
+	assert.DeepEqual(t, "parseFingerprint()", parsed, fingerprint)
+	_, err = parseFingerprint("not hex")
+	assert.DeepEqual(t, "parseFingerprint()", err != nil, true)
+}
+
+func TestCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := netRequester{}.httpRequest(ctx, dpApplicance{name: "cancel"}, server.URL, "GET", "")
+	assert.DeepEqual(t, "httpRequest() canceled", errors.Is(err, context.Canceled), true)
+	assert.DeepEqual(t, "httpRequest() canceled in time", time.Since(start) < 2*time.Second, true)
+}
@@ -2,6 +2,9 @@
 package localfs
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"github.com/croz-ltd/dpcmder/config"
 	"github.com/croz-ltd/dpcmder/model"
@@ -29,7 +32,7 @@ func (r localRepo) String() string {
 }
 
 // GetInitialView returns initialy opened local directory info.
-func (r localRepo) GetInitialItem() (model.Item, error) {
+func (r localRepo) GetInitialItem(ctx context.Context) (model.Item, error) {
 	logging.LogDebug("repo/localfs/GetInitialItem()")
 	currPath, err := filepath.Abs(*config.LocalFolderPath)
 	if err != nil {
@@ -53,7 +56,7 @@ func (r localRepo) GetTitle(itemToShow *model.ItemConfig) string {
 }
 
 // GetList returns list of items for current directory.
-func (r localRepo) GetList(itemToShow *model.ItemConfig) (model.ItemList, error) {
+func (r localRepo) GetList(ctx context.Context, itemToShow *model.ItemConfig) (model.ItemList, error) {
 	logging.LogDebugf("repo/localfs/GetList('%s')", itemToShow)
 	currPath := itemToShow.Path
 	currName := paths.GetFileName(currPath)
@@ -78,7 +81,7 @@ func (r localRepo) GetList(itemToShow *model.ItemConfig) (model.ItemList, error)
 
 func (r localRepo) InvalidateCache() {}
 
-func (r localRepo) GetFile(currentView *model.ItemConfig, fileName string) ([]byte, error) {
+func (r localRepo) GetFile(ctx context.Context, currentView *model.ItemConfig, fileName string) ([]byte, error) {
 	logging.LogDebugf("repo/localfs/GetFile(%v, '%s')", currentView, fileName)
 	parentPath := currentView.Path
 	filePath := paths.GetFilePath(parentPath, fileName)
@@ -86,7 +89,7 @@ func (r localRepo) GetFile(currentView *model.ItemConfig, fileName string) ([]by
 	return GetFileByPath(filePath)
 }
 
-func (r localRepo) UpdateFile(currentView *model.ItemConfig, fileName string, newFileContent []byte) (bool, error) {
+func (r localRepo) UpdateFile(ctx context.Context, currentView *model.ItemConfig, fileName string, newFileContent []byte) (bool, error) {
 	logging.LogDebugf("repo/localfs/UpdateFile(%v, '%s', ..)", currentView, fileName)
 	parentPath := currentView.Path
 	filePath := paths.GetFilePath(parentPath, fileName)
@@ -100,7 +103,7 @@ func (r localRepo) UpdateFile(currentView *model.ItemConfig, fileName string, ne
 	return true, nil
 }
 
-func (r localRepo) GetFileType(viewConfig *model.ItemConfig, parentPath, fileName string) (model.ItemType, error) {
+func (r localRepo) GetFileType(ctx context.Context, viewConfig *model.ItemConfig, parentPath, fileName string) (model.ItemType, error) {
 	logging.LogDebugf("repo/localfs/GetFileType(%v, '%s', '%s')", viewConfig, parentPath, fileName)
 	filePath := r.GetFilePath(parentPath, fileName)
 	return getFileTypeFromPath(filePath)
@@ -111,7 +114,7 @@ func (r localRepo) GetFilePath(parentPath, fileName string) string {
 	return paths.GetFilePath(parentPath, fileName)
 }
 
-func (r localRepo) CreateDir(viewConfig *model.ItemConfig, parentPath, dirName string) (bool, error) {
+func (r localRepo) CreateDir(ctx context.Context, viewConfig *model.ItemConfig, parentPath, dirName string) (bool, error) {
 	logging.LogDebugf("repo/localfs/CreateDir(%v, '%s', '%s')", viewConfig, parentPath, dirName)
 	fi, err := os.Lstat(parentPath)
 	if err != nil {
@@ -127,9 +130,9 @@ func (r localRepo) CreateDir(viewConfig *model.ItemConfig, parentPath, dirName s
 	return true, nil
 }
 
-func (r localRepo) Delete(currentView *model.ItemConfig, itemType model.ItemType, parentPath, fileName string) (bool, error) {
+func (r localRepo) Delete(ctx context.Context, currentView *model.ItemConfig, itemType model.ItemType, parentPath, fileName string) (bool, error) {
 	logging.LogDebugf("repo/localfs/Delete(%v, '%s', '%s' (%s))", currentView, parentPath, fileName, itemType)
-	fileType, err := r.GetFileType(currentView, parentPath, fileName)
+	fileType, err := r.GetFileType(ctx, currentView, parentPath, fileName)
 	if err != nil {
 		logging.LogDebugf("repo/localfs/Delete(), err: %v", err)
 		return false, err
@@ -149,7 +152,7 @@ func (r localRepo) Delete(currentView *model.ItemConfig, itemType model.ItemType
 			return false, err
 		}
 		for _, subFile := range subFiles {
-			r.Delete(currentView, model.ItemAny, filePath, subFile.Name())
+			r.Delete(ctx, currentView, model.ItemAny, filePath, subFile.Name())
 		}
 		os.Remove(filePath)
 	default:
@@ -158,7 +161,7 @@ func (r localRepo) Delete(currentView *model.ItemConfig, itemType model.ItemType
 	return true, nil
 }
 
-func (r localRepo) GetViewConfigByPath(currentView *model.ItemConfig, dirPath string) (*model.ItemConfig, error) {
+func (r localRepo) GetViewConfigByPath(ctx context.Context, currentView *model.ItemConfig, dirPath string) (*model.ItemConfig, error) {
 	logging.LogDebugf("repo/localfs/GetViewConfigByPath('%s')", dirPath)
 	if dirPath != "/" {
 		dirPath = strings.TrimRight(dirPath, "/")
@@ -186,7 +189,7 @@ func (r localRepo) GetViewConfigByPath(currentView *model.ItemConfig, dirPath st
 	}
 }
 
-func (r localRepo) GetItemInfo(itemConfig *model.ItemConfig) ([]byte, error) {
+func (r localRepo) GetItemInfo(ctx context.Context, itemConfig *model.ItemConfig) ([]byte, error) {
 	logging.LogDebugf("repo/localfs/GetItemInfo(%v)", itemConfig)
 
 	fi, err := os.Lstat(itemConfig.Path)
@@ -241,6 +244,152 @@ func (t Tree) FileChanged(anotherTree *Tree) bool {
 	return anotherTree == nil || t.ModTime != anotherTree.ModTime
 }
 
+// Flatten returns all files and directories under this tree (without tree root)
+// mapped by their path from root.
+func (t Tree) Flatten() map[string]Tree {
+	result := make(map[string]Tree)
+	t.flattenTo(result)
+	return result
+}
+
+func (t Tree) flattenTo(result map[string]Tree) {
+	for _, child := range t.Children {
+		result[child.PathFromRoot] = child
+		if child.Dir {
+			child.flattenTo(result)
+		}
+	}
+}
+
+// Prune returns copy of the tree without entries which should be ignored
+// (ignored function gets path from root with "/" as a separator) and number of
+// ignored entries (children of ignored directory are not counted).
+func (t Tree) Prune(ignored func(pathFromRoot string, dir bool) bool) (Tree, int) {
+	if !t.Dir {
+		return t, 0
+	}
+
+	ignoredCount := 0
+	children := make([]Tree, 0, len(t.Children))
+	for _, child := range t.Children {
+		if ignored(filepath.ToSlash(child.PathFromRoot), child.Dir) {
+			ignoredCount++
+			continue
+		}
+		prunedChild, prunedCount := child.Prune(ignored)
+		ignoredCount += prunedCount
+		children = append(children, prunedChild)
+	}
+	t.Children = children
+
+	return t, ignoredCount
+}
+
+// TreeRemoval contains entry removed from the tree and entry it was renamed (or
+// moved) to if rename is detected.
+type TreeRemoval struct {
+	Removed   Tree
+	RenamedTo *Tree
+}
+
+// FindRemovals compares tree with older snapshot of the same tree and returns
+// removed entries (for removed directory only directory is returned, without
+// its children). Removed entry is considered renamed if new entry of the same
+// type and with the same modification time appears in the tree.
+func (t Tree) FindRemovals(treeOld *Tree) []TreeRemoval {
+	entries := t.Flatten()
+	entriesOld := treeOld.Flatten()
+
+	removedPaths := make([]string, 0)
+	for pathFromRoot := range entriesOld {
+		if _, ok := entries[pathFromRoot]; !ok {
+			removedPaths = append(removedPaths, pathFromRoot)
+		}
+	}
+	sort.Strings(removedPaths)
+
+	addedPaths := make([]string, 0)
+	for pathFromRoot := range entries {
+		if _, ok := entriesOld[pathFromRoot]; !ok {
+			addedPaths = append(addedPaths, pathFromRoot)
+		}
+	}
+	sort.Strings(addedPaths)
+
+	removedDirs := make(map[string]bool)
+	renamedTo := make(map[string]bool)
+	result := make([]TreeRemoval, 0)
+	for _, removedPath := range removedPaths {
+		if removedDirs[filepath.Dir(removedPath)] {
+			if entriesOld[removedPath].Dir {
+				removedDirs[removedPath] = true
+			}
+			continue
+		}
+		removed := entriesOld[removedPath]
+		if removed.Dir {
+			removedDirs[removedPath] = true
+		}
+		removal := TreeRemoval{Removed: removed}
+		for _, addedPath := range addedPaths {
+			added := entries[addedPath]
+			if !renamedTo[addedPath] && added.Dir == removed.Dir && added.ModTime.Equal(removed.ModTime) {
+				renamedTo[addedPath] = true
+				removal.RenamedTo = &added
+				break
+			}
+		}
+		result = append(result, removal)
+	}
+
+	return result
+}
+
+// SyncAction is an action required to synchronize file in bidirectional
+// sync mode.
+type SyncAction byte
+
+// Available bidirectional sync actions.
+const (
+	SyncNone     = SyncAction('-')
+	SyncUpload   = SyncAction('u')
+	SyncDownload = SyncAction('d')
+	SyncConflict = SyncAction('c')
+)
+
+// FileHash calculates hash of file content used to detect changes between syncs.
+func FileHash(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+// GetSyncAction decides which action is required to synchronize file from
+// hash of file saved during last sync and current hashes of local file and
+// DataPower file (empty hash means file doesn't exist). File deleted on one
+// side and unchanged on other side is left as it is.
+func GetSyncAction(syncedHash, localHash, dpHash string) SyncAction {
+	switch {
+	case localHash == dpHash:
+		return SyncNone
+	case localHash == "":
+		if syncedHash != "" && dpHash == syncedHash {
+			return SyncNone
+		}
+		return SyncDownload
+	case dpHash == "":
+		if syncedHash != "" && localHash == syncedHash {
+			return SyncNone
+		}
+		return SyncUpload
+	case localHash == syncedHash:
+		return SyncDownload
+	case dpHash == syncedHash:
+		return SyncUpload
+	default:
+		return SyncConflict
+	}
+}
+
 // LoadTree loads directory hierarchy information into Tree object.
 func LoadTree(pathFromRoot, filePath string) (Tree, error) {
 	tree := Tree{}
@@ -1,11 +1,13 @@
 package localfs
 
 import (
+	"context"
 	"fmt"
 	"github.com/croz-ltd/dpcmder/config"
 	"github.com/croz-ltd/dpcmder/model"
 	"github.com/croz-ltd/dpcmder/utils/assert"
 	"github.com/croz-ltd/dpcmder/utils/errs"
+	"strings"
 	"testing"
 	"time"
 )
@@ -21,7 +23,7 @@ func TestLocalRepoGetInitialItem(t *testing.T) {
 	initialDirName := "dir3"
 	config.LocalFolderPath = &initialDir
 
-	item, _ := Repo.GetInitialItem()
+	item, _ := Repo.GetInitialItem(context.Background())
 	want := model.Item{Config: &model.ItemConfig{
 		Type: model.ItemDirectory, Name: initialDirName, Path: initialDir,
 		Parent: &model.ItemConfig{
@@ -48,7 +50,7 @@ func TestLocalRepoGetViewConfigByPath(t *testing.T) {
 	}
 
 	for _, testCase := range testDataMatrix {
-		newView, err := Repo.GetViewConfigByPath(testCase.currentView, testCase.dirPath)
+		newView, err := Repo.GetViewConfigByPath(context.Background(), testCase.currentView, testCase.dirPath)
 		methodCall := fmt.Sprintf("GetViewConfigByPath(%v, '%s')", testCase.currentView, testCase.dirPath)
 
 		assert.DeepEqual(t, methodCall, newView, testCase.newView)
@@ -92,3 +94,109 @@ func TestTreeFileChanged(t *testing.T) {
 	assert.DeepEqual(t, "FileChanged()", tree1.FileChanged(&tree2), true)
 	assert.DeepEqual(t, "FileChanged()", tree1.FileChanged(&tree3), true)
 }
+
+func TestTreeFlatten(t *testing.T) {
+	tree := Tree{Dir: true, Name: "root", Children: []Tree{
+		Tree{Dir: false, Name: "file1", PathFromRoot: "file1"},
+		Tree{Dir: true, Name: "dir1", PathFromRoot: "dir1", Children: []Tree{
+			Tree{Dir: false, Name: "file2", PathFromRoot: "dir1/file2"},
+		}},
+	}}
+
+	flatTree := tree.Flatten()
+	assert.DeepEqual(t, "Flatten() len", len(flatTree), 3)
+	assert.DeepEqual(t, "Flatten() file1", flatTree["file1"].Name, "file1")
+	assert.DeepEqual(t, "Flatten() dir1", flatTree["dir1"].Dir, true)
+	assert.DeepEqual(t, "Flatten() dir1/file2", flatTree["dir1/file2"].Name, "file2")
+}
+
+func TestFileHash(t *testing.T) {
+	assert.DeepEqual(t, "FileHash()", FileHash([]byte("")),
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	assert.DeepEqual(t, "FileHash()", FileHash([]byte("abc")),
+		"ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+}
+
+func TestGetSyncAction(t *testing.T) {
+	testDataMatrix := []struct {
+		syncedHash string
+		localHash  string
+		dpHash     string
+		action     SyncAction
+	}{
+		{"", "", "", SyncNone},
+		{"a", "a", "a", SyncNone},
+		{"", "a", "a", SyncNone},
+		{"a", "b", "b", SyncNone},
+		{"", "a", "", SyncUpload},
+		{"", "", "a", SyncDownload},
+		{"a", "", "a", SyncNone},
+		{"a", "a", "", SyncNone},
+		{"a", "", "b", SyncDownload},
+		{"a", "b", "", SyncUpload},
+		{"a", "a", "b", SyncDownload},
+		{"a", "b", "a", SyncUpload},
+		{"a", "b", "c", SyncConflict},
+		{"", "b", "c", SyncConflict},
+	}
+
+	for _, testCase := range testDataMatrix {
+		methodCall := fmt.Sprintf("GetSyncAction('%s', '%s', '%s')",
+			testCase.syncedHash, testCase.localHash, testCase.dpHash)
+		assert.DeepEqual(t, methodCall,
+			GetSyncAction(testCase.syncedHash, testCase.localHash, testCase.dpHash), testCase.action)
+	}
+}
+
+func TestTreeFindRemovals(t *testing.T) {
+	time1 := time.Now()
+	time2 := time1.Add(time.Minute * 10)
+	time3 := time1.Add(time.Minute * 20)
+	treeOld := Tree{Dir: true, Name: "root", Children: []Tree{
+		Tree{Dir: false, Name: "file1", PathFromRoot: "file1", ModTime: time1},
+		Tree{Dir: false, Name: "file2", PathFromRoot: "file2", ModTime: time2},
+		Tree{Dir: true, Name: "dir1", PathFromRoot: "dir1", ModTime: time3, Children: []Tree{
+			Tree{Dir: false, Name: "file3", PathFromRoot: "dir1/file3", ModTime: time1},
+		}},
+	}}
+	tree := Tree{Dir: true, Name: "root", Children: []Tree{
+		Tree{Dir: false, Name: "file1", PathFromRoot: "file1", ModTime: time2},
+		Tree{Dir: false, Name: "file2-renamed", PathFromRoot: "file2-renamed", ModTime: time2},
+	}}
+
+	removals := tree.FindRemovals(&treeOld)
+	assert.DeepEqual(t, "FindRemovals() len", len(removals), 2)
+	assert.DeepEqual(t, "FindRemovals() [0]", removals[0].Removed.PathFromRoot, "dir1")
+	assert.DeepEqual(t, "FindRemovals() [0]", removals[0].RenamedTo, (*Tree)(nil))
+	assert.DeepEqual(t, "FindRemovals() [1]", removals[1].Removed.PathFromRoot, "file2")
+	assert.DeepEqual(t, "FindRemovals() [1]", removals[1].RenamedTo.PathFromRoot, "file2-renamed")
+
+	assert.DeepEqual(t, "FindRemovals() unchanged", len(treeOld.FindRemovals(&treeOld)), 0)
+}
+
+func TestTreePrune(t *testing.T) {
+	tree := Tree{Dir: true, Name: "root", Children: []Tree{
+		Tree{Dir: false, Name: "file1", PathFromRoot: "file1"},
+		Tree{Dir: false, Name: "file1.swp", PathFromRoot: "file1.swp"},
+		Tree{Dir: true, Name: ".git", PathFromRoot: ".git", Children: []Tree{
+			Tree{Dir: false, Name: "HEAD", PathFromRoot: ".git/HEAD"},
+		}},
+		Tree{Dir: true, Name: "dir1", PathFromRoot: "dir1", Children: []Tree{
+			Tree{Dir: false, Name: "file2", PathFromRoot: "dir1/file2"},
+			Tree{Dir: false, Name: "file2.swp", PathFromRoot: "dir1/file2.swp"},
+		}},
+	}}
+	ignored := func(pathFromRoot string, dir bool) bool {
+		return pathFromRoot == ".git" || strings.HasSuffix(pathFromRoot, ".swp")
+	}
+
+	prunedTree, ignoredCount := tree.Prune(ignored)
+	assert.DeepEqual(t, "Prune() ignored", ignoredCount, 3)
+	assert.DeepEqual(t, "Prune() tree", prunedTree, Tree{Dir: true, Name: "root", Children: []Tree{
+		Tree{Dir: false, Name: "file1", PathFromRoot: "file1"},
+		Tree{Dir: true, Name: "dir1", PathFromRoot: "dir1", Children: []Tree{
+			Tree{Dir: false, Name: "file2", PathFromRoot: "dir1/file2"},
+		}},
+	}})
+	assert.DeepEqual(t, "Prune() original tree", len(tree.Children), 4)
+}
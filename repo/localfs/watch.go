@@ -0,0 +1,161 @@
+package localfs
+
+import (
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"github.com/fsnotify/fsnotify"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// maxDebounceFactor limits how long (in multiples of debounce time) changes can
+// be delayed while files keep changing.
+const maxDebounceFactor = 10
+
+// Watch starts watching directory hierarchy for changes (new subdirectories are
+// watched as they appear). Ignored directories are not watched and changes of
+// ignored paths are not reported (ignored function gets path relative to
+// dirPath with "/" as a separator, like in Tree.Prune). Paths changed in a
+// burst are coalesced - they are sent to returned channel once no change
+// happened for debounceTime (or when changes were delayed for
+// maxDebounceFactor * debounceTime). Watching stops and returned channel is
+// closed when done channel is closed.
+func Watch(dirPath string, ignored func(pathFromRoot string, dir bool) bool,
+	debounceTime time.Duration, done <-chan struct{}) (<-chan []string, error) {
+	logging.LogDebugf("repo/localfs/Watch('%s', %v)", dirPath, debounceTime)
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := dirWatcher{watcher: watcher, rootPath: dirPath, ignored: ignored}
+	err = w.addRecursive(dirPath)
+	if err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	changes := make(chan []string)
+	go w.loop(debounceTime, done, changes)
+
+	return changes, nil
+}
+
+// dirWatcher watches directory hierarchy skipping ignored paths.
+type dirWatcher struct {
+	watcher  *fsnotify.Watcher
+	rootPath string
+	ignored  func(pathFromRoot string, dir bool) bool
+}
+
+// isIgnored checks if path should be ignored, if it is not known if path is
+// directory (removed path) both file and directory patterns are checked.
+func (w dirWatcher) isIgnored(path string, dir, dirKnown bool) bool {
+	if w.ignored == nil {
+		return false
+	}
+	pathFromRoot, err := filepath.Rel(w.rootPath, path)
+	if err != nil || pathFromRoot == "." {
+		return false
+	}
+	pathFromRoot = filepath.ToSlash(pathFromRoot)
+	if dirKnown {
+		return w.ignored(pathFromRoot, dir)
+	}
+	return w.ignored(pathFromRoot, false) || w.ignored(pathFromRoot, true)
+}
+
+// addRecursive adds directory and all its subdirectories (except ignored
+// ones) to watcher.
+func (w dirWatcher) addRecursive(dirPath string) error {
+	return filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if w.isIgnored(path, true, true) {
+				logging.LogTracef("repo/localfs/addRecursive(), '%s' ignored.", path)
+				return filepath.SkipDir
+			}
+			return w.watcher.Add(path)
+		}
+		return nil
+	})
+}
+
+// loop collects watcher events and sends coalesced changed paths.
+func (w dirWatcher) loop(debounceTime time.Duration,
+	done <-chan struct{}, changes chan<- []string) {
+	watcher := w.watcher
+	defer close(changes)
+	defer watcher.Close()
+
+	changedPaths := make(map[string]bool)
+	var firstChangeTime time.Time
+	var debounceTimer <-chan time.Time
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			logging.LogTracef("repo/localfs/loop(), event: %v", event)
+			fi, err := os.Lstat(event.Name)
+			if w.isIgnored(event.Name, err == nil && fi.IsDir(), err == nil) {
+				continue
+			}
+			if event.Op&fsnotify.Create == fsnotify.Create && err == nil && fi.IsDir() {
+				err = w.addRecursive(event.Name)
+				if err != nil {
+					logging.LogDebugf("repo/localfs/loop(), can't watch '%s': %v", event.Name, err)
+				}
+			}
+
+			if len(changedPaths) == 0 {
+				firstChangeTime = time.Now()
+			}
+			changedPaths[event.Name] = true
+			debounceTimer = time.After(
+				debounceDelay(debounceTime, time.Since(firstChangeTime)))
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logging.LogDebugf("repo/localfs/loop(), watcher err: %v", err)
+		case <-debounceTimer:
+			debounceTimer = nil
+			paths := make([]string, 0, len(changedPaths))
+			for path := range changedPaths {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			changedPaths = make(map[string]bool)
+
+			select {
+			case changes <- paths:
+			case <-done:
+				return
+			}
+		}
+	}
+}
+
+// debounceDelay returns how long to wait for more changes when changes are
+// already waiting for given time.
+func debounceDelay(debounceTime, waitingTime time.Duration) time.Duration {
+	maxWaitingTime := debounceTime * maxDebounceFactor
+	switch {
+	case waitingTime >= maxWaitingTime:
+		return 0
+	case waitingTime+debounceTime > maxWaitingTime:
+		return maxWaitingTime - waitingTime
+	default:
+		return debounceTime
+	}
+}
@@ -0,0 +1,92 @@
+package localfs
+
+import (
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebounceDelay(t *testing.T) {
+	debounceTime := 100 * time.Millisecond
+	assert.DeepEqual(t, "debounceDelay()", debounceDelay(debounceTime, 0), debounceTime)
+	assert.DeepEqual(t, "debounceDelay()", debounceDelay(debounceTime, 500*time.Millisecond), debounceTime)
+	assert.DeepEqual(t, "debounceDelay()", debounceDelay(debounceTime, 950*time.Millisecond), 50*time.Millisecond)
+	assert.DeepEqual(t, "debounceDelay()", debounceDelay(debounceTime, 2*time.Second), time.Duration(0))
+}
+
+func TestWatch(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "dpcmder-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	done := make(chan struct{})
+	defer close(done)
+	changes, err := Watch(dirPath, nil, 50*time.Millisecond, done)
+	assert.DeepEqual(t, "Watch() err", err, nil)
+
+	subDirPath := filepath.Join(dirPath, "dir1")
+	filePath := filepath.Join(dirPath, "file1")
+	ioutil.WriteFile(filePath, []byte("first"), os.ModePerm)
+	ioutil.WriteFile(filePath, []byte("second"), os.ModePerm)
+	os.Mkdir(subDirPath, os.ModePerm)
+
+	select {
+	case changedPaths := <-changes:
+		assert.DeepEqual(t, "Watch() changes", changedPaths, []string{subDirPath, filePath})
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch() changes not received.")
+	}
+
+	// Files in new subdirectories are watched too.
+	subFilePath := filepath.Join(subDirPath, "file2")
+	ioutil.WriteFile(subFilePath, []byte("third"), os.ModePerm)
+
+	select {
+	case changedPaths := <-changes:
+		assert.DeepEqual(t, "Watch() changes", changedPaths, []string{subFilePath})
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch() changes not received.")
+	}
+}
+
+func TestWatchIgnored(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "dpcmder-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+	ignoredDirPath := filepath.Join(dirPath, "node_modules")
+	os.Mkdir(ignoredDirPath, os.ModePerm)
+
+	ignored := func(pathFromRoot string, dir bool) bool {
+		return pathFromRoot == "node_modules" || pathFromRoot == "build" ||
+			strings.HasSuffix(pathFromRoot, ".tmp")
+	}
+	done := make(chan struct{})
+	defer close(done)
+	changes, err := Watch(dirPath, ignored, 50*time.Millisecond, done)
+	assert.DeepEqual(t, "Watch() err", err, nil)
+
+	// Ignored directories are not watched, new ignored directories neither.
+	buildDirPath := filepath.Join(dirPath, "build")
+	os.Mkdir(buildDirPath, os.ModePerm)
+	ioutil.WriteFile(filepath.Join(ignoredDirPath, "module.js"), []byte("ignored"), os.ModePerm)
+	ioutil.WriteFile(filepath.Join(dirPath, "file1.tmp"), []byte("ignored"), os.ModePerm)
+	time.Sleep(100 * time.Millisecond)
+	ioutil.WriteFile(filepath.Join(buildDirPath, "out.js"), []byte("ignored"), os.ModePerm)
+	filePath := filepath.Join(dirPath, "file1")
+	ioutil.WriteFile(filePath, []byte("first"), os.ModePerm)
+
+	select {
+	case changedPaths := <-changes:
+		assert.DeepEqual(t, "Watch() changes", changedPaths, []string{filePath})
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watch() changes not received.")
+	}
+}
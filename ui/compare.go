@@ -0,0 +1,270 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/repo/dp"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"sort"
+)
+
+// compareResults contains compare results of items (by name) which are not
+// the same on both sides.
+type compareResults [2]map[string]model.ItemCompareResult
+
+// comparedSameFiles contains paths of files (for each side) found to be the
+// same on both sides by last compare of panes so copy of directories marked
+// as different can skip them.
+var comparedSameFiles [2]map[string]bool
+
+// compareCurrent compares current DataPower object with the same object in
+// other domain or appliance (in object mode) or directories shown in panes.
+func compareCurrent(ctx context.Context, m *model.Model) error {
+	if m.CurrSide() == model.Left && m.CurrItem().Config.Type == model.ItemDpObject {
+		return compareObject(ctx, m)
+	}
+	return comparePanes(ctx, m)
+}
+
+// comparePanes compares directories shown in both panes (subdirectories are
+// compared recursively), marks items which exist only on one side or are
+// different and selects them so differences can be copied to the other side.
+func comparePanes(ctx context.Context, m *model.Model) error {
+	logging.LogDebug("ui/comparePanes()")
+	for _, side := range []model.Side{model.Left, model.Right} {
+		viewType := m.ViewConfig(side).Type
+		if viewType != model.ItemDirectory && viewType != model.ItemDpFilestore {
+			return errs.Errorf("Can't compare panes, only directories and filestores can be compared (%s is shown).",
+				viewType.UserFriendlyString())
+		}
+	}
+
+	repos[model.Left].InvalidateCache()
+	repos[model.Right].InvalidateCache()
+	showProgressDialog("Comparing panes...")
+	defer hideProgressDialog()
+	comparedSameFiles = [2]map[string]bool{make(map[string]bool), make(map[string]bool)}
+	results, err := compareDirs(ctx, m.ViewConfig(model.Left), m.ViewConfig(model.Right))
+	if err != nil {
+		return err
+	}
+
+	m.MarkCompared(model.Left, results[model.Left])
+	m.MarkCompared(model.Right, results[model.Right])
+	onlyLeft, onlyRight, different := 0, 0, 0
+	for _, result := range results[model.Left] {
+		switch result {
+		case model.CompareOnlyHere:
+			onlyLeft++
+		case model.CompareDifferent:
+			different++
+		}
+	}
+	for _, result := range results[model.Right] {
+		if result == model.CompareOnlyHere {
+			onlyRight++
+		}
+	}
+	if onlyLeft+onlyRight+different == 0 {
+		updateStatus("Panes compared, no differences found.")
+	} else {
+		updateStatusf("Panes compared, %d item(s) only left, %d item(s) only right, %d different item(s) (selected).",
+			onlyLeft, onlyRight, different)
+	}
+	return nil
+}
+
+// compareDirs compares items of left and right directory and returns compare
+// results of items which are not the same.
+func compareDirs(ctx context.Context, leftView, rightView *model.ItemConfig) (compareResults, error) {
+	logging.LogDebugf("ui/compareDirs(%v, %v)", leftView, rightView)
+	results := compareResults{make(map[string]model.ItemCompareResult), make(map[string]model.ItemCompareResult)}
+	leftItems, err := repos[model.Left].GetList(ctx, leftView)
+	if err != nil {
+		return results, err
+	}
+	rightItems, err := repos[model.Right].GetList(ctx, rightView)
+	if err != nil {
+		return results, err
+	}
+
+	rightItemsByName := make(map[string]model.Item)
+	for _, item := range rightItems {
+		if comparableItem(item) {
+			rightItemsByName[item.Name] = item
+		}
+	}
+	for _, leftItem := range leftItems {
+		if !comparableItem(leftItem) {
+			continue
+		}
+		rightItem, ok := rightItemsByName[leftItem.Name]
+		if !ok {
+			results[model.Left][leftItem.Name] = model.CompareOnlyHere
+			continue
+		}
+		delete(rightItemsByName, leftItem.Name)
+		same, err := compareItems(ctx, leftView, rightView, leftItem, rightItem)
+		if err != nil {
+			return results, err
+		}
+		if !same {
+			results[model.Left][leftItem.Name] = model.CompareDifferent
+			results[model.Right][rightItem.Name] = model.CompareDifferent
+		}
+	}
+	for name := range rightItemsByName {
+		results[model.Right][name] = model.CompareOnlyHere
+	}
+
+	return results, nil
+}
+
+// sameFilesForCopy returns paths of files found to be the same on both sides
+// by last compare of panes if items being copied are marked as different,
+// otherwise (panes not compared or reloaded since) it returns nil.
+func sameFilesForCopy(side model.Side, items []model.Item) map[string]bool {
+	for _, item := range items {
+		if item.Compared == model.CompareDifferent && item.Config.Type == model.ItemDirectory {
+			return comparedSameFiles[side]
+		}
+	}
+	return nil
+}
+
+// comparableItem returns true for items compared when panes are compared
+// (files and directories).
+func comparableItem(item model.Item) bool {
+	return item.Name != ".." &&
+		(item.Config.Type == model.ItemFile || item.Config.Type == model.ItemDirectory)
+}
+
+// compareItems returns true if left and right items with the same name are
+// the same - directories are compared recursively, files are compared by size
+// and if size is the same by content (paths of same files are saved to
+// comparedSameFiles).
+func compareItems(ctx context.Context, leftView, rightView *model.ItemConfig, leftItem, rightItem model.Item) (bool, error) {
+	updateProgressDialogMessagef("Comparing '%s'...", leftItem.Config.Path)
+	switch {
+	case leftItem.Config.Type != rightItem.Config.Type:
+		return false, nil
+	case leftItem.Config.Type == model.ItemDirectory:
+		results, err := compareDirs(ctx, leftItem.Config, rightItem.Config)
+		return len(results[model.Left]) == 0 && len(results[model.Right]) == 0, err
+	case leftItem.Size != rightItem.Size:
+		return false, nil
+	}
+
+	leftContent, err := repos[model.Left].GetFile(ctx, leftView, leftItem.Name)
+	if err != nil {
+		return false, err
+	}
+	rightContent, err := repos[model.Right].GetFile(ctx, rightView, rightItem.Name)
+	if err != nil {
+		return false, err
+	}
+	if !sameContent(leftContent, rightContent) {
+		return false, nil
+	}
+	comparedSameFiles[model.Left][repos[model.Left].GetFilePath(leftView.Path, leftItem.Name)] = true
+	comparedSameFiles[model.Right][repos[model.Right].GetFilePath(rightView.Path, rightItem.Name)] = true
+	return true, nil
+}
+
+// compareObject compares current DataPower object to the object with the same
+// class and name in other domain of the current or other DataPower appliance.
+// Both objects are fetched using separate connections and are normalized
+// (cleaned and pretty printed) by GetObject before comparing.
+func compareObject(ctx context.Context, m *model.Model) error {
+	objectItem := m.CurrItem()
+	objectConfig := objectItem.Config
+	logging.LogDebugf("ui/compareObject(%v)", objectItem)
+
+	applianceNames := make([]string, 0, len(config.Conf.DataPowerAppliances))
+	for applianceName := range config.Conf.DataPowerAppliances {
+		if applianceName != objectConfig.DpAppliance {
+			applianceNames = append(applianceNames, applianceName)
+		}
+	}
+	sort.Strings(applianceNames)
+	applianceNames = append([]string{objectConfig.DpAppliance}, applianceNames...)
+	dialogResult := selectFromList(fmt.Sprintf("Compare %s '%s' with object on DataPower appliance:",
+		objectConfig.Path, objectItem.Name), applianceNames, 0)
+	if !dialogResult.dialogSubmitted {
+		updateStatus("Compare of DataPower object canceled.")
+		return nil
+	}
+	targetApplianceName := applianceNames[dialogResult.selectionIdx]
+
+	targetRepo := dp.Repo.Clone()
+	if targetApplianceName != objectConfig.DpAppliance {
+		dpa, err := applianceConfig(targetApplianceName)
+		if err != nil {
+			return err
+		}
+		targetRepo = dp.NewRepo("CompareDataPower", model.DpObjectMode)
+		err = targetRepo.InitNetworkSettings(targetApplianceName, dpa)
+		if err != nil {
+			return err
+		}
+	}
+	if targetRepo.GetManagementInterface() != dp.Repo.GetManagementInterface() {
+		return errs.Errorf("Can't compare object fetched using %s management interface to object fetched using %s.",
+			dp.Repo.GetManagementInterface(), targetRepo.GetManagementInterface())
+	}
+
+	allDomainNames, err := targetRepo.GetDomainNames(ctx)
+	if err != nil {
+		return err
+	}
+	domainNames := make([]string, 0, len(allDomainNames))
+	domainIdx := 0
+	for _, domainName := range allDomainNames {
+		switch {
+		case targetApplianceName == objectConfig.DpAppliance && domainName == objectConfig.DpDomain:
+			continue
+		case domainName == objectConfig.DpDomain:
+			domainIdx = len(domainNames)
+		}
+		domainNames = append(domainNames, domainName)
+	}
+	if len(domainNames) == 0 {
+		return errs.Errorf("No other domains found on DataPower appliance '%s'.", targetApplianceName)
+	}
+	dialogResult = selectFromList(fmt.Sprintf("Compare %s '%s' with object in domain on '%s':",
+		objectConfig.Path, objectItem.Name, targetApplianceName), domainNames, domainIdx)
+	if !dialogResult.dialogSubmitted {
+		updateStatus("Compare of DataPower object canceled.")
+		return nil
+	}
+	targetDomainName := domainNames[dialogResult.selectionIdx]
+
+	showProgressDialog("Fetching DataPower objects...")
+	var targetObjectContent []byte
+	objectContent, err := dp.Repo.Clone().GetObject(ctx,
+		objectConfig.DpDomain, objectConfig.Path, objectItem.Name, false)
+	if err == nil {
+		targetObjectContent, err = targetRepo.GetObject(ctx,
+			targetDomainName, objectConfig.Path, objectItem.Name, false)
+	}
+	hideProgressDialog()
+	if err != nil {
+		return err
+	}
+	if targetObjectContent == nil {
+		return errs.Errorf("DataPower object %s '%s' not found in domain '%s' on '%s'.",
+			objectConfig.Path, objectItem.Name, targetDomainName, targetApplianceName)
+	}
+
+	objectSuffix := ".xml"
+	if dp.Repo.GetManagementInterface() == config.DpInterfaceRest {
+		objectSuffix = ".json"
+	}
+	return diffContents(ctx,
+		fmt.Sprintf("%s_%s_%s%s", objectConfig.DpAppliance, objectConfig.DpDomain, objectItem.Name, objectSuffix),
+		fmt.Sprintf("%s_%s_%s%s", targetApplianceName, targetDomainName, objectItem.Name, objectSuffix),
+		objectContent, targetObjectContent)
+}
@@ -0,0 +1,283 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/repo"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"time"
+)
+
+// copyConflictPolicy is user's decision how to resolve all following copy
+// conflicts (file or object which already exists at copy target).
+type copyConflictPolicy int
+
+// All copy conflict policies.
+const (
+	conflictAsk copyConflictPolicy = iota
+	conflictOverwriteAll
+	conflictSkipAll
+	conflictOverwriteNewer
+	conflictOverwriteDifferent
+)
+
+// copyConflictAction is action taken to resolve single copy conflict.
+type copyConflictAction int
+
+// All copy conflict actions.
+const (
+	conflictOverwrite copyConflictAction = iota
+	conflictOverwriteIfDifferent
+	conflictSkip
+	conflictRename
+)
+
+// Options shown in copy conflict dialog.
+const (
+	conflictOptionOverwrite = iota
+	conflictOptionOverwriteIfNewer
+	conflictOptionOverwriteIfDifferent
+	conflictOptionSkip
+	conflictOptionOverwriteAll
+	conflictOptionSkipAll
+	conflictOptionOverwriteNewer
+	conflictOptionOverwriteDifferent
+	conflictOptionRename
+	conflictOptionDiff
+	conflictOptionAbort
+)
+
+var conflictOptions = []string{
+	conflictOptionOverwrite:            "Overwrite",
+	conflictOptionOverwriteIfNewer:     "Overwrite only if newer",
+	conflictOptionOverwriteIfDifferent: "Overwrite only if content is different",
+	conflictOptionSkip:                 "Skip",
+	conflictOptionOverwriteAll:         "Overwrite all",
+	conflictOptionSkipAll:              "Skip all",
+	conflictOptionOverwriteNewer:       "Overwrite all only if newer",
+	conflictOptionOverwriteDifferent:   "Overwrite all only if content is different",
+	conflictOptionRename:               "Rename target...",
+	conflictOptionDiff:                 "Show diff",
+	conflictOptionAbort:                "Abort copy",
+}
+
+// backgroundConflictOptions are conflict policies user can choose from before
+// background copy is started (background job can't show conflict dialog).
+var backgroundConflictOptions = []struct {
+	name   string
+	policy copyConflictPolicy
+}{
+	{"Skip existing", conflictSkipAll},
+	{"Overwrite existing", conflictOverwriteAll},
+	{"Overwrite existing only if newer", conflictOverwriteNewer},
+	{"Overwrite existing only if content is different", conflictOverwriteDifferent},
+}
+
+// modifiedTimeLayout is layout of item modification time shown for files on
+// local filesystem and on DataPower.
+const modifiedTimeLayout = "2006-01-02 15:04:05"
+
+// errCopyAborted is returned when user aborts copy in conflict dialog.
+const errCopyAborted = errs.Error("Copy aborted.")
+
+// copyConflict contains information about file or object which already exists
+// at copy target. Contents are fetched only when needed (to show diff).
+type copyConflict struct {
+	kind          string
+	name          string
+	targetPath    string
+	source        model.Item
+	target        model.Item
+	sourceContent func(ctx context.Context) ([]byte, error)
+	targetContent func(ctx context.Context) ([]byte, error)
+}
+
+// resolveConflict decides what to do with file or object which already exists
+// at copy target - using policy user chose for all conflicts or asking user
+// with conflict dialog. For rename action new target name is returned as well.
+func (cs *copySession) resolveConflict(ctx context.Context, conflict copyConflict) (copyConflictAction, string, error) {
+	logging.LogDebugf("ui/resolveConflict('%s', '%s'), policy: %d",
+		conflict.name, conflict.targetPath, cs.conflictPolicy)
+	switch cs.conflictPolicy {
+	case conflictOverwriteAll:
+		return conflictOverwrite, "", nil
+	case conflictSkipAll:
+		return conflictSkip, "", nil
+	case conflictOverwriteNewer:
+		return overwriteIfNewer(conflict), "", nil
+	case conflictOverwriteDifferent:
+		return conflictOverwriteIfDifferent, "", nil
+	}
+	if cs.job != nil {
+		return conflictSkip, "", nil
+	}
+
+	return cs.askConflict(ctx, conflict)
+}
+
+// askConflict shows conflict dialog where user can overwrite (optionally only
+// if source is newer or different) or skip target (once or for all following
+// conflicts), rename target, show diff between
+// source and target or abort copy.
+func (cs *copySession) askConflict(ctx context.Context, conflict copyConflict) (copyConflictAction, string, error) {
+	// When progress dialog is shown we don't won't it to hide our conflict dialog.
+	progressDialogSession.waitUserInput = true
+	defer func() { progressDialogSession.waitUserInput = false }()
+
+	message := fmt.Sprintf("%s '%s' already exists at '%s' (source: %s, target: %s):",
+		conflict.kind, conflict.name, conflict.targetPath,
+		itemInfo(conflict.source), itemInfo(conflict.target))
+	selectionIdx := conflictOptionOverwrite
+	for {
+		dialogResult := selectFromList(message, conflictOptions, selectionIdx)
+		if dialogResult.dialogCanceled {
+			return conflictSkip, "", nil
+		}
+		selectionIdx = dialogResult.selectionIdx
+		logging.LogDebugf("ui/askConflict(), selected: '%s'", conflictOptions[selectionIdx])
+
+		switch selectionIdx {
+		case conflictOptionOverwrite:
+			return conflictOverwrite, "", nil
+		case conflictOptionOverwriteIfNewer:
+			return overwriteIfNewer(conflict), "", nil
+		case conflictOptionOverwriteIfDifferent:
+			return conflictOverwriteIfDifferent, "", nil
+		case conflictOptionSkip:
+			return conflictSkip, "", nil
+		case conflictOptionOverwriteAll:
+			cs.conflictPolicy = conflictOverwriteAll
+		case conflictOptionSkipAll:
+			cs.conflictPolicy = conflictSkipAll
+		case conflictOptionOverwriteNewer:
+			cs.conflictPolicy = conflictOverwriteNewer
+		case conflictOptionOverwriteDifferent:
+			cs.conflictPolicy = conflictOverwriteDifferent
+		case conflictOptionRename:
+			inputResult := askUserInput("New target name: ", conflict.name, false)
+			if inputResult.dialogSubmitted && inputResult.inputAnswer != "" &&
+				inputResult.inputAnswer != conflict.name {
+				return conflictRename, inputResult.inputAnswer, nil
+			}
+			continue
+		case conflictOptionDiff:
+			err := showConflictDiff(ctx, conflict)
+			if err != nil {
+				updateStatusf("Can't show diff of '%s': %v", conflict.name, err)
+			}
+			continue
+		case conflictOptionAbort:
+			return conflictSkip, "", errCopyAborted
+		}
+
+		return cs.resolveConflict(ctx, conflict)
+	}
+}
+
+// overwriteIfNewer returns action overwriting target only if source is newer,
+// if it is not known which one is newer target is overwritten only if content
+// is different.
+func overwriteIfNewer(conflict copyConflict) copyConflictAction {
+	newer, known := sourceNewer(conflict.source, conflict.target)
+	switch {
+	case !known:
+		return conflictOverwriteIfDifferent
+	case newer:
+		return conflictOverwrite
+	default:
+		return conflictSkip
+	}
+}
+
+// showConflictDiff saves source and target contents to temporary directory
+// and shows differences using diff program.
+func showConflictDiff(ctx context.Context, conflict copyConflict) error {
+	logging.LogDebugf("ui/showConflictDiff('%s')", conflict.name)
+	targetBytes, err := conflict.targetContent(ctx)
+	if err != nil {
+		return err
+	}
+	sourceBytes, err := conflict.sourceContent(ctx)
+	if err != nil {
+		return err
+	}
+
+	return diffContents(ctx, "target_"+conflict.name, "source_"+conflict.name, targetBytes, sourceBytes)
+}
+
+// targetItem finds item with given name in target directory. Target directory
+// listings are cached during copy session, if item can't be found empty item
+// (with unknown size and modification time) is returned.
+func (cs *copySession) targetItem(ctx context.Context, toRepo repo.Repo, toViewConfig *model.ItemConfig, name string) model.Item {
+	listKey := toRepo.String() + ":" + toViewConfig.Path
+	items, ok := cs.targetLists[listKey]
+	if !ok {
+		var err error
+		items, err = toRepo.GetList(ctx, toViewConfig)
+		if err != nil {
+			logging.LogDebugf("ui/targetItem(), can't list '%s': %v", toViewConfig.Path, err)
+		}
+		if cs.targetLists == nil {
+			cs.targetLists = make(map[string]model.ItemList)
+		}
+		cs.targetLists[listKey] = items
+	}
+	for _, item := range items {
+		if item.Name == name {
+			return item
+		}
+	}
+
+	return model.Item{Name: name}
+}
+
+// itemInfo returns size and modification time of item shown in conflict
+// dialog.
+func itemInfo(item model.Item) string {
+	size := "size unknown"
+	if item.Size != "" {
+		size = formatBytes(itemSize(item))
+	}
+	modified := "modified unknown"
+	if _, err := time.Parse(modifiedTimeLayout, item.Modified); err == nil {
+		modified = item.Modified
+	}
+	return size + ", " + modified
+}
+
+// sourceNewer checks if source item is modified after target item. Result is
+// known only if modification times of both items are known and are in the
+// same time zone - modification times don't contain time zone, local files
+// are shown in local time and DataPower files in DataPower appliance's time.
+func sourceNewer(source, target model.Item) (newer, known bool) {
+	if itemClock(source) != itemClock(target) {
+		return false, false
+	}
+	sourceModified, err := time.Parse(modifiedTimeLayout, source.Modified)
+	if err != nil {
+		return false, false
+	}
+	targetModified, err := time.Parse(modifiedTimeLayout, target.Modified)
+	if err != nil {
+		return false, false
+	}
+	return sourceModified.After(targetModified), true
+}
+
+// itemClock returns name of DataPower appliance item modification time is
+// taken from (empty for local files).
+func itemClock(item model.Item) string {
+	if item.Config == nil {
+		return ""
+	}
+	return item.Config.DpAppliance
+}
+
+// sameContent checks if both contents are the same (compared byte by byte,
+// both contents are already read to memory so hashing them would not help).
+func sameContent(a, b []byte) bool {
+	return bytes.Equal(a, b)
+}
@@ -0,0 +1,245 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/repo"
+	"github.com/croz-ltd/dpcmder/repo/dp"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// copyTransfer contains file transfer prepared by copy session (after
+// copy conflict is resolved) and executed by one of copy workers. File is
+// saved as toFileName, if onlyIfDifferent is set existing target file is
+// overwritten only if its content differs from source file content.
+type copyTransfer struct {
+	fromRepo        repo.Repo
+	toRepo          repo.Repo
+	fromViewConfig  *model.ItemConfig
+	toViewConfig    *model.ItemConfig
+	fileName        string
+	toFileName      string
+	size            int64
+	onlyIfDifferent bool
+}
+
+// copyProgress contains copy counters used to show progress (files, bytes and
+// ETA) and failures summary.
+type copyProgress struct {
+	mutex        sync.Mutex
+	started      time.Time
+	filesTotal   int
+	filesDone    int
+	filesSkipped int
+	bytesTotal   int64
+	bytesDone    int64
+	failures     []string
+}
+
+// copyWorkersCount returns number of workers transferring files in parallel.
+func copyWorkersCount() int {
+	if config.Conf.Copy.Workers < 1 {
+		return 1
+	}
+	return config.Conf.Copy.Workers
+}
+
+// itemSize returns size of file item (0 if size is unknown).
+func itemSize(item model.Item) int64 {
+	size, err := strconv.ParseInt(item.Size, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// addTransfer queues file transfer to copy workers (workers are started with
+// first transfer). Waits if all workers are busy.
+func (cs *copySession) addTransfer(ctx context.Context, transfer copyTransfer) {
+	logging.LogDebugf("ui/addTransfer('%s', %d)", transfer.fileName, transfer.size)
+	if cs.transfers == nil {
+		cs.startWorkers(ctx)
+	}
+	if transfer.toRepo.String() == dp.Repo.String() {
+		cs.uploadRepo = transfer.toRepo
+	}
+	cs.progress.mutex.Lock()
+	cs.progress.filesTotal++
+	cs.progress.bytesTotal += transfer.size
+	cs.progress.mutex.Unlock()
+	cs.progressf("%s", cs.progress.String())
+	cs.transfers <- transfer
+}
+
+// startWorkers starts configured number of copy workers.
+func (cs *copySession) startWorkers(ctx context.Context) {
+	workers := copyWorkersCount()
+	logging.LogDebugf("ui/startWorkers(), workers: %d", workers)
+	cs.transfers = make(chan copyTransfer, workers)
+	cs.progress.started = time.Now()
+	for idx := 0; idx < workers; idx++ {
+		cs.workersDone.Add(1)
+		go cs.runWorker(ctx)
+	}
+}
+
+// runWorker transfers files queued to copy session until all transfers are
+// queued (transfers are skipped when copy is canceled). DataPower repo can't
+// be used concurrently so each worker uses its own DataPower repo clone.
+func (cs *copySession) runWorker(ctx context.Context) {
+	defer cs.workersDone.Done()
+	var workerDpRepo repo.Repo
+	workerRepo := func(r repo.Repo) repo.Repo {
+		if r.String() != dp.Repo.String() {
+			return r
+		}
+		if workerDpRepo == nil {
+			workerDpRepo = cs.cloneDpRepo()
+		}
+		return workerDpRepo
+	}
+
+	for transfer := range cs.transfers {
+		if ctx.Err() != nil {
+			continue
+		}
+		copied, err := transferFile(ctx, workerRepo(transfer.fromRepo), workerRepo(transfer.toRepo), transfer)
+		cs.transferDone(ctx, transfer, copied, err)
+	}
+}
+
+// transferFile copies file from one repo to another, returns false if file
+// is not copied since target file has the same content.
+func transferFile(ctx context.Context, fromRepo, toRepo repo.Repo, transfer copyTransfer) (bool, error) {
+	fBytes, err := fromRepo.GetFile(ctx, transfer.fromViewConfig, transfer.fileName)
+	if err != nil {
+		return false, err
+	}
+	if transfer.onlyIfDifferent {
+		targetBytes, err := toRepo.GetFile(ctx, transfer.toViewConfig, transfer.toFileName)
+		if err != nil {
+			return false, err
+		}
+		if sameContent(fBytes, targetBytes) {
+			return false, nil
+		}
+	}
+	copySuccess, err := toRepo.UpdateFile(ctx, transfer.toViewConfig, transfer.toFileName, fBytes)
+	if err != nil {
+		return false, err
+	}
+	if !copySuccess {
+		return false, errs.Error("update not successful")
+	}
+	return true, nil
+}
+
+// transferDone updates copy progress after file transfer and shows file copy
+// status.
+func (cs *copySession) transferDone(ctx context.Context, transfer copyTransfer, copied bool, err error) {
+	logging.LogDebugf("ui/transferDone('%s', %t, %v)", transfer.fileName, copied, err)
+	switch {
+	case ctx.Err() != nil:
+		return
+	case err != nil:
+		cs.failedf("File '%s' not copied from '%s' to '%s': %v",
+			transfer.fileName, transfer.fromViewConfig.Path, transfer.toViewConfig.Path, err)
+	case !copied:
+		cs.statusf("File '%s' skipped, '%s' at '%s' has the same content.",
+			transfer.fileName, transfer.toFileName, transfer.toViewConfig.Path)
+	case transfer.toFileName != transfer.fileName:
+		cs.statusf("File '%s' copied from '%s' to '%s' as '%s'.",
+			transfer.fileName, transfer.fromViewConfig.Path, transfer.toViewConfig.Path, transfer.toFileName)
+	default:
+		cs.statusf("File '%s' copied from '%s' to '%s'.",
+			transfer.fileName, transfer.fromViewConfig.Path, transfer.toViewConfig.Path)
+	}
+
+	cs.progress.mutex.Lock()
+	cs.progress.filesDone++
+	if err == nil && !copied {
+		cs.progress.filesSkipped++
+	}
+	cs.progress.bytesDone += transfer.size
+	cs.progress.mutex.Unlock()
+	cs.progressf("%s", cs.progress.String())
+}
+
+// failedf saves copy failure shown in failures summary after copy is done.
+func (cs *copySession) failedf(format string, v ...interface{}) {
+	failure := fmt.Sprintf(format, v...)
+	logging.LogDebugf("ui/failedf() '%s'", failure)
+	cs.progress.mutex.Lock()
+	cs.progress.failures = append(cs.progress.failures, failure)
+	cs.progress.mutex.Unlock()
+}
+
+// finishTransfers waits until all queued file transfers are done and returns
+// error containing failures summary if any item failed to copy. Files uploaded
+// by workers are not in DataPower repo cache so cache is invalidated.
+func (cs *copySession) finishTransfers() error {
+	if cs.transfers != nil {
+		close(cs.transfers)
+		cs.workersDone.Wait()
+		cs.transfers = nil
+	}
+	if cs.uploadRepo != nil {
+		cs.uploadRepo.InvalidateCache()
+	}
+
+	cs.progress.mutex.Lock()
+	failures := cs.progress.failures
+	filesCopied := cs.progress.filesDone - cs.progress.filesSkipped - len(failures)
+	cs.progress.failures = nil
+	cs.progress.mutex.Unlock()
+	if len(failures) == 0 {
+		return nil
+	}
+
+	for _, failure := range failures {
+		cs.statusf("ERROR: %s", failure)
+	}
+	details := fmt.Sprintf("see status messages ('%s')", actionKeys("messages"))
+	if cs.job != nil {
+		details = "see job log"
+	}
+	return errs.Errorf("Copy finished with %d failure(s), %d file(s) copied - %s.",
+		len(failures), filesCopied, details)
+}
+
+// String returns copy progress - files and bytes transferred and estimated
+// time until all files queued so far are transferred.
+func (p *copyProgress) String() string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	eta := "-"
+	elapsed := time.Since(p.started)
+	switch {
+	case p.bytesDone > 0 && p.bytesTotal > 0:
+		eta = time.Duration(float64(elapsed) * float64(p.bytesTotal-p.bytesDone) / float64(p.bytesDone)).Round(time.Second).String()
+	case p.filesDone > 0:
+		eta = time.Duration(float64(elapsed) * float64(p.filesTotal-p.filesDone) / float64(p.filesDone)).Round(time.Second).String()
+	}
+	return fmt.Sprintf("Copying files: %d/%d, %s/%s, ETA %s",
+		p.filesDone, p.filesTotal, formatBytes(p.bytesDone), formatBytes(p.bytesTotal), eta)
+}
+
+// formatBytes formats byte count in human readable form.
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
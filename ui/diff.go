@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/extprogs"
+	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/repo/localfs"
+	"github.com/croz-ltd/dpcmder/ui/out"
+	"github.com/croz-ltd/dpcmder/utils/diff"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"github.com/gdamore/tcell"
+)
+
+// diffContextLines is number of unchanged lines shown around changed lines in
+// built-in diff viewer.
+const diffContextLines = 3
+
+// noDifferencesStatus is status shown when compared content is the same.
+const noDifferencesStatus = "No differences found."
+
+// diffPaths compares local files or directories in external diff if "Diff"
+// command is configured or in built-in diff viewer otherwise, returns true if
+// built-in diff didn't find any difference.
+func diffPaths(oldPath, newPath string) (bool, error) {
+	if config.Conf.Cmd.Diff != "" {
+		return false, extprogs.Diff(oldPath, newPath)
+	}
+	files, err := diff.Paths(oldPath, newPath, diffContextLines)
+	if err != nil {
+		return false, err
+	}
+	if len(files) == 0 {
+		return true, nil
+	}
+	showDiffView(fmt.Sprintf("%s - %s", oldPath, newPath), files)
+	return false, nil
+}
+
+// diffContents compares old and new content in external diff (using
+// temporary files with given names) if "Diff" command is configured or in
+// built-in diff viewer otherwise.
+func diffContents(ctx context.Context, oldName, newName string, oldContent, newContent []byte) error {
+	logging.LogDebugf("ui/diffContents('%s', '%s')", oldName, newName)
+	if config.Conf.Cmd.Diff != "" {
+		tmpDir := extprogs.CreateTempDir("dp")
+		tmpView := model.ItemConfig{Path: tmpDir}
+		_, err := localfs.Repo.UpdateFile(ctx, &tmpView, oldName, oldContent)
+		if err == nil {
+			_, err = localfs.Repo.UpdateFile(ctx, &tmpView, newName, newContent)
+		}
+		if err != nil {
+			extprogs.DeleteTempDir(tmpDir)
+			return err
+		}
+		return diffFilesWithCleanup(tmpDir,
+			localfs.Repo.GetFilePath(tmpDir, oldName), localfs.Repo.GetFilePath(tmpDir, newName))
+	}
+
+	file, changed := diff.Contents(oldName, newName, oldContent, newContent, diffContextLines)
+	if !changed {
+		updateStatus(noDifferencesStatus)
+		return nil
+	}
+	showDiffView(fmt.Sprintf("%s - %s", oldName, newName), []diff.File{file})
+	return nil
+}
+
+// showDiffView shows differences in built-in diff viewer until user closes it.
+func showDiffView(title string, files []diff.File) {
+	logging.LogDebugf("ui/showDiffView('%s', %d file(s))", title, len(files))
+	// When progress dialog is shown we don't won't it to hid our diff viewer.
+	progressDialogSession.waitUserInput = true
+	defer func() { progressDialogSession.waitUserInput = false }()
+
+	diffView := out.NewDiffView(title, files)
+	status := ""
+	for {
+		diffView.Status = status
+		if status == "" {
+			diffView.Status = diffViewHint()
+		}
+		out.DrawDiffView(diffView)
+
+		status = ""
+		switch event := pollEvent().(type) {
+		case *tcell.EventKey:
+			var quit bool
+			quit, status = processDiffViewInput(diffView, event)
+			if quit {
+				return
+			}
+		case *tcell.EventMouse:
+			diffView.Scroll(mouseWheel(event) * wheelScrollRows)
+		}
+	}
+}
+
+// processDiffViewInput processes user's input to built-in diff viewer,
+// returns true if diff viewer should be closed and status message which
+// should be shown.
+func processDiffViewInput(diffView *out.DiffView, keyEvent *tcell.EventKey) (bool, string) {
+	logging.LogDebugf("ui/processDiffViewInput(%#v)", keyEvent)
+	switch keyEvent.Rune() {
+	case 'q', 'Q':
+		return true, ""
+	case 's':
+		diffView.ToggleSideBySide()
+		return false, ""
+	case ' ':
+		diffView.ScrollPage(1)
+		return false, ""
+	}
+	if keyEvent.Key() == tcell.KeyEsc {
+		return true, ""
+	}
+
+	switch keyAction(keyEvent) {
+	case "quit", "diff", "help":
+		return true, ""
+	case "up":
+		diffView.Scroll(-1)
+	case "down", "enter":
+		diffView.Scroll(1)
+	case "pageUp":
+		diffView.ScrollPage(-1)
+	case "pageDown":
+		diffView.ScrollPage(1)
+	case "top":
+		diffView.ScrollTop()
+	case "bottom":
+		diffView.ScrollBottom()
+	case "scrollLeft":
+		diffView.ScrollHoriz(-pagerScrollColumns)
+	case "scrollRight":
+		diffView.ScrollHoriz(pagerScrollColumns)
+	case "searchNext":
+		if !diffView.NextHunk(false) {
+			return false, "No more changes below."
+		}
+	case "searchPrev":
+		if !diffView.NextHunk(true) {
+			return false, "No more changes above."
+		}
+	}
+
+	return false, ""
+}
+
+// diffViewHint returns keys hint shown in the status line of built-in diff
+// viewer.
+func diffViewHint() string {
+	return fmt.Sprintf("q - quit, %s/%s - next/previous change, s - side-by-side/unified view",
+		actionKeys("searchNext"), actionKeys("searchPrev"))
+}
@@ -1,14 +1,21 @@
 package ui
 
 import (
+	"github.com/croz-ltd/dpcmder/events"
 	"github.com/croz-ltd/dpcmder/ui/out"
 	"github.com/croz-ltd/dpcmder/utils/logging"
+	"github.com/gdamore/tcell"
+	"time"
 )
 
+// inputEvents contains user's input events read from screen.
+var inputEvents = make(chan tcell.Event, 16)
+
 // StartReadingKeys starts (blocking) reading user's input.
 func StartReadingKeys() {
 	logging.LogDebug("ui/in/Start()")
 
+	go readInputEvents()
 	inputEventLoop()
 }
 
@@ -19,7 +26,7 @@ func inputEventLoop() {
 loop:
 	for {
 		logging.LogTrace("ui/inputEventLoop(), waiting to read")
-		event := out.Screen.PollEvent()
+		event := pollEvent()
 
 		logging.LogTracef("ui/inputEventLoop(), event: %#v", event)
 		err := ProcessInputEvent(event)
@@ -29,3 +36,48 @@ loop:
 	}
 	logging.LogDebug("ui/inputEventLoop() stopping")
 }
+
+// readInputEvents reads user's input events from screen while actions are
+// processed, so Esc pressed while progress dialog is shown can cancel running
+// action. Mouse moves which don't change pressed buttons are dropped, other
+// events are sent to inputEvents channel.
+func readInputEvents() {
+	mouseButtons := tcell.ButtonNone
+	for {
+		event := out.PollEvent()
+		if event == nil {
+			// Screen is stopped (external program is running or dpcmder is ending).
+			time.Sleep(50 * time.Millisecond)
+			continue
+		}
+		if keyEvent, ok := event.(*tcell.EventKey); ok &&
+			keyEvent.Key() == tcell.KeyEscape && progressDialogShown() {
+			logging.LogDebug("ui/readInputEvents(), canceling current action.")
+			cancelCurrentAction()
+			continue
+		}
+		if mouseEvent, ok := event.(*tcell.EventMouse); ok {
+			buttons := mouseEvent.Buttons()
+			if buttons == mouseButtons && buttons&mouseWheelButtons == 0 {
+				continue
+			}
+			mouseButtons = buttons
+		}
+		inputEvents <- event
+	}
+}
+
+// pollEvent waits for the next user's input event or background job
+// notification (received as interrupt event with job event data). Job events
+// are also saved to be processed after current input event (see
+// processPendingJobEvents).
+func pollEvent() tcell.Event {
+	select {
+	case event := <-inputEvents:
+		return event
+	case jobEvent := <-events.JobEvents:
+		showJobEvent(jobEvent)
+		addPendingJobEvent(jobEvent)
+		return tcell.NewEventInterrupt(jobEvent)
+	}
+}
@@ -0,0 +1,330 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"github.com/croz-ltd/dpcmder/events"
+	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/ui/out"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"github.com/gdamore/tcell"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jobStatus is state of background job.
+type jobStatus int
+
+// All background job states.
+const (
+	jobRunning jobStatus = iota
+	jobFinished
+	jobFailed
+	jobCanceled
+)
+
+func (s jobStatus) String() string {
+	switch s {
+	case jobRunning:
+		return "running"
+	case jobFinished:
+		return "finished"
+	case jobFailed:
+		return "failed"
+	case jobCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// maxJobLogLines is maximum number of status messages saved to job log.
+const maxJobLogLines = 1000
+
+// jobsPanelMessage is message shown at the top of jobs panel.
+const jobsPanelMessage = "Background jobs (Enter - view output, x - cancel/remove job, Esc - close):"
+
+// job contains information about long running action (export, copy, policy
+// analysis) running in background while user keeps browsing.
+type job struct {
+	id         int
+	name       string
+	status     jobStatus
+	message    string
+	log        []string
+	started    time.Time
+	ended      time.Time
+	outputName string
+	output     []byte
+	cancel     context.CancelFunc
+}
+
+// jobs contains all background jobs started (until removed from jobs panel).
+var jobs []*job
+var lastJobID int
+var jobsMutex sync.Mutex
+
+// pendingJobEvents contains job events received while waiting for user's
+// input (in main loop, dialogs or viewers), they are processed after input
+// event is processed so views are refreshed even if event is received while
+// dialog is shown.
+var pendingJobEvents []events.JobEvent
+var pendingJobEventsMutex sync.Mutex
+
+// startJob starts given action as background job. Action gets context which
+// is canceled when user cancels job and job to report status and output to.
+func startJob(name string, action func(ctx context.Context, j *job) error) {
+	logging.LogDebugf("ui/startJob('%s')", name)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	jobsMutex.Lock()
+	lastJobID++
+	j := &job{id: lastJobID, name: name, status: jobRunning, started: time.Now(), cancel: cancel}
+	jobs = append(jobs, j)
+	jobsMutex.Unlock()
+
+	updateStatusf("Job %d '%s' started in background (press '%s' to show jobs).",
+		j.id, name, actionKeys("jobs"))
+	go func() {
+		defer cancel()
+		err := action(ctx, j)
+		j.end(ctx, err)
+	}()
+}
+
+// statusf sets job status message (saved to job log) and notifies UI about job
+// progress. Progress notification is skipped if UI didn't process previous
+// notifications yet.
+func (j *job) statusf(format string, v ...interface{}) {
+	message := fmt.Sprintf(format, v...)
+	logging.LogDebugf("ui/job.statusf() job %d: '%s'", j.id, message)
+	jobsMutex.Lock()
+	j.message = message
+	j.addLog(message)
+	jobsMutex.Unlock()
+
+	select {
+	case events.JobEvents <- events.JobEvent{Type: events.JobProgress,
+		JobID: j.id, JobName: j.name, Message: message}:
+	default:
+	}
+}
+
+// setOutput sets job output shown in viewer when user selects finished job.
+func (j *job) setOutput(outputName string, output []byte) {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+	j.outputName = outputName
+	j.output = output
+}
+
+// end sets final job status and notifies UI job is finished.
+func (j *job) end(ctx context.Context, err error) {
+	jobsMutex.Lock()
+	j.ended = time.Now()
+	jobEvent := events.JobEvent{JobID: j.id, JobName: j.name}
+	switch {
+	case ctx.Err() == context.Canceled:
+		j.status = jobCanceled
+		jobEvent.Type = events.JobCanceled
+		jobEvent.Message = fmt.Sprintf("Job %d '%s' canceled.", j.id, j.name)
+	case err != nil:
+		j.status = jobFailed
+		jobEvent.Type = events.JobFailed
+		jobEvent.Message = fmt.Sprintf("Job %d '%s' failed: %v", j.id, j.name, err)
+	default:
+		j.status = jobFinished
+		jobEvent.Type = events.JobFinished
+		jobEvent.Message = fmt.Sprintf("Job %d '%s' finished: %s", j.id, j.name, j.message)
+	}
+	j.addLog(jobEvent.Message)
+	jobsMutex.Unlock()
+
+	logging.LogDebugf("ui/job.end() %s", jobEvent.Message)
+	events.JobEvents <- jobEvent
+}
+
+// addLog adds message to job log (jobsMutex must be locked).
+func (j *job) addLog(message string) {
+	j.log = append(j.log, time.Now().Format("15:04:05")+" "+message)
+	if len(j.log) > maxJobLogLines {
+		j.log = j.log[len(j.log)-maxJobLogLines:]
+	}
+}
+
+// displayString returns job info shown in jobs panel (jobsMutex must be locked).
+func (j *job) displayString() string {
+	duration := time.Since(j.started)
+	if j.status != jobRunning {
+		duration = j.ended.Sub(j.started)
+	}
+	return fmt.Sprintf("%3d %-8s %8s  %s - %s",
+		j.id, j.status, duration.Round(time.Second), j.name, j.message)
+}
+
+// runningJobsCount returns number of background jobs still running.
+func runningJobsCount() int {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+	count := 0
+	for _, j := range jobs {
+		if j.status == jobRunning {
+			count++
+		}
+	}
+	return count
+}
+
+// jobsDisplayList returns list of jobs info shown in jobs panel.
+func jobsDisplayList() []string {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+	list := make([]string, len(jobs))
+	for idx, j := range jobs {
+		list[idx] = j.displayString()
+	}
+	return list
+}
+
+// showJobEvent shows status message when background job ends (progress
+// notifications are shown only in jobs panel).
+func showJobEvent(jobEvent events.JobEvent) {
+	logging.LogDebugf("ui/showJobEvent(%v)", jobEvent)
+	if jobEvent.Type != events.JobProgress {
+		updateStatus(jobEvent.Message)
+	}
+}
+
+// addPendingJobEvent saves job event to be processed after current input event.
+func addPendingJobEvent(jobEvent events.JobEvent) {
+	pendingJobEventsMutex.Lock()
+	defer pendingJobEventsMutex.Unlock()
+	pendingJobEvents = append(pendingJobEvents, jobEvent)
+}
+
+// processPendingJobEvents processes all job events received since last call.
+func processPendingJobEvents(ctx context.Context, m *model.Model) error {
+	pendingJobEventsMutex.Lock()
+	jobEvents := pendingJobEvents
+	pendingJobEvents = nil
+	pendingJobEventsMutex.Unlock()
+
+	var err error
+	for _, jobEvent := range jobEvents {
+		if jobErr := processJobEvent(ctx, m, jobEvent); jobErr != nil {
+			err = jobErr
+		}
+	}
+	return err
+}
+
+// processJobEvent refreshes local filesystem view when background job
+// finishes (export or copy could create new local files).
+func processJobEvent(ctx context.Context, m *model.Model, jobEvent events.JobEvent) error {
+	logging.LogDebugf("ui/processJobEvent(%v)", jobEvent)
+	if jobEvent.Type != events.JobFinished {
+		return nil
+	}
+	return showItem(ctx, model.Right, m.ViewConfig(model.Right), ".")
+}
+
+// showJobs shows jobs panel with running, finished and failed background jobs.
+// Panel is refreshed on each job notification, job output (or job log) can be
+// opened in viewer and running job can be canceled.
+func showJobs() error {
+	logging.LogDebug("ui/showJobs()")
+	if len(jobsDisplayList()) == 0 {
+		return errs.Error("No background jobs started.")
+	}
+
+	// When progress dialog is shown we don't won't it to hid our jobs panel.
+	progressDialogSession.waitUserInput = true
+	defer func() { progressDialogSession.waitUserInput = false }()
+
+	dialogSession := listSelectionDialogSessionInfo{message: jobsPanelMessage}
+	for {
+		dialogSession.list = jobsDisplayList()
+		if len(dialogSession.list) == 0 {
+			return nil
+		}
+		if dialogSession.selectionIdx >= len(dialogSession.list) {
+			dialogSession.selectionIdx = len(dialogSession.list) - 1
+		}
+		out.DrawEvent(events.UpdateViewEvent{
+			Type:                     events.UpdateViewShowListSelectionDialog,
+			ListSelectionMessage:     dialogSession.message,
+			ListSelectionList:        dialogSession.list,
+			ListSelectionSelectedIdx: dialogSession.selectionIdx})
+
+		switch event := pollEvent().(type) {
+		case *tcell.EventKey:
+			switch {
+			case event.Rune() == 'x', event.Key() == tcell.KeyDelete:
+				cancelOrRemoveJob(dialogSession.selectionIdx)
+			case keyAction(event) == "jobs":
+				dialogSession.dialogCanceled = true
+			default:
+				processSelectListDialogInput(&dialogSession, event)
+			}
+		case *tcell.EventMouse:
+			processSelectListDialogMouse(&dialogSession, event)
+		}
+
+		switch {
+		case dialogSession.dialogCanceled:
+			return nil
+		case dialogSession.dialogSubmitted:
+			return viewJobOutput(dialogSession.selectionIdx)
+		}
+	}
+}
+
+// cancelOrRemoveJob cancels running job or removes ended job from jobs panel.
+func cancelOrRemoveJob(jobIdx int) {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+	if jobIdx < 0 || jobIdx >= len(jobs) {
+		return
+	}
+	j := jobs[jobIdx]
+	logging.LogDebugf("ui/cancelOrRemoveJob(), job %d (%s)", j.id, j.status)
+	if j.status == jobRunning {
+		j.cancel()
+		return
+	}
+	jobs = append(jobs[:jobIdx], jobs[jobIdx+1:]...)
+}
+
+// viewJobOutput opens output of finished job in viewer, for jobs without
+// output (or not finished) job log is shown.
+func viewJobOutput(jobIdx int) error {
+	jobsMutex.Lock()
+	if jobIdx < 0 || jobIdx >= len(jobs) {
+		jobsMutex.Unlock()
+		return nil
+	}
+	j := jobs[jobIdx]
+	name := j.outputName
+	output := j.output
+	if j.status != jobFinished || output == nil {
+		name = fmt.Sprintf("Job_%d_log", j.id)
+		output = []byte(j.displayString() + "\n\n" + strings.Join(j.log, "\n") + "\n")
+	}
+	jobsMutex.Unlock()
+
+	return viewContent(name, output)
+}
+
+// confirmQuitWithJobs asks user to confirm quitting dpcmder if there are
+// background jobs still running.
+func confirmQuitWithJobs() bool {
+	runningCount := runningJobsCount()
+	if runningCount == 0 {
+		return true
+	}
+	dialogResult := askUserInput(
+		fmt.Sprintf("%d background job(s) still running, quit anyway (y/n): ", runningCount), "", false)
+	return dialogResult.dialogSubmitted && dialogResult.inputAnswer == "y"
+}
@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/ui/keys"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"github.com/gdamore/tcell"
+)
+
+// keyBindings contains actions bound to keys from "Keys" configuration.
+var keyBindings keys.Bindings
+
+// initKeyBindings prepares key bindings from "Keys" configuration and returns
+// error describing keys which can't be used.
+func initKeyBindings() error {
+	logging.LogDebug("ui/initKeyBindings()")
+	var err error
+	keyBindings, err = keys.NewBindings(config.Conf.Keys)
+	return err
+}
+
+// keyAction returns name of action bound to key pressed.
+func keyAction(keyEvent *tcell.EventKey) string {
+	return keyBindings.Action(keyEvent)
+}
+
+// actionKeys returns keys bound to action (as shown to user).
+func actionKeys(actionName string) string {
+	return keyBindings.ActionKeys(actionName)
+}
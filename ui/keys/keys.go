@@ -0,0 +1,158 @@
+// Package keys parses key names from "Keys" configuration and maps keys
+// pressed to actions bound to them.
+package keys
+
+import (
+	"fmt"
+	"github.com/croz-ltd/dpcmder/help"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"github.com/gdamore/tcell"
+	"strings"
+	"unicode/utf8"
+)
+
+// binding is key (character or special key with modifiers) which can be
+// bound to action.
+type binding struct {
+	key tcell.Key
+	r   rune
+	mod tcell.ModMask
+}
+
+// keyNames contains names of special keys which can be used in "Keys"
+// configuration (names are case insensitive).
+var keyNames = map[string]tcell.Key{
+	"arrowup": tcell.KeyUp, "up": tcell.KeyUp,
+	"arrowdown": tcell.KeyDown, "down": tcell.KeyDown,
+	"arrowleft": tcell.KeyLeft, "left": tcell.KeyLeft,
+	"arrowright": tcell.KeyRight, "right": tcell.KeyRight,
+	"pgup": tcell.KeyPgUp, "pageup": tcell.KeyPgUp,
+	"pgdown": tcell.KeyPgDn, "pgdn": tcell.KeyPgDn, "pagedown": tcell.KeyPgDn,
+	"home": tcell.KeyHome, "end": tcell.KeyEnd,
+	"insert": tcell.KeyInsert, "ins": tcell.KeyInsert,
+	"del": tcell.KeyDelete, "delete": tcell.KeyDelete,
+	"return": tcell.KeyEnter, "enter": tcell.KeyEnter,
+	"tab": tcell.KeyTab, "backspace": tcell.KeyBackspace2,
+	"f1": tcell.KeyF1, "f2": tcell.KeyF2, "f3": tcell.KeyF3, "f4": tcell.KeyF4,
+	"f5": tcell.KeyF5, "f6": tcell.KeyF6, "f7": tcell.KeyF7, "f8": tcell.KeyF8,
+	"f9": tcell.KeyF9, "f10": tcell.KeyF10, "f11": tcell.KeyF11, "f12": tcell.KeyF12,
+}
+
+// keyModifiers contains modifiers which can prefix key names in "Keys"
+// configuration (like "Shift+ArrowUp").
+var keyModifiers = map[string]tcell.ModMask{
+	"shift": tcell.ModShift,
+	"ctrl":  tcell.ModCtrl,
+	"alt":   tcell.ModAlt,
+}
+
+// Bindings contains actions bound to keys and key names (as configured)
+// accepted for each action.
+type Bindings struct {
+	actions map[binding]string
+	Keys    map[string][]string
+}
+
+// NewBindings prepares key bindings from "Keys" configuration - keys which
+// can't be parsed or are already bound to other action are skipped and
+// described in returned error.
+func NewBindings(keys map[string][]string) (Bindings, error) {
+	bindings := Bindings{actions: make(map[binding]string), Keys: make(map[string][]string)}
+	problems := make([]string, 0)
+	for _, action := range help.Actions {
+		for _, keyName := range keys[action.Name] {
+			b, err := parseKey(keyName)
+			if err != nil {
+				problems = append(problems, err.Error())
+				continue
+			}
+			if boundAction, ok := bindings.actions[b]; ok {
+				problems = append(problems,
+					fmt.Sprintf("key '%s' of '%s' already bound to '%s'", keyName, action.Name, boundAction))
+				continue
+			}
+			bindings.actions[b] = action.Name
+			bindings.Keys[action.Name] = append(bindings.Keys[action.Name], keyName)
+		}
+	}
+	for actionName := range keys {
+		if _, ok := help.FindAction(actionName); !ok {
+			problems = append(problems, fmt.Sprintf("unknown action '%s'", actionName))
+		}
+	}
+
+	if len(problems) != 0 {
+		return bindings, errs.Errorf("Keys configuration: %s.", strings.Join(problems, ", "))
+	}
+	return bindings, nil
+}
+
+// parseKey parses key name from "Keys" configuration.
+func parseKey(keyName string) (binding, error) {
+	b := binding{}
+	name := keyName
+	for {
+		plusIdx := strings.Index(name, "+")
+		if plusIdx < 1 || plusIdx == len(name)-1 {
+			break
+		}
+		mod, ok := keyModifiers[strings.ToLower(name[:plusIdx])]
+		if !ok {
+			return b, errs.Errorf("unknown key modifier in '%s'", keyName)
+		}
+		b.mod |= mod
+		name = name[plusIdx+1:]
+	}
+
+	switch {
+	case strings.EqualFold(name, "space"):
+		b.key, b.r = tcell.KeyRune, ' '
+	case utf8.RuneCountInString(name) == 1 && b.mod&tcell.ModCtrl != 0:
+		r := []rune(strings.ToUpper(name))[0]
+		if r < 'A' || r > 'Z' {
+			return b, errs.Errorf("unknown key '%s'", keyName)
+		}
+		b.key = tcell.KeyCtrlA + tcell.Key(r-'A')
+	case utf8.RuneCountInString(name) == 1:
+		b.key, b.r = tcell.KeyRune, []rune(name)[0]
+		// Shift is already part of character entered.
+		b.mod &^= tcell.ModShift
+	default:
+		key, ok := keyNames[strings.ToLower(name)]
+		if !ok {
+			return b, errs.Errorf("unknown key '%s'", keyName)
+		}
+		b.key = key
+	}
+
+	return b, nil
+}
+
+// Action returns name of action bound to key pressed.
+func (bindings Bindings) Action(keyEvent *tcell.EventKey) string {
+	b := binding{key: keyEvent.Key(), mod: keyEvent.Modifiers()}
+	if b.key == tcell.KeyRune {
+		b.r = keyEvent.Rune()
+		b.mod &^= tcell.ModShift
+		if action, ok := bindings.actions[b]; ok {
+			return action
+		}
+		// Characters not bound with modifiers pressed (like "Alt+x") are bound
+		// regardless of modifiers, so Alt+i runs the same action as i.
+		b.mod = tcell.ModNone
+	}
+	if b.key == tcell.KeyBackspace {
+		b.key = tcell.KeyBackspace2
+	}
+
+	return bindings.actions[b]
+}
+
+// ActionKeys returns keys bound to action (as shown to user).
+func (bindings Bindings) ActionKeys(actionName string) string {
+	keys := bindings.Keys[actionName]
+	if len(keys) == 0 {
+		return "(not bound)"
+	}
+	return strings.Join(keys, "/")
+}
@@ -0,0 +1,109 @@
+package keys
+
+import (
+	"github.com/croz-ltd/dpcmder/help"
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"github.com/gdamore/tcell"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	testDataMatrix := []struct {
+		keyName string
+		binding binding
+		err     string
+	}{
+		{"a", binding{key: tcell.KeyRune, r: 'a'}, ""},
+		{"A", binding{key: tcell.KeyRune, r: 'A'}, ""},
+		{"=", binding{key: tcell.KeyRune, r: '='}, ""},
+		{"+", binding{key: tcell.KeyRune, r: '+'}, ""},
+		{"Shift+a", binding{key: tcell.KeyRune, r: 'a'}, ""},
+		{"Alt+x", binding{key: tcell.KeyRune, r: 'x', mod: tcell.ModAlt}, ""},
+		{"Space", binding{key: tcell.KeyRune, r: ' '}, ""},
+		{"space", binding{key: tcell.KeyRune, r: ' '}, ""},
+		{"Ctrl+Space", binding{key: tcell.KeyRune, r: ' ', mod: tcell.ModCtrl}, ""},
+		{"Ctrl+C", binding{key: tcell.KeyCtrlC, mod: tcell.ModCtrl}, ""},
+		{"ctrl+c", binding{key: tcell.KeyCtrlC, mod: tcell.ModCtrl}, ""},
+		{"Ctrl+Alt+z", binding{key: tcell.KeyCtrlZ, mod: tcell.ModCtrl | tcell.ModAlt}, ""},
+		{"Ctrl+1", binding{}, "unknown key 'Ctrl+1'"},
+		{"ArrowUp", binding{key: tcell.KeyUp}, ""},
+		{"Shift+ArrowUp", binding{key: tcell.KeyUp, mod: tcell.ModShift}, ""},
+		{"SHIFT+pgdn", binding{key: tcell.KeyPgDn, mod: tcell.ModShift}, ""},
+		{"Backspace", binding{key: tcell.KeyBackspace2}, ""},
+		{"F12", binding{key: tcell.KeyF12}, ""},
+		{"Hyper+a", binding{}, "unknown key modifier in 'Hyper+a'"},
+		{"F13", binding{}, "unknown key 'F13'"},
+		{"", binding{}, "unknown key ''"},
+	}
+	for _, testCase := range testDataMatrix {
+		b, err := parseKey(testCase.keyName)
+		if testCase.err != "" {
+			assert.Equals(t, "parseKey('"+testCase.keyName+"') error", err.Error(), testCase.err)
+			continue
+		}
+		assert.Equals(t, "parseKey('"+testCase.keyName+"') error", err, nil)
+		assert.Equals(t, "parseKey('"+testCase.keyName+"')", b, testCase.binding)
+	}
+}
+
+func TestNewBindings(t *testing.T) {
+	bindings, err := NewBindings(help.DefaultKeys())
+	assert.Equals(t, "NewBindings() default keys error", err, nil)
+	assert.DeepEqual(t, "NewBindings() default keys", bindings.Keys, help.DefaultKeys())
+
+	bindings, err = NewBindings(map[string][]string{
+		"up":      {"i", "Foo"},
+		"down":    {"I", "i", "k"},
+		"top":     {},
+		"unknown": {"x"},
+	})
+	assert.Equals(t, "NewBindings() error", err.Error(), "Keys configuration: unknown key 'Foo', "+
+		"key 'i' of 'down' already bound to 'up', unknown action 'unknown'.")
+	assert.DeepEqual(t, "NewBindings() accepted keys", bindings.Keys,
+		map[string][]string{"up": {"i"}, "down": {"I", "k"}})
+	assert.Equals(t, "ActionKeys('up')", bindings.ActionKeys("up"), "i")
+	assert.Equals(t, "ActionKeys('down')", bindings.ActionKeys("down"), "I/k")
+	assert.Equals(t, "ActionKeys('top')", bindings.ActionKeys("top"), "(not bound)")
+}
+
+func TestAction(t *testing.T) {
+	bindings, err := NewBindings(map[string][]string{
+		"up":         {"ArrowUp", "i"},
+		"selectUp":   {"Shift+ArrowUp", "I"},
+		"select":     {"Space"},
+		"delete":     {"Backspace", "x"},
+		"diff":       {"Alt+x"},
+		"quit":       {"q", "Ctrl+C"},
+		"searchNext": {"Ctrl+Alt+n"},
+	})
+	assert.Equals(t, "NewBindings() error", err, nil)
+
+	testDataMatrix := []struct {
+		name   string
+		event  *tcell.EventKey
+		action string
+	}{
+		{"rune", tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModNone), "up"},
+		{"shifted rune", tcell.NewEventKey(tcell.KeyRune, 'I', tcell.ModShift), "selectUp"},
+		{"shifted rune without modifier", tcell.NewEventKey(tcell.KeyRune, 'I', tcell.ModNone), "selectUp"},
+		{"special key", tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone), "up"},
+		{"shift special key", tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModShift), "selectUp"},
+		{"ctrl special key", tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModCtrl), ""},
+		{"space", tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone), "select"},
+		{"ctrl letter", tcell.NewEventKey(tcell.KeyRune, 3, tcell.ModNone), "quit"},
+		{"ctrl letter key", tcell.NewEventKey(tcell.KeyCtrlC, 3, tcell.ModCtrl), "quit"},
+		{"ctrl alt letter", tcell.NewEventKey(tcell.KeyCtrlN, 14, tcell.ModCtrl|tcell.ModAlt), "searchNext"},
+		{"ctrl letter not bound", tcell.NewEventKey(tcell.KeyCtrlN, 14, tcell.ModCtrl), ""},
+		{"alt rune", tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModAlt), "diff"},
+		{"rune with other modifier", tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModAlt), "quit"},
+		{"shifted rune with other modifier", tcell.NewEventKey(tcell.KeyRune, 'I', tcell.ModAlt|tcell.ModShift), "selectUp"},
+		{"rune with ctrl modifier", tcell.NewEventKey(tcell.KeyRune, 'i', tcell.ModCtrl), "up"},
+		{"backspace", tcell.NewEventKey(tcell.KeyBackspace, 0, tcell.ModNone), "delete"},
+		{"backspace2", tcell.NewEventKey(tcell.KeyBackspace2, 0, tcell.ModNone), "delete"},
+		{"unbound rune", tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone), ""},
+		{"unbound key", tcell.NewEventKey(tcell.KeyF1, 0, tcell.ModNone), ""},
+	}
+	for _, testCase := range testDataMatrix {
+		assert.Equals(t, "Action() "+testCase.name, bindings.Action(testCase.event), testCase.action)
+	}
+}
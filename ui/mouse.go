@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"context"
+	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/ui/out"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"github.com/gdamore/tcell"
+	"time"
+	"unicode/utf8"
+)
+
+// doubleClickInterval is maximum time between two clicks at the same position
+// which are handled as a double-click.
+const doubleClickInterval = 500 * time.Millisecond
+
+// wheelScrollRows is number of item rows scrolled for each mouse wheel move.
+const wheelScrollRows = 3
+
+// mouseWheelButtons contains all mouse wheel "buttons".
+const mouseWheelButtons = tcell.WheelUp | tcell.WheelDown | tcell.WheelLeft | tcell.WheelRight
+
+// mouseState contains position and time of the last click (used to recognize
+// double-click).
+var mouseState struct {
+	clickX    int
+	clickY    int
+	clickTime time.Time
+}
+
+// mouseClick returns true if left mouse button is pressed in the mouse event
+// and true if the click is a double-click. Mouse events which don't change
+// pressed buttons are dropped by readInputEvents so each left button press
+// is received only once.
+func mouseClick(event *tcell.EventMouse) (click, doubleClick bool) {
+	if event.Buttons()&tcell.Button1 == 0 {
+		return false, false
+	}
+
+	x, y := event.Position()
+	doubleClick = x == mouseState.clickX && y == mouseState.clickY &&
+		event.When().Sub(mouseState.clickTime) < doubleClickInterval
+	if doubleClick {
+		// Next click starts new double-click.
+		mouseState.clickTime = time.Time{}
+	} else {
+		mouseState.clickX, mouseState.clickY, mouseState.clickTime = x, y, event.When()
+	}
+
+	return true, doubleClick
+}
+
+// mouseWheel returns -1 if mouse wheel is moved up, 1 if mouse wheel is moved
+// down and 0 if mouse event is not a wheel move.
+func mouseWheel(event *tcell.EventMouse) int {
+	switch {
+	case event.Buttons()&tcell.WheelUp != 0:
+		return -1
+	case event.Buttons()&tcell.WheelDown != 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// processMouseEvent processes mouse event in the main view: click moves cursor
+// (and changes current side), double-click enters directory or views file,
+// Ctrl-click toggles selection of item, Shift-click selects items from the
+// current one and the wheel scrolls items.
+func processMouseEvent(ctx context.Context, m *model.Model, event *tcell.EventMouse) error {
+	x, y := event.Position()
+	logging.LogDebugf("ui/processMouseEvent(), x: %d, y: %d, buttons: %v, modifiers: %v",
+		x, y, event.Buttons(), event.Modifiers())
+	side, rowIdx := out.PaneAt(x, y)
+
+	if wheel := mouseWheel(event); wheel != 0 {
+		m.ScrollView(side, wheel*wheelScrollRows)
+		return nil
+	}
+
+	click, doubleClick := mouseClick(event)
+	if !click {
+		return nil
+	}
+	m.SetCurrSide(side)
+	switch {
+	case rowIdx < 0 || rowIdx >= m.GetVisibleItemCount(side):
+		// Click outside of items only changes current side.
+	case event.Modifiers()&tcell.ModShift != 0:
+		m.SelToRow(rowIdx)
+		m.NavToRow(side, rowIdx)
+	case event.Modifiers()&tcell.ModCtrl != 0:
+		m.NavToRow(side, rowIdx)
+		m.ToggleCurrItem()
+	default:
+		m.NavToRow(side, rowIdx)
+		if doubleClick {
+			return openCurrentItem(ctx, m)
+		}
+	}
+
+	return nil
+}
+
+// openCurrentItem views current file, DataPower object or status and enters
+// any other item (directory, domain, filestore, ...).
+func openCurrentItem(ctx context.Context, m *model.Model) error {
+	switch m.CurrItem().Config.Type {
+	case model.ItemFile, model.ItemDpObject, model.ItemDpStatus:
+		return viewCurrent(ctx, m)
+	default:
+		return enterCurrentDirectory(ctx)
+	}
+}
+
+// processInputDialogMouse processes mouse input to input dialog: click on
+// answer moves cursor, double-click submits answer and click outside of the
+// dialog cancels it.
+func processInputDialogMouse(dialogSession *userDialogInputSessionInfo, event *tcell.EventMouse) {
+	logging.LogDebugf("ui/processInputDialogMouse(): '%s'", dialogSession)
+	click, doubleClick := mouseClick(event)
+	if !click {
+		return
+	}
+
+	answerIdx, inDialog := out.QuestionDialogAnswerIdxAt(event.Position())
+	switch {
+	case !inDialog:
+		dialogSession.dialogCanceled = true
+	case doubleClick:
+		dialogSession.dialogSubmitted = true
+	case answerIdx >= 0:
+		answerLen := utf8.RuneCountInString(dialogSession.inputAnswer)
+		if answerIdx > answerLen {
+			answerIdx = answerLen
+		}
+		dialogSession.inputAnswerCursorIdx = answerIdx
+	}
+}
+
+// processSelectListDialogMouse processes mouse input to list selection dialog:
+// click selects list item, double-click accepts selected item, wheel moves
+// selection and click outside of the dialog cancels it.
+func processSelectListDialogMouse(dialogSession *listSelectionDialogSessionInfo, event *tcell.EventMouse) {
+	logging.LogDebugf("ui/processSelectListDialogMouse(): '%s'", dialogSession)
+	if wheel := mouseWheel(event); wheel != 0 {
+		selectionIdx := dialogSession.selectionIdx + wheel
+		if selectionIdx >= 0 && selectionIdx < len(dialogSession.list) {
+			dialogSession.selectionIdx = selectionIdx
+		}
+		return
+	}
+
+	click, doubleClick := mouseClick(event)
+	if !click {
+		return
+	}
+
+	listIdx, inDialog := out.ListSelectionDialogIdxAt(event.Position())
+	switch {
+	case !inDialog:
+		dialogSession.dialogCanceled = true
+	case listIdx >= 0 && listIdx < len(dialogSession.list):
+		dialogSession.dialogSubmitted = doubleClick && listIdx == dialogSession.selectionIdx
+		dialogSession.selectionIdx = listIdx
+	}
+}
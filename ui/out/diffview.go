@@ -0,0 +1,396 @@
+package out
+
+import (
+	"fmt"
+	"github.com/croz-ltd/dpcmder/utils/diff"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"github.com/gdamore/tcell"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// diffSeparator is character separating old and new content in side-by-side
+// diff view.
+const diffSeparator = '│'
+
+// DiffView contains differences shown in built-in diff viewer and state of
+// the viewer (scroll position, unified or side-by-side view).
+type DiffView struct {
+	// Status is message shown in the status line of the diff viewer.
+	Status      string
+	title       string
+	files       []diff.File
+	rows        []diffRow
+	hunkCount   int
+	sideBySide  bool
+	lineNoWidth int
+	width       int
+	height      int
+	topRow      int
+	horizScroll int
+}
+
+// diffRow is a single screen row of diff viewer - file or hunk header or
+// content line(s). In unified view only old cell is used for content lines.
+type diffRow struct {
+	header  string
+	elem    string
+	hunkIdx int
+	old     diffCell
+	new     diffCell
+}
+
+// diffCell is a content line shown in diff viewer with its line number(s).
+type diffCell struct {
+	oldNo int
+	newNo int
+	text  string
+	op    diff.Op
+}
+
+// NewDiffView prepares differences found in files for showing in built-in
+// diff viewer (in unified view).
+func NewDiffView(title string, files []diff.File) *DiffView {
+	logging.LogDebugf("ui/out/NewDiffView('%s', %d file(s))", title, len(files))
+	dv := &DiffView{title: title, files: files}
+	maxLineNo := 0
+	for _, file := range files {
+		for _, hunk := range file.Hunks {
+			if hunk.OldStart+hunk.OldLines > maxLineNo {
+				maxLineNo = hunk.OldStart + hunk.OldLines
+			}
+			if hunk.NewStart+hunk.NewLines > maxLineNo {
+				maxLineNo = hunk.NewStart + hunk.NewLines
+			}
+		}
+	}
+	dv.lineNoWidth = len(strconv.Itoa(maxLineNo))
+	dv.layout()
+	return dv
+}
+
+// layout prepares rows shown for current (unified or side-by-side) view
+// keeping the hunk shown at the top of the screen. Files without hunks
+// (binary or empty files) are navigated as a single hunk.
+func (dv *DiffView) layout() {
+	topHunk := 0
+	if dv.topRow < len(dv.rows) {
+		topHunk = dv.rows[dv.topRow].hunkIdx
+	}
+
+	dv.rows = make([]diffRow, 0)
+	hunkIdx := 0
+	for _, file := range dv.files {
+		dv.rows = append(dv.rows, fileHeaderRows(file, hunkIdx)...)
+		if len(file.Hunks) == 0 {
+			if file.Status == diff.Binary {
+				dv.rows = append(dv.rows, diffRow{header: "Binary files differ", elem: elemDiffHunk, hunkIdx: hunkIdx})
+			}
+			hunkIdx++
+		}
+		for _, hunk := range file.Hunks {
+			dv.rows = append(dv.rows, diffRow{header: hunk.Header(), elem: elemDiffHunk, hunkIdx: hunkIdx})
+			if dv.sideBySide {
+				dv.rows = append(dv.rows, sideBySideRows(hunk, hunkIdx)...)
+			} else {
+				dv.rows = append(dv.rows, unifiedRows(hunk, hunkIdx)...)
+			}
+			hunkIdx++
+		}
+	}
+	dv.hunkCount = hunkIdx
+
+	dv.topRow = dv.hunkRow(topHunk)
+	dv.Scroll(0)
+}
+
+// hunkRow returns index of the first row of hunk with given index.
+func (dv *DiffView) hunkRow(hunkIdx int) int {
+	for rowIdx, row := range dv.rows {
+		if row.hunkIdx >= hunkIdx {
+			return rowIdx
+		}
+	}
+	return 0
+}
+
+// fileHeaderRows returns header rows of changed file.
+func fileHeaderRows(file diff.File, hunkIdx int) []diffRow {
+	switch file.Status {
+	case diff.Added:
+		return []diffRow{{header: "+++ " + file.NewPath + " (added)", elem: elemDiffHeader, hunkIdx: hunkIdx}}
+	case diff.Removed:
+		return []diffRow{{header: "--- " + file.OldPath + " (removed)", elem: elemDiffHeader, hunkIdx: hunkIdx}}
+	default:
+		return []diffRow{
+			{header: "--- " + file.OldPath, elem: elemDiffHeader, hunkIdx: hunkIdx},
+			{header: "+++ " + file.NewPath, elem: elemDiffHeader, hunkIdx: hunkIdx}}
+	}
+}
+
+// unifiedRows returns rows of hunk lines shown in unified view.
+func unifiedRows(hunk diff.Hunk, hunkIdx int) []diffRow {
+	rows := make([]diffRow, len(hunk.Lines))
+	for idx, line := range hunk.Lines {
+		rows[idx] = diffRow{hunkIdx: hunkIdx,
+			old: diffCell{oldNo: line.OldNo, newNo: line.NewNo, text: lineText(line), op: line.Op}}
+	}
+	return rows
+}
+
+// sideBySideRows returns rows of hunk lines shown in side-by-side view, runs
+// of deleted lines are shown next to inserted lines which replace them.
+func sideBySideRows(hunk diff.Hunk, hunkIdx int) []diffRow {
+	rows := make([]diffRow, 0, len(hunk.Lines))
+	for idx := 0; idx < len(hunk.Lines); {
+		line := hunk.Lines[idx]
+		if line.Op == diff.Equal {
+			rows = append(rows, diffRow{hunkIdx: hunkIdx,
+				old: diffCell{oldNo: line.OldNo, text: lineText(line), op: line.Op},
+				new: diffCell{newNo: line.NewNo, text: lineText(line), op: line.Op}})
+			idx++
+			continue
+		}
+
+		deleted := make([]diff.Line, 0)
+		for ; idx < len(hunk.Lines) && hunk.Lines[idx].Op == diff.Delete; idx++ {
+			deleted = append(deleted, hunk.Lines[idx])
+		}
+		inserted := make([]diff.Line, 0)
+		for ; idx < len(hunk.Lines) && hunk.Lines[idx].Op == diff.Insert; idx++ {
+			inserted = append(inserted, hunk.Lines[idx])
+		}
+		for changeIdx := 0; changeIdx < len(deleted) || changeIdx < len(inserted); changeIdx++ {
+			row := diffRow{hunkIdx: hunkIdx}
+			if changeIdx < len(deleted) {
+				row.old = diffCell{oldNo: deleted[changeIdx].OldNo, text: lineText(deleted[changeIdx]), op: diff.Delete}
+			}
+			if changeIdx < len(inserted) {
+				row.new = diffCell{newNo: inserted[changeIdx].NewNo, text: lineText(inserted[changeIdx]), op: diff.Insert}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// noNewlineText is shown after last line of content without newline at the end.
+const noNewlineText = "  \\ No newline at end of file"
+
+// lineText returns text of line shown in diff viewer.
+func lineText(line diff.Line) string {
+	if line.NoNewline {
+		return diffText(line.Text) + noNewlineText
+	}
+	return diffText(line.Text)
+}
+
+// diffText prepares line for showing in diff viewer (tabs are expanded and
+// control characters are replaced).
+func diffText(line string) string {
+	line = strings.Replace(line, "\t", strings.Repeat(" ", pagerTabWidth), -1)
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return '.'
+		}
+		return r
+	}, line)
+}
+
+// Resize sets size of the screen used by diff viewer.
+func (dv *DiffView) Resize(width, height int) {
+	dv.width, dv.height = width, height
+	dv.Scroll(0)
+}
+
+// pageRows returns number of rows shown on one page (screen without title and
+// status line).
+func (dv *DiffView) pageRows() int {
+	if dv.height < 3 {
+		return 1
+	}
+	return dv.height - 2
+}
+
+// Scroll scrolls differences by given number of rows (up if rows is negative).
+func (dv *DiffView) Scroll(rows int) {
+	dv.topRow += rows
+	if dv.topRow > len(dv.rows)-dv.pageRows() {
+		dv.topRow = len(dv.rows) - dv.pageRows()
+	}
+	if dv.topRow < 0 {
+		dv.topRow = 0
+	}
+}
+
+// ScrollPage scrolls differences by given number of pages (up if pages is
+// negative).
+func (dv *DiffView) ScrollPage(pages int) {
+	dv.Scroll(pages * (dv.pageRows() - 1))
+}
+
+// ScrollTop scrolls to the beginning of the differences.
+func (dv *DiffView) ScrollTop() {
+	dv.topRow = 0
+}
+
+// ScrollBottom scrolls to the end of the differences.
+func (dv *DiffView) ScrollBottom() {
+	dv.Scroll(len(dv.rows))
+}
+
+// ScrollHoriz scrolls content lines horizontally by given number of columns.
+func (dv *DiffView) ScrollHoriz(columns int) {
+	dv.horizScroll += columns
+	if dv.horizScroll < 0 {
+		dv.horizScroll = 0
+	}
+}
+
+// ToggleSideBySide switches between unified and side-by-side view.
+func (dv *DiffView) ToggleSideBySide() {
+	dv.sideBySide = !dv.sideBySide
+	dv.layout()
+}
+
+// NextHunk scrolls to the next (or previous) hunk, false is returned if there
+// are no more hunks.
+func (dv *DiffView) NextHunk(reverse bool) bool {
+	if len(dv.rows) == 0 {
+		return false
+	}
+	targetHunk := dv.rows[dv.topRow].hunkIdx + 1
+	if reverse {
+		targetHunk = dv.rows[dv.topRow].hunkIdx
+		if dv.topRow == dv.hunkRow(targetHunk) {
+			targetHunk--
+		}
+	}
+	switch {
+	case targetHunk < 0 || targetHunk >= dv.hunkCount:
+		return false
+	case !reverse && dv.lastRow() == len(dv.rows)-1:
+		// Last page is already shown.
+		return false
+	}
+	dv.topRow = dv.hunkRow(targetHunk)
+	dv.Scroll(0)
+	return true
+}
+
+// lastRow returns index of the last row shown.
+func (dv *DiffView) lastRow() int {
+	lastRow := dv.topRow + dv.pageRows() - 1
+	if lastRow >= len(dv.rows) {
+		lastRow = len(dv.rows) - 1
+	}
+	return lastRow
+}
+
+// positionString returns position of shown differences (hunk and rows shown).
+func (dv *DiffView) positionString() string {
+	if len(dv.rows) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("hunk %d/%d, %d-%d/%d", dv.rows[dv.topRow].hunkIdx+1, dv.hunkCount,
+		dv.topRow+1, dv.lastRow()+1, len(dv.rows))
+}
+
+// DrawDiffView shows built-in diff viewer on the terminal screen.
+func DrawDiffView(dv *DiffView) {
+	logging.LogDebugf("ui/out/DrawDiffView('%s')", dv.title)
+	width, height := Screen.Size()
+	dv.Resize(width, height)
+
+	Screen.Clear()
+	Screen.SetStyle(stNormal)
+
+	title := dv.title
+	if dv.sideBySide {
+		title += " (side-by-side)"
+	}
+	writeLine(0, 0, strings.Repeat(" ", width), 0, stCurrentTitle)
+	writeLine(0, 0, title, 0, stCurrentTitle)
+	position := dv.positionString()
+	writeLine(width-utf8.RuneCountInString(position)-1, 0, position, 0, stCurrentTitle)
+
+	for screenRow := 0; screenRow < dv.pageRows() && dv.topRow+screenRow < len(dv.rows); screenRow++ {
+		row := dv.rows[dv.topRow+screenRow]
+		y := screenRow + 1
+		switch {
+		case row.header != "":
+			writeLine(0, y, strings.Repeat(" ", width), 0, stDiff[row.elem])
+			writeLine(0, y, row.header, 0, stDiff[row.elem])
+		case dv.sideBySide:
+			oldWidth := (width - 1) / 2
+			dv.drawCell(0, y, oldWidth, dv.lineNo(row.old.oldNo), row.old)
+			Screen.SetContent(oldWidth, y, diffSeparator, nil, stLineNumber)
+			dv.drawCell(oldWidth+1, y, width-oldWidth-1, dv.lineNo(row.new.newNo), row.new)
+		default:
+			dv.drawCell(0, y, width, dv.lineNo(row.old.oldNo)+dv.lineNo(row.old.newNo), row.old)
+		}
+	}
+
+	writeLine(0, height-1, strings.Repeat(" ", width), 0, stStatus)
+	writeLine(0, height-1, dv.Status, 0, stStatus)
+
+	Screen.Show()
+}
+
+// lineNo returns formatted line number (empty if line doesn't exist).
+func (dv *DiffView) lineNo(no int) string {
+	if no == 0 {
+		return strings.Repeat(" ", dv.lineNoWidth+1)
+	}
+	return fmt.Sprintf("%*d ", dv.lineNoWidth, no)
+}
+
+// drawCell draws content line with line number(s) and change marker in given
+// part of the screen row, changed lines are highlighted to the end of the part.
+func (dv *DiffView) drawCell(x, y, width int, lineNumbers string, cell diffCell) {
+	if cell.oldNo == 0 && cell.newNo == 0 {
+		// Empty side of the side-by-side row.
+		return
+	}
+	endX := x + width
+	x = dv.drawText(x, endX, y, lineNumbers, stLineNumber)
+
+	style := stNormal
+	switch cell.op {
+	case diff.Insert:
+		style = stDiff[elemDiffAdded]
+	case diff.Delete:
+		style = stDiff[elemDiffRemoved]
+	}
+	x = dv.drawText(x, endX, y, cell.op.String(), style)
+
+	horizScroll := dv.horizScroll
+	for _, r := range cell.text {
+		if horizScroll > 0 {
+			horizScroll--
+			continue
+		}
+		if x >= endX {
+			return
+		}
+		Screen.SetContent(x, y, r, nil, style)
+		x++
+	}
+	if cell.op != diff.Equal && x < endX {
+		dv.drawText(x, endX, y, strings.Repeat(" ", endX-x), style)
+	}
+}
+
+// drawText draws text between x and endX and returns position after text.
+func (dv *DiffView) drawText(x, endX, y int, text string, style tcell.Style) int {
+	for _, r := range text {
+		if x >= endX {
+			break
+		}
+		Screen.SetContent(x, y, r, nil, style)
+		x++
+	}
+	return x
+}
@@ -0,0 +1,93 @@
+package out
+
+import (
+	"fmt"
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"github.com/croz-ltd/dpcmder/utils/diff"
+	"strings"
+	"testing"
+)
+
+// diffRowString returns short description of diff viewer row.
+func diffRowString(row diffRow) string {
+	if row.header != "" {
+		return fmt.Sprintf("%d:%s", row.hunkIdx, row.header)
+	}
+	return fmt.Sprintf("%d:%d%s%s|%d%s%s", row.hunkIdx,
+		row.old.oldNo, row.old.op, row.old.text, row.new.newNo, row.new.op, row.new.text)
+}
+
+func testDiffFiles() []diff.File {
+	oldFile, _ := diff.Contents("old.txt", "new.txt", []byte("a\nb\nc\nd\n"), []byte("a\nx\ny\nc\nd\n"), 1)
+	addedFile, _ := diff.Contents("", "added.txt", nil, []byte("n\n"), 1)
+	binaryFile, _ := diff.Contents("old.bin", "new.bin", []byte("\x00"), []byte("\x01\x00"), 1)
+	return []diff.File{oldFile, addedFile, binaryFile}
+}
+
+func TestDiffViewRows(t *testing.T) {
+	dv := NewDiffView("test", testDiffFiles())
+	dv.Resize(80, 30)
+
+	rows := make([]string, len(dv.rows))
+	for idx, row := range dv.rows {
+		rows[idx] = diffRowString(row)
+	}
+	want := []string{
+		"0:--- old.txt", "0:+++ new.txt", "0:@@ -1,3 +1,4 @@",
+		"0:1 a|0 ", "0:2-b|0 ", "0:0+x|0 ", "0:0+y|0 ", "0:3 c|0 ",
+		"1:+++ added.txt (added)", "1:@@ -0,0 +1 @@", "1:0+n|0 ",
+		"2:--- old.bin", "2:+++ new.bin", "2:Binary files differ",
+	}
+	assert.DeepEqual(t, "NewDiffView() unified rows", strings.Join(rows, ","), strings.Join(want, ","))
+	assert.Equals(t, "NewDiffView() hunk count", dv.hunkCount, 3)
+
+	dv.ToggleSideBySide()
+	rows = make([]string, len(dv.rows))
+	for idx, row := range dv.rows {
+		rows[idx] = diffRowString(row)
+	}
+	want = []string{
+		"0:--- old.txt", "0:+++ new.txt", "0:@@ -1,3 +1,4 @@",
+		"0:1 a|1 a", "0:2-b|2+x", "0:0 |3+y", "0:3 c|4 c",
+		"1:+++ added.txt (added)", "1:@@ -0,0 +1 @@", "1:0 |1+n",
+		"2:--- old.bin", "2:+++ new.bin", "2:Binary files differ",
+	}
+	assert.DeepEqual(t, "ToggleSideBySide() rows", strings.Join(rows, ","), strings.Join(want, ","))
+}
+
+func TestDiffViewNoNewline(t *testing.T) {
+	file, _ := diff.Contents("old.txt", "new.txt", []byte("a"), []byte("a\n"), 1)
+	dv := NewDiffView("test", []diff.File{file})
+	rows := make([]string, len(dv.rows))
+	for idx, row := range dv.rows {
+		rows[idx] = diffRowString(row)
+	}
+	want := []string{
+		"0:--- old.txt", "0:+++ new.txt", "0:@@ -1 +1 @@",
+		"0:1-a  \\ No newline at end of file|0 ", "0:0+a|0 ",
+	}
+	assert.DeepEqual(t, "NewDiffView() no newline rows", strings.Join(rows, ","), strings.Join(want, ","))
+}
+
+func TestDiffViewNextHunk(t *testing.T) {
+	dv := NewDiffView("test", testDiffFiles())
+	dv.Resize(80, 6)
+
+	assert.Equals(t, "NextHunk(true) at the top", dv.NextHunk(true), false)
+	assert.Equals(t, "NextHunk(false) 1", dv.NextHunk(false), true)
+	assert.Equals(t, "NextHunk(false) 1 top row", dv.topRow, 8)
+	assert.Equals(t, "NextHunk(false) 1 position", dv.positionString(), "hunk 2/3, 9-12/14")
+	assert.Equals(t, "NextHunk(false) 2", dv.NextHunk(false), true)
+	assert.Equals(t, "NextHunk(false) 2 top row", dv.topRow, 10)
+	assert.Equals(t, "NextHunk(false) at the bottom", dv.NextHunk(false), false)
+
+	dv.Scroll(-1)
+	assert.Equals(t, "NextHunk(true) inside hunk", dv.NextHunk(true), true)
+	assert.Equals(t, "NextHunk(true) inside hunk top row", dv.topRow, 8)
+	assert.Equals(t, "NextHunk(true) 2", dv.NextHunk(true), true)
+	assert.Equals(t, "NextHunk(true) 2 top row", dv.topRow, 0)
+
+	dv.Scroll(9)
+	dv.ToggleSideBySide()
+	assert.Equals(t, "ToggleSideBySide() keeps hunk", dv.rows[dv.topRow].hunkIdx, 1)
+}
@@ -0,0 +1,389 @@
+package out
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"path"
+	"strings"
+)
+
+// Syntax types of content shown in built-in pager.
+const (
+	syntaxNone = "none"
+	syntaxXML  = "xml"
+	syntaxXSLT = "xslt"
+	syntaxJSON = "json"
+	syntaxJS   = "js"
+)
+
+// syntaxExtensions maps file extensions to syntax types.
+var syntaxExtensions = map[string]string{
+	".xml": syntaxXML, ".xsd": syntaxXML, ".wsdl": syntaxXML, ".svg": syntaxXML,
+	".xsl": syntaxXSLT, ".xslt": syntaxXSLT,
+	".json": syntaxJSON,
+	".js":   syntaxJS, ".gs": syntaxJS, ".mjs": syntaxJS,
+}
+
+// jsKeywords contains JavaScript (GatewayScript) keywords and literals.
+var jsKeywords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true,
+	"do": true, "else": true, "export": true, "extends": true, "finally": true,
+	"for": true, "function": true, "if": true, "import": true, "in": true,
+	"instanceof": true, "let": true, "new": true, "return": true, "super": true,
+	"switch": true, "this": true, "throw": true, "try": true, "typeof": true,
+	"var": true, "void": true, "while": true, "with": true, "yield": true,
+	"async": true, "await": true, "of": true,
+	"true": true, "false": true, "null": true, "undefined": true,
+}
+
+// span is part of text (byte offsets) shown with style of given UI element.
+type span struct {
+	start, end int
+	elem       string
+}
+
+// detectSyntax returns syntax type of content from name extension or (if
+// extension is unknown) from the first character of content.
+func detectSyntax(name string, content string) string {
+	if syntax, ok := syntaxExtensions[strings.ToLower(path.Ext(name))]; ok {
+		if syntax == syntaxXML && strings.Contains(content, "/XSL/Transform") {
+			return syntaxXSLT
+		}
+		return syntax
+	}
+	trimmed := strings.TrimSpace(content)
+	switch {
+	case strings.HasPrefix(trimmed, "<") && strings.Contains(content, "/XSL/Transform"):
+		return syntaxXSLT
+	case strings.HasPrefix(trimmed, "<"):
+		return syntaxXML
+	case strings.HasPrefix(trimmed, "{"), strings.HasPrefix(trimmed, "["):
+		return syntaxJSON
+	default:
+		return syntaxNone
+	}
+}
+
+// prettyPrint returns indented XML or JSON content, false is returned if
+// content can't be parsed or syntax can't be pretty printed.
+func prettyPrint(syntax, content string) (string, bool) {
+	switch syntax {
+	case syntaxJSON:
+		var prettyJSON bytes.Buffer
+		if err := json.Indent(&prettyJSON, []byte(content), "", "  "); err != nil {
+			return content, false
+		}
+		return prettyJSON.String(), true
+	case syntaxXML, syntaxXSLT:
+		return prettyPrintXML(content)
+	default:
+		return content, false
+	}
+}
+
+// prettyPrintXML indents XML elements - elements containing only text are
+// kept in a single line, namespace prefixes are kept as they are.
+func prettyPrintXML(content string) (string, bool) {
+	// RawToken keeps namespace prefixes but doesn't check if elements match.
+	validator := xml.NewDecoder(strings.NewReader(content))
+	for {
+		_, err := validator.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return content, false
+		}
+	}
+
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	tokens := make([]xml.Token, 0)
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return content, false
+		}
+		if charData, ok := token.(xml.CharData); ok && len(bytes.TrimSpace(charData)) == 0 {
+			continue
+		}
+		tokens = append(tokens, xml.CopyToken(token))
+	}
+
+	var result strings.Builder
+	depth := 0
+	writeIndented := func(text string) {
+		result.WriteString(strings.Repeat("  ", depth))
+		result.WriteString(text)
+		result.WriteString("\n")
+	}
+	for idx := 0; idx < len(tokens); idx++ {
+		switch token := tokens[idx].(type) {
+		case xml.StartElement:
+			startTag := xmlStartTag(token)
+			switch {
+			case idx+1 < len(tokens) && isEndElement(tokens[idx+1]):
+				writeIndented(startTag[:len(startTag)-1] + "/>")
+				idx++
+			case idx+2 < len(tokens) && isCharData(tokens[idx+1]) && isEndElement(tokens[idx+2]):
+				writeIndented(startTag + xmlEscape(string(tokens[idx+1].(xml.CharData))) +
+					xmlEndTag(tokens[idx+2].(xml.EndElement)))
+				idx += 2
+			default:
+				writeIndented(startTag)
+				depth++
+			}
+		case xml.EndElement:
+			if depth > 0 {
+				depth--
+			}
+			writeIndented(xmlEndTag(token))
+		case xml.CharData:
+			writeIndented(xmlEscape(strings.TrimSpace(string(token))))
+		case xml.Comment:
+			writeIndented("<!--" + string(token) + "-->")
+		case xml.ProcInst:
+			writeIndented("<?" + token.Target + " " + string(token.Inst) + "?>")
+		case xml.Directive:
+			writeIndented("<!" + string(token) + ">")
+		}
+	}
+
+	return result.String(), true
+}
+
+// isCharData returns true if token is XML text.
+func isCharData(token xml.Token) bool {
+	_, ok := token.(xml.CharData)
+	return ok
+}
+
+// isEndElement returns true if token is XML end element.
+func isEndElement(token xml.Token) bool {
+	_, ok := token.(xml.EndElement)
+	return ok
+}
+
+// xmlName returns XML name with namespace prefix (as returned by RawToken).
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// xmlStartTag returns XML start tag with all attributes.
+func xmlStartTag(element xml.StartElement) string {
+	var tag strings.Builder
+	tag.WriteString("<" + xmlName(element.Name))
+	for _, attr := range element.Attr {
+		tag.WriteString(" " + xmlName(attr.Name) + `="` + xmlEscape(attr.Value) + `"`)
+	}
+	tag.WriteString(">")
+	return tag.String()
+}
+
+// xmlEndTag returns XML end tag.
+func xmlEndTag(element xml.EndElement) string {
+	return "</" + xmlName(element.Name) + ">"
+}
+
+// xmlEscape escapes XML special characters in text.
+func xmlEscape(text string) string {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(text))
+	return strings.NewReplacer("&#xA;", "\n", "&#x9;", "\t", "&#xD;", "\r").Replace(escaped.String())
+}
+
+// highlight returns spans of content which should be highlighted for syntax.
+func highlight(syntax, content string) []span {
+	switch syntax {
+	case syntaxXML, syntaxXSLT:
+		return highlightXML(content)
+	case syntaxJSON, syntaxJS:
+		return highlightScript(syntax, content)
+	default:
+		return nil
+	}
+}
+
+// highlightXML returns spans of XML tags, attributes, attribute values and
+// comments - XSLT elements (xsl:*) are highlighted as keywords.
+func highlightXML(content string) []span {
+	spans := make([]span, 0)
+	idx := 0
+	for idx < len(content) {
+		tagStart := strings.IndexByte(content[idx:], '<')
+		if tagStart == -1 {
+			break
+		}
+		idx += tagStart
+
+		switch {
+		case strings.HasPrefix(content[idx:], "<!--"):
+			end := indexAfter(content, idx+4, "-->")
+			spans = append(spans, span{idx, end, elemSyntaxComment})
+			idx = end
+			continue
+		case strings.HasPrefix(content[idx:], "<![CDATA["):
+			end := indexAfter(content, idx+9, "]]>")
+			spans = append(spans, span{idx, end, elemSyntaxString})
+			idx = end
+			continue
+		}
+
+		nameEnd := idx + 1
+		if nameEnd < len(content) && strings.ContainsRune("/?!", rune(content[nameEnd])) {
+			nameEnd++
+		}
+		for nameEnd < len(content) && !strings.ContainsRune(" \t\r\n/?>", rune(content[nameEnd])) {
+			nameEnd++
+		}
+		tagElem := elemSyntaxTag
+		if strings.HasPrefix(content[idx:], "<xsl:") || strings.HasPrefix(content[idx:], "</xsl:") {
+			tagElem = elemSyntaxKeyword
+		}
+		spans = append(spans, span{idx, nameEnd, tagElem})
+		idx = nameEnd
+
+	tagLoop:
+		for idx < len(content) {
+			switch c := content[idx]; {
+			case c == '>':
+				spans = append(spans, span{idx, idx + 1, tagElem})
+				idx++
+				break tagLoop
+			case (c == '/' || c == '?') && idx+1 < len(content) && content[idx+1] == '>':
+				spans = append(spans, span{idx, idx + 2, tagElem})
+				idx += 2
+				break tagLoop
+			case c == '"' || c == '\'':
+				end := strings.IndexByte(content[idx+1:], c)
+				if end == -1 {
+					end = len(content)
+				} else {
+					end += idx + 2
+				}
+				spans = append(spans, span{idx, end, elemSyntaxString})
+				idx = end
+			case c == '<':
+				break tagLoop
+			case strings.ContainsRune(" \t\r\n=/?", rune(c)):
+				idx++
+			default:
+				attrEnd := idx
+				for attrEnd < len(content) && !strings.ContainsRune(" \t\r\n=/?>\"'<", rune(content[attrEnd])) {
+					attrEnd++
+				}
+				spans = append(spans, span{idx, attrEnd, elemSyntaxAttr})
+				idx = attrEnd
+			}
+		}
+	}
+
+	return spans
+}
+
+// indexAfter returns index after the first occurrence of substring found
+// after given index (or content length if substring is not found).
+func indexAfter(content string, idx int, substr string) int {
+	end := strings.Index(content[idx:], substr)
+	if end == -1 {
+		return len(content)
+	}
+	return idx + end + len(substr)
+}
+
+// highlightScript returns spans of JSON or JavaScript strings, numbers,
+// keywords and comments - JSON object keys are highlighted as attributes.
+func highlightScript(syntax, content string) []span {
+	spans := make([]span, 0)
+	idx := 0
+	for idx < len(content) {
+		c := content[idx]
+		switch {
+		case c == '"' || (syntax == syntaxJS && (c == '\'' || c == '`')):
+			end := idx + 1
+			for end < len(content) && content[end] != c && (content[end] != '\n' || c == '`') {
+				if content[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end < len(content) && content[end] == c {
+				end++
+			}
+			if end > len(content) {
+				end = len(content)
+			}
+			elem := elemSyntaxString
+			if syntax == syntaxJSON && strings.HasPrefix(strings.TrimLeft(content[end:], " \t\r\n"), ":") {
+				elem = elemSyntaxAttr
+			}
+			spans = append(spans, span{idx, end, elem})
+			idx = end
+		case syntax == syntaxJS && strings.HasPrefix(content[idx:], "//"):
+			end := strings.IndexByte(content[idx:], '\n')
+			if end == -1 {
+				end = len(content) - idx
+			}
+			spans = append(spans, span{idx, idx + end, elemSyntaxComment})
+			idx += end
+		case syntax == syntaxJS && strings.HasPrefix(content[idx:], "/*"):
+			end := indexAfter(content, idx+2, "*/")
+			spans = append(spans, span{idx, end, elemSyntaxComment})
+			idx = end
+		case isDigit(c), c == '-' && idx+1 < len(content) && isDigit(content[idx+1]):
+			end := numberEnd(content, idx)
+			spans = append(spans, span{idx, end, elemSyntaxNumber})
+			idx = end
+		case isWordChar(c):
+			end := idx
+			for end < len(content) && isWordChar(content[end]) {
+				end++
+			}
+			word := content[idx:end]
+			if syntax == syntaxJS && jsKeywords[word] ||
+				syntax == syntaxJSON && (word == "true" || word == "false" || word == "null") {
+				spans = append(spans, span{idx, end, elemSyntaxKeyword})
+			}
+			idx = end
+		default:
+			idx++
+		}
+	}
+
+	return spans
+}
+
+// numberEnd returns index after the number starting at given index.
+func numberEnd(content string, idx int) int {
+	end := idx + 1
+	for end < len(content) {
+		switch c := content[end]; {
+		case isWordChar(c), c == '.':
+		case (c == '+' || c == '-') && (content[end-1] == 'e' || content[end-1] == 'E'):
+		default:
+			return end
+		}
+		end++
+	}
+	return end
+}
+
+// isDigit returns true for decimal digits.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isWordChar returns true for characters which can be part of identifier or
+// number.
+func isWordChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '$'
+}
@@ -0,0 +1,90 @@
+package out
+
+import (
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"testing"
+)
+
+func TestDetectSyntax(t *testing.T) {
+	testDataMatrix := []struct {
+		name, content, syntax string
+	}{
+		{"test.xml", "<a/>", syntaxXML},
+		{"test.XSL", "<xsl:stylesheet/>", syntaxXSLT},
+		{"test.xml", `<xsl:stylesheet xmlns:xsl="http://www.w3.org/1999/XSL/Transform"/>`, syntaxXSLT},
+		{"test.json", "{}", syntaxJSON},
+		{"test.js", "var a = 1;", syntaxJS},
+		{"test.gs", "var a = 1;", syntaxJS},
+		{"MyObject", "  <a/>", syntaxXML},
+		{"MyObject", `<xsl:stylesheet xmlns:xsl="http://www.w3.org/1999/XSL/Transform"/>`, syntaxXSLT},
+		{"MyObject", "\n[1, 2]", syntaxJSON},
+		{"README", "some text", syntaxNone},
+	}
+	for _, row := range testDataMatrix {
+		assert.DeepEqual(t, "detectSyntax("+row.name+")", detectSyntax(row.name, row.content), row.syntax)
+	}
+}
+
+func TestPrettyPrint(t *testing.T) {
+	testDataMatrix := []struct {
+		syntax, content, pretty string
+		ok                      bool
+	}{
+		{syntaxJSON, `{"a":[1,2],"b":{}}`, "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {}\n}", true},
+		{syntaxJSON, `{"a":`, `{"a":`, false},
+		{syntaxXML, `<?xml version="1.0"?><dp:a xmlns:dp="urn:x"><b c="1&amp;2">x &lt; y</b><d/><!-- c --><e> </e></dp:a>`,
+			`<?xml version="1.0"?>
+<dp:a xmlns:dp="urn:x">
+  <b c="1&amp;2">x &lt; y</b>
+  <d/>
+  <!-- c -->
+  <e/>
+</dp:a>
+`, true},
+		{syntaxXML, "<a><b>text<c/>tail</b></a>", "<a>\n  <b>\n    text\n    <c/>\n    tail\n  </b>\n</a>\n", true},
+		{syntaxXML, "<a><b></a>", "<a><b></a>", false},
+		{syntaxJS, "var a = 1;", "var a = 1;", false},
+	}
+	for _, row := range testDataMatrix {
+		pretty, ok := prettyPrint(row.syntax, row.content)
+		assert.DeepEqual(t, "prettyPrint("+row.content+")", pretty, row.pretty)
+		assert.DeepEqual(t, "prettyPrint("+row.content+") ok", ok, row.ok)
+	}
+}
+
+// highlighted returns highlighted parts of content with UI elements used.
+func highlighted(syntax, content string) [][]string {
+	result := make([][]string, 0)
+	for _, s := range highlight(syntax, content) {
+		result = append(result, []string{content[s.start:s.end], s.elem})
+	}
+	return result
+}
+
+func TestHighlight(t *testing.T) {
+	assert.DeepEqual(t, "highlight(XSLT)",
+		highlighted(syntaxXSLT, `<xsl:if test="$a &gt; 1"><!-- c --><b x='y'>t</b><![CDATA[<d>]]></xsl:if>`),
+		[][]string{
+			{"<xsl:if", elemSyntaxKeyword}, {"test", elemSyntaxAttr}, {`"$a &gt; 1"`, elemSyntaxString},
+			{">", elemSyntaxKeyword}, {"<!-- c -->", elemSyntaxComment},
+			{"<b", elemSyntaxTag}, {"x", elemSyntaxAttr}, {"'y'", elemSyntaxString}, {">", elemSyntaxTag},
+			{"</b", elemSyntaxTag}, {">", elemSyntaxTag}, {"<![CDATA[<d>]]>", elemSyntaxString},
+			{"</xsl:if", elemSyntaxKeyword}, {">", elemSyntaxKeyword}})
+	assert.DeepEqual(t, "highlight(XML)",
+		highlighted(syntaxXML, `<?xml version="1.0"?><a/>`),
+		[][]string{
+			{"<?xml", elemSyntaxTag}, {"version", elemSyntaxAttr}, {`"1.0"`, elemSyntaxString}, {"?>", elemSyntaxTag},
+			{"<a", elemSyntaxTag}, {"/>", elemSyntaxTag}})
+	assert.DeepEqual(t, "highlight(JSON)",
+		highlighted(syntaxJSON, `{"a\"b": "c", "d" : [-1.5e+3, true, null]}`),
+		[][]string{
+			{`"a\"b"`, elemSyntaxAttr}, {`"c"`, elemSyntaxString}, {`"d"`, elemSyntaxAttr},
+			{"-1.5e+3", elemSyntaxNumber}, {"true", elemSyntaxKeyword}, {"null", elemSyntaxKeyword}})
+	assert.DeepEqual(t, "highlight(JS)",
+		highlighted(syntaxJS, "// c\nvar a1 = 'x' + `y`; /* d */ return 10;"),
+		[][]string{
+			{"// c", elemSyntaxComment}, {"var", elemSyntaxKeyword}, {"'x'", elemSyntaxString},
+			{"`y`", elemSyntaxString}, {"/* d */", elemSyntaxComment}, {"return", elemSyntaxKeyword},
+			{"10", elemSyntaxNumber}})
+	assert.DeepEqual(t, "highlight(none)", highlighted(syntaxNone, "var a = 1;"), [][]string{})
+}
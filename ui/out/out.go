@@ -3,51 +3,79 @@ package out
 
 import (
 	"fmt"
+	"github.com/croz-ltd/dpcmder/config"
 	"github.com/croz-ltd/dpcmder/events"
 	"github.com/croz-ltd/dpcmder/model"
+	"github.com/croz-ltd/dpcmder/utils/errs"
 	"github.com/croz-ltd/dpcmder/utils/logging"
 	"github.com/gdamore/tcell"
 	"os"
 	"strings"
+	"sync"
 	"unicode/utf8"
 )
 
-// Colors used for coloring text and background.
-const (
-	fgNormal   = tcell.ColorDefault
-	bgNormal   = tcell.ColorDefault
-	fgSelected = tcell.ColorRed
-	bgCurrent  = tcell.ColorGreen
-)
-
-// Styles used for console cell (text with background) coloring.
+// Styles used for console cell (text with background) coloring, default
+// theme styles are used until theme from configuration is set (see SetTheme).
 var (
-	stNormal          = tcell.StyleDefault.Foreground(fgNormal).Background(bgNormal)
-	stSelected        = tcell.StyleDefault.Foreground(fgSelected).Background(bgNormal)
-	stCurrent         = tcell.StyleDefault.Foreground(fgNormal).Background(bgCurrent)
-	stCurrentSelected = tcell.StyleDefault.Foreground(fgSelected).Background(bgCurrent)
-	stCursor          = stNormal.Reverse(true)
+	defaultStyles, _  = buildTheme(config.Theme{})
+	stNormal          = defaultStyles[elemNormal]
+	stTitle           = defaultStyles[elemTitle]
+	stCurrentTitle    = defaultStyles[elemCurrentTitle]
+	stCurrent         = defaultStyles[elemCurrent]
+	stSelected        = defaultStyles[elemSelected]
+	stCurrentSelected = defaultStyles[elemCurrentSelected]
+	stModified        = defaultStyles[elemModified]
+	stDown            = defaultStyles[elemDown]
+	stDialog          = defaultStyles[elemDialog]
+	stDialogSelected  = defaultStyles[elemDialogSelected]
+	stStatus          = defaultStyles[elemStatus]
+	stLineNumber      = defaultStyles[elemLineNumber]
+	stSearchMatch     = defaultStyles[elemSearchMatch]
+	stSyntax          = elemStyles(defaultStyles, syntaxElems)
+	stDiff            = elemStyles(defaultStyles, diffElems)
+	stCursor          = stDialog.Reverse(true)
 )
 
 // Screen is used to show text on console and poll input events (key press,
-// console resize, could add mouse).
+// console resize, mouse). Screen is replaced on each Init (after external
+// program is run) so other goroutines should use PollEvent and PostEvent.
 var Screen tcell.Screen
 
+// screenMutex guards Screen changes from goroutines reading input events.
+var screenMutex sync.RWMutex
+
+// itemsFirstLine is the screen line where the first item of each side is shown.
+const itemsFirstLine = 2
+
+// questionDialog contains position of the last question dialog shown.
+var questionDialog struct {
+	x, y, width, answerX int
+}
+
+// listDialog contains position of the last list selection dialog shown.
+var listDialog struct {
+	x, width, firstLine, lastLine, textLineStart, textLineEnd, scroll int
+}
+
 // Init initializes console screen.
 func Init() {
 	logging.LogDebug("ui/out/Init()")
 
-	var err error
-	Screen, err = tcell.NewScreen()
+	screen, err := tcell.NewScreen()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
-	err = Screen.Init()
+	err = screen.Init()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
+	screen.EnableMouse()
+	screenMutex.Lock()
+	Screen = screen
+	screenMutex.Unlock()
 }
 
 // Stop terminates console screen.
@@ -57,6 +85,32 @@ func Stop() {
 	logging.LogDebug("ui/out/Stop() end")
 }
 
+// currentScreen returns screen used at the moment.
+func currentScreen() tcell.Screen {
+	screenMutex.RLock()
+	defer screenMutex.RUnlock()
+	return Screen
+}
+
+// PollEvent waits for the next input event from screen, nil is returned if
+// screen is stopped (or not initialized yet).
+func PollEvent() tcell.Event {
+	screen := currentScreen()
+	if screen == nil {
+		return nil
+	}
+	return screen.PollEvent()
+}
+
+// PostEvent posts event to be read by PollEvent.
+func PostEvent(event tcell.Event) error {
+	screen := currentScreen()
+	if screen == nil {
+		return errs.Error("Screen not initialized.")
+	}
+	return screen.PostEvent(event)
+}
+
 // GetScreenSize returns size of console screen.
 func GetScreenSize() (width, height int) {
 	width, height = Screen.Size()
@@ -64,6 +118,48 @@ func GetScreenSize() (width, height int) {
 	return width, height
 }
 
+// PaneAt returns side shown at given screen position and index of the item row
+// at that position (-1 if position is not on item row - like title line).
+func PaneAt(x, y int) (model.Side, int) {
+	width, _ := Screen.Size()
+	side := model.Left
+	if x >= width/2 {
+		side = model.Right
+	}
+	if y < itemsFirstLine {
+		return side, -1
+	}
+	return side, y - itemsFirstLine
+}
+
+// QuestionDialogAnswerIdxAt returns answer (rune) index at given screen
+// position in the last question dialog shown (-1 if position is not on the
+// answer line) and true if position is inside of the dialog.
+func QuestionDialogAnswerIdxAt(x, y int) (int, bool) {
+	d := questionDialog
+	if x < d.x || x >= d.x+d.width || y < d.y-2 || y > d.y+2 {
+		return -1, false
+	}
+	if y != d.y || x < d.answerX {
+		return -1, true
+	}
+	return x - d.answerX, true
+}
+
+// ListSelectionDialogIdxAt returns list index at given screen position in the
+// last list selection dialog shown (-1 if position is not on list item) and
+// true if position is inside of the dialog.
+func ListSelectionDialogIdxAt(x, y int) (int, bool) {
+	d := listDialog
+	if x < d.x || x >= d.x+d.width || y < d.firstLine || y > d.lastLine {
+		return -1, false
+	}
+	if y < d.textLineStart || y > d.textLineEnd {
+		return -1, true
+	}
+	return y - d.textLineStart + d.scroll, true
+}
+
 // DrawEvent crates appropriate changes to screen for given event. Usually either
 // refresh whole screen or just update status message.
 func DrawEvent(updateViewEvent events.UpdateViewEvent) {
@@ -94,49 +190,52 @@ func refreshScreen(m model.Model) {
 	m.ResizeView()
 
 	Screen.Clear()
-	Screen.SetStyle(tcell.StyleDefault.Foreground(fgNormal).Background(bgNormal))
+	Screen.SetStyle(stNormal)
 
 	width, _ := Screen.Size()
 	if m.IsCurrentSide(model.Left) {
-		writeLine(0, 0, m.Title(model.Left), m.HorizScroll, stCurrent)
-		writeLine(width/2, 0, m.Title(model.Right), m.HorizScroll, stNormal)
+		writeLine(0, 0, m.Title(model.Left), m.HorizScroll, stCurrentTitle)
+		writeLine(width/2, 0, m.Title(model.Right), m.HorizScroll, stTitle)
 	} else {
-		writeLine(0, 0, m.Title(model.Left), m.HorizScroll, stNormal)
-		writeLine(width/2, 0, m.Title(model.Right), m.HorizScroll, stCurrent)
+		writeLine(0, 0, m.Title(model.Left), m.HorizScroll, stTitle)
+		writeLine(width/2, 0, m.Title(model.Right), m.HorizScroll, stCurrentTitle)
 	}
 
 	for idx := 0; idx < m.GetVisibleItemCount(model.Left); idx++ {
 		logging.LogTrace("ui/out/draw(), idx: ", idx)
 		item := m.GetVisibleItem(model.Left, idx)
-		var st = stNormal
-		switch {
-		case m.IsCurrentRow(model.Left, idx) && item.Selected:
-			st = stCurrentSelected
-		case m.IsCurrentRow(model.Left, idx):
-			st = stCurrent
-		case item.Selected:
-			st = stSelected
-		}
-		writeLine(0, idx+2, item.DisplayString(), m.HorizScroll, st)
+		st := itemStyle(item, m.IsCurrentRow(model.Left, idx))
+		writeLine(0, idx+itemsFirstLine, item.DisplayString(), m.HorizScroll, st)
 	}
 	for idx := 0; idx < m.GetVisibleItemCount(model.Right); idx++ {
 		logging.LogTrace("ui/out/draw(), idx: ", idx)
 		item := m.GetVisibleItem(model.Right, idx)
-		var st = stNormal
-		switch {
-		case m.IsCurrentRow(model.Right, idx) && item.Selected:
-			st = stCurrentSelected
-		case m.IsCurrentRow(model.Right, idx):
-			st = stCurrent
-		case item.Selected:
-			st = stSelected
-		}
-		writeLine(width/2, idx+2, item.DisplayString(), m.HorizScroll, st)
+		st := itemStyle(item, m.IsCurrentRow(model.Right, idx))
+		writeLine(width/2, idx+itemsFirstLine, item.DisplayString(), m.HorizScroll, st)
 	}
 
 	showStatus(&m, m.LastStatus())
 }
 
+// itemStyle returns style used to show item - current row and selected items
+// styles have precedence over modified and down DataPower objects styles.
+func itemStyle(item model.Item, currentRow bool) tcell.Style {
+	switch {
+	case currentRow && item.Selected:
+		return stCurrentSelected
+	case currentRow:
+		return stCurrent
+	case item.Selected:
+		return stSelected
+	case item.IsDown():
+		return stDown
+	case item.IsModified():
+		return stModified
+	default:
+		return stNormal
+	}
+}
+
 // showQuestionDialog shows question dialog on the terminal screen.
 func showQuestionDialog(question, answer string, answerCursorIdx int) {
 	logging.LogDebugf("ui/out/showQuestionDialog('%s', '%s', %d)", question, answer, answerCursorIdx)
@@ -149,12 +248,14 @@ func showQuestionDialog(question, answer string, answerCursorIdx int) {
 	line := question + answer
 	cursorIdx := utf8.RuneCountInString(question) + answerCursorIdx
 
-	writeLine(x, y-2, buildLine("", "*", "", dialogWidth), 0, stNormal)
-	writeLine(x, y-1, buildLine("*", " ", "*", dialogWidth), 0, stNormal)
-	writeLine(x, y, buildLine("*", " ", "*", dialogWidth), 0, stNormal)
-	writeLine(x, y+1, buildLine("*", " ", "*", dialogWidth), 0, stNormal)
-	writeLine(x, y+2, buildLine("", "*", "", dialogWidth), 0, stNormal)
-	writeLineWithCursor(x+2, y, line, 0, stNormal, x+2+cursorIdx, stCursor)
+	writeLine(x, y-2, buildLine("", "*", "", dialogWidth), 0, stDialog)
+	writeLine(x, y-1, buildLine("*", " ", "*", dialogWidth), 0, stDialog)
+	writeLine(x, y, buildLine("*", " ", "*", dialogWidth), 0, stDialog)
+	writeLine(x, y+1, buildLine("*", " ", "*", dialogWidth), 0, stDialog)
+	writeLine(x, y+2, buildLine("", "*", "", dialogWidth), 0, stDialog)
+	writeLineWithCursor(x+2, y, line, 0, stDialog, x+2+cursorIdx, stCursor)
+	questionDialog.x, questionDialog.y, questionDialog.width = x, y, dialogWidth
+	questionDialog.answerX = x + 2 + utf8.RuneCountInString(question)
 
 	Screen.Show()
 }
@@ -170,11 +271,11 @@ func showListSelectionDialog(message string, list []string, selectionIdx int) {
 	dialogWidth := width - 20
 
 	// write top frame line
-	writeLine(x, firstLine, buildLine("", "*", "", dialogWidth), 0, stNormal)
+	writeLine(x, firstLine, buildLine("", "*", "", dialogWidth), 0, stDialog)
 
 	// write message before list
-	writeLine(x, firstLine+1, buildLine("*", " ", "*", dialogWidth), 0, stNormal)
-	writeLine(x+2, firstLine+1, message, 0, stNormal)
+	writeLine(x, firstLine+1, buildLine("*", " ", "*", dialogWidth), 0, stDialog)
+	writeLine(x+2, firstLine+1, message, 0, stDialog)
 
 	textLinesMaxNo := lastLine - firstLine - 2
 	textLinesNo := len(list)
@@ -186,10 +287,14 @@ func showListSelectionDialog(message string, list []string, selectionIdx int) {
 		listScrollVert = selectionIdx - textLinesMaxNo + 1
 	}
 	logging.LogDebugf("ui/out/showListSelectionDialog() %d/%d/%d/%d", textLinesNo, textLinesMaxNo, selectionIdx, listScrollVert)
+	listDialog.x, listDialog.width = x, dialogWidth
+	listDialog.firstLine, listDialog.lastLine = firstLine, lastLine
+	listDialog.textLineStart, listDialog.textLineEnd = textLineStart, textLineEnd
+	listDialog.scroll = listScrollVert
 
 	// write select dialog frame and list items
 	for lineNo := textLineStart; lineNo <= textLineEnd; lineNo++ {
-		writeLine(x, lineNo, buildLine("*", " ", "*", dialogWidth), 0, stNormal)
+		writeLine(x, lineNo, buildLine("*", " ", "*", dialogWidth), 0, stDialog)
 		listIdx := lineNo - textLineStart + listScrollVert
 		if listIdx < len(list) {
 			text := list[listIdx]
@@ -198,15 +303,15 @@ func showListSelectionDialog(message string, list []string, selectionIdx int) {
 				text = text[len(text)-maxTextWidth:]
 			}
 			if listIdx == selectionIdx {
-				writeLine(x+2, lineNo, text, 0, stCurrent)
+				writeLine(x+2, lineNo, text, 0, stDialogSelected)
 			} else {
-				writeLine(x+2, lineNo, text, 0, stNormal)
+				writeLine(x+2, lineNo, text, 0, stDialog)
 			}
 		}
 	}
 
 	// write bottom frame line
-	writeLine(x, lastLine, buildLine("", "*", "", dialogWidth), 0, stNormal)
+	writeLine(x, lastLine, buildLine("", "*", "", dialogWidth), 0, stDialog)
 
 	Screen.Show()
 }
@@ -292,14 +397,21 @@ func showStatus(m *model.Model, status string) {
 		} else {
 			syncStatusSymbol = " "
 		}
-		syncMsg = fmt.Sprintf("%s Sync (%s/'%s' <- '%s') | ", syncStatusSymbol, m.SyncDpDomain, m.SyncDirDp, m.SyncDirLocal)
+		switch {
+		case m.SyncProfile != "":
+			syncMsg = fmt.Sprintf("%s Sync (profile '%s' <- '%s') | ", syncStatusSymbol, m.SyncProfile, m.SyncDirLocal)
+		case m.SyncBidirectional:
+			syncMsg = fmt.Sprintf("%s Sync (%s/'%s' <-> '%s') | ", syncStatusSymbol, m.SyncDpDomain, m.SyncDirDp, m.SyncDirLocal)
+		default:
+			syncMsg = fmt.Sprintf("%s Sync (%s/'%s' <- '%s') | ", syncStatusSymbol, m.SyncDpDomain, m.SyncDirDp, m.SyncDirLocal)
+		}
 	}
 
 	statusMsg := fmt.Sprintf("%s%s%s", syncMsg, filterMsg, status)
 
 	w, h := Screen.Size()
-	writeLine(0, h-1, strings.Repeat(" ", w), m.HorizScroll, stNormal)
-	writeLine(0, h-1, statusMsg, m.HorizScroll, stNormal)
+	writeLine(0, h-1, strings.Repeat(" ", w), m.HorizScroll, stStatus)
+	writeLine(0, h-1, statusMsg, m.HorizScroll, stStatus)
 	Screen.Show()
 }
 
@@ -321,13 +433,15 @@ func showProgressDialog(msg string, progress int) {
 		progressX = x + 2 + (dialogWidth-4)*(99-progress)/50
 		progressY = y + 1
 	}
-	writeLine(x, y-2, buildLine("", "*", "", dialogWidth), 0, stNormal)
-	writeLine(x, y-1, buildLine("*", " ", "*", dialogWidth), 0, stNormal)
-	writeLine(x, y, buildLine("*", " ", "*", dialogWidth), 0, stNormal)
-	writeLine(x, y+1, buildLine("*", " ", "*", dialogWidth), 0, stNormal)
-	writeLine(x, y+2, buildLine("", "*", "", dialogWidth), 0, stNormal)
-	writeLine(progressX, progressY, "****", 0, stNormal)
-	writeLine(x+4, y, msg, 0, stNormal)
+	writeLine(x, y-2, buildLine("", "*", "", dialogWidth), 0, stDialog)
+	writeLine(x, y-1, buildLine("*", " ", "*", dialogWidth), 0, stDialog)
+	writeLine(x, y, buildLine("*", " ", "*", dialogWidth), 0, stDialog)
+	writeLine(x, y+1, buildLine("*", " ", "*", dialogWidth), 0, stDialog)
+	writeLine(x, y+2, buildLine("", "*", "", dialogWidth), 0, stDialog)
+	writeLine(progressX, progressY, "****", 0, stDialog)
+	writeLine(x+4, y, msg, 0, stDialog)
+	cancelHint := " Esc - cancel "
+	writeLine(x+dialogWidth-utf8.RuneCountInString(cancelHint)-2, y+2, cancelHint, 0, stDialog)
 
 	Screen.Show()
 }
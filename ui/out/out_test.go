@@ -44,3 +44,42 @@ func TestScrollLineHoriz(t *testing.T) {
 		}
 	}
 }
+
+func TestDialogPositions(t *testing.T) {
+	questionDialog.x, questionDialog.y, questionDialog.width, questionDialog.answerX = 10, 20, 60, 30
+	listDialog.x, listDialog.width = 10, 60
+	listDialog.firstLine, listDialog.lastLine = 2, 30
+	listDialog.textLineStart, listDialog.textLineEnd = 4, 29
+	listDialog.scroll = 5
+
+	testDataMatrix := []struct {
+		x, y         int
+		answerIdx    int
+		inQuestion   bool
+		listIdx      int
+		inListDialog bool
+	}{
+		{9, 20, -1, false, -1, false},
+		{10, 18, -1, true, 19, true},
+		{29, 20, -1, true, 21, true},
+		{35, 20, 5, true, 21, true},
+		{69, 22, -1, true, 23, true},
+		{70, 20, -1, false, -1, false},
+		{12, 3, -1, false, -1, true},
+		{12, 4, -1, false, 5, true},
+		{12, 31, -1, false, -1, false},
+	}
+
+	for _, row := range testDataMatrix {
+		answerIdx, inQuestion := QuestionDialogAnswerIdxAt(row.x, row.y)
+		if answerIdx != row.answerIdx || inQuestion != row.inQuestion {
+			t.Errorf("for QuestionDialogAnswerIdxAt(%d, %d): got %d, %v, want %d, %v",
+				row.x, row.y, answerIdx, inQuestion, row.answerIdx, row.inQuestion)
+		}
+		listIdx, inListDialog := ListSelectionDialogIdxAt(row.x, row.y)
+		if listIdx != row.listIdx || inListDialog != row.inListDialog {
+			t.Errorf("for ListSelectionDialogIdxAt(%d, %d): got %d, %v, want %d, %v",
+				row.x, row.y, listIdx, inListDialog, row.listIdx, row.inListDialog)
+		}
+	}
+}
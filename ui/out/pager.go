@@ -0,0 +1,401 @@
+package out
+
+import (
+	"fmt"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// pagerTabWidth is number of spaces tab is replaced with in built-in pager.
+const pagerTabWidth = 4
+
+// Pager contains content shown in built-in pager and state of the pager
+// (scroll position, search, line numbers, wrap & pretty print toggles).
+type Pager struct {
+	// Status is message shown in the status line of the pager.
+	Status      string
+	name        string
+	syntax      string
+	original    string
+	lines       []string
+	lineSpans   [][]span
+	rows        []pagerRow
+	width       int
+	height      int
+	topRow      int
+	horizScroll int
+	lineNumbers bool
+	wrap        bool
+	pretty      bool
+	searchText  string
+	search      *regexp.Regexp
+	searchLine  int
+}
+
+// pagerRow is part of content line shown in a single screen line (byte
+// offsets in the line).
+type pagerRow struct {
+	line, start, end int
+}
+
+// NewPager prepares content for showing in built-in pager, content syntax is
+// detected from name extension or content itself.
+func NewPager(name string, content []byte) *Pager {
+	logging.LogDebugf("ui/out/NewPager('%s', ..)", name)
+	original := strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n', r == '\t':
+			return r
+		case r == '\r':
+			return -1
+		case unicode.IsControl(r):
+			return '.'
+		default:
+			return r
+		}
+	}, string(content))
+	p := &Pager{name: strings.TrimPrefix(name, "*."),
+		syntax:     detectSyntax(strings.TrimPrefix(name, "*."), original),
+		original:   original,
+		searchLine: -1}
+	p.setContent(original)
+	return p
+}
+
+// setContent splits content to lines and finds spans highlighted in lines.
+func (p *Pager) setContent(content string) {
+	content = strings.Replace(content, "\t", strings.Repeat(" ", pagerTabWidth), -1)
+	content = strings.TrimSuffix(content, "\n")
+	p.lines = strings.Split(content, "\n")
+	p.lineSpans = make([][]span, len(p.lines))
+
+	spans := highlight(p.syntax, content)
+	lineStart, lineIdx := 0, 0
+	for _, s := range spans {
+		for s.start < s.end {
+			for lineIdx < len(p.lines)-1 && s.start > lineStart+len(p.lines[lineIdx]) {
+				lineStart += len(p.lines[lineIdx]) + 1
+				lineIdx++
+			}
+			lineEnd := lineStart + len(p.lines[lineIdx])
+			end := s.end
+			if end > lineEnd {
+				end = lineEnd
+			}
+			if s.start < end {
+				p.lineSpans[lineIdx] = append(p.lineSpans[lineIdx], span{s.start - lineStart, end - lineStart, s.elem})
+			}
+			if end == s.end || lineIdx == len(p.lines)-1 {
+				break
+			}
+			s.start = lineEnd + 1
+		}
+	}
+
+	p.searchLine = -1
+	p.layout()
+}
+
+// Resize sets size of the screen used by pager.
+func (p *Pager) Resize(width, height int) {
+	if width != p.width || height != p.height {
+		p.width, p.height = width, height
+		p.layout()
+	}
+}
+
+// layout splits lines to screen rows (if wrap is set) and ensures scroll
+// position is valid.
+func (p *Pager) layout() {
+	topLine := 0
+	if p.topRow < len(p.rows) {
+		topLine = p.rows[p.topRow].line
+	}
+
+	p.rows = make([]pagerRow, 0, len(p.lines))
+	textWidth := p.textWidth()
+	for lineIdx, line := range p.lines {
+		if !p.wrap || textWidth < 1 {
+			p.rows = append(p.rows, pagerRow{lineIdx, 0, len(line)})
+			continue
+		}
+		start, runes := 0, 0
+		for byteIdx := range line {
+			if runes == textWidth {
+				p.rows = append(p.rows, pagerRow{lineIdx, start, byteIdx})
+				start, runes = byteIdx, 0
+			}
+			runes++
+		}
+		p.rows = append(p.rows, pagerRow{lineIdx, start, len(line)})
+	}
+
+	p.topRow = p.lineRow(topLine)
+	p.Scroll(0)
+}
+
+// lineRow returns index of the first row of given line.
+func (p *Pager) lineRow(lineIdx int) int {
+	for rowIdx, row := range p.rows {
+		if row.line == lineIdx {
+			return rowIdx
+		}
+	}
+	return 0
+}
+
+// textWidth returns number of columns used to show text (without line numbers).
+func (p *Pager) textWidth() int {
+	return p.width - p.lineNumbersWidth()
+}
+
+// lineNumbersWidth returns number of columns used to show line numbers.
+func (p *Pager) lineNumbersWidth() int {
+	if !p.lineNumbers {
+		return 0
+	}
+	return len(strconv.Itoa(len(p.lines))) + 1
+}
+
+// pageRows returns number of rows shown on one page (screen without title and
+// status line).
+func (p *Pager) pageRows() int {
+	if p.height < 3 {
+		return 1
+	}
+	return p.height - 2
+}
+
+// Scroll scrolls content by given number of rows (up if rows is negative).
+func (p *Pager) Scroll(rows int) {
+	p.topRow += rows
+	if p.topRow > len(p.rows)-p.pageRows() {
+		p.topRow = len(p.rows) - p.pageRows()
+	}
+	if p.topRow < 0 {
+		p.topRow = 0
+	}
+}
+
+// ScrollPage scrolls content by given number of pages (up if pages is negative).
+func (p *Pager) ScrollPage(pages int) {
+	p.Scroll(pages * (p.pageRows() - 1))
+}
+
+// ScrollTop scrolls to the beginning of the content.
+func (p *Pager) ScrollTop() {
+	p.topRow = 0
+}
+
+// ScrollBottom scrolls to the end of the content.
+func (p *Pager) ScrollBottom() {
+	p.Scroll(len(p.rows))
+}
+
+// ScrollHoriz scrolls content horizontally by given number of columns (when
+// lines are not wrapped).
+func (p *Pager) ScrollHoriz(columns int) {
+	if p.wrap {
+		return
+	}
+	p.horizScroll += columns
+	if p.horizScroll < 0 {
+		p.horizScroll = 0
+	}
+}
+
+// ToggleLineNumbers shows or hides line numbers.
+func (p *Pager) ToggleLineNumbers() {
+	p.lineNumbers = !p.lineNumbers
+	p.layout()
+}
+
+// ToggleWrap turns wrapping of long lines on or off.
+func (p *Pager) ToggleWrap() {
+	p.wrap = !p.wrap
+	p.horizScroll = 0
+	p.layout()
+}
+
+// TogglePretty turns pretty printing of XML or JSON content on or off, false
+// is returned if content can't be pretty printed.
+func (p *Pager) TogglePretty() bool {
+	if p.pretty {
+		p.pretty = false
+		p.topRow = 0
+		p.setContent(p.original)
+		return true
+	}
+	pretty, ok := prettyPrint(p.syntax, p.original)
+	if !ok {
+		return false
+	}
+	p.pretty = true
+	p.topRow = 0
+	p.setContent(pretty)
+	return true
+}
+
+// Search searches for (case insensitive) text from the top of the page (or
+// from the last match) and scrolls to the line found, false is returned if
+// text is not found.
+func (p *Pager) Search(text string, reverse bool) bool {
+	if text == "" {
+		p.searchText, p.search = "", nil
+		return false
+	}
+	p.searchText = text
+	p.search = regexp.MustCompile("(?i)" + regexp.QuoteMeta(text))
+	p.searchLine = -1
+	return p.SearchNext(reverse)
+}
+
+// SearchNext searches for next (or previous) line containing text searched
+// for and scrolls to it, false is returned if no more lines are found.
+func (p *Pager) SearchNext(reverse bool) bool {
+	if p.search == nil || len(p.rows) == 0 {
+		return false
+	}
+	firstLine, lastLine := p.visibleLines()
+	fromLine := p.searchLine
+	if fromLine < firstLine || fromLine > lastLine {
+		// Last match is not shown, search from the top of the page.
+		fromLine = firstLine - 1
+		if reverse {
+			fromLine = firstLine
+		}
+	}
+
+	step := 1
+	if reverse {
+		step = -1
+	}
+	for lineIdx := fromLine + step; lineIdx >= 0 && lineIdx < len(p.lines); lineIdx += step {
+		if p.search.MatchString(p.lines[lineIdx]) {
+			p.searchLine = lineIdx
+			p.topRow = p.lineRow(lineIdx)
+			p.Scroll(0)
+			if !p.wrap {
+				p.scrollToMatch(lineIdx)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// scrollToMatch scrolls content horizontally so first match in line is shown.
+func (p *Pager) scrollToMatch(lineIdx int) {
+	match := p.search.FindStringIndex(p.lines[lineIdx])
+	matchStart := utf8.RuneCountInString(p.lines[lineIdx][:match[0]])
+	matchEnd := utf8.RuneCountInString(p.lines[lineIdx][:match[1]])
+	if matchStart < p.horizScroll || matchEnd > p.horizScroll+p.textWidth() {
+		p.horizScroll = matchStart - p.textWidth()/2
+		if p.horizScroll < 0 {
+			p.horizScroll = 0
+		}
+	}
+}
+
+// SearchText returns text which is currently searched for.
+func (p *Pager) SearchText() string {
+	return p.searchText
+}
+
+// visibleLines returns indexes of the first and the last line shown.
+func (p *Pager) visibleLines() (int, int) {
+	if len(p.rows) == 0 {
+		return 0, -1
+	}
+	lastRow := p.topRow + p.pageRows() - 1
+	if lastRow >= len(p.rows) {
+		lastRow = len(p.rows) - 1
+	}
+	return p.rows[p.topRow].line, p.rows[lastRow].line
+}
+
+// positionString returns position of shown content (lines shown and
+// percentage).
+func (p *Pager) positionString() string {
+	firstLine, lastLine := p.visibleLines()
+	return fmt.Sprintf("%d-%d/%d %d%%", firstLine+1, lastLine+1, len(p.lines), (lastLine+1)*100/len(p.lines))
+}
+
+// DrawPager shows built-in pager on the terminal screen.
+func DrawPager(p *Pager) {
+	logging.LogDebugf("ui/out/DrawPager('%s')", p.name)
+	width, height := Screen.Size()
+	p.Resize(width, height)
+
+	Screen.Clear()
+	Screen.SetStyle(stNormal)
+
+	title := p.name
+	if p.pretty {
+		title += " (pretty printed)"
+	}
+	writeLine(0, 0, strings.Repeat(" ", width), 0, stCurrentTitle)
+	writeLine(0, 0, title, 0, stCurrentTitle)
+	position := p.positionString()
+	writeLine(width-utf8.RuneCountInString(position)-1, 0, position, 0, stCurrentTitle)
+
+	lnWidth := p.lineNumbersWidth()
+	for screenRow := 0; screenRow < p.pageRows() && p.topRow+screenRow < len(p.rows); screenRow++ {
+		row := p.rows[p.topRow+screenRow]
+		y := screenRow + 1
+		if lnWidth > 0 && row.start == 0 {
+			lineNo := strconv.Itoa(row.line + 1)
+			writeLine(lnWidth-1-len(lineNo), y, lineNo, 0, stLineNumber)
+		}
+		p.drawRow(lnWidth, y, row)
+	}
+
+	writeLine(0, height-1, strings.Repeat(" ", width), 0, stStatus)
+	writeLine(0, height-1, p.Status, 0, stStatus)
+
+	Screen.Show()
+}
+
+// drawRow draws single row of content with syntax and search highlighting.
+func (p *Pager) drawRow(x, y int, row pagerRow) {
+	line := p.lines[row.line]
+	spans := p.lineSpans[row.line]
+	var matches [][]int
+	if p.search != nil {
+		matches = p.search.FindAllStringIndex(line, -1)
+	}
+
+	horizScroll := p.horizScroll
+	spanIdx, matchIdx := 0, 0
+	for byteIdx, r := range line[row.start:row.end] {
+		byteIdx += row.start
+		if horizScroll > 0 {
+			horizScroll--
+			continue
+		}
+		if x >= p.width {
+			break
+		}
+
+		style := stNormal
+		for spanIdx < len(spans) && spans[spanIdx].end <= byteIdx {
+			spanIdx++
+		}
+		if spanIdx < len(spans) && spans[spanIdx].start <= byteIdx {
+			style = stSyntax[spans[spanIdx].elem]
+		}
+		for matchIdx < len(matches) && matches[matchIdx][1] <= byteIdx {
+			matchIdx++
+		}
+		if matchIdx < len(matches) && matches[matchIdx][0] <= byteIdx {
+			style = stSearchMatch
+		}
+
+		Screen.SetContent(x, y, r, nil, style)
+		x++
+	}
+}
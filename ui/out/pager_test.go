@@ -0,0 +1,72 @@
+package out
+
+import (
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"testing"
+)
+
+func TestPagerContent(t *testing.T) {
+	p := NewPager("*.test.xml", []byte("<a>\r\n\t<!-- multi\nline -->\x01\n</a>\n"))
+	assert.DeepEqual(t, "Pager name", p.name, "test.xml")
+	assert.DeepEqual(t, "Pager lines", p.lines, []string{"<a>", "    <!-- multi", "line -->.", "</a>"})
+	assert.DeepEqual(t, "Pager line spans", p.lineSpans, [][]span{
+		{{0, 2, elemSyntaxTag}, {2, 3, elemSyntaxTag}},
+		{{4, 14, elemSyntaxComment}},
+		{{0, 8, elemSyntaxComment}},
+		{{0, 3, elemSyntaxTag}, {3, 4, elemSyntaxTag}}})
+
+	assert.DeepEqual(t, "TogglePretty()", p.TogglePretty(), true)
+	assert.DeepEqual(t, "Pager pretty lines", p.lines, []string{"<a>", "  <!-- multi", "line -->", "  .", "</a>"})
+	assert.DeepEqual(t, "TogglePretty() back", p.TogglePretty(), true)
+	assert.DeepEqual(t, "Pager original lines", p.lines[1], "    <!-- multi")
+
+	p = NewPager("test.txt", []byte("text"))
+	assert.DeepEqual(t, "TogglePretty() text", p.TogglePretty(), false)
+}
+
+func TestPagerScrollAndWrap(t *testing.T) {
+	p := NewPager("test.txt", []byte("1\n2\n3\n4567890\n5\n6\n7\n8\n9\n10\n"))
+	p.Resize(5, 6)
+	assert.DeepEqual(t, "rows", len(p.rows), 10)
+	p.ScrollPage(1)
+	assert.DeepEqual(t, "ScrollPage(1)", p.topRow, 3)
+	p.ScrollBottom()
+	assert.DeepEqual(t, "ScrollBottom()", p.topRow, 6)
+	assert.DeepEqual(t, "positionString()", p.positionString(), "7-10/10 100%")
+	p.Scroll(-2)
+	assert.DeepEqual(t, "Scroll(-2)", p.topRow, 4)
+	p.ScrollTop()
+	assert.DeepEqual(t, "ScrollTop()", p.topRow, 0)
+	p.ScrollHoriz(-3)
+	assert.DeepEqual(t, "ScrollHoriz(-3)", p.horizScroll, 0)
+	p.ScrollHoriz(3)
+	assert.DeepEqual(t, "ScrollHoriz(3)", p.horizScroll, 3)
+
+	p.ToggleWrap()
+	assert.DeepEqual(t, "wrapped rows", p.rows[3:6], []pagerRow{{3, 0, 5}, {3, 5, 7}, {4, 0, 1}})
+	assert.DeepEqual(t, "wrap resets horizontal scroll", p.horizScroll, 0)
+	p.ToggleLineNumbers()
+	assert.DeepEqual(t, "wrapped rows with line numbers", p.rows[3:6], []pagerRow{{3, 0, 2}, {3, 2, 4}, {3, 4, 6}})
+	p.ScrollBottom()
+	assert.DeepEqual(t, "wrapped ScrollBottom()", p.topRow, len(p.rows)-4)
+	p.ToggleWrap()
+	assert.DeepEqual(t, "unwrapped keeps top line", p.rows[p.topRow].line, 6)
+}
+
+func TestPagerSearch(t *testing.T) {
+	p := NewPager("test.txt", []byte("abc\nxyz\nABC\nxyz\nabc\n"))
+	p.Resize(20, 4)
+	assert.DeepEqual(t, "Search()", p.Search("Bc", false), true)
+	assert.DeepEqual(t, "Search() line", p.searchLine, 0)
+	assert.DeepEqual(t, "SearchNext()", p.SearchNext(false), true)
+	assert.DeepEqual(t, "SearchNext() line", p.searchLine, 2)
+	assert.DeepEqual(t, "SearchNext()", p.SearchNext(false), true)
+	assert.DeepEqual(t, "SearchNext() line", p.searchLine, 4)
+	assert.DeepEqual(t, "SearchNext() at end", p.SearchNext(false), false)
+	assert.DeepEqual(t, "SearchNext(reverse)", p.SearchNext(true), true)
+	assert.DeepEqual(t, "SearchNext(reverse) line", p.searchLine, 2)
+	assert.DeepEqual(t, "SearchText()", p.SearchText(), "Bc")
+	assert.DeepEqual(t, "Search() not found", p.Search("q", false), false)
+	assert.DeepEqual(t, "Search() empty", p.Search("", false), false)
+	assert.DeepEqual(t, "SearchNext() without search", p.SearchNext(false), false)
+}
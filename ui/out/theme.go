@@ -0,0 +1,323 @@
+package out
+
+import (
+	"fmt"
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"github.com/gdamore/tcell"
+	"sort"
+	"strings"
+)
+
+// UI elements which can be styled by theme.
+const (
+	elemNormal          = "normal"
+	elemTitle           = "title"
+	elemCurrentTitle    = "currentTitle"
+	elemCurrent         = "current"
+	elemSelected        = "selected"
+	elemCurrentSelected = "currentSelected"
+	elemModified        = "modified"
+	elemDown            = "down"
+	elemDialog          = "dialog"
+	elemDialogSelected  = "dialogSelected"
+	elemStatus          = "status"
+	elemLineNumber      = "lineNumber"
+	elemSearchMatch     = "searchMatch"
+	elemSyntaxTag       = "syntaxTag"
+	elemSyntaxAttr      = "syntaxAttr"
+	elemSyntaxString    = "syntaxString"
+	elemSyntaxComment   = "syntaxComment"
+	elemSyntaxKeyword   = "syntaxKeyword"
+	elemSyntaxNumber    = "syntaxNumber"
+	elemDiffHeader      = "diffHeader"
+	elemDiffHunk        = "diffHunk"
+	elemDiffAdded       = "diffAdded"
+	elemDiffRemoved     = "diffRemoved"
+)
+
+// syntaxElems contains UI elements used for syntax highlighting in pager.
+var syntaxElems = []string{elemSyntaxTag, elemSyntaxAttr, elemSyntaxString,
+	elemSyntaxComment, elemSyntaxKeyword, elemSyntaxNumber}
+
+// diffElems contains UI elements used for highlighting changes in diff viewer.
+var diffElems = []string{elemDiffHeader, elemDiffHunk, elemDiffAdded, elemDiffRemoved}
+
+// themeStyle contains colors and attributes of UI element.
+type themeStyle struct {
+	fg    tcell.Color
+	bg    tcell.Color
+	attrs tcell.AttrMask
+}
+
+// themeAttrs contains attributes which can be used in theme configuration.
+var themeAttrs = map[string]tcell.AttrMask{
+	"bold":      tcell.AttrBold,
+	"underline": tcell.AttrUnderline,
+	"reverse":   tcell.AttrReverse,
+	"dim":       tcell.AttrDim,
+}
+
+const (
+	cDefault = tcell.ColorDefault
+	aNone    = tcell.AttrNone
+)
+
+// themePresets contains styles of all UI elements for each predefined theme.
+var themePresets = map[string]map[string]themeStyle{
+	"default": {
+		elemNormal:          {cDefault, cDefault, aNone},
+		elemTitle:           {cDefault, cDefault, aNone},
+		elemCurrentTitle:    {cDefault, tcell.ColorGreen, aNone},
+		elemCurrent:         {cDefault, tcell.ColorGreen, aNone},
+		elemSelected:        {tcell.ColorRed, cDefault, aNone},
+		elemCurrentSelected: {tcell.ColorRed, tcell.ColorGreen, aNone},
+		elemModified:        {tcell.ColorOlive, cDefault, aNone},
+		elemDown:            {tcell.ColorPurple, cDefault, aNone},
+		elemDialog:          {cDefault, cDefault, aNone},
+		elemDialogSelected:  {cDefault, tcell.ColorGreen, aNone},
+		elemStatus:          {cDefault, cDefault, aNone},
+		elemLineNumber:      {tcell.ColorGray, cDefault, aNone},
+		elemSearchMatch:     {tcell.ColorBlack, tcell.ColorYellow, aNone},
+		elemSyntaxTag:       {tcell.ColorTeal, cDefault, aNone},
+		elemSyntaxAttr:      {tcell.ColorOlive, cDefault, aNone},
+		elemSyntaxString:    {tcell.ColorGreen, cDefault, aNone},
+		elemSyntaxComment:   {tcell.ColorGray, cDefault, aNone},
+		elemSyntaxKeyword:   {tcell.ColorPurple, cDefault, tcell.AttrBold},
+		elemSyntaxNumber:    {tcell.ColorMaroon, cDefault, aNone},
+		elemDiffHeader:      {cDefault, cDefault, tcell.AttrBold},
+		elemDiffHunk:        {tcell.ColorTeal, cDefault, aNone},
+		elemDiffAdded:       {tcell.ColorGreen, cDefault, aNone},
+		elemDiffRemoved:     {tcell.ColorRed, cDefault, aNone},
+	},
+	"dark": {
+		elemNormal:          {tcell.ColorSilver, tcell.ColorBlack, aNone},
+		elemTitle:           {tcell.ColorSilver, tcell.ColorNavy, aNone},
+		elemCurrentTitle:    {tcell.ColorWhite, tcell.ColorTeal, tcell.AttrBold},
+		elemCurrent:         {tcell.ColorWhite, tcell.ColorTeal, aNone},
+		elemSelected:        {tcell.ColorYellow, tcell.ColorBlack, tcell.AttrBold},
+		elemCurrentSelected: {tcell.ColorYellow, tcell.ColorTeal, tcell.AttrBold},
+		elemModified:        {tcell.ColorOrange, tcell.ColorBlack, aNone},
+		elemDown:            {tcell.ColorRed, tcell.ColorBlack, aNone},
+		elemDialog:          {tcell.ColorWhite, tcell.ColorNavy, aNone},
+		elemDialogSelected:  {tcell.ColorBlack, tcell.ColorAqua, aNone},
+		elemStatus:          {tcell.ColorBlack, tcell.ColorSilver, aNone},
+		elemLineNumber:      {tcell.ColorGray, tcell.ColorBlack, aNone},
+		elemSearchMatch:     {tcell.ColorBlack, tcell.ColorYellow, aNone},
+		elemSyntaxTag:       {tcell.ColorAqua, tcell.ColorBlack, aNone},
+		elemSyntaxAttr:      {tcell.ColorYellow, tcell.ColorBlack, aNone},
+		elemSyntaxString:    {tcell.ColorLime, tcell.ColorBlack, aNone},
+		elemSyntaxComment:   {tcell.ColorGray, tcell.ColorBlack, aNone},
+		elemSyntaxKeyword:   {tcell.ColorFuchsia, tcell.ColorBlack, tcell.AttrBold},
+		elemSyntaxNumber:    {tcell.ColorOrange, tcell.ColorBlack, aNone},
+		elemDiffHeader:      {tcell.ColorWhite, tcell.ColorBlack, tcell.AttrBold},
+		elemDiffHunk:        {tcell.ColorAqua, tcell.ColorBlack, aNone},
+		elemDiffAdded:       {tcell.ColorLime, tcell.ColorBlack, aNone},
+		elemDiffRemoved:     {tcell.ColorRed, tcell.ColorBlack, aNone},
+	},
+	"light": {
+		elemNormal:          {tcell.ColorBlack, tcell.ColorWhite, aNone},
+		elemTitle:           {tcell.ColorBlack, tcell.ColorSilver, aNone},
+		elemCurrentTitle:    {tcell.ColorWhite, tcell.ColorBlue, tcell.AttrBold},
+		elemCurrent:         {tcell.ColorWhite, tcell.ColorBlue, aNone},
+		elemSelected:        {tcell.ColorMaroon, tcell.ColorWhite, tcell.AttrBold},
+		elemCurrentSelected: {tcell.ColorYellow, tcell.ColorBlue, tcell.AttrBold},
+		elemModified:        {tcell.ColorOlive, tcell.ColorWhite, aNone},
+		elemDown:            {tcell.ColorPurple, tcell.ColorWhite, aNone},
+		elemDialog:          {tcell.ColorBlack, tcell.ColorSilver, aNone},
+		elemDialogSelected:  {tcell.ColorWhite, tcell.ColorBlue, aNone},
+		elemStatus:          {tcell.ColorWhite, tcell.ColorGray, aNone},
+		elemLineNumber:      {tcell.ColorGray, tcell.ColorWhite, aNone},
+		elemSearchMatch:     {tcell.ColorBlack, tcell.ColorYellow, aNone},
+		elemSyntaxTag:       {tcell.ColorNavy, tcell.ColorWhite, aNone},
+		elemSyntaxAttr:      {tcell.ColorMaroon, tcell.ColorWhite, aNone},
+		elemSyntaxString:    {tcell.ColorGreen, tcell.ColorWhite, aNone},
+		elemSyntaxComment:   {tcell.ColorGray, tcell.ColorWhite, aNone},
+		elemSyntaxKeyword:   {tcell.ColorPurple, tcell.ColorWhite, tcell.AttrBold},
+		elemSyntaxNumber:    {tcell.ColorTeal, tcell.ColorWhite, aNone},
+		elemDiffHeader:      {tcell.ColorBlack, tcell.ColorWhite, tcell.AttrBold},
+		elemDiffHunk:        {tcell.ColorTeal, tcell.ColorWhite, aNone},
+		elemDiffAdded:       {tcell.ColorGreen, tcell.ColorWhite, aNone},
+		elemDiffRemoved:     {tcell.ColorMaroon, tcell.ColorWhite, aNone},
+	},
+	"high-contrast": {
+		elemNormal:          {tcell.ColorWhite, tcell.ColorBlack, aNone},
+		elemTitle:           {tcell.ColorWhite, tcell.ColorBlack, tcell.AttrUnderline},
+		elemCurrentTitle:    {tcell.ColorBlack, tcell.ColorYellow, tcell.AttrBold},
+		elemCurrent:         {tcell.ColorBlack, tcell.ColorWhite, tcell.AttrBold},
+		elemSelected:        {tcell.ColorYellow, tcell.ColorBlack, tcell.AttrBold | tcell.AttrUnderline},
+		elemCurrentSelected: {tcell.ColorBlack, tcell.ColorYellow, tcell.AttrBold | tcell.AttrUnderline},
+		elemModified:        {tcell.ColorAqua, tcell.ColorBlack, tcell.AttrBold},
+		elemDown:            {tcell.ColorFuchsia, tcell.ColorBlack, tcell.AttrBold},
+		elemDialog:          {tcell.ColorWhite, tcell.ColorBlack, tcell.AttrBold},
+		elemDialogSelected:  {tcell.ColorBlack, tcell.ColorYellow, tcell.AttrBold},
+		elemStatus:          {tcell.ColorBlack, tcell.ColorWhite, tcell.AttrBold},
+		elemLineNumber:      {tcell.ColorYellow, tcell.ColorBlack, aNone},
+		elemSearchMatch:     {tcell.ColorBlack, tcell.ColorYellow, tcell.AttrBold},
+		elemSyntaxTag:       {tcell.ColorAqua, tcell.ColorBlack, tcell.AttrBold},
+		elemSyntaxAttr:      {tcell.ColorYellow, tcell.ColorBlack, aNone},
+		elemSyntaxString:    {tcell.ColorLime, tcell.ColorBlack, aNone},
+		elemSyntaxComment:   {tcell.ColorSilver, tcell.ColorBlack, aNone},
+		elemSyntaxKeyword:   {tcell.ColorFuchsia, tcell.ColorBlack, tcell.AttrBold},
+		elemSyntaxNumber:    {tcell.ColorWhite, tcell.ColorBlack, tcell.AttrBold},
+		elemDiffHeader:      {tcell.ColorWhite, tcell.ColorBlack, tcell.AttrBold | tcell.AttrUnderline},
+		elemDiffHunk:        {tcell.ColorAqua, tcell.ColorBlack, tcell.AttrBold},
+		elemDiffAdded:       {tcell.ColorLime, tcell.ColorBlack, tcell.AttrBold},
+		elemDiffRemoved:     {tcell.ColorRed, tcell.ColorBlack, tcell.AttrBold},
+	},
+	"monochrome": {
+		elemNormal:          {cDefault, cDefault, aNone},
+		elemTitle:           {cDefault, cDefault, tcell.AttrUnderline},
+		elemCurrentTitle:    {cDefault, cDefault, tcell.AttrReverse},
+		elemCurrent:         {cDefault, cDefault, tcell.AttrReverse},
+		elemSelected:        {cDefault, cDefault, tcell.AttrBold},
+		elemCurrentSelected: {cDefault, cDefault, tcell.AttrReverse | tcell.AttrBold},
+		elemModified:        {cDefault, cDefault, tcell.AttrUnderline},
+		elemDown:            {cDefault, cDefault, tcell.AttrDim},
+		elemDialog:          {cDefault, cDefault, aNone},
+		elemDialogSelected:  {cDefault, cDefault, tcell.AttrReverse},
+		elemStatus:          {cDefault, cDefault, tcell.AttrReverse},
+		elemLineNumber:      {cDefault, cDefault, tcell.AttrDim},
+		elemSearchMatch:     {cDefault, cDefault, tcell.AttrReverse},
+		elemSyntaxTag:       {cDefault, cDefault, tcell.AttrBold},
+		elemSyntaxAttr:      {cDefault, cDefault, aNone},
+		elemSyntaxString:    {cDefault, cDefault, tcell.AttrUnderline},
+		elemSyntaxComment:   {cDefault, cDefault, tcell.AttrDim},
+		elemSyntaxKeyword:   {cDefault, cDefault, tcell.AttrBold | tcell.AttrUnderline},
+		elemSyntaxNumber:    {cDefault, cDefault, aNone},
+		elemDiffHeader:      {cDefault, cDefault, tcell.AttrBold | tcell.AttrUnderline},
+		elemDiffHunk:        {cDefault, cDefault, tcell.AttrDim},
+		elemDiffAdded:       {cDefault, cDefault, tcell.AttrBold},
+		elemDiffRemoved:     {cDefault, cDefault, tcell.AttrReverse},
+	},
+}
+
+// defaultThemePreset is theme preset used if configured preset is not set.
+const defaultThemePreset = "default"
+
+// SetTheme sets styles used to draw UI elements from given theme
+// configuration. If preset or some of element styles can't be used error is
+// returned and default styles are used instead.
+func SetTheme(theme config.Theme) error {
+	logging.LogDebugf("ui/out/SetTheme(%v)", theme)
+	styles, err := buildTheme(theme)
+	stNormal = styles[elemNormal]
+	stTitle = styles[elemTitle]
+	stCurrentTitle = styles[elemCurrentTitle]
+	stCurrent = styles[elemCurrent]
+	stSelected = styles[elemSelected]
+	stCurrentSelected = styles[elemCurrentSelected]
+	stModified = styles[elemModified]
+	stDown = styles[elemDown]
+	stDialog = styles[elemDialog]
+	stDialogSelected = styles[elemDialogSelected]
+	stStatus = styles[elemStatus]
+	stLineNumber = styles[elemLineNumber]
+	stSearchMatch = styles[elemSearchMatch]
+	stSyntax = elemStyles(styles, syntaxElems)
+	stDiff = elemStyles(styles, diffElems)
+	stCursor = stDialog.Reverse(true)
+	return err
+}
+
+// buildTheme prepares styles of all UI elements from given theme
+// configuration (preset styles with element overrides applied).
+func buildTheme(theme config.Theme) (map[string]tcell.Style, error) {
+	problems := make([]string, 0)
+	presetName := theme.Preset
+	if presetName == "" {
+		presetName = defaultThemePreset
+	}
+	preset, ok := themePresets[presetName]
+	if !ok {
+		problems = append(problems, fmt.Sprintf("unknown preset '%s'", presetName))
+		preset = themePresets[defaultThemePreset]
+	}
+
+	styles := make(map[string]tcell.Style, len(preset))
+	for elemName, presetStyle := range preset {
+		elemStyle := presetStyle
+		if override, ok := theme.Elements[elemName]; ok {
+			var err error
+			elemStyle, err = overrideStyle(presetStyle, override)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("element '%s': %v", elemName, err))
+				elemStyle = presetStyle
+			}
+		}
+		styles[elemName] = tcell.StyleDefault.
+			Foreground(elemStyle.fg).Background(elemStyle.bg).
+			Bold(elemStyle.attrs&tcell.AttrBold != 0).
+			Underline(elemStyle.attrs&tcell.AttrUnderline != 0).
+			Reverse(elemStyle.attrs&tcell.AttrReverse != 0).
+			Dim(elemStyle.attrs&tcell.AttrDim != 0)
+	}
+	for elemName := range theme.Elements {
+		if _, ok := preset[elemName]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown element '%s'", elemName))
+		}
+	}
+
+	if len(problems) != 0 {
+		sort.Strings(problems)
+		return styles, errs.Errorf("Theme configuration: %s.", strings.Join(problems, ", "))
+	}
+	return styles, nil
+}
+
+// elemStyles returns styles of given UI elements (used for syntax and diff
+// highlighting).
+func elemStyles(styles map[string]tcell.Style, elems []string) map[string]tcell.Style {
+	result := make(map[string]tcell.Style, len(elems))
+	for _, elemName := range elems {
+		result[elemName] = styles[elemName]
+	}
+	return result
+}
+
+// overrideStyle applies configured element style to preset style.
+func overrideStyle(presetStyle themeStyle, override config.ThemeStyle) (themeStyle, error) {
+	result := presetStyle
+	var err error
+	if override.Fg != "" {
+		result.fg, err = parseColor(override.Fg)
+		if err != nil {
+			return presetStyle, err
+		}
+	}
+	if override.Bg != "" {
+		result.bg, err = parseColor(override.Bg)
+		if err != nil {
+			return presetStyle, err
+		}
+	}
+	if override.Attrs != nil {
+		result.attrs = aNone
+		for _, attrName := range override.Attrs {
+			attr, ok := themeAttrs[strings.ToLower(attrName)]
+			if !ok {
+				return presetStyle, errs.Errorf("unknown attribute '%s'", attrName)
+			}
+			result.attrs |= attr
+		}
+	}
+	return result, nil
+}
+
+// parseColor parses color name (like "red", "navy" or "default") or hex color
+// value (like "#ff8800").
+func parseColor(name string) (tcell.Color, error) {
+	name = strings.ToLower(name)
+	if name == "default" {
+		return tcell.ColorDefault, nil
+	}
+	if _, ok := tcell.ColorNames[name]; !ok && !(len(name) == 7 && name[0] == '#') {
+		return tcell.ColorDefault, errs.Errorf("unknown color '%s'", name)
+	}
+	color := tcell.GetColor(name)
+	if color == tcell.ColorDefault {
+		return color, errs.Errorf("unknown color '%s'", name)
+	}
+	return color, nil
+}
@@ -0,0 +1,56 @@
+package out
+
+import (
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"github.com/gdamore/tcell"
+	"testing"
+)
+
+func TestBuildTheme(t *testing.T) {
+	for presetName, preset := range themePresets {
+		styles, err := buildTheme(config.Theme{Preset: presetName})
+		assert.DeepEqual(t, "buildTheme("+presetName+") error", err, nil)
+		assert.DeepEqual(t, "buildTheme("+presetName+") elements", len(styles), len(preset))
+	}
+
+	styles, err := buildTheme(config.Theme{Preset: "dark", Elements: map[string]config.ThemeStyle{
+		elemCurrent:  {Fg: "black", Bg: "#ff8800", Attrs: []string{"Bold", "underline"}},
+		elemSelected: {Bg: "navy"},
+		elemStatus:   {Attrs: []string{}}}})
+	assert.DeepEqual(t, "buildTheme() error", err, nil)
+	assert.DeepEqual(t, "buildTheme() current", styles[elemCurrent],
+		tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.NewHexColor(0xff8800)).Bold(true).Underline(true))
+	assert.DeepEqual(t, "buildTheme() selected", styles[elemSelected],
+		tcell.StyleDefault.Foreground(tcell.ColorYellow).Background(tcell.ColorNavy).Bold(true))
+	assert.DeepEqual(t, "buildTheme() status", styles[elemStatus],
+		tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorSilver))
+
+	styles, err = buildTheme(config.Theme{Preset: "unknown", Elements: map[string]config.ThemeStyle{
+		elemCurrent: {Fg: "nocolor"},
+		"titles":    {Fg: "red"}}})
+	assert.DeepEqual(t, "buildTheme() wrong config error", err.Error(),
+		"Theme configuration: element 'current': unknown color 'nocolor', unknown element 'titles', unknown preset 'unknown'.")
+	defaultStyles, _ := buildTheme(config.Theme{})
+	assert.DeepEqual(t, "buildTheme() wrong config uses default", styles, defaultStyles)
+}
+
+func TestParseColor(t *testing.T) {
+	testDataMatrix := []struct {
+		name  string
+		color tcell.Color
+		ok    bool
+	}{
+		{"red", tcell.ColorRed, true},
+		{"Navy", tcell.ColorNavy, true},
+		{"default", tcell.ColorDefault, true},
+		{"#000000", tcell.NewHexColor(0), true},
+		{"#zzzzzz", tcell.ColorDefault, false},
+		{"reddish", tcell.ColorDefault, false},
+	}
+	for _, row := range testDataMatrix {
+		color, err := parseColor(row.name)
+		assert.DeepEqual(t, "parseColor("+row.name+")", color, row.color)
+		assert.DeepEqual(t, "parseColor("+row.name+") ok", err == nil, row.ok)
+	}
+}
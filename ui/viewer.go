@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/croz-ltd/dpcmder/config"
+	"github.com/croz-ltd/dpcmder/extprogs"
+	"github.com/croz-ltd/dpcmder/help"
+	"github.com/croz-ltd/dpcmder/ui/out"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"github.com/gdamore/tcell"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// pagerScrollColumns is number of columns pager scrolls horizontally.
+const pagerScrollColumns = 10
+
+// viewContent shows content in external viewer if "Viewer" command is
+// configured or in built-in pager otherwise.
+func viewContent(name string, content []byte) error {
+	if config.Conf.Cmd.Viewer != "" {
+		return extprogs.View(name, content)
+	}
+	showPager(name, content)
+	return nil
+}
+
+// viewLocalFile shows local file in external viewer if "Viewer" command is
+// configured or in built-in pager otherwise.
+func viewLocalFile(filePath string) error {
+	if config.Conf.Cmd.Viewer != "" {
+		return extprogs.ViewFile(filePath)
+	}
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	showPager(filepath.Base(filePath), content)
+	return nil
+}
+
+// showHelp shows dpcmder help.
+func showHelp() error {
+	return viewContent("Help", []byte(help.Help))
+}
+
+// showPager shows content in built-in pager until user closes it.
+func showPager(name string, content []byte) {
+	logging.LogDebugf("ui/showPager('%s', ..)", name)
+	// When progress dialog is shown we don't won't it to hid our pager.
+	progressDialogSession.waitUserInput = true
+	defer func() { progressDialogSession.waitUserInput = false }()
+
+	pager := out.NewPager(name, content)
+	status := ""
+	for {
+		pager.Status = status
+		if status == "" {
+			pager.Status = pagerHint()
+		}
+		out.DrawPager(pager)
+
+		status = ""
+		switch event := pollEvent().(type) {
+		case *tcell.EventKey:
+			var quit bool
+			quit, status = processPagerInput(pager, event)
+			if quit {
+				return
+			}
+		case *tcell.EventMouse:
+			pager.Scroll(mouseWheel(event) * wheelScrollRows)
+		}
+	}
+}
+
+// processPagerInput processes user's input to built-in pager, returns true if
+// pager should be closed and status message which should be shown.
+func processPagerInput(pager *out.Pager, keyEvent *tcell.EventKey) (bool, string) {
+	logging.LogDebugf("ui/processPagerInput(%#v)", keyEvent)
+	switch keyEvent.Rune() {
+	case 'q', 'Q':
+		return true, ""
+	case '#':
+		pager.ToggleLineNumbers()
+		return false, ""
+	case 'w':
+		pager.ToggleWrap()
+		return false, ""
+	case 'p':
+		if !pager.TogglePretty() {
+			return false, "Content can't be pretty printed (only valid XML and JSON can be)."
+		}
+		return false, ""
+	case ' ':
+		pager.ScrollPage(1)
+		return false, ""
+	}
+	if keyEvent.Key() == tcell.KeyEsc {
+		return true, ""
+	}
+
+	switch keyAction(keyEvent) {
+	case "quit", "view", "help":
+		return true, ""
+	case "up":
+		pager.Scroll(-1)
+	case "down", "enter":
+		pager.Scroll(1)
+	case "pageUp":
+		pager.ScrollPage(-1)
+	case "pageDown":
+		pager.ScrollPage(1)
+	case "top":
+		pager.ScrollTop()
+	case "bottom":
+		pager.ScrollBottom()
+	case "scrollLeft":
+		pager.ScrollHoriz(-pagerScrollColumns)
+	case "scrollRight":
+		pager.ScrollHoriz(pagerScrollColumns)
+	case "search":
+		dialogResult := askUserInput("Search for: ", pager.SearchText(), false)
+		if dialogResult.dialogSubmitted && !pager.Search(dialogResult.inputAnswer, false) {
+			return false, fmt.Sprintf("Text '%s' not found.", dialogResult.inputAnswer)
+		}
+	case "searchNext", "searchPrev":
+		if pager.SearchText() == "" {
+			return false, fmt.Sprintf("Use %s to search for text first.", actionKeys("search"))
+		}
+		if !pager.SearchNext(keyAction(keyEvent) == "searchPrev") {
+			return false, fmt.Sprintf("No more lines with '%s' found.", pager.SearchText())
+		}
+	}
+
+	return false, ""
+}
+
+// pagerHint returns keys hint shown in the status line of built-in pager.
+func pagerHint() string {
+	return fmt.Sprintf("q - quit, %s - search, %s/%s - next/previous, # - line numbers, w - wrap, p - pretty print",
+		actionKeys("search"), actionKeys("searchNext"), actionKeys("searchPrev"))
+}
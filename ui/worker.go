@@ -2,9 +2,15 @@ package ui
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -17,6 +23,7 @@ import (
 	"github.com/croz-ltd/dpcmder/repo/localfs"
 	"github.com/croz-ltd/dpcmder/ui/out"
 	"github.com/croz-ltd/dpcmder/utils/errs"
+	"github.com/croz-ltd/dpcmder/utils/ignore"
 	"github.com/croz-ltd/dpcmder/utils/logging"
 	"github.com/gdamore/tcell"
 )
@@ -89,8 +96,107 @@ type progressDialogInfo struct {
 // progressDialogSession contains progress dialog info for long running actions.
 var progressDialogSession = progressDialogInfo{}
 
+// currentActionCancel cancels context of the user action currently processed
+// (set while ProcessInputEvent is running).
+var currentActionCancel context.CancelFunc
+var currentActionMutex sync.Mutex
+
+// copyIgnoreInfo is structure containing ignore patterns used while copying
+// directory recursively and number of entries skipped.
+type copyIgnoreInfo struct {
+	matcher  ignore.Matcher
+	rootPath string
+	skipped  int
+}
+
+// copySession contains state of one copy operation - DataPower repo used,
+// ignore patterns and job reporting progress if copy runs in background (copy
+// started by user directly shows progress dialog and asks for confirmations).
+// Files are transferred by copy workers (see copy.go).
+type copySession struct {
+	dpRepo         dpCopyRepo
+	cloneDpRepo    func() repo.Repo
+	dpViewMode     model.DpViewMode
+	ignore         copyIgnoreInfo
+	job            *job
+	conflictPolicy copyConflictPolicy
+	sameFiles      map[string]bool
+	targetLists    map[string]model.ItemList
+	transfers      chan copyTransfer
+	workersDone    sync.WaitGroup
+	uploadRepo     repo.Repo
+	progress       copyProgress
+}
+
+// dpCopyRepo contains DataPower repo methods used to copy DataPower objects
+// and to export DataPower domains and appliances.
+type dpCopyRepo interface {
+	GetManagementInterface() string
+	GetObject(ctx context.Context, dpDomain, objectClass, objectName string, persisted bool) ([]byte, error)
+	SetObject(ctx context.Context, dpDomain, objectClass, objectName string, objectContent []byte, existingObject bool) error
+	ParseObjectClassAndName(objectBytes []byte) (objectClass, objectName string, err error)
+	RenameObject(dpObject []byte, objectName string) ([]byte, error)
+	ExportDomain(ctx context.Context, domainName, exportFileName string) ([]byte, error)
+	ExportAppliance(ctx context.Context, applianceConfigName, exportFileName string) ([]byte, error)
+}
+
+// dpSyncRepo contains DataPower repo methods used to sync local filesystem
+// to DataPower.
+type dpSyncRepo interface {
+	GetFilePath(parentPath, fileName string) string
+	GetFileByPath(ctx context.Context, dpDomain, filePath string) ([]byte, error)
+	UpdateFileByPath(ctx context.Context, dpDomain, filePath string, newFileContent []byte) (bool, error)
+	GetFileTypeByPath(ctx context.Context, dpDomain, parentPath, fileName string) (model.ItemType, error)
+	CreateDirByPath(ctx context.Context, dpDomain, parentPath, dirName string) (bool, error)
+	Delete(ctx context.Context, currentView *model.ItemConfig, itemType model.ItemType, parentPath, fileName string) (bool, error)
+	MoveFileByPath(ctx context.Context, dpDomain, filePath, newFilePath string) (bool, error)
+}
+
+// syncTarget is structure containing DataPower location local directory is
+// synced to (with its own DataPower connection) and sync results counters.
+type syncTarget struct {
+	name        string
+	repo        dpSyncRepo
+	dpDomain    string
+	dpDir       string
+	changeCount int
+	errCount    int
+}
+
+// syncTargets contains all DataPower locations local directory is synced to.
+var syncTargets []*syncTarget
+
+// syncStop is closed when sync mode is disabled to stop goroutine syncing
+// local filesystem changes, each sync session has its own channel.
+var syncStop chan struct{}
+
+// syncStateInfo is structure containing bidirectional sync state - hashes of
+// files saved after last sync (same on both sides), last DataPower tree and
+// conflicts waiting for user's decision.
+type syncStateInfo struct {
+	mutex        sync.Mutex
+	syncedHashes map[string]string
+	treeDpOld    localfs.Tree
+	conflicts    map[string]syncConflict
+}
+
+// syncConflict contains info about file changed both locally and on DataPower.
+type syncConflict struct {
+	pathFromRoot string
+	localHash    string
+	dpHash       string
+	skipped      bool
+}
+
+// syncDirHash is saved instead of file hash for synced directories.
+const syncDirHash = "dir"
+
+// syncState contains bidirectional sync state.
+var syncState = syncStateInfo{}
+
 // InitialLoad initializes DataPower and local filesystem access and load initial views.
 func InitialLoad() {
+	ctx := context.Background()
 	logging.LogDebug("ui/InitialLoad()")
 	err := dp.Repo.InitNetworkSettings(
 		config.CurrentApplianceName, config.CurrentAppliance)
@@ -98,18 +204,27 @@ func InitialLoad() {
 		logging.LogDebug("ui/initialLoadRepo(): ", err)
 		updateStatus(err.Error())
 	}
-	initialLoadDp()
-	initialLoadLocalfs()
+	initialLoadDp(ctx)
+	initialLoadLocalfs(ctx)
+
+	err = initKeyBindings()
+	if err != nil {
+		updateStatus(err.Error())
+	}
+	err = out.SetTheme(config.Conf.Theme)
+	if err != nil {
+		updateStatus(err.Error())
+	}
 
 	setScreenSize()
 	out.DrawEvent(events.UpdateViewEvent{Type: events.UpdateViewRefresh, Model: &workingModel})
-	updateStatusf("Press 'h' key to show help.")
+	updateStatusf("Press '%s' key to show help.", actionKeys("help"))
 }
 
 // initialLoadRepo loads initial view for given repo on given side.
-func initialLoadRepo(side model.Side, repo repo.Repo) {
+func initialLoadRepo(ctx context.Context, side model.Side, repo repo.Repo) {
 	logging.LogDebugf("ui/initialLoadRepo(%v, %v)", side, repo)
-	initialItem, err := repo.GetInitialItem()
+	initialItem, err := repo.GetInitialItem(ctx)
 	if err != nil {
 		logging.LogDebug("ui/initialLoadRepo(): ", err)
 		updateStatus(err.Error())
@@ -120,7 +235,7 @@ func initialLoadRepo(side model.Side, repo repo.Repo) {
 	title := repo.GetTitle(initialItem.Config)
 	workingModel.AddNextView(side, initialItem.Config, title)
 
-	itemList, err := repo.GetList(initialItem.Config)
+	itemList, err := repo.GetList(ctx, initialItem.Config)
 	if err != nil {
 		logging.LogDebug("ui/initialLoadRepo(): ", err)
 		updateStatus(err.Error())
@@ -130,13 +245,13 @@ func initialLoadRepo(side model.Side, repo repo.Repo) {
 }
 
 // initialLoadDp loads initial DataPower view on the left side.
-func initialLoadDp() {
-	initialLoadRepo(model.Left, &dp.Repo)
+func initialLoadDp(ctx context.Context) {
+	initialLoadRepo(ctx, model.Left, &dp.Repo)
 }
 
 // initialLoadLocalfs loads initial local filesystem view on the right side.
-func initialLoadLocalfs() {
-	initialLoadRepo(model.Right, &localfs.Repo)
+func initialLoadLocalfs(ctx context.Context) {
+	initialLoadRepo(ctx, model.Right, &localfs.Repo)
 }
 
 // ProcessInputEvent processes given input event and do appropriate action.
@@ -146,116 +261,145 @@ func ProcessInputEvent(event tcell.Event) error {
 
 	setScreenSize()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	setCurrentActionCancel(cancel)
+	defer func() {
+		setCurrentActionCancel(nil)
+		cancel()
+	}()
+
 	var err error
 
 	switch event := event.(type) {
 	case *tcell.EventKey:
-		c := event.Rune()
-		k := event.Key()
-		m := event.Modifiers()
-		// updateStatusf("Key pressed value: '%#v'", k)
-		switch {
-		case c == 'q', (k == tcell.KeyCtrlC && m == tcell.ModCtrl):
-			return QuitError
-		case k == tcell.KeyTab:
+		action := keyAction(event)
+		logging.LogDebugf("ui/ProcessInputEvent(), action: '%s'", action)
+		switch action {
+		case "quit":
+			if confirmQuitWithJobs() {
+				return QuitError
+			}
+		case "switchSide":
 			workingModel.ToggleSide()
-		case k == tcell.KeyEnter:
-			err = enterCurrentDirectory()
-		case (k == tcell.KeyLeft && m == tcell.ModShift), c == 'J':
-			err = showPrevView()
-		case (k == tcell.KeyRight && m == tcell.ModShift), c == 'L':
-			err = showNextView()
-		case c == 'H':
-			err = showViewHistory()
-		case c == ' ':
+		case "enter":
+			err = enterCurrentDirectory(ctx)
+		case "prevView":
+			err = showPrevView(ctx)
+		case "nextView":
+			err = showNextView(ctx)
+		case "viewHistory":
+			err = showViewHistory(ctx)
+		case "select":
 			workingModel.ToggleCurrItem()
-		case c == '.':
-			err = enterDirectoryPath(&workingModel)
-		case k == tcell.KeyLeft, c == 'j':
+		case "enterPath":
+			err = enterDirectoryPath(ctx, &workingModel)
+		case "scrollLeft":
 			workingModel.HorizScroll -= 10
 			if workingModel.HorizScroll < 0 {
 				workingModel.HorizScroll = 0
 			}
-		case k == tcell.KeyRight, c == 'l':
+		case "scrollRight":
 			workingModel.HorizScroll += 10
-		case (k == tcell.KeyUp && m == tcell.ModNone), c == 'i':
+		case "up":
 			workingModel.NavUp()
-		case (k == tcell.KeyDown && m == tcell.ModNone), c == 'k':
+		case "down":
 			workingModel.NavDown()
-		case (k == tcell.KeyUp && m == tcell.ModShift), c == 'I':
+		case "selectUp":
 			workingModel.ToggleCurrItem()
 			workingModel.NavUp()
-		case (k == tcell.KeyDown && m == tcell.ModShift), c == 'K':
+		case "selectDown":
 			workingModel.ToggleCurrItem()
 			workingModel.NavDown()
-		case (k == tcell.KeyPgUp && m == tcell.ModNone), c == 'u':
+		case "pageUp":
 			workingModel.NavPgUp()
-		case (k == tcell.KeyPgDn && m == tcell.ModNone), c == 'o':
+		case "pageDown":
 			workingModel.NavPgDown()
-		case (k == tcell.KeyPgUp && m == tcell.ModShift), c == 'U':
+		case "selectPageUp":
 			workingModel.SelPgUp()
 			workingModel.NavPgUp()
-		case (k == tcell.KeyPgDn && m == tcell.ModShift), c == 'O':
+		case "selectPageDown":
 			workingModel.SelPgDown()
 			workingModel.NavPgDown()
-		case (k == tcell.KeyHome && m == tcell.ModNone), c == 'a':
+		case "top":
 			workingModel.NavTop()
-		case (k == tcell.KeyEnd && m == tcell.ModNone), c == 'z':
+		case "bottom":
 			workingModel.NavBottom()
-		case (k == tcell.KeyHome && m == tcell.ModShift), c == 'A':
+		case "selectTop":
 			workingModel.SelToTop()
 			workingModel.NavTop()
-		case (k == tcell.KeyEnd && m == tcell.ModShift), c == 'Z':
+		case "selectBottom":
 			workingModel.SelToBottom()
 			workingModel.NavBottom()
-		case c == 'f':
+		case "filter":
 			filterItems(&workingModel)
-		case c == '/':
+		case "search":
 			searchItem(&workingModel)
-		case c == 'n', c == 'N':
-			searchNextItem(&workingModel, c == 'N')
-		case k == tcell.KeyF2, c == '2':
-			err = refreshCurrentView(&workingModel)
-		case k == tcell.KeyF3, c == '3':
-			err = viewCurrent(&workingModel)
-		case k == tcell.KeyF4, c == '4':
-			err = editCurrent(&workingModel)
-		case k == tcell.KeyF5, c == '5':
-			err = copyCurrent(&workingModel)
-		case c == 'd':
-			err = diffCurrent(&workingModel)
-		case k == tcell.KeyF7, c == '7':
-			err = createDirectoryOrDomain(&workingModel)
-		case k == tcell.KeyF8, c == '8':
-			err = createEmptyFile(&workingModel)
-		case k == tcell.KeyF9, c == '9':
-			err = cloneCurrent(&workingModel)
-		case k == tcell.KeyDelete, c == 'x':
-			err = deleteCurrent(&workingModel)
-		case c == 's':
-			err = syncModeToggle(&workingModel)
-		case c == 'S':
-			err = saveDataPowerConfig(&workingModel)
-		case c == 'm':
+		case "searchNext", "searchPrev":
+			searchNextItem(&workingModel, action == "searchPrev")
+		case "refresh":
+			err = refreshCurrentView(ctx, &workingModel)
+		case "view":
+			err = viewCurrent(ctx, &workingModel)
+		case "edit":
+			err = editCurrent(ctx, &workingModel)
+		case "copy":
+			err = copyCurrent(ctx, &workingModel)
+		case "copyInBackground":
+			err = copyCurrentInBackground(ctx, &workingModel)
+		case "jobs":
+			err = showJobs()
+		case "diff":
+			err = diffCurrent(ctx, &workingModel)
+		case "compare":
+			err = compareCurrent(ctx, &workingModel)
+		case "createDir":
+			err = createDirectoryOrDomain(ctx, &workingModel)
+		case "createFile":
+			err = createEmptyFile(ctx, &workingModel)
+		case "clone":
+			err = cloneCurrent(ctx, &workingModel)
+		case "delete":
+			err = deleteCurrent(ctx, &workingModel)
+		case "sync":
+			err = syncModeToggle(ctx, &workingModel)
+		case "saveConfig":
+			err = saveDataPowerConfig(ctx, &workingModel)
+		case "messages":
 			err = showStatusMessages(workingModel.Statuses())
-		case c == '0':
-			err = toggleObjectMode(&workingModel)
-		case c == '?':
-			err = showItemInfo(&workingModel)
-		case c == 'P':
-			err = showObjectDetails(&workingModel)
-		case c == 'h':
-			err = extprogs.ShowHelp()
+		case "toggleMode":
+			err = toggleObjectMode(ctx, &workingModel)
+		case "info":
+			err = showItemInfo(ctx, &workingModel)
+		case "policy":
+			err = showObjectDetails(ctx, &workingModel)
+		case "help":
+			err = showHelp()
 
 		default:
-			err = extprogs.ShowHelp()
+			err = showHelp()
 			updateStatusf("Key event value (before showing help): '%#v'", event)
 		}
+	case *tcell.EventMouse:
+		err = processMouseEvent(ctx, &workingModel, event)
 	case *tcell.EventResize:
 		workingModel.ResizeView()
+	case *tcell.EventInterrupt:
+		// Job events are processed with pending job events below.
+		if _, ok := event.Data().(events.JobEvent); !ok {
+			err = resolveSyncConflicts(ctx, &workingModel)
+		}
+	}
+	// Job events received while dialogs (or viewers) were shown.
+	if jobErr := processPendingJobEvents(ctx, &workingModel); err == nil {
+		err = jobErr
 	}
 
-	if err != nil {
+	switch {
+	case ctx.Err() == context.Canceled:
+		// Cached DataPower data could be fetched only partially.
+		dp.Repo.InvalidateCache()
+		updateStatus("Action canceled.")
+	case err != nil:
 		updateStatus(err.Error())
 	}
 
@@ -293,10 +437,12 @@ loop:
 	for {
 		updateViewEvent := prepareInputDialog(&dialogSession)
 		out.DrawEvent(updateViewEvent)
-		event := out.Screen.PollEvent()
+		event := pollEvent()
 		switch event := event.(type) {
 		case *tcell.EventKey:
 			processInputDialogInput(&dialogSession, event)
+		case *tcell.EventMouse:
+			processInputDialogMouse(&dialogSession, event)
 		}
 
 		if dialogSession.dialogCanceled || dialogSession.dialogSubmitted {
@@ -380,13 +526,13 @@ func processInputDialogInput(dialogSession *userDialogInputSessionInfo, keyEvent
 	}
 }
 
-func enterCurrentDirectory() error {
+func enterCurrentDirectory(ctx context.Context) error {
 	logging.LogDebugf("ui/enterCurrentDirectory()")
 	item := workingModel.CurrItem()
 	if item == nil {
 		return errs.Error("Nothing found, can't enter current directory.")
 	}
-	err := showItem(workingModel.CurrSide(), item.Config, item.Name)
+	err := showItem(ctx, workingModel.CurrSide(), item.Config, item.Name)
 
 	switch err {
 	case dpMissingPasswordError:
@@ -417,7 +563,7 @@ func enterCurrentDirectory() error {
 // - "." - refresh current view - keep the current item
 // - ".." - go to parent view - use the previous view as the current item
 // - other - adding new view - set the first item as the current item
-func showView(side model.Side, itemConfig *model.ItemConfig,
+func showView(ctx context.Context, side model.Side, itemConfig *model.ItemConfig,
 	viewItemName, currentItemName string, changeHistory bool) error {
 	logging.LogDebugf("ui/showView(%d, %v, '%s', '%s')",
 		side, itemConfig, viewItemName, currentItemName)
@@ -426,7 +572,7 @@ func showView(side model.Side, itemConfig *model.ItemConfig,
 		if applianceName != ".." && applianceName != "." && applianceName != "" {
 			applicanceConfig := config.Conf.DataPowerAppliances[applianceName]
 			dpTransientPassword := config.DpTransientPasswordMap[applianceName]
-			logging.LogDebugf("ui/showView(), applicanceConfig: '%s'", applicanceConfig)
+			logging.LogDebugf("ui/showView(), applicanceConfig: '%v'", applicanceConfig)
 			if applicanceConfig.Password == "" && dpTransientPassword == "" {
 				return dpMissingPasswordError
 			}
@@ -443,7 +589,7 @@ func showView(side model.Side, itemConfig *model.ItemConfig,
 		model.ItemDpObjectClassList, model.ItemDpObjectClass,
 		model.ItemDpStatusClassList, model.ItemDpStatusClass,
 		model.ItemNone:
-		itemList, err = r.GetList(itemConfig)
+		itemList, err = r.GetList(ctx, itemConfig)
 		if err != nil {
 			return err
 		}
@@ -502,13 +648,13 @@ func showView(side model.Side, itemConfig *model.ItemConfig,
 	return nil
 }
 
-func showItem(side model.Side, itemConfig *model.ItemConfig, itemName string) error {
+func showItem(ctx context.Context, side model.Side, itemConfig *model.ItemConfig, itemName string) error {
 	logging.LogDebugf("ui/showItem(%d, %v, '%s')", side, itemConfig, itemName)
-	return showView(side, itemConfig, itemName, "", itemName != ".")
+	return showView(ctx, side, itemConfig, itemName, "", itemName != ".")
 }
 
 // showPrevView navigate to the previous view from the view history.
-func showPrevView() error {
+func showPrevView(ctx context.Context) error {
 	logging.LogDebugf("ui/showPrevView()")
 	side := workingModel.CurrSide()
 	oldView := workingModel.ViewConfig(side)
@@ -533,11 +679,11 @@ func showPrevView() error {
 		updateStatusf("Can't move back from the first view in the history.")
 	}
 
-	return showView(side, newView, "", oldView.Name, false)
+	return showView(ctx, side, newView, "", oldView.Name, false)
 }
 
 // showNextView navigate to the next view from the view history.
-func showNextView() error {
+func showNextView(ctx context.Context) error {
 	logging.LogDebugf("ui/showNextView()")
 	side := workingModel.CurrSide()
 	oldView := workingModel.ViewConfig(side)
@@ -566,12 +712,12 @@ func showNextView() error {
 	if nextViewFromHistory != nil {
 		nextViewName = nextViewFromHistory.Name
 	}
-	return showView(side, newView, "", nextViewName, false)
+	return showView(ctx, side, newView, "", nextViewName, false)
 }
 
 // showViewHistory shows dialog with history of views where we can select any
 // view and jump straight to it.
-func showViewHistory() error {
+func showViewHistory(ctx context.Context) error {
 	logging.LogDebug("ui/showViewHistory()")
 	// When progress dialog is shown we don't won't it to hid our selection dialog.
 	progressDialogSession.waitUserInput = true
@@ -585,29 +731,8 @@ func showViewHistory() error {
 		pathHistory[idx] = r.GetTitle(view)
 	}
 	logging.LogDebugf("ui/showViewHistory(), pathHistory: %v", pathHistory)
-	dialogSession := listSelectionDialogSessionInfo{message: "Select a view:",
-		list:         pathHistory,
-		selectionIdx: workingModel.ViewConfigHistorySelectedIdx(side)}
-
-loop:
-	for {
-		updateViewEvent := events.UpdateViewEvent{
-			Type:                     events.UpdateViewShowListSelectionDialog,
-			ListSelectionMessage:     dialogSession.message,
-			ListSelectionList:        dialogSession.list,
-			ListSelectionSelectedIdx: dialogSession.selectionIdx}
-
-		out.DrawEvent(updateViewEvent)
-		event := out.Screen.PollEvent()
-		switch event := event.(type) {
-		case *tcell.EventKey:
-			processSelectListDialogInput(&dialogSession, event)
-		}
-
-		if dialogSession.dialogCanceled || dialogSession.dialogSubmitted {
-			break loop
-		}
-	}
+	dialogSession := selectFromList("Select a view:", pathHistory,
+		workingModel.ViewConfigHistorySelectedIdx(side))
 
 	if dialogSession.dialogSubmitted {
 		newView := workingModel.NavCurrentViewIdx(side, dialogSession.selectionIdx)
@@ -622,7 +747,7 @@ loop:
 				dp.Repo.DpViewMode = model.DpFilestoreMode
 			}
 		}
-		showView(side, newView, ".", "", false)
+		showView(ctx, side, newView, ".", "", false)
 	}
 
 	// When progress dialog was shown we show it after we don't need selection
@@ -632,6 +757,39 @@ loop:
 	return nil
 }
 
+// selectFromList shows list selection dialog and waits until user selects an
+// item from the list or cancels selection.
+func selectFromList(message string, list []string, selectionIdx int) listSelectionDialogSessionInfo {
+	logging.LogDebugf("ui/selectFromList('%s', .., %d)", message, selectionIdx)
+	dialogSession := listSelectionDialogSessionInfo{message: message,
+		list:         list,
+		selectionIdx: selectionIdx}
+
+loop:
+	for {
+		updateViewEvent := events.UpdateViewEvent{
+			Type:                     events.UpdateViewShowListSelectionDialog,
+			ListSelectionMessage:     dialogSession.message,
+			ListSelectionList:        dialogSession.list,
+			ListSelectionSelectedIdx: dialogSession.selectionIdx}
+
+		out.DrawEvent(updateViewEvent)
+		event := pollEvent()
+		switch event := event.(type) {
+		case *tcell.EventKey:
+			processSelectListDialogInput(&dialogSession, event)
+		case *tcell.EventMouse:
+			processSelectListDialogMouse(&dialogSession, event)
+		}
+
+		if dialogSession.dialogCanceled || dialogSession.dialogSubmitted {
+			break loop
+		}
+	}
+
+	return dialogSession
+}
+
 // processSelectListDialogInput processes user's input to list selection dialog.
 func processSelectListDialogInput(dialogSession *listSelectionDialogSessionInfo, keyEvent *tcell.EventKey) {
 	logging.LogDebugf("ui/processSelectListDialogInput(): '%s'", dialogSession)
@@ -666,17 +824,54 @@ func setCurrentDpPlainPassword(password string) {
 	config.DpTransientPasswordMap[applianceName] = password
 }
 
+// setDpApplianceConfig saves DataPower appliance configuration, asking user
+// for master passphrase first if credential store is still locked.
+func setDpApplianceConfig(name string, contents []byte) error {
+	err := config.Conf.SetDpApplianceConfig(name, contents)
+	if err != config.ErrCredentialsLocked {
+		return err
+	}
+	err = unlockCredentials()
+	if err != nil {
+		return err
+	}
+	return config.Conf.SetDpApplianceConfig(name, contents)
+}
+
+// unlockCredentials asks user for master passphrase and unlocks credential
+// store (new master passphrase is asked twice if store is not initialized).
+func unlockCredentials() error {
+	logging.LogDebug("ui/unlockCredentials()")
+	if config.CredentialsInitialized() {
+		dialogResult := askUserInput("Master passphrase (to save DataPower password): ", "", true)
+		if dialogResult.dialogCanceled {
+			return errs.Error("Unlocking of credential store canceled.")
+		}
+		return config.UnlockCredentials(dialogResult.inputAnswer)
+	}
+
+	dialogResult := askUserInput("New master passphrase (to save DataPower passwords): ", "", true)
+	if dialogResult.dialogCanceled || dialogResult.inputAnswer == "" {
+		return errs.Error("Master passphrase not set, DataPower configuration not saved.")
+	}
+	repeatResult := askUserInput("Repeat master passphrase: ", "", true)
+	if repeatResult.dialogCanceled || repeatResult.inputAnswer != dialogResult.inputAnswer {
+		return errs.Error("Master passphrases don't match, DataPower configuration not saved.")
+	}
+	return config.UnlockCredentials(dialogResult.inputAnswer)
+}
+
 func setScreenSize() {
 	_, height := out.GetScreenSize()
 	workingModel.ItemMaxRows = height - 3
 }
 
-func refreshView(m *model.Model, side model.Side) error {
+func refreshView(ctx context.Context, m *model.Model, side model.Side) error {
 	repo := repos[side]
 	repo.InvalidateCache()
 	viewConfig := m.ViewConfig(side)
 	logging.LogDebugf("ui/refreshView() viewConfig: %v", viewConfig)
-	err := showView(side, viewConfig, ".", "", false)
+	err := showView(ctx, side, viewConfig, ".", "", false)
 	if err != nil {
 		return err
 	}
@@ -685,11 +880,11 @@ func refreshView(m *model.Model, side model.Side) error {
 	return nil
 }
 
-func refreshCurrentView(m *model.Model) error {
-	return refreshView(m, m.CurrSide())
+func refreshCurrentView(ctx context.Context, m *model.Model) error {
+	return refreshView(ctx, m, m.CurrSide())
 }
 
-func viewCurrent(m *model.Model) error {
+func viewCurrent(ctx context.Context, m *model.Model) error {
 	ci := m.CurrItem()
 	logging.LogDebugf("ui/viewCurrent(), item: %v", ci)
 	if ci.Name == ".." {
@@ -702,19 +897,19 @@ func viewCurrent(m *model.Model) error {
 		if m.CurrSide() == model.Left {
 			currView := workingModel.ViewConfig(workingModel.CurrSide())
 			showProgressDialogf("Fetching '%s' file from DataPower...", ci.Name)
-			fileContent, err := repos[m.CurrSide()].GetFile(currView, ci.Name)
+			fileContent, err := repos[m.CurrSide()].GetFile(ctx, currView, ci.Name)
 			hideProgressDialog()
 			if err != nil {
 				return err
 			}
 			if fileContent != nil {
-				err = extprogs.View("*."+ci.Name, fileContent)
+				err = viewContent("*."+ci.Name, fileContent)
 				if err != nil {
 					return err
 				}
 			}
 		} else {
-			err = extprogs.ViewFile(ci.Config.Path)
+			err = viewLocalFile(ci.Config.Path)
 			if err != nil {
 				return err
 			}
@@ -724,16 +919,16 @@ func viewCurrent(m *model.Model) error {
 		if err != nil {
 			return err
 		}
-		err = extprogs.View("*."+ci.Name+".json", fileContent)
+		err = viewContent("*."+ci.Name+".json", fileContent)
 		if err != nil {
 			return err
 		}
 	case model.ItemDpObject:
-		objectContent, err := dp.Repo.GetObject(ci.Config.DpDomain, ci.Config.Path, ci.Name, false)
+		objectContent, err := dp.Repo.GetObject(ctx, ci.Config.DpDomain, ci.Config.Path, ci.Name, false)
 		if err != nil {
 			return err
 		}
-		err = extprogs.View(getObjectTmpName(ci.Name), objectContent)
+		err = viewContent(getObjectTmpName(ci.Name), objectContent)
 		if err != nil {
 			return err
 		}
@@ -743,21 +938,21 @@ func viewCurrent(m *model.Model) error {
 			return err
 		}
 		statusContent, err :=
-			dp.Repo.GetStatus(ci.Config.DpDomain, ci.Config.Parent.Name, statusIdx)
+			dp.Repo.GetStatus(ctx, ci.Config.DpDomain, ci.Config.Parent.Name, statusIdx)
 		if err != nil {
 			return err
 		}
-		err = extprogs.View(getObjectTmpName(ci.Name), statusContent)
+		err = viewContent(getObjectTmpName(ci.Name), statusContent)
 		if err != nil {
 			return err
 		}
 	case model.ItemDpStatusClass:
 		statusesContent, err :=
-			dp.Repo.GetStatuses(ci.Config.DpDomain, ci.Config.Name)
+			dp.Repo.GetStatuses(ctx, ci.Config.DpDomain, ci.Config.Name)
 		if err != nil {
 			return err
 		}
-		err = extprogs.View(getObjectTmpName(ci.Name), statusesContent)
+		err = viewContent(getObjectTmpName(ci.Name), statusesContent)
 		if err != nil {
 			return err
 		}
@@ -769,7 +964,7 @@ func viewCurrent(m *model.Model) error {
 	return err
 }
 
-func editCurrent(m *model.Model) error {
+func editCurrent(ctx context.Context, m *model.Model) error {
 	ci := m.CurrItem()
 	logging.LogDebugf("ui/editCurrent(), item: %v", ci)
 	if ci.Name == ".." {
@@ -781,7 +976,7 @@ func editCurrent(m *model.Model) error {
 		currView := workingModel.ViewConfig(workingModel.CurrSide())
 		if m.CurrSide() == model.Left {
 			showProgressDialogf("Fetching '%s' file from DataPower...", ci.Name)
-			fileContent, err := repos[m.CurrSide()].GetFile(currView, ci.Name)
+			fileContent, err := repos[m.CurrSide()].GetFile(ctx, currView, ci.Name)
 			hideProgressDialog()
 			if err != nil {
 				return err
@@ -791,18 +986,18 @@ func editCurrent(m *model.Model) error {
 				return err
 			}
 			if changed {
-				_, err := repos[m.CurrSide()].UpdateFile(currView, ci.Name, newFileContent)
+				_, err := repos[m.CurrSide()].UpdateFile(ctx, currView, ci.Name, newFileContent)
 				if err != nil {
 					return err
 				}
-				showItem(workingModel.CurrSide(), currView, ".")
+				showItem(ctx, workingModel.CurrSide(), currView, ".")
 			}
 		} else {
 			err := extprogs.EditFile(ci.Config.Path)
 			if err != nil {
 				return err
 			}
-			showItem(workingModel.CurrSide(), currView, ".")
+			showItem(ctx, workingModel.CurrSide(), currView, ".")
 		}
 		updateStatusf("File '%s' on path '%s' updated.", ci.Name, ci.Config.Path)
 
@@ -816,7 +1011,7 @@ func editCurrent(m *model.Model) error {
 			return err
 		}
 		if changed {
-			err := config.Conf.SetDpApplianceConfig(ci.Name, newFileContent)
+			err := setDpApplianceConfig(ci.Name, newFileContent)
 			if err != nil {
 				return err
 			}
@@ -824,7 +1019,7 @@ func editCurrent(m *model.Model) error {
 		updateStatusf("DataPower configuration '%s' updated.", ci.Name)
 
 	case model.ItemDpObject:
-		objectContent, err := dp.Repo.GetObject(ci.Config.DpDomain, ci.Config.Path, ci.Name, false)
+		objectContent, err := dp.Repo.GetObject(ctx, ci.Config.DpDomain, ci.Config.Path, ci.Name, false)
 		if err != nil {
 			return err
 		}
@@ -833,13 +1028,13 @@ func editCurrent(m *model.Model) error {
 			return err
 		}
 		if changed {
-			err := dp.Repo.SetObject(ci.Config.DpDomain, ci.Config.Path, ci.Name, newObjectContent, true)
+			err := dp.Repo.SetObject(ctx, ci.Config.DpDomain, ci.Config.Path, ci.Name, newObjectContent, true)
 			if err != nil {
 				return err
 			}
 			updateStatusf("DataPower object '%s' of class '%s' updated.", ci.Name, ci.Config.Path)
 			currView := workingModel.ViewConfig(workingModel.CurrSide())
-			showItem(workingModel.CurrSide(), currView, ".")
+			showItem(ctx, workingModel.CurrSide(), currView, ".")
 		} else {
 			updateStatusf("DataPower object '%s' of class '%s' not changed.", ci.Name, ci.Config.Path)
 		}
@@ -851,7 +1046,7 @@ func editCurrent(m *model.Model) error {
 	return err
 }
 
-func copyCurrent(m *model.Model) error {
+func copyCurrent(ctx context.Context, m *model.Model) error {
 	fromSide := m.CurrSide()
 	toSide := m.OtherSide()
 
@@ -863,26 +1058,82 @@ func copyCurrent(m *model.Model) error {
 	for idx, item := range itemsToCopy {
 		itemsDisplayToCopy[idx] = item.DisplayString()
 	}
+	// If we copy zip file to DataPower domain or appliance we import it.
+	if toSide == model.Left {
+		dpItem := m.CurrItemForSide(toSide)
+		if dpItem.Name != ".." &&
+			(dpItem.Config.Type == model.ItemDpDomain || dpItem.Config.Type == model.ItemDpConfiguration) {
+			err := importDomainOrAppliance(ctx, dpItem, fromViewConfig, itemsToCopy)
+			if err != nil {
+				return err
+			}
+			return showItem(ctx, toSide, m.ViewConfig(toSide), ".")
+		}
+	}
+
 	updateStatusf("Copy from '%s' to '%s', items: %v", fromViewConfig.Path, toViewConfig.Path, itemsDisplayToCopy)
 
-	var confirmOverwrite = "n"
-	var err error
-	for _, item := range itemsToCopy {
-		confirmOverwrite, err = copyItem(repos[fromSide], repos[toSide], fromViewConfig, toViewConfig, item, confirmOverwrite)
-		if err != nil {
-			return err
-		}
-		if confirmOverwrite == "na" {
-			break
+	cs := newCopySession()
+	cs.sameFiles = sameFilesForCopy(fromSide, itemsToCopy)
+	err := cs.copyItems(ctx, repos[fromSide], repos[toSide], fromViewConfig, toViewConfig, itemsToCopy)
+	if err != nil {
+		return err
+	}
+
+	return showItem(ctx, toSide, m.ViewConfig(toSide), ".")
+}
+
+// copyCurrentInBackground copies selected (or current) items to the other side
+// as background job. User decides how existing files are handled before job
+// is started since background job can't show conflict dialog.
+func copyCurrentInBackground(ctx context.Context, m *model.Model) error {
+	logging.LogDebug("ui/copyCurrentInBackground()")
+	fromSide := m.CurrSide()
+	toSide := m.OtherSide()
+
+	fromViewConfig := m.ViewConfig(fromSide)
+	toViewConfig := m.ViewConfig(toSide)
+
+	itemsToCopy := getSelectedOrCurrent(m)
+	if len(itemsToCopy) == 0 {
+		return errs.Error("Select items to copy in background.")
+	}
+	if toSide == model.Left {
+		dpItem := m.CurrItemForSide(toSide)
+		if dpItem.Name != ".." &&
+			(dpItem.Config.Type == model.ItemDpDomain || dpItem.Config.Type == model.ItemDpConfiguration) {
+			return errs.Errorf("Can't import to DataPower %s '%s' in background.",
+				dpItem.Config.Type.UserFriendlyString(), dpItem.Name)
 		}
 	}
 
-	return showItem(toSide, m.ViewConfig(toSide), ".")
+	policyNames := make([]string, len(backgroundConflictOptions))
+	for idx, option := range backgroundConflictOptions {
+		policyNames[idx] = option.name
+	}
+	dialogResult := selectFromList("Existing files during background copy:", policyNames, 0)
+	if dialogResult.dialogCanceled {
+		return nil
+	}
+	conflictPolicy := backgroundConflictOptions[dialogResult.selectionIdx].policy
+	sameFiles := sameFilesForCopy(fromSide, itemsToCopy)
+
+	jobDpRepo := dp.Repo.Clone()
+	jobRepos := []repo.Repo{model.Left: jobDpRepo, model.Right: &localfs.Repo}
+	jobName := fmt.Sprintf("Copy %d item(s) from '%s' to '%s'",
+		len(itemsToCopy), fromViewConfig.Path, toViewConfig.Path)
+	startJob(jobName, func(ctx context.Context, j *job) error {
+		cs := copySession{dpRepo: jobDpRepo, cloneDpRepo: func() repo.Repo { return jobDpRepo.Clone() },
+			dpViewMode: jobDpRepo.DpViewMode, job: j, conflictPolicy: conflictPolicy, sameFiles: sameFiles}
+		return cs.copyItems(ctx, jobRepos[fromSide], jobRepos[toSide], fromViewConfig, toViewConfig, itemsToCopy)
+	})
+
+	return nil
 }
 
 func diffFilesWithCleanup(tmpDir, oldPath, newPath string) error {
 	logging.LogDebug("ui/diffFiles()")
-	err := extprogs.Diff(oldPath, newPath)
+	noDifferences, err := diffPaths(oldPath, newPath)
 	if err != nil {
 		errdel := extprogs.DeleteTempDir(tmpDir)
 		if errdel == nil {
@@ -899,11 +1150,14 @@ func diffFilesWithCleanup(tmpDir, oldPath, newPath string) error {
 		return err
 	}
 	updateStatusf("Deleted tmp dir on localfs '%s'", tmpDir)
+	if noDifferences {
+		updateStatus(noDifferencesStatus)
+	}
 	logging.LogDebug("ui/diffFiles() end")
 	return nil
 }
 
-func diffCurrent(m *model.Model) error {
+func diffCurrent(ctx context.Context, m *model.Model) error {
 	logging.LogDebug("ui/diffCurrent()")
 	dpItem := m.CurrItemForSide(model.Left)
 	if dpItem.Name == ".." {
@@ -928,7 +1182,10 @@ func diffCurrent(m *model.Model) error {
 		dpCopyDir := extprogs.CreateTempDir("dp")
 		updateStatusf("Created tmp dir on localfs '%s'", dpCopyDir)
 		localViewTmp := model.ItemConfig{Type: model.ItemDirectory, Path: dpCopyDir}
-		copyItem(&dp.Repo, localfs.Repo, dpView, &localViewTmp, *dpItem, "y")
+		cs := newCopySession()
+		cs.conflictPolicy = conflictOverwriteAll
+		cs.copyItem(ctx, &dp.Repo, localfs.Repo, dpView, &localViewTmp, *dpItem)
+		cs.finishTransfers()
 		var dpDirName string
 		switch dpItem.Config.Type {
 		case model.ItemDpFilestore:
@@ -943,40 +1200,19 @@ func diffCurrent(m *model.Model) error {
 
 		return err
 	case dpItem.Config.Type == model.ItemDpObject && dpItem.Modified == "modified":
-		dpCopyDir := extprogs.CreateTempDir("dp")
-		updateStatusf("Created tmp dir on localfs '%s'", dpCopyDir)
-
-		localViewTmp := model.ItemConfig{Type: model.ItemDirectory, Path: dpCopyDir}
-
-		objectContentMemory, err := dp.Repo.GetObject(
+		objectContentMemory, err := dp.Repo.GetObject(ctx,
 			dpItem.Config.DpDomain, dpItem.Config.Path, dpItem.Name, false)
 		if err != nil {
 			return err
 		}
-		objectContentSaved, err := dp.Repo.GetObject(
+		objectContentSaved, err := dp.Repo.GetObject(ctx,
 			dpItem.Config.DpDomain, dpItem.Config.Path, dpItem.Name, true)
 		if err != nil {
 			return err
 		}
 
-		objectNameMemory := dpItem.Name + "_memory.xml"
-		objectNameSaved := dpItem.Name + "_saved.xml"
-
-		_, err = localfs.Repo.UpdateFile(&localViewTmp, objectNameMemory, objectContentMemory)
-		if err != nil {
-			return err
-		}
-		_, err = localfs.Repo.UpdateFile(&localViewTmp, objectNameSaved, objectContentSaved)
-		if err != nil {
-			return err
-		}
-
-		dpObjectMemoryPath := localfs.Repo.GetFilePath(dpCopyDir, objectNameMemory)
-		dpObjectSavedPath := localfs.Repo.GetFilePath(dpCopyDir, objectNameSaved)
-
-		err = diffFilesWithCleanup(dpCopyDir, dpObjectSavedPath, dpObjectMemoryPath)
-
-		return err
+		return diffContents(ctx, dpItem.Name+"_saved.xml", dpItem.Name+"_memory.xml",
+			objectContentSaved, objectContentMemory)
 	case dpItem.Config.Type == model.ItemDpObject:
 		err := errs.Errorf("Can't view changes on DataPower object '%s' if not modified (%s)",
 			dpItem.Name, dpItem.Modified)
@@ -990,6 +1226,78 @@ func diffCurrent(m *model.Model) error {
 	}
 }
 
+// newCopySession creates session for copy started by user, using the same
+// DataPower repo used for browsing.
+func newCopySession() *copySession {
+	return &copySession{dpRepo: &dp.Repo, cloneDpRepo: func() repo.Repo { return dp.Repo.Clone() },
+		dpViewMode: dp.Repo.DpViewMode}
+}
+
+// copyItems copies given items (with ignore patterns applied to each item),
+// shows how many ignored entries were skipped and returns summary of failed
+// items (copy continues after failure).
+func (cs *copySession) copyItems(ctx context.Context, fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig, items []model.Item) error {
+	cs.showProgress("Copying...")
+	defer cs.hideProgress()
+	var err error
+	for _, item := range items {
+		cs.ignoreStart(fromRepo, fromViewConfig, item.Name)
+		err = cs.copyItem(ctx, fromRepo, toRepo, fromViewConfig, toViewConfig, item)
+		if err != nil {
+			if ctx.Err() != nil || err == errCopyAborted {
+				break
+			}
+			cs.failedf("Item '%s' not copied: %v", item.Name, err)
+		}
+	}
+	finishErr := cs.finishTransfers()
+	if cs.ignore.skipped > 0 {
+		cs.statusf("Copy skipped %d ignored entries.", cs.ignore.skipped)
+	}
+	if err == errCopyAborted {
+		return err
+	}
+
+	return finishErr
+}
+
+// statusf shows copy status message (or sets job status if copy runs in
+// background).
+func (cs *copySession) statusf(format string, v ...interface{}) {
+	if cs.job != nil {
+		cs.job.statusf(format, v...)
+		return
+	}
+	updateStatusf(format, v...)
+}
+
+// showProgress shows progress dialog (or sets job status if copy runs in
+// background).
+func (cs *copySession) showProgress(msg string) {
+	if cs.job != nil {
+		cs.job.statusf("%s", msg)
+		return
+	}
+	showProgressDialog(msg)
+}
+
+// hideProgress hides progress dialog shown by showProgress.
+func (cs *copySession) hideProgress() {
+	if cs.job == nil {
+		hideProgressDialog()
+	}
+}
+
+// progressf changes progress dialog message (or sets job status if copy runs
+// in background).
+func (cs *copySession) progressf(format string, v ...interface{}) {
+	if cs.job != nil {
+		cs.job.statusf(format, v...)
+		return
+	}
+	updateProgressDialogMessagef(format, v...)
+}
+
 func getSelectedOrCurrent(m *model.Model) []model.Item {
 	selectedItems := m.GetSelectedItems(m.CurrSide())
 	if len(selectedItems) == 0 {
@@ -1002,91 +1310,98 @@ func getSelectedOrCurrent(m *model.Model) []model.Item {
 	return selectedItems
 }
 
-func copyItem(fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig, item model.Item, confirmOverwrite string) (string, error) {
-	logging.LogDebugf("ui/copyItem(.., .., %v, %v, %v, '%s')", fromViewConfig, toViewConfig, item, confirmOverwrite)
-	res := confirmOverwrite
+// ignoreStart prepares ignore patterns for copying item - global patterns
+// from configuration and patterns from ignore file in local directory copied.
+func (cs *copySession) ignoreStart(fromRepo repo.Repo, fromViewConfig *model.ItemConfig, itemName string) {
+	cs.ignore.rootPath = fromRepo.GetFilePath(fromViewConfig.Path, itemName)
+	cs.ignore.matcher = ignore.NewMatcher(config.Conf.Sync.Ignore...)
+	if fromRepo.String() == localfs.Repo.String() {
+		ignoreFilePath := localfs.Repo.GetFilePath(cs.ignore.rootPath, ignore.FileName)
+		err := cs.ignore.matcher.AddFile(ignoreFilePath)
+		if err != nil && !os.IsNotExist(err) {
+			logging.LogDebugf("worker/ignoreStart(), can't read '%s': %v", ignoreFilePath, err)
+		}
+	}
+}
+
+// ignored checks if item at given path should be skipped during recursive
+// copy (and counts skipped items).
+func (cs *copySession) ignored(itemPath string, dir bool) bool {
+	relPath := strings.TrimPrefix(itemPath, cs.ignore.rootPath)
+	relPath = filepath.ToSlash(strings.TrimLeft(relPath, `/\`))
+	if cs.ignore.matcher.Ignored(relPath, dir) {
+		logging.LogDebugf("worker/ignored(), '%s' ignored.", itemPath)
+		cs.ignore.skipped++
+		return true
+	}
+
+	return false
+}
+
+func (cs *copySession) copyItem(ctx context.Context, fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig, item model.Item) error {
+	logging.LogDebugf("ui/copyItem(.., .., %v, %v, %v)", fromViewConfig, toViewConfig, item)
 	var err error
 	switch item.Config.Type {
 	case model.ItemDpFilestore:
-		res, err = copyFilestore(fromRepo, toRepo, fromViewConfig, toViewConfig, item.Name, confirmOverwrite)
-		if err != nil {
-			return res, err
-		}
+		err = cs.copyFilestore(ctx, fromRepo, toRepo, fromViewConfig, toViewConfig, item.Name)
 	case model.ItemDirectory:
-		res, err = copyDirs(fromRepo, toRepo, fromViewConfig, toViewConfig, item.Name, confirmOverwrite)
-		if err != nil {
-			return res, err
-		}
+		err = cs.copyDirs(ctx, fromRepo, toRepo, fromViewConfig, toViewConfig, item.Name)
 	case model.ItemFile:
 		// If we copy to DataPower and we are in ObjectConfigMode we copy file to object.
 		switch {
-		case toRepo.String() == dp.Repo.String() && dp.Repo.DpViewMode == model.DpObjectMode:
-			res, err = copyFileToObject(item.Config, item.Name, fromRepo, toRepo, fromViewConfig, toViewConfig, confirmOverwrite)
-		case toRepo.String() == dp.Repo.String() && dp.Repo.DpViewMode == model.DpStatusMode:
+		case toRepo.String() == dp.Repo.String() && cs.dpViewMode == model.DpObjectMode:
+			err = cs.copyFileToObject(ctx, item, fromRepo, toRepo, fromViewConfig, toViewConfig)
+		case toRepo.String() == dp.Repo.String() && cs.dpViewMode == model.DpStatusMode:
 			err = errs.Errorf("Can't copy to DataPower status.")
 		default:
-			res, err = copyFile(fromRepo, toRepo, fromViewConfig, toViewConfig, item.Name, confirmOverwrite)
-		}
-
-		if err != nil {
-			return res, err
+			err = cs.copyFile(ctx, fromRepo, toRepo, fromViewConfig, toViewConfig, item, item.Name)
 		}
 	case model.ItemDpDomain:
-		err = exportDomain(fromViewConfig, toViewConfig, item.Name)
-		if err != nil {
-			return res, err
-		}
+		err = cs.exportDomain(ctx, fromViewConfig, toViewConfig, item.Name)
 	case model.ItemDpConfiguration:
-		err = exportAppliance(item.Config, toViewConfig, item.Name)
-		if err != nil {
-			return res, err
-		}
+		err = cs.exportAppliance(ctx, item.Config, toViewConfig, item.Name)
 	case model.ItemDpObject:
-		res, err = copyObjectToFile(item.Config, item.Name, fromRepo, toRepo, fromViewConfig, toViewConfig, confirmOverwrite)
-		if err != nil {
-			return res, err
-		}
+		err = cs.copyObjectToFile(ctx, item, fromRepo, toRepo, fromViewConfig, toViewConfig)
 	default:
-		updateStatusf("Item of type '%s' can't be copied/exported.", item.Config.Type.UserFriendlyString())
+		cs.statusf("Item of type '%s' can't be copied/exported.", item.Config.Type.UserFriendlyString())
 	}
 
-	logging.LogDebugf("ui/copyItem(), res: '%s'", res)
-	return res, nil
+	return err
 }
 
-func copyFilestore(fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig, dirName, confirmOverwrite string) (string, error) {
+func (cs *copySession) copyFilestore(ctx context.Context, fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig, dirName string) error {
 	dirToName := dirName[0 : len(dirName)-1]
-	return copyDirsOrFilestores(fromRepo, toRepo, fromViewConfig, toViewConfig, dirName, dirToName, confirmOverwrite)
+	return cs.copyDirsOrFilestores(ctx, fromRepo, toRepo, fromViewConfig, toViewConfig, dirName, dirToName)
 }
 
-func copyDirs(fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig, dirName, confirmOverwrite string) (string, error) {
-	return copyDirsOrFilestores(fromRepo, toRepo, fromViewConfig, toViewConfig, dirName, dirName, confirmOverwrite)
+func (cs *copySession) copyDirs(ctx context.Context, fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig, dirName string) error {
+	return cs.copyDirsOrFilestores(ctx, fromRepo, toRepo, fromViewConfig, toViewConfig, dirName, dirName)
 }
 
-func copyDirsOrFilestores(fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig, dirFromName, dirToName, confirmOverwrite string) (string, error) {
-	logging.LogDebugf("ui/copyDirsOrFilestores(.., .., %v, %v, '%s', '%s', '%s')", fromViewConfig, toViewConfig, dirFromName, dirToName, confirmOverwrite)
+func (cs *copySession) copyDirsOrFilestores(ctx context.Context, fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig, dirFromName, dirToName string) error {
+	logging.LogDebugf("ui/copyDirsOrFilestores(.., .., %v, %v, '%s', '%s')", fromViewConfig, toViewConfig, dirFromName, dirToName)
 	toParentPath := toViewConfig.Path
-	toFileType, err := toRepo.GetFileType(toViewConfig, toParentPath, dirToName)
+	toFileType, err := toRepo.GetFileType(ctx, toViewConfig, toParentPath, dirToName)
 	if err != nil {
-		return confirmOverwrite, err
+		return err
 	}
 	toPath := toRepo.GetFilePath(toParentPath, dirToName)
 	switch toFileType {
 	case model.ItemNone:
-		_, err = toRepo.CreateDir(toViewConfig, toParentPath, dirToName)
+		_, err = toRepo.CreateDir(ctx, toViewConfig, toParentPath, dirToName)
 		if err != nil {
 			logging.LogDebugf("ui/copyDirsOrFilestores() - err: %v", err)
-			return confirmOverwrite, err
+			return err
 		}
-		updateStatusf("Directory '%s' created.", toPath)
+		cs.statusf("Directory '%s' created.", toPath)
 	case model.ItemDirectory:
-		updateStatusf("Directory '%s' already exists.", toPath)
+		cs.statusf("Directory '%s' already exists.", toPath)
 	case model.ItemDpFilestore:
-		updateStatusf("DataPower filestore '%s' already exists.", toPath)
+		cs.statusf("DataPower filestore '%s' already exists.", toPath)
 	default:
 		errMsg := fmt.Sprintf("Non dir '%s' exists (%v), can't create dir.", toPath, toFileType)
 		logging.LogDebugf("ui/copyDirsOrFilestores() - %s", errMsg)
-		return confirmOverwrite, errs.Error(errMsg)
+		return errs.Error(errMsg)
 	}
 
 	fromViewConfigDir := model.ItemConfig{
@@ -1096,281 +1411,309 @@ func copyDirsOrFilestores(fromRepo, toRepo repo.Repo, fromViewConfig, toViewConf
 		DpAppliance: fromViewConfig.DpAppliance,
 		DpDomain:    fromViewConfig.DpDomain,
 		DpFilestore: fromViewConfig.DpFilestore}
-	items, err := fromRepo.GetList(&fromViewConfigDir)
+	items, err := fromRepo.GetList(ctx, &fromViewConfigDir)
 	if err != nil {
-		return confirmOverwrite, err
+		return err
 	}
 
-	showProgressDialog("Copying files from DataPower...")
-	defer hideProgressDialog()
 	for _, item := range items {
-		if item.Name != ".." {
+		if item.Name != ".." &&
+			!cs.ignored(fromRepo.GetFilePath(fromViewConfigDir.Path, item.Name),
+				item.Config.Type == model.ItemDirectory) {
 			toViewConfigDir := model.ItemConfig{Parent: toViewConfig,
+				Type:        model.ItemDirectory,
 				Path:        toRepo.GetFilePath(toViewConfig.Path, dirToName),
 				DpAppliance: toViewConfig.DpAppliance,
 				DpDomain:    toViewConfig.DpDomain,
 				DpFilestore: toViewConfig.DpFilestore}
-			confirmOverwrite, err = copyItem(fromRepo, toRepo, &fromViewConfigDir, &toViewConfigDir, item, confirmOverwrite)
+			err = cs.copyItem(ctx, fromRepo, toRepo, &fromViewConfigDir, &toViewConfigDir, item)
 			if err != nil {
-				return confirmOverwrite, err
+				if ctx.Err() != nil || err == errCopyAborted {
+					return err
+				}
+				cs.failedf("Item '%s' not copied: %v",
+					fromRepo.GetFilePath(fromViewConfigDir.Path, item.Name), err)
 			}
 		}
 	}
 
-	return confirmOverwrite, err
+	return nil
 }
 
-func copyFile(fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig, fileName, confirmOverwrite string) (string, error) {
-	logging.LogDebugf("ui/copyFile(.., .., %v, %v, '%s', '%s')",
-		fromViewConfig, toViewConfig, fileName, confirmOverwrite)
-	showProgressDialog("Copying file(s) from DataPower...")
-	defer hideProgressDialog()
-	updateProgressDialogMessagef("Preparing to copy file '%s' from %s to %s...",
-		fileName, fromRepo, toRepo)
-	res := confirmOverwrite
-	targetFileType, err := toRepo.GetFileType(toViewConfig, toViewConfig.Path, fileName)
+// copyFile checks if file can be copied as toFileName (and resolves conflict
+// if file already exists) and queues file transfer to copy workers.
+func (cs *copySession) copyFile(ctx context.Context, fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig, item model.Item, toFileName string) error {
+	logging.LogDebugf("ui/copyFile(.., .., %v, %v, %v, '%s')",
+		fromViewConfig, toViewConfig, item, toFileName)
+	targetFileType, err := toRepo.GetFileType(ctx, toViewConfig, toViewConfig.Path, toFileName)
 	if err != nil {
-		return res, err
+		return err
 	}
 
+	transfer := copyTransfer{fromRepo: fromRepo, toRepo: toRepo,
+		fromViewConfig: fromViewConfig, toViewConfig: toViewConfig,
+		fileName: item.Name, toFileName: toFileName, size: itemSize(item)}
 	switch targetFileType {
 	case model.ItemDirectory:
-		copyFileToDirStatus :=
-			fmt.Sprintf("ERROR: File '%s' could not be copied from '%s' to '%s' - directory with same name exists.",
-				fileName, fromViewConfig.Path, toViewConfig.Path)
-		updateStatus(copyFileToDirStatus)
+		cs.failedf("File '%s' could not be copied from '%s' to '%s' - directory with same name exists.",
+			toFileName, fromViewConfig.Path, toViewConfig.Path)
+		return nil
 	case model.ItemFile:
-		if res != "ya" && res != "na" {
-			logging.LogDebugf("ui/copyFile(), confirm overwrite: '%s'", res)
-			dialogResult := askUserInput(
-				fmt.Sprintf("Confirm overwrite of file '%s' at '%s' (y/ya/n/na): ",
-					fileName, toViewConfig.Path), "", false)
-			if dialogResult.dialogSubmitted {
-				res = dialogResult.inputAnswer
-			}
+		if cs.sameFiles[fromRepo.GetFilePath(fromViewConfig.Path, item.Name)] && toFileName == item.Name {
+			cs.statusf("Skipped file '%s' at '%s', same as source when panes were compared.",
+				toFileName, toViewConfig.Path)
+			return nil
 		}
-	case model.ItemNone:
-		res = "y"
-	}
-	if res == "y" || res == "ya" {
-		switch targetFileType {
-		case model.ItemFile, model.ItemNone:
-			fBytes, err := fromRepo.GetFile(fromViewConfig, fileName)
-			if err != nil {
-				return res, err
-			}
-			copySuccess, err := toRepo.UpdateFile(toViewConfig, fileName, fBytes)
-			if err != nil {
-				return res, err
-			}
-			logging.LogDebugf("ui/copyFile(): %v", copySuccess)
-			if copySuccess {
-				copySuccessStatus := fmt.Sprintf("File '%s' copied from '%s' to '%s'.",
-					fileName, fromViewConfig.Path, toViewConfig.Path)
-				updateStatus(copySuccessStatus)
-			} else {
-				copyErrStatus := fmt.Sprintf("ERROR: File '%s' not copied from '%s' to '%s'.",
-					fileName, fromViewConfig.Path, toViewConfig.Path)
-				updateStatus(copyErrStatus)
-			}
+		action, newName, err := cs.resolveConflict(ctx, copyConflict{
+			kind: "File", name: toFileName, targetPath: toViewConfig.Path,
+			source: item, target: cs.targetItem(ctx, toRepo, toViewConfig, toFileName),
+			sourceContent: func(ctx context.Context) ([]byte, error) {
+				return fromRepo.GetFile(ctx, fromViewConfig, item.Name)
+			},
+			targetContent: func(ctx context.Context) ([]byte, error) {
+				return toRepo.GetFile(ctx, toViewConfig, toFileName)
+			}})
+		if err != nil {
+			return err
+		}
+		logging.LogDebugf("ui/copyFile(), conflict action: %d", action)
+		switch action {
+		case conflictSkip:
+			cs.statusf("Skipped existing file '%s' at '%s'.", toFileName, toViewConfig.Path)
+			return nil
+		case conflictRename:
+			return cs.copyFile(ctx, fromRepo, toRepo, fromViewConfig, toViewConfig, item, newName)
+		case conflictOverwriteIfDifferent:
+			transfer.onlyIfDifferent = true
 		}
-	} else {
-		updateStatusf("Canceled overwrite of '%s'", fileName)
 	}
 
-	logging.LogDebugf("ui/copyFile(), res: '%s'", res)
-	return res, nil
+	cs.addTransfer(ctx, transfer)
+	return nil
 }
 
-func copyObjectToFile(itemConfig *model.ItemConfig, itemName string,
-	fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig,
-	confirmOverwrite string) (string, error) {
-	logging.LogDebugf("ui/copyObjectToFile(%v, '%s', .., .., %v, %v, '%s')",
-		itemConfig, itemName, fromViewConfig, toViewConfig, confirmOverwrite)
-	res := confirmOverwrite
-
-	objectName := itemName
-	var objectFileSuffix string
-
-	switch dp.Repo.GetManagementInterface() {
+// objectFileSuffix returns suffix of files DataPower objects are saved to
+// (depends on DataPower management interface used).
+func (cs *copySession) objectFileSuffix() (string, error) {
+	switch cs.dpRepo.GetManagementInterface() {
 	case config.DpInterfaceRest:
-		objectFileSuffix = ".json"
+		return ".json", nil
 	case config.DpInterfaceSoma:
-		objectFileSuffix = ".xml"
+		return ".xml", nil
 	default:
-		logging.LogDebug("ui/copyObjectToFile(), using neither REST neither SOMA.")
+		logging.LogDebug("ui/objectFileSuffix(), using neither REST neither SOMA.")
 		return "", errs.Error("DataPower management interface not set.")
 	}
-	objectFileName := itemName + objectFileSuffix
-	logging.LogDebugf("ui/copyObjectToFile(), objectName: '%s', objectFileName: '%s'.",
-		objectName, objectFileName)
+}
+
+func (cs *copySession) copyObjectToFile(ctx context.Context, item model.Item,
+	fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig) error {
+	logging.LogDebugf("ui/copyObjectToFile(%v, .., .., %v, %v)",
+		item, fromViewConfig, toViewConfig)
+	objectFileSuffix, err := cs.objectFileSuffix()
+	if err != nil {
+		return err
+	}
+	return cs.copyObjectToFileAs(ctx, item, fromViewConfig, toRepo, toViewConfig, item.Name+objectFileSuffix)
+}
+
+// copyObjectToFileAs saves DataPower object to file objectFileName (and
+// resolves conflict if file already exists).
+func (cs *copySession) copyObjectToFileAs(ctx context.Context, item model.Item,
+	fromViewConfig *model.ItemConfig, toRepo repo.Repo, toViewConfig *model.ItemConfig,
+	objectFileName string) error {
+	logging.LogDebugf("ui/copyObjectToFileAs(%v, %v, .., %v, '%s')",
+		item, fromViewConfig, toViewConfig, objectFileName)
+	itemConfig := item.Config
+	getObject := func(ctx context.Context) ([]byte, error) {
+		return cs.dpRepo.GetObject(ctx, itemConfig.DpDomain, itemConfig.Path, item.Name, false)
+	}
 
-	targetFileType, err := toRepo.GetFileType(toViewConfig, toViewConfig.Path, objectFileName)
+	targetFileType, err := toRepo.GetFileType(ctx, toViewConfig, toViewConfig.Path, objectFileName)
 	if err != nil {
-		return res, err
+		return err
 	}
 
+	onlyIfDifferent := false
 	switch targetFileType {
 	case model.ItemDirectory:
-		copyFileToDirStatus :=
-			fmt.Sprintf("ERROR: Object '%s' could not be copied from '%s' to '%s' - directory with same name exists.",
-				objectFileName, fromViewConfig.Path, toViewConfig.Path)
-		updateStatus(copyFileToDirStatus)
+		cs.statusf("ERROR: Object '%s' could not be copied from '%s' to '%s' - directory with same name exists.",
+			objectFileName, fromViewConfig.Path, toViewConfig.Path)
+		return nil
 	case model.ItemFile:
-		if res != "ya" && res != "na" {
-			logging.LogDebugf("ui/copyObjectToFile(), confirm overwrite: '%s'", res)
-			dialogResult := askUserInput(
-				fmt.Sprintf("Confirm overwrite of file '%s' at '%s' (y/ya/n/na): ",
-					objectFileName, toViewConfig.Path), "", false)
-			if dialogResult.dialogSubmitted {
-				res = dialogResult.inputAnswer
-			}
+		action, newName, err := cs.resolveConflict(ctx, copyConflict{
+			kind: "File", name: objectFileName, targetPath: toViewConfig.Path,
+			target:        cs.targetItem(ctx, toRepo, toViewConfig, objectFileName),
+			sourceContent: getObject,
+			targetContent: func(ctx context.Context) ([]byte, error) {
+				return toRepo.GetFile(ctx, toViewConfig, objectFileName)
+			}})
+		if err != nil {
+			return err
+		}
+		switch action {
+		case conflictSkip:
+			cs.statusf("Skipped existing file '%s' at '%s'.", objectFileName, toViewConfig.Path)
+			return nil
+		case conflictRename:
+			return cs.copyObjectToFileAs(ctx, item, fromViewConfig, toRepo, toViewConfig, newName)
+		case conflictOverwriteIfDifferent:
+			onlyIfDifferent = true
 		}
-	case model.ItemNone:
-		res = "y"
 	}
-	if res == "y" || res == "ya" {
-		switch targetFileType {
-		case model.ItemFile, model.ItemNone:
-			fBytes, err := dp.Repo.GetObject(itemConfig.DpDomain, itemConfig.Path, objectName, false)
-			if err != nil {
-				return res, err
-			}
-			copySuccess, err := toRepo.UpdateFile(toViewConfig, objectFileName, fBytes)
-			if err != nil {
-				return res, err
-			}
-			logging.LogDebugf("ui/copyObject(): %v", copySuccess)
-			if copySuccess {
-				copySuccessStatus := fmt.Sprintf("File '%s' copied from '%s' to '%s'.",
-					objectFileName, fromViewConfig.Path, toViewConfig.Path)
-				updateStatus(copySuccessStatus)
-			} else {
-				copyErrStatus := fmt.Sprintf("ERROR: File '%s' not copied from '%s' to '%s'.",
-					objectFileName, fromViewConfig.Path, toViewConfig.Path)
-				updateStatus(copyErrStatus)
-			}
+
+	fBytes, err := getObject(ctx)
+	if err != nil {
+		return err
+	}
+	if onlyIfDifferent {
+		targetBytes, err := toRepo.GetFile(ctx, toViewConfig, objectFileName)
+		if err != nil {
+			return err
 		}
+		if sameContent(fBytes, targetBytes) {
+			cs.statusf("Object '%s' skipped, '%s' at '%s' has the same content.",
+				item.Name, objectFileName, toViewConfig.Path)
+			return nil
+		}
+	}
+	copySuccess, err := toRepo.UpdateFile(ctx, toViewConfig, objectFileName, fBytes)
+	if err != nil {
+		return err
+	}
+	logging.LogDebugf("ui/copyObjectToFileAs(): %v", copySuccess)
+	if copySuccess {
+		cs.statusf("File '%s' copied from '%s' to '%s'.",
+			objectFileName, fromViewConfig.Path, toViewConfig.Path)
 	} else {
-		updateStatusf("Canceled overwrite of '%s'", objectFileName)
+		cs.statusf("ERROR: File '%s' not copied from '%s' to '%s'.",
+			objectFileName, fromViewConfig.Path, toViewConfig.Path)
 	}
 
-	logging.LogDebugf("ui/copyObjectToFile(), res: '%s'", res)
-	return res, nil
+	return nil
 }
 
-func copyFileToObject(itemConfig *model.ItemConfig, itemName string,
-	fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig,
-	confirmOverwrite string) (string, error) {
-	logging.LogDebugf("ui/copyFileToObject(%v, '%s', .., .., %v, %v, '%s')",
-		itemConfig, itemName, fromViewConfig, toViewConfig, confirmOverwrite)
-	res := confirmOverwrite
-
-	var objectFileSuffix string
-
-	switch dp.Repo.GetManagementInterface() {
-	case config.DpInterfaceRest:
-		objectFileSuffix = ".json"
-	case config.DpInterfaceSoma:
-		objectFileSuffix = ".xml"
-	default:
-		logging.LogDebug("ui/copyFileToObject(), using neither REST neither SOMA.")
-		return "", errs.Error("DataPower management interface not set.")
+func (cs *copySession) copyFileToObject(ctx context.Context, item model.Item,
+	fromRepo, toRepo repo.Repo, fromViewConfig, toViewConfig *model.ItemConfig) error {
+	logging.LogDebugf("ui/copyFileToObject(%v, .., .., %v, %v)",
+		item, fromViewConfig, toViewConfig)
+	objectFileSuffix, err := cs.objectFileSuffix()
+	if err != nil {
+		return err
 	}
 
-	if !strings.HasSuffix(itemName, objectFileSuffix) {
-		return "", errs.Errorf("Copy from file '%s' to object - wrong suffix, '%s' expected.",
-			itemName, objectFileSuffix)
+	if !strings.HasSuffix(item.Name, objectFileSuffix) {
+		return errs.Errorf("Copy from file '%s' to object - wrong suffix, '%s' expected.",
+			item.Name, objectFileSuffix)
 	}
-	objectFileName := itemName
+	objectFileName := item.Name
 
-	objectBytesLocal, err := localfs.Repo.GetFile(fromViewConfig, objectFileName)
+	objectBytesLocal, err := localfs.Repo.GetFile(ctx, fromViewConfig, objectFileName)
 	if err != nil {
-		return "", err
-	}
-	objectClassName, objectName, err := dp.Repo.ParseObjectClassAndName(objectBytesLocal)
-	if err != nil {
-		return "", err
+		return err
 	}
-	objectBytesDp, err := dp.Repo.GetObject(
-		toViewConfig.DpDomain, objectClassName, objectName, false)
+	objectClassName, objectName, err := cs.dpRepo.ParseObjectClassAndName(objectBytesLocal)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	targetItemType := model.ItemDpObject
-	if objectBytesDp == nil {
-		targetItemType = model.ItemNone
-	}
+	for {
+		objectBytesDp, err := cs.dpRepo.GetObject(ctx,
+			toViewConfig.DpDomain, objectClassName, objectName, false)
+		if err != nil {
+			return err
+		}
 
-	existingObject := false
-	switch targetItemType {
-	case model.ItemDpObject:
-		existingObject = true
-		if res != "ya" && res != "na" {
-			logging.LogDebugf("ui/copyFileToObject(), confirm overwrite: '%s'", res)
-			dialogResult := askUserInput(
-				fmt.Sprintf("Confirm overwrite of object '%s' of class '%s' from  file '%s' (y/ya/n/na): ",
-					objectName, objectClassName, objectFileName), "", false)
-			if dialogResult.dialogSubmitted {
-				res = dialogResult.inputAnswer
+		existingObject := objectBytesDp != nil
+		logging.LogDebugf("ui/copyFileToObject(), existingObject: %t.", existingObject)
+		if existingObject {
+			action, newName, err := cs.resolveConflict(ctx, copyConflict{
+				kind: "Object", name: objectName, targetPath: objectClassName,
+				source: item,
+				sourceContent: func(ctx context.Context) ([]byte, error) {
+					return objectBytesLocal, nil
+				},
+				targetContent: func(ctx context.Context) ([]byte, error) {
+					return objectBytesDp, nil
+				}})
+			if err != nil {
+				return err
+			}
+			switch action {
+			case conflictSkip:
+				cs.statusf("Skipped existing object '%s' of class '%s'.", objectName, objectClassName)
+				return nil
+			case conflictRename:
+				objectBytesLocal, err = cs.dpRepo.RenameObject(objectBytesLocal, newName)
+				if err != nil {
+					return err
+				}
+				objectName = newName
+				continue
+			case conflictOverwriteIfDifferent:
+				if sameContent(objectBytesLocal, objectBytesDp) {
+					cs.statusf("Object '%s' of class '%s' skipped, it has the same content as file '%s'.",
+						objectName, objectClassName, objectFileName)
+					return nil
+				}
 			}
 		}
-	case model.ItemNone:
-		res = "y"
-	default:
-		return "", errs.Errorf("Unknown target item type (%s).", targetItemType)
-	}
-
-	logging.LogDebugf("ui/copyFileToObject(), targetItemType: '%s', existingObject: %t, res: '%s'.",
-		targetItemType, existingObject, res)
 
-	if res == "y" || res == "ya" {
-		err = dp.Repo.SetObject(
+		err = cs.dpRepo.SetObject(ctx,
 			toViewConfig.DpDomain, objectClassName, objectName, objectBytesLocal, existingObject)
 		if err != nil {
-			return res, err
+			return err
 		}
 		logging.LogDebugf("ui/copyFileToObject() Object '%s' of class '%s' copied from file '%s' to the appliance.",
 			objectName, objectClassName, objectFileName)
-		updateStatusf("Object '%s' of class '%s' copied from file '%s' to the appliance.",
+		cs.statusf("Object '%s' of class '%s' copied from file '%s' to the appliance.",
 			objectName, objectClassName, objectFileName)
-	} else {
-		updateStatusf("Canceled overwrite of '%s'", objectName)
+		return nil
 	}
-
-	logging.LogDebugf("ui/copyFileToObject(), res: '%s'", res)
-	return res, nil
 }
 
-func exportDomain(fromViewConfig, toViewConfig *model.ItemConfig, domainName string) error {
+// exportDomain exports DataPower domain to local file. Export started by user
+// runs as background job since big domain export can take a long time.
+func (cs *copySession) exportDomain(ctx context.Context, fromViewConfig, toViewConfig *model.ItemConfig, domainName string) error {
 	logging.LogDebugf("ui/exportDomain(%v, %v, '%s')", fromViewConfig, toViewConfig, domainName)
+	if cs.job == nil {
+		jobDpRepo := dp.Repo.Clone()
+		startJob(fmt.Sprintf("Export domain '%s'", domainName), func(ctx context.Context, j *job) error {
+			jobSession := copySession{dpRepo: jobDpRepo, dpViewMode: jobDpRepo.DpViewMode, job: j}
+			return jobSession.exportDomain(ctx, fromViewConfig, toViewConfig, domainName)
+		})
+		return nil
+	}
+
 	exportFileName := fromViewConfig.DpAppliance + "_" + domainName + "_" + time.Now().Format("20060102150405") + ".zip"
 	logging.LogDebugf("ui/exportDomain() exportFileName: '%s'", exportFileName)
-	showProgressDialogf("Exporting domain '%s'...", domainName)
-	exportFileBytes, err := dp.Repo.ExportDomain(domainName, exportFileName)
-	hideProgressDialog()
+	cs.statusf("Exporting domain '%s'...", domainName)
+	exportFileBytes, err := cs.dpRepo.ExportDomain(ctx, domainName, exportFileName)
 	if err != nil {
 		return err
 	}
-	_, err = localfs.Repo.UpdateFile(toViewConfig, exportFileName, exportFileBytes)
+	_, err = localfs.Repo.UpdateFile(ctx, toViewConfig, exportFileName, exportFileBytes)
 	if err == nil {
-		updateStatusf("Domain '%s' exported to file '%s' on path '%s'.",
+		cs.statusf("Domain '%s' exported to file '%s' on path '%s'.",
 			domainName, exportFileName, toViewConfig.Path)
 	}
 	return err
 }
 
-func exportAppliance(dpApplianceConfig, toViewConfig *model.ItemConfig, applianceConfigName string) error {
+// exportAppliance exports whole DataPower appliance to local file. Export
+// started by user runs as background job since appliance export can take a
+// long time.
+func (cs *copySession) exportAppliance(ctx context.Context, dpApplianceConfig, toViewConfig *model.ItemConfig, applianceConfigName string) error {
 	logging.LogDebugf("ui/exportAppliance(%v, %v)", dpApplianceConfig, toViewConfig)
 	applianceName := dpApplianceConfig.DpAppliance
-	exportFileName := applianceName + "_" + time.Now().Format("20060102150405") + ".zip"
-	logging.LogDebugf("ui/exportAppliance() exportFileName: '%s'", exportFileName)
 
 	applicanceConfig := config.Conf.DataPowerAppliances[applianceName]
 	dpTransientPassword := config.DpTransientPasswordMap[applianceName]
-	logging.LogDebugf("ui/exportAppliance(), applicanceConfig: '%s'", applicanceConfig)
+	logging.LogDebugf("ui/exportAppliance(), applicanceConfig: '%v'", applicanceConfig)
 	if applicanceConfig.Password == "" && dpTransientPassword == "" {
+		if cs.job != nil {
+			return errs.Errorf("DataPower password for appliance '%s' is not set.", applianceName)
+		}
 		logging.LogDebugf("ui/exportAppliance(), before asking password.")
 		dialogResult := askUserInput("Please enter DataPower password: ", "", true)
 		logging.LogDebugf("ui/exportAppliance(), after asking password (%#v).", dialogResult)
@@ -1380,21 +1723,86 @@ func exportAppliance(dpApplianceConfig, toViewConfig *model.ItemConfig, applianc
 		setCurrentDpPlainPassword(dialogResult.inputAnswer)
 	}
 
-	showProgressDialogf("Exporting DataPower appliance '%s'...", applianceName)
-	exportFileBytes, err := dp.Repo.ExportAppliance(applianceConfigName, exportFileName)
-	hideProgressDialog()
+	if cs.job == nil {
+		jobDpRepo := dp.Repo.Clone()
+		startJob(fmt.Sprintf("Export appliance '%s'", applianceName), func(ctx context.Context, j *job) error {
+			jobSession := copySession{dpRepo: jobDpRepo, dpViewMode: jobDpRepo.DpViewMode, job: j}
+			return jobSession.exportAppliance(ctx, dpApplianceConfig, toViewConfig, applianceConfigName)
+		})
+		return nil
+	}
+
+	exportFileName := applianceName + "_" + time.Now().Format("20060102150405") + ".zip"
+	logging.LogDebugf("ui/exportAppliance() exportFileName: '%s'", exportFileName)
+	cs.statusf("Exporting DataPower appliance '%s'...", applianceName)
+	exportFileBytes, err := cs.dpRepo.ExportAppliance(ctx, applianceConfigName, exportFileName)
 	if err != nil {
 		return err
 	}
-	_, err = localfs.Repo.UpdateFile(toViewConfig, exportFileName, exportFileBytes)
+	_, err = localfs.Repo.UpdateFile(ctx, toViewConfig, exportFileName, exportFileBytes)
 	if err == nil {
-		updateStatusf("Appliance '%s' exported to file '%s' on path '%s'.",
+		cs.statusf("Appliance '%s' exported to file '%s' on path '%s'.",
 			applianceName, exportFileName, toViewConfig.Path)
 	}
 	return err
 }
 
-func createEmptyFile(m *model.Model) error {
+func importDomainOrAppliance(ctx context.Context, dpItem *model.Item, fromViewConfig *model.ItemConfig, itemsToImport []model.Item) error {
+	logging.LogDebugf("ui/importDomainOrAppliance(%v, %v, %v)", dpItem, fromViewConfig, itemsToImport)
+	if len(itemsToImport) != 1 || itemsToImport[0].Config.Type != model.ItemFile ||
+		!strings.HasSuffix(itemsToImport[0].Name, ".zip") {
+		return errs.Errorf("Select one zip file to import to DataPower %s '%s'.",
+			dpItem.Config.Type.UserFriendlyString(), dpItem.Name)
+	}
+	importFileName := itemsToImport[0].Name
+
+	var importTarget string
+	switch dpItem.Config.Type {
+	case model.ItemDpDomain:
+		importTarget = fmt.Sprintf("domain '%s'", dpItem.Name)
+	default:
+		importTarget = fmt.Sprintf("appliance '%s'", dpItem.Name)
+		applicanceConfig := config.Conf.DataPowerAppliances[dpItem.Name]
+		dpTransientPassword := config.DpTransientPasswordMap[dpItem.Name]
+		if applicanceConfig.Password == "" && dpTransientPassword == "" {
+			dialogResult := askUserInput("Please enter DataPower password: ", "", true)
+			if dialogResult.dialogCanceled || dialogResult.inputAnswer == "" {
+				return nil
+			}
+			config.DpTransientPasswordMap[dpItem.Name] = dialogResult.inputAnswer
+		}
+	}
+
+	dialogResult := askUserInput(
+		fmt.Sprintf("Confirm import of file '%s' to %s (y/n): ", importFileName, importTarget), "", false)
+	if dialogResult.dialogCanceled || dialogResult.inputAnswer != "y" {
+		updateStatusf("Canceled import of '%s' to %s.", importFileName, importTarget)
+		return nil
+	}
+
+	importFileBytes, err := localfs.Repo.GetFile(ctx, fromViewConfig, importFileName)
+	if err != nil {
+		return err
+	}
+
+	showProgressDialogf("Importing '%s' to %s...", importFileName, importTarget)
+	var importResults []byte
+	switch dpItem.Config.Type {
+	case model.ItemDpDomain:
+		importResults, err = dp.Repo.ImportDomain(ctx, dpItem.Name, importFileName, importFileBytes)
+	default:
+		importResults, err = dp.Repo.ImportAppliance(ctx, dpItem.Name, importFileName, importFileBytes)
+	}
+	hideProgressDialog()
+	if err != nil {
+		return err
+	}
+	updateStatusf("File '%s' imported to %s.", importFileName, importTarget)
+
+	return viewContent("Import_Results", importResults)
+}
+
+func createEmptyFile(ctx context.Context, m *model.Model) error {
 	logging.LogDebugf("ui/createEmptyFile()")
 	side := m.CurrSide()
 	viewConfig := m.ViewConfig(side)
@@ -1404,20 +1812,20 @@ func createEmptyFile(m *model.Model) error {
 		if dialogResult.dialogSubmitted {
 			fileName := dialogResult.inputAnswer
 			r := repos[side]
-			targetFileType, err := r.GetFileType(viewConfig, viewConfig.Path, fileName)
+			targetFileType, err := r.GetFileType(ctx, viewConfig, viewConfig.Path, fileName)
 			if err != nil {
 				return err
 			}
 			if targetFileType != model.ItemNone {
 				return errs.Errorf("File with name '%s' already exists at '%s'.", fileName, viewConfig.Path)
 			}
-			_, err = r.UpdateFile(viewConfig, fileName, make([]byte, 0))
+			_, err = r.UpdateFile(ctx, viewConfig, fileName, make([]byte, 0))
 			if err != nil {
 				return errs.Errorf("Can't create file with name '%s' - '%v'.", fileName, err)
 			}
 
 			updateStatusf("File '%s' created.", fileName)
-			return showItem(side, viewConfig, ".")
+			return showItem(ctx, side, viewConfig, ".")
 		}
 		updateStatus("Creation of new file canceled.")
 	case model.ItemNone:
@@ -1435,22 +1843,131 @@ func createEmptyFile(m *model.Model) error {
 					return err
 				}
 				if changed {
-					err := config.Conf.SetDpApplianceConfig(confName, newFileContent)
+					err := setDpApplianceConfig(confName, newFileContent)
 					if err != nil {
 						return err
 					}
 				}
 
 				updateStatusf("DataPower configuration '%s' created.", confName)
-				return showItem(side, viewConfig, ".")
+				return showItem(ctx, side, viewConfig, ".")
 			}
 			updateStatus("Creation of new DataPower configuration canceled.")
 		}
+	case model.ItemDpObjectClassList, model.ItemDpObjectClass:
+		return createDpObject(ctx, m)
 	}
 	return nil
 }
 
-func cloneCurrent(m *model.Model) error {
+func createDpObject(ctx context.Context, m *model.Model) error {
+	logging.LogDebug("ui/createDpObject()")
+	side := m.CurrSide()
+	viewConfig := m.ViewConfig(side)
+
+	objectClass := ""
+	if viewConfig.Type == model.ItemDpObjectClass {
+		objectClass = viewConfig.Path
+	}
+	classDialogResult := askUserInput(
+		"Enter class (or part of class name) of DataPower object to create: ", objectClass, false)
+	if !classDialogResult.dialogSubmitted {
+		updateStatus("Creation of new DataPower object canceled.")
+		return nil
+	}
+
+	showProgressDialog("Fetching DataPower object classes...")
+	classNames, err := dp.Repo.GetObjectClasses(ctx)
+	hideProgressDialog()
+	if err != nil {
+		return err
+	}
+
+	classFilter := strings.ToLower(classDialogResult.inputAnswer)
+	matchingClassNames := make([]string, 0)
+	objectClass = ""
+	for _, className := range classNames {
+		if strings.ToLower(className) == classFilter {
+			objectClass = className
+			break
+		}
+		if strings.Contains(strings.ToLower(className), classFilter) {
+			matchingClassNames = append(matchingClassNames, className)
+		}
+	}
+	if objectClass == "" {
+		switch len(matchingClassNames) {
+		case 0:
+			return errs.Errorf("Can't find DataPower object class matching '%s'.",
+				classDialogResult.inputAnswer)
+		case 1:
+			objectClass = matchingClassNames[0]
+		default:
+			progressDialogSession.waitUserInput = true
+			selectionSession := selectFromList("Select a DataPower object class:", matchingClassNames, 0)
+			progressDialogSession.waitUserInput = false
+			if !selectionSession.dialogSubmitted {
+				updateStatus("Creation of new DataPower object canceled.")
+				return nil
+			}
+			objectClass = matchingClassNames[selectionSession.selectionIdx]
+		}
+	}
+
+	nameDialogResult := askUserInput(
+		fmt.Sprintf("Enter name of the new %s object: ", objectClass), "", false)
+	if !nameDialogResult.dialogSubmitted || nameDialogResult.inputAnswer == "" {
+		updateStatus("Creation of new DataPower object canceled.")
+		return nil
+	}
+	objectName := nameDialogResult.inputAnswer
+
+	existingObject, err := dp.Repo.GetObject(ctx, viewConfig.DpDomain, objectClass, objectName, false)
+	if err != nil {
+		return err
+	}
+	if existingObject != nil {
+		return errs.Errorf("DataPower object '%s' of class '%s' already exists.", objectName, objectClass)
+	}
+
+	objectSkeleton, err := dp.Repo.CreateObjectSkeleton(ctx, objectClass, objectName)
+	if err != nil {
+		return err
+	}
+	changed, newObjectContent, err := extprogs.Edit(getObjectTmpName(objectName), objectSkeleton)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(newObjectContent)) == 0 {
+		updateStatusf("Creation of new DataPower object '%s' of class '%s' canceled.", objectName, objectClass)
+		return nil
+	}
+	if !changed {
+		// Skeleton already contains required properties with default values.
+		confirmDialogResult := askUserInput(
+			fmt.Sprintf("Object skeleton not changed, create %s object '%s' with default values (y/n): ",
+				objectClass, objectName), "", false)
+		if confirmDialogResult.dialogCanceled || confirmDialogResult.inputAnswer != "y" {
+			updateStatusf("Creation of new DataPower object '%s' of class '%s' canceled.", objectName, objectClass)
+			return nil
+		}
+	}
+
+	// User could change object name (or even class) while editing object.
+	objectClass, objectName, err = dp.Repo.ParseObjectClassAndName(newObjectContent)
+	if err != nil {
+		return err
+	}
+	err = dp.Repo.SetObject(ctx, viewConfig.DpDomain, objectClass, objectName, newObjectContent, false)
+	if err != nil {
+		return err
+	}
+
+	updateStatusf("DataPower object '%s' of class '%s' created.", objectName, objectClass)
+	return showItem(ctx, side, viewConfig, ".")
+}
+
+func cloneCurrent(ctx context.Context, m *model.Model) error {
 	logging.LogDebug("ui/cloneCurrent()")
 	currentItem := m.CurrItem()
 	if currentItem.Name == ".." {
@@ -1479,14 +1996,14 @@ func cloneCurrent(m *model.Model) error {
 			if err != nil {
 				return err
 			}
-			err = config.Conf.SetDpApplianceConfig(newItemName, clonedConfigContent)
+			err = setDpApplianceConfig(newItemName, clonedConfigContent)
 		case model.ItemDpObject:
 			// func (r *dpRepo) GetObject(dpDomain, objectClass, objectName string, persisted bool) ([]byte, error) {
 			dpDomain := currentItem.Config.DpDomain
 			objectClass := currentItem.Config.Path
 			objectNameOld := currentItem.Name
 			objectNameNew := newItemName
-			objectConfigToOverwrite, err := dp.Repo.GetObject(dpDomain, objectClass, objectNameNew, false)
+			objectConfigToOverwrite, err := dp.Repo.GetObject(ctx, dpDomain, objectClass, objectNameNew, false)
 			logging.LogDebugf("ui/cloneCurrent(), err: %v, objectConfigToOverwrite: '%v'", err, objectConfigToOverwrite)
 			if err != nil {
 				return err
@@ -1503,7 +2020,7 @@ func cloneCurrent(m *model.Model) error {
 					return nil
 				}
 			}
-			objectConfigOld, err := dp.Repo.GetObject(dpDomain, objectClass, objectNameOld, false)
+			objectConfigOld, err := dp.Repo.GetObject(ctx, dpDomain, objectClass, objectNameOld, false)
 			logging.LogDebugf("ui/cloneCurrent(), err: %v, objectConfigOld: '%v'", err, objectConfigOld)
 			if err != nil {
 				return err
@@ -1513,13 +2030,13 @@ func cloneCurrent(m *model.Model) error {
 			if err != nil {
 				return err
 			}
-			err = dp.Repo.SetObject(dpDomain, objectClass, objectNameNew, objectConfigNew, existingObject)
+			err = dp.Repo.SetObject(ctx, dpDomain, objectClass, objectNameNew, objectConfigNew, existingObject)
 			if err != nil {
 				return err
 			}
 
 			updateStatusf("DataPower object '%s' of class '%s' cloned to '%s'.", objectClass, objectNameOld, objectNameNew)
-			return showItem(side, viewConfig, ".")
+			return showItem(ctx, side, viewConfig, ".")
 		default:
 			return errs.Errorf("Can't clone item '%s' (%s).",
 				currentItem.Name, currentItem.Config.Type.UserFriendlyString())
@@ -1531,7 +2048,7 @@ func cloneCurrent(m *model.Model) error {
 
 		updateStatusf("'%s' (%s) cloned to '%s'.",
 			currentItem.Name, currentItem.Config.Type.UserFriendlyString(), newItemName)
-		return showItem(side, viewConfig, ".")
+		return showItem(ctx, side, viewConfig, ".")
 	} else {
 		updateStatusf("Clonning of '%s' (%s) canceled.",
 			currentItem.Name, currentItem.Config.Type.UserFriendlyString())
@@ -1540,23 +2057,23 @@ func cloneCurrent(m *model.Model) error {
 	return nil
 }
 
-func createDirectoryOrDomain(m *model.Model) error {
+func createDirectoryOrDomain(ctx context.Context, m *model.Model) error {
 	logging.LogDebug("ui/createDirectoryOrDomain()")
 
 	side := m.CurrSide()
 	viewConfig := m.ViewConfig(side)
 	switch viewConfig.Type {
 	case model.ItemDirectory, model.ItemDpFilestore:
-		return createDirectory(m)
+		return createDirectory(ctx, m)
 	case model.ItemDpConfiguration:
-		return createDomain(m)
+		return createDomain(ctx, m)
 	default:
 		return errs.Errorf("Can't create directory, parent type '%s' doesn't support directory creation.",
 			viewConfig.Type.UserFriendlyString())
 	}
 }
 
-func createDirectory(m *model.Model) error {
+func createDirectory(ctx context.Context, m *model.Model) error {
 	logging.LogDebug("ui/createDirectory()")
 	dialogResult := askUserInput("Enter directory name to create: ", "", false)
 	if dialogResult.dialogSubmitted {
@@ -1564,46 +2081,46 @@ func createDirectory(m *model.Model) error {
 		side := m.CurrSide()
 		viewConfig := m.ViewConfig(side)
 		r := repos[side]
-		targetFileType, err := r.GetFileType(viewConfig, viewConfig.Path, dirName)
+		targetFileType, err := r.GetFileType(ctx, viewConfig, viewConfig.Path, dirName)
 		if err != nil {
 			return err
 		}
 		if targetFileType != model.ItemNone {
 			return errs.Errorf("Directory with name '%s' already exists at '%s'.", dirName, viewConfig.Path)
 		}
-		_, err = r.CreateDir(viewConfig, viewConfig.Path, dirName)
+		_, err = r.CreateDir(ctx, viewConfig, viewConfig.Path, dirName)
 		if err != nil {
 			return errs.Errorf("Can't create directory with name '%s' - '%v'.", dirName, err)
 		}
 
 		updateStatusf("Directory '%s' created.", dirName)
-		return showItem(side, viewConfig, ".")
+		return showItem(ctx, side, viewConfig, ".")
 	}
 	updateStatus("Creation of new directory canceled.")
 	return nil
 }
 
-func createDomain(m *model.Model) error {
+func createDomain(ctx context.Context, m *model.Model) error {
 	logging.LogDebug("ui/createDomain()")
 	dialogResult := askUserInput("Enter domain name to create: ", "", false)
 	if dialogResult.dialogSubmitted {
 		domainName := dialogResult.inputAnswer
 		side := m.CurrSide()
 		viewConfig := m.ViewConfig(side)
-		err := dp.Repo.CreateDomain(domainName)
+		err := dp.Repo.CreateDomain(ctx, domainName)
 		if err != nil {
 			return err
 		}
 
 		updateStatusf("Domain '%s' created.", domainName)
-		return showItem(side, viewConfig, ".")
+		return showItem(ctx, side, viewConfig, ".")
 	}
 
 	updateStatus("Creation of new directory canceled.")
 	return nil
 }
 
-func deleteCurrent(m *model.Model) error {
+func deleteCurrent(ctx context.Context, m *model.Model) error {
 	logging.LogDebug("ui/deleteCurrent()")
 	selectedItems := getSelectedOrCurrent(m)
 
@@ -1631,6 +2148,23 @@ func deleteCurrent(m *model.Model) error {
 				item.Name, item.Config.Type.UserFriendlyString())
 			cancelMsg = fmt.Sprintf("Canceled deleting of '%s' (%s).",
 				item.Name, item.Config.Type.UserFriendlyString())
+		case model.ItemDpDomain:
+			if item.Name == ".." {
+				return errs.Errorf("Won't delete parent item '%s' (%s) at '%s', aborting...",
+					item.Name, item.Config.Type.UserFriendlyString(), viewConfig.Path)
+			}
+			if item.Name == "default" {
+				return errs.Errorf("Won't delete DataPower '%s' domain, aborting...", item.Name)
+			}
+			confirmMsg =
+				fmt.Sprintf("Confirm deletion of '%s' (%s) with all its objects and files (y/ya/n/na): ",
+					item.Name, item.Config.Type.UserFriendlyString())
+			successMsg = fmt.Sprintf("Successfully deleted '%s' (%s).",
+				item.Name, item.Config.Type.UserFriendlyString())
+			errorMsg = fmt.Sprintf("Couldn't delete '%s' (%s).",
+				item.Name, item.Config.Type.UserFriendlyString())
+			cancelMsg = fmt.Sprintf("Canceled deleting of '%s' (%s).",
+				item.Name, item.Config.Type.UserFriendlyString())
 		case model.ItemDpStatusClass:
 			switch item.Name {
 			case "StylesheetCachingSummary", "DocumentCachingSummary",
@@ -1675,12 +2209,22 @@ func deleteCurrent(m *model.Model) error {
 			var err error
 			switch item.Config.Type {
 			case model.ItemDirectory, model.ItemFile, model.ItemDpConfiguration, model.ItemDpObject:
-				res, err = repos[m.CurrSide()].Delete(viewConfig, item.Config.Type, viewConfig.Path, item.Name)
+				res, err = repos[m.CurrSide()].Delete(ctx, viewConfig, item.Config.Type, viewConfig.Path, item.Name)
+			case model.ItemDpDomain:
+				// Domain deletion has to be confirmed by entering domain name,
+				// even if deletion of all items is confirmed.
+				dialogResult := askUserInput(
+					"Are you really sure? Enter name of the domain to delete: ", "", false)
+				if !dialogResult.dialogSubmitted || dialogResult.inputAnswer != item.Name {
+					updateStatus(cancelMsg)
+					continue
+				}
+				res, err = repos[m.CurrSide()].Delete(ctx, viewConfig, item.Config.Type, viewConfig.Path, item.Name)
 			case model.ItemDpStatusClass:
-				res, err = dp.Repo.FlushCache(
+				res, err = dp.Repo.FlushCache(ctx,
 					viewConfig.DpDomain, item.Name, "", item.Config.Type)
 			case model.ItemDpStatus:
-				res, err = dp.Repo.FlushCache(
+				res, err = dp.Repo.FlushCache(ctx,
 					viewConfig.DpDomain, viewConfig.Path, item.Name, item.Config.Type)
 			default:
 				return errs.Errorf("Can't delete '%s' (%s) at '%s'",
@@ -1694,7 +2238,7 @@ func deleteCurrent(m *model.Model) error {
 			default:
 				updateStatus(errorMsg)
 			}
-			showItem(side, viewConfig, ".")
+			showItem(ctx, side, viewConfig, ".")
 		} else {
 			updateStatus(cancelMsg)
 		}
@@ -1703,7 +2247,7 @@ func deleteCurrent(m *model.Model) error {
 	return nil
 }
 
-func enterDirectoryPath(m *model.Model) error {
+func enterDirectoryPath(ctx context.Context, m *model.Model) error {
 	logging.LogDebug("ui/enterDirectoryPath()")
 	side := m.CurrSide()
 	viewConfig := m.ViewConfig(side)
@@ -1718,7 +2262,7 @@ func enterDirectoryPath(m *model.Model) error {
 	}
 
 	path := dialogResult.inputAnswer
-	newViewConfig, err := repos[side].GetViewConfigByPath(viewConfig, path)
+	newViewConfig, err := repos[side].GetViewConfigByPath(ctx, viewConfig, path)
 	if err != nil {
 		logging.LogDebugf("ui/enterDirectoryPath(), err: %v", err)
 		return err
@@ -1727,7 +2271,7 @@ func enterDirectoryPath(m *model.Model) error {
 	switch newViewConfig.Type {
 	case model.ItemDirectory, model.ItemDpFilestore, model.ItemDpDomain:
 		updateStatusf("Showing path '%s'.", path)
-		return showView(side, newViewConfig, path, "", true)
+		return showView(ctx, side, newViewConfig, path, "", true)
 	default:
 		return errs.Errorf("Can't show path '%s', not directory nor filestore nor domain.", path)
 	}
@@ -1772,7 +2316,7 @@ func searchNextItem(m *model.Model, reverse bool) {
 }
 
 // saveDataPowerConfig saves current's DataPower domain configuration.
-func saveDataPowerConfig(m *model.Model) error {
+func saveDataPowerConfig(ctx context.Context, m *model.Model) error {
 	logging.LogDebug("ui/saveDataPowerConfig()")
 	side := m.CurrSide()
 	viewConfig := m.ViewConfig(side)
@@ -1787,12 +2331,12 @@ func saveDataPowerConfig(m *model.Model) error {
 			return errs.Errorf("Canceled saving of DataPower configuration for domain '%s'.", viewConfig.DpDomain)
 		}
 
-		err := dp.Repo.SaveConfiguration(viewConfig)
+		err := dp.Repo.SaveConfiguration(ctx, viewConfig)
 		if err != nil {
 			return err
 		}
 		currView := workingModel.ViewConfig(workingModel.CurrSide())
-		showItem(workingModel.CurrSide(), currView, ".")
+		showItem(ctx, workingModel.CurrSide(), currView, ".")
 		updateStatusf("Domain '%s' saved.", viewConfig.DpDomain)
 		return nil
 	}
@@ -1806,56 +2350,376 @@ func showStatusMessages(statuses []string) error {
 	for _, status := range statuses {
 		statusesText = statusesText + status + "\n"
 	}
-	return extprogs.View("Status_Messages", []byte(statusesText))
+	return viewContent("Status_Messages", []byte(statusesText))
 }
 
 // syncModeToggle toggles sync mode (off <-> on). Sync mode is used to copy
 // local changes to DataPower.
-func syncModeToggle(m *model.Model) error {
+func syncModeToggle(ctx context.Context, m *model.Model) error {
 	logging.LogDebug("ui/syncModeToggle()")
-	var syncModeToggleConfirm userDialogResult
-
-	dpViewConfig := m.ViewConfig(model.Left)
-	dpApplianceName := dpViewConfig.DpAppliance
-	dpDomain := dpViewConfig.DpDomain
-	dpDir := dpViewConfig.Path
 
 	if m.SyncModeOn {
-		syncModeToggleConfirm = askUserInput("Are you sure you want to disable sync mode (y/n): ", "", false)
-	} else {
-		if dpDomain != "" && dpDir != "" {
-			syncModeToggleConfirm = askUserInput("Are you sure you want to enable sync mode (y/n): ", "", false)
-		} else {
-			return errs.Errorf("Can't sync if DataPower domain (%s) or path (%s) are not selected.", dpDomain, dpDir)
-		}
-	}
-
-	if syncModeToggleConfirm.dialogSubmitted && syncModeToggleConfirm.inputAnswer == "y" {
-		m.SyncModeOn = !m.SyncModeOn
-		if m.SyncModeOn {
-			dp.SyncRepo.InitNetworkSettings(
-				dpApplianceName, config.Conf.DataPowerAppliances[dpApplianceName])
-			m.SyncDpDomain = dpDomain
-			m.SyncDirDp = dpDir
-			m.SyncDirLocal = m.ViewConfig(model.Right).Path
-			m.SyncInitial = true
-			go syncLocalToDp(m)
-			updateStatusf("Synchronization mode enabled (%s/'%s' <- '%s').", m.SyncDpDomain, m.SyncDirDp, m.SyncDirLocal)
-		} else {
+		syncModeToggleConfirm := askUserInput("Are you sure you want to disable sync mode (y/n): ", "", false)
+		if syncModeToggleConfirm.dialogSubmitted && syncModeToggleConfirm.inputAnswer == "y" {
+			close(syncStop)
+			m.SyncModeOn = false
+			m.SyncProfile = ""
 			m.SyncDpDomain = ""
 			m.SyncDirDp = ""
 			m.SyncDirLocal = ""
 			m.SyncInitial = false
+			m.SyncBidirectional = false
 			updateStatus("Synchronization mode disabled.")
+		} else {
+			updateStatus("Synchronization mode change canceled.")
 		}
+		return nil
+	}
+
+	if len(config.Conf.Sync.Profiles) > 0 {
+		profileNames := make([]string, 0, len(config.Conf.Sync.Profiles))
+		for profileName := range config.Conf.Sync.Profiles {
+			profileNames = append(profileNames, profileName)
+		}
+		sort.Strings(profileNames)
+		options := append([]string{"(current DataPower view)"}, profileNames...)
+		dialogSession := selectFromList("Select sync profile:", options, 0)
+		if !dialogSession.dialogSubmitted {
+			updateStatus("Synchronization mode change canceled.")
+			return nil
+		}
+		if dialogSession.selectionIdx > 0 {
+			return syncModeEnableProfile(ctx, m, options[dialogSession.selectionIdx])
+		}
+	}
+
+	return syncModeEnableView(ctx, m)
+}
+
+// syncModeEnableView enables sync mode from local directory to DataPower
+// directory shown in current views.
+func syncModeEnableView(ctx context.Context, m *model.Model) error {
+	dpViewConfig := m.ViewConfig(model.Left)
+	dpApplianceName := dpViewConfig.DpAppliance
+	dpDomain := dpViewConfig.DpDomain
+	dpDir := dpViewConfig.Path
+
+	if dpDomain == "" || dpDir == "" {
+		return errs.Errorf("Can't sync if DataPower domain (%s) or path (%s) are not selected.", dpDomain, dpDir)
+	}
+	syncModeToggleConfirm := askUserInput("Are you sure you want to enable sync mode (y/n, b for bidirectional sync): ", "", false)
+	if !syncModeToggleConfirm.dialogSubmitted ||
+		(syncModeToggleConfirm.inputAnswer != "y" && syncModeToggleConfirm.inputAnswer != "b") {
+		updateStatus("Synchronization mode change canceled.")
+		return nil
+	}
+
+	dpa, err := applianceConfig(dpApplianceName)
+	if err != nil {
+		return err
+	}
+	err = dp.SyncRepo.InitNetworkSettings(dpApplianceName, dpa)
+	if err != nil {
+		return err
+	}
+	syncTargets = []*syncTarget{{repo: &dp.SyncRepo, dpDomain: dpDomain, dpDir: dpDir}}
+
+	m.SyncModeOn = true
+	m.SyncDpDomain = dpDomain
+	m.SyncDirDp = dpDir
+	m.SyncDirLocal = m.ViewConfig(model.Right).Path
+	m.SyncInitial = true
+	m.SyncBidirectional = syncModeToggleConfirm.inputAnswer == "b"
+	syncStop = make(chan struct{})
+	go syncLocalToDpStart(context.Background(), m, syncStop)
+	if m.SyncBidirectional {
+		updateStatusf("Bidirectional synchronization mode enabled (%s/'%s' <-> '%s').", m.SyncDpDomain, m.SyncDirDp, m.SyncDirLocal)
 	} else {
+		updateStatusf("Synchronization mode enabled (%s/'%s' <- '%s').", m.SyncDpDomain, m.SyncDirDp, m.SyncDirLocal)
+	}
+
+	return nil
+}
+
+// syncModeEnableProfile enables sync mode from local directory to all
+// DataPower targets from sync profile.
+func syncModeEnableProfile(ctx context.Context, m *model.Model, profileName string) error {
+	logging.LogDebugf("ui/syncModeEnableProfile('%s')", profileName)
+	profile := config.Conf.Sync.Profiles[profileName]
+	if len(profile.Targets) == 0 {
+		return errs.Errorf("Sync profile '%s' has no targets.", profileName)
+	}
+	syncModeToggleConfirm := askUserInput(
+		fmt.Sprintf("Are you sure you want to enable sync mode to %d targets from profile '%s' (y/n): ",
+			len(profile.Targets), profileName), "", false)
+	if !syncModeToggleConfirm.dialogSubmitted || syncModeToggleConfirm.inputAnswer != "y" {
 		updateStatus("Synchronization mode change canceled.")
+		return nil
 	}
 
+	targets := make([]*syncTarget, 0, len(profile.Targets))
+	for _, target := range profile.Targets {
+		if target.Appliance == "" || target.Domain == "" || target.Path == "" {
+			return errs.Errorf("Sync profile '%s' target must have appliance (%s), domain (%s) and path (%s).",
+				profileName, target.Appliance, target.Domain, target.Path)
+		}
+		dpa, err := applianceConfig(target.Appliance)
+		if err != nil {
+			return err
+		}
+		targetRepo := dp.NewRepo(fmt.Sprintf("SyncDataPower-%d", len(targets)+1), model.DpFilestoreMode)
+		err = targetRepo.InitNetworkSettings(target.Appliance, dpa)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, &syncTarget{
+			name:     fmt.Sprintf("%s/%s/'%s'", target.Appliance, target.Domain, target.Path),
+			repo:     targetRepo,
+			dpDomain: target.Domain,
+			dpDir:    target.Path})
+	}
+	syncTargets = targets
+
+	m.SyncModeOn = true
+	m.SyncProfile = profileName
+	m.SyncDirLocal = m.ViewConfig(model.Right).Path
+	m.SyncInitial = true
+	m.SyncBidirectional = false
+	syncStop = make(chan struct{})
+	go syncLocalToDpStart(context.Background(), m, syncStop)
+	updateStatusf("Synchronization mode enabled (profile '%s' <- '%s').", m.SyncProfile, m.SyncDirLocal)
+
 	return nil
 }
 
-func syncLocalToDp(m *model.Model) {
+// applianceConfig returns DataPower appliance configuration used for syncing
+// or comparing, asking for password if it is not saved or entered before.
+func applianceConfig(applianceName string) (config.DataPowerAppliance, error) {
+	dpa, ok := config.Conf.DataPowerAppliances[applianceName]
+	if !ok {
+		return dpa, errs.Errorf("DataPower appliance '%s' configuration not found.", applianceName)
+	}
+	if dpa.Password == "" {
+		password := config.DpTransientPasswordMap[applianceName]
+		if password == "" {
+			dialogResult := askUserInput(
+				fmt.Sprintf("Please enter DataPower password for '%s': ", applianceName), "", true)
+			if dialogResult.dialogCanceled || dialogResult.inputAnswer == "" {
+				return dpa, errs.Errorf("DataPower password for '%s' not entered.", applianceName)
+			}
+			password = dialogResult.inputAnswer
+			config.DpTransientPasswordMap[applianceName] = password
+		}
+		dpa.SetDpPlaintextPassword(password)
+	}
+
+	return dpa, nil
+}
+
+// syncToTargets runs sync function for all sync targets concurrently and
+// reports result for each target when syncing to many targets.
+func syncToTargets(syncFunc func(target *syncTarget) bool) bool {
+	targets := syncTargets
+	results := make([]bool, len(targets))
+	var wg sync.WaitGroup
+	for idx, target := range targets {
+		target.changeCount = 0
+		target.errCount = 0
+		wg.Add(1)
+		go func(idx int, target *syncTarget) {
+			defer wg.Done()
+			results[idx] = syncFunc(target)
+		}(idx, target)
+	}
+	wg.Wait()
+
+	changesMade := false
+	for idx, target := range targets {
+		if results[idx] {
+			changesMade = true
+		}
+		if len(targets) > 1 {
+			switch {
+			case target.errCount > 0:
+				updateStatusf("Sync to %s failed (%d errors, %d changes).",
+					target.name, target.errCount, target.changeCount)
+			case target.changeCount > 0:
+				updateStatusf("Sync to %s succeeded (%d changes).", target.name, target.changeCount)
+			}
+		}
+	}
+
+	return changesMade
+}
+
+// changed shows status message about change made on sync target.
+func (t *syncTarget) changed(format string, v ...interface{}) {
+	t.changeCount++
+	t.statusf(format, v...)
+}
+
+// failed shows status message about error syncing to sync target.
+func (t *syncTarget) failed(format string, v ...interface{}) {
+	t.errCount++
+	t.statusf(format, v...)
+}
+
+// statusf shows status message prefixed with sync target name (if set).
+func (t *syncTarget) statusf(format string, v ...interface{}) {
+	if t.name != "" {
+		format = "[" + t.name + "] " + format
+	}
+	updateStatusf(format, v...)
+}
+
+// syncDebounceTime is time without new local filesystem changes after which
+// changed files are synced to DataPower (editors often save in several steps).
+const syncDebounceTime = 300 * time.Millisecond
+
+// syncLocalToDpStart starts syncing local filesystem changes to DataPower
+// using filesystem notifications, falling back to polling if local directory
+// can't be watched. Bidirectional sync always uses polling (to find DataPower
+// changes). Syncing stops when stop channel is closed.
+func syncLocalToDpStart(ctx context.Context, m *model.Model, stop <-chan struct{}) {
+	if !m.SyncBidirectional {
+		err := syncLocalToDpWatch(ctx, m, stop)
+		if err == nil {
+			return
+		}
+		logging.LogDebug("worker/syncLocalToDpStart(), watch err: ", err)
+		updateStatusf("Can't watch local changes (%s), sync falls back to polling.", err)
+		m.SyncInitial = true
+	}
+	syncLocalToDp(ctx, m, stop)
+}
+
+// syncLocalToDpWatch syncs local filesystem changes to DataPower as soon as
+// filesystem notifications about changes arrive. Whole local tree is synced
+// only initially, later it is reloaded after each change to find removed and
+// renamed files (the same way as when polling). Directories ignored when sync starts are not watched.
+// Watching stops when stop channel is closed.
+func syncLocalToDpWatch(ctx context.Context, m *model.Model, stop <-chan struct{}) error {
+	logging.LogDebug("worker/syncLocalToDpWatch()")
+	syncIgnore := loadSyncIgnore(m)
+	done := make(chan struct{})
+	defer close(done)
+	changes, err := localfs.Watch(m.SyncDirLocal, syncIgnore.Ignored, syncDebounceTime, done)
+	if err != nil {
+		return err
+	}
+
+	tree, err := localfs.LoadTree("", m.SyncDirLocal)
+	if err != nil {
+		updateStatusf("Sync err: %s.", err)
+	}
+	tree, ignoredCount := tree.Prune(syncIgnore.Ignored)
+	if ignoredCount > 0 {
+		updateStatusf("Sync skipped %d ignored entries.", ignoredCount)
+	}
+	changesMade := syncToTargets(func(target *syncTarget) bool {
+		return syncLocalToDpInitial(ctx, target, &tree)
+	})
+	m.SyncInitial = false
+	syncRefreshView(ctx, m, changesMade)
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case changedPaths, ok := <-changes:
+			if !ok {
+				return errs.Error("filesystem watching stopped")
+			}
+			syncIgnore = loadSyncIgnore(m)
+			treeNew, err := localfs.LoadTree("", m.SyncDirLocal)
+			treeNew, _ = treeNew.Prune(syncIgnore.Ignored)
+			// Partially loaded tree must not be used to delete files on DataPower.
+			propagateDeletes := config.Conf.Sync.PropagateDeletes && err == nil
+			changesMade = syncToTargets(func(target *syncTarget) bool {
+				// Renamed files are moved on DataPower before upload so they are
+				// not uploaded again.
+				targetChangesMade := propagateDeletes && syncRemovalsToDp(ctx, target, &treeNew, &tree)
+				if syncChangedPathsToDp(ctx, m, target, changedPaths, syncIgnore) {
+					targetChangesMade = true
+				}
+				return targetChangesMade
+			})
+			if err == nil {
+				tree = treeNew
+			}
+			syncRefreshView(ctx, m, changesMade)
+		}
+	}
+}
+
+// syncChangedPathsToDp syncs changed local files and directories (reported by
+// filesystem notifications) to DataPower, removed paths are handled by
+// syncRemovalsToDp.
+func syncChangedPathsToDp(ctx context.Context, m *model.Model, target *syncTarget, changedPaths []string, syncIgnore ignore.Matcher) bool {
+	logging.LogDebugf("worker/syncChangedPathsToDp('%s', %v)", target.name, changedPaths)
+	changesMade := false
+
+	for _, changedPath := range changedPaths {
+		pathFromRoot, err := filepath.Rel(m.SyncDirLocal, changedPath)
+		if err != nil || pathFromRoot == "." || strings.HasPrefix(pathFromRoot, "..") {
+			continue
+		}
+		ignorePath := filepath.ToSlash(pathFromRoot)
+
+		fi, err := os.Lstat(changedPath)
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			logging.LogDebug("worker/syncChangedPathsToDp(), err: ", err)
+		case syncIgnore.Ignored(ignorePath, fi.IsDir()):
+			logging.LogDebugf("worker/syncChangedPathsToDp(), '%s' ignored.", pathFromRoot)
+		case fi.IsDir():
+			tree, err := localfs.LoadTree(pathFromRoot, changedPath)
+			if err != nil {
+				target.failed("Sync err: %s.", err)
+			}
+			tree, _ = tree.Prune(syncIgnore.Ignored)
+			if syncLocalToDpInitial(ctx, target, &tree) {
+				changesMade = true
+			}
+		default:
+			tree := localfs.Tree{Name: fi.Name(), Path: changedPath,
+				PathFromRoot: pathFromRoot, ModTime: fi.ModTime()}
+			if updateDpFile(ctx, target, &tree) {
+				changesMade = true
+			}
+		}
+	}
+
+	return changesMade
+}
+
+// loadSyncIgnore loads ignore patterns used for syncing - global patterns from
+// configuration and patterns from ignore file at the local sync root.
+func loadSyncIgnore(m *model.Model) ignore.Matcher {
+	syncIgnore := ignore.NewMatcher(config.Conf.Sync.Ignore...)
+	ignoreFilePath := localfs.Repo.GetFilePath(m.SyncDirLocal, ignore.FileName)
+	err := syncIgnore.AddFile(ignoreFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		logging.LogDebugf("worker/loadSyncIgnore(), can't read '%s': %v", ignoreFilePath, err)
+	}
+
+	return syncIgnore
+}
+
+// syncRefreshView refreshes DataPower view (or just status) after sync.
+func syncRefreshView(ctx context.Context, m *model.Model, changesMade bool) {
+	if changesMade {
+		refreshView(ctx, m, model.Left)
+	} else {
+		refreshStatus()
+	}
+}
+
+// syncLocalToDp polls local filesystem (and DataPower in bidirectional mode)
+// for changes every "Sync": {"Seconds": N} seconds until stop channel is closed.
+func syncLocalToDp(ctx context.Context, m *model.Model, stop <-chan struct{}) {
 	// 1. Fetch dp & local file tree
 	// 2. Initial sync files from local to dp:
 	// 2a. Copy non-existing from local to dp
@@ -1863,86 +2727,119 @@ func syncLocalToDp(m *model.Model) {
 	// 3. Save local file tree (file path + modify timestamp)
 	// 4. Sync files from local to dp:
 	// 4a. When local modify timestamp changes or new file appears copy to dp
+	// In bidirectional mode both trees are compared with hashes saved during
+	// last sync (see syncBidirectional).
 	cnt := 0
 	var treeOld localfs.Tree
 	syncCheckTime := time.Duration(config.Conf.Sync.Seconds) * time.Second
-	for m.SyncModeOn {
+	for {
 		var changesMade bool
 		tree, err := localfs.LoadTree("", m.SyncDirLocal)
 		if err != nil {
 			updateStatusf("Sync err: %s.", err)
 		}
-		logging.LogDebug("syncLocalToDp(), tree: ", tree)
+		syncIgnore := loadSyncIgnore(m)
+		tree, ignoredCount := tree.Prune(syncIgnore.Ignored)
+		// Entries which become ignored are not considered removed.
+		treeOld, _ = treeOld.Prune(syncIgnore.Ignored)
+		logging.LogDebug("syncLocalToDp(ctx), tree: ", tree)
+		if m.SyncInitial && ignoredCount > 0 {
+			updateStatusf("Sync skipped %d ignored entries.", ignoredCount)
+		}
 
-		if m.SyncInitial {
-			changesMade = syncLocalToDpInitial(&tree)
-			logging.LogDebug("syncLocalToDp(), after initial sync - changesMade: ", changesMade)
+		switch {
+		case m.SyncBidirectional:
+			if m.SyncInitial {
+				syncStateReset()
+				treeOld = localfs.Tree{}
+				m.SyncInitial = false
+			}
+			changesMade = syncBidirectional(ctx, m, &tree, &treeOld, syncIgnore)
+			logging.LogDebug("syncLocalToDp(ctx), after bidirectional sync - changesMade: ", changesMade)
+		case m.SyncInitial:
+			changesMade = syncToTargets(func(target *syncTarget) bool {
+				return syncLocalToDpInitial(ctx, target, &tree)
+			})
+			logging.LogDebug("syncLocalToDp(ctx), after initial sync - changesMade: ", changesMade)
 			m.SyncInitial = false
-		} else {
-			changesMade = syncLocalToDpLater(&tree, &treeOld)
-			logging.LogDebug("syncLocalToDp(), after later sync - changesMade: ", changesMade)
+		default:
+			// Partially loaded tree must not be used to delete files on DataPower.
+			propagateDeletes := config.Conf.Sync.PropagateDeletes && err == nil
+			changesMade = syncToTargets(func(target *syncTarget) bool {
+				// Renamed files are moved on DataPower before upload so they are
+				// not uploaded again.
+				targetChangesMade := propagateDeletes && syncRemovalsToDp(ctx, target, &tree, &treeOld)
+				if syncLocalToDpLater(ctx, target, &tree, &treeOld) {
+					targetChangesMade = true
+				}
+				return targetChangesMade
+			})
+			logging.LogDebug("syncLocalToDp(ctx), after later sync - changesMade: ", changesMade)
 		}
 
 		treeOld = tree
 
 		cnt++
 		if changesMade {
-			refreshView(m, model.Left)
+			refreshView(ctx, m, model.Left)
+			if m.SyncBidirectional {
+				refreshView(ctx, m, model.Right)
+			}
 		} else {
 			refreshStatus()
 		}
-		time.Sleep(syncCheckTime)
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(syncCheckTime):
+		}
 	}
 }
 
-func syncLocalToDpInitial(tree *localfs.Tree) bool {
+func syncLocalToDpInitial(ctx context.Context, target *syncTarget, tree *localfs.Tree) bool {
 	changesMade := false
-	logging.LogDebug(fmt.Sprintf("syncLocalToDpInitial(%v)", tree))
-	// m := &model.Model{}
-	m := &workingModel
-	// logging.LogDebug("syncLocalToDpInitial(), syncDirDp: ", syncDirDp, ", tree.PathFromRoot: ", tree.PathFromRoot)
+	logging.LogDebug(fmt.Sprintf("syncLocalToDpInitial(ctx, '%s', %v)", target.name, tree))
 
 	if tree.Dir {
-		dpPath := dp.SyncRepo.GetFilePath(m.SyncDirDp, tree.PathFromRoot)
-		fileType, err := dp.SyncRepo.GetFileTypeByPath(m.SyncDpDomain, dpPath, ".")
+		dpPath := target.repo.GetFilePath(target.dpDir, tree.PathFromRoot)
+		fileType, err := target.repo.GetFileTypeByPath(ctx, target.dpDomain, dpPath, ".")
 		if err != nil {
 			logging.LogDebug("worker/syncLocalToDpInitial(), err: ", err)
 		}
 
 		if fileType == model.ItemNone {
-			dp.SyncRepo.CreateDirByPath(m.SyncDpDomain, dpPath, ".")
+			target.repo.CreateDirByPath(ctx, target.dpDomain, dpPath, ".")
 			changesMade = true
 		} else if fileType == model.ItemFile {
 			logging.LogDebugf("worker/syncLocalToDpInitial() - In place of dir there is a file on dp: '%s'", dpPath)
 		}
 		for _, child := range tree.Children {
-			if syncLocalToDpInitial(&child) {
+			if syncLocalToDpInitial(ctx, target, &child) {
 				changesMade = true
 			}
 		}
 	} else {
-		changesMade = updateDpFile(m, tree)
+		changesMade = updateDpFile(ctx, target, tree)
 	}
 
 	return changesMade
 }
 
-func syncLocalToDpLater(tree, treeOld *localfs.Tree) bool {
+func syncLocalToDpLater(ctx context.Context, target *syncTarget, tree, treeOld *localfs.Tree) bool {
 	changesMade := false
-	logging.LogDebugf("worker/syncLocalToDpLater(%v, %v)", tree, treeOld)
-	// m := &model.Model{}
-	m := &workingModel
+	logging.LogDebugf("worker/syncLocalToDpLater('%s', %v, %v)", target.name, tree, treeOld)
 
 	if tree.Dir {
 		if treeOld == nil {
-			dpPath := dp.SyncRepo.GetFilePath(m.SyncDirDp, tree.PathFromRoot)
-			fileType, err := dp.SyncRepo.GetFileTypeByPath(m.SyncDpDomain, dpPath, ".")
+			dpPath := target.repo.GetFilePath(target.dpDir, tree.PathFromRoot)
+			fileType, err := target.repo.GetFileTypeByPath(ctx, target.dpDomain, dpPath, ".")
 			if err != nil {
 				logging.LogDebug("worker/syncLocalToDpLater(), err: ", err)
 				return false
 			}
 			if fileType == model.ItemNone {
-				dp.SyncRepo.CreateDirByPath(m.SyncDpDomain, dpPath, ".")
+				target.repo.CreateDirByPath(ctx, target.dpDomain, dpPath, ".")
 				changesMade = true
 			} else if fileType == model.ItemFile {
 				logging.LogDebugf("worker/syncLocalToDpLater() - In place of dir there is a file on dp: '%s'", dpPath)
@@ -1955,22 +2852,360 @@ func syncLocalToDpLater(tree, treeOld *localfs.Tree) bool {
 			if treeOld != nil {
 				childOld = treeOld.FindChild(&child)
 			}
-			if syncLocalToDpLater(&child, childOld) {
+			if syncLocalToDpLater(ctx, target, &child, childOld) {
 				changesMade = true
 			}
 		}
 	} else {
 		if tree.FileChanged(treeOld) {
-			changesMade = updateDpFile(m, tree)
+			changesMade = updateDpFile(ctx, target, tree)
 		}
 	}
 
 	return changesMade
 }
 
+// syncRemovalsToDp deletes DataPower files and directories removed on local
+// filesystem since last sync check, DataPower files renamed (or moved) locally
+// are moved to the new path.
+func syncRemovalsToDp(ctx context.Context, target *syncTarget, tree, treeOld *localfs.Tree) bool {
+	logging.LogDebugf("worker/syncRemovalsToDp('%s', %v, %v)", target.name, tree, treeOld)
+	changesMade := false
+
+	for _, removal := range tree.FindRemovals(treeOld) {
+		removed := removal.Removed
+		itemType := model.ItemFile
+		if removed.Dir {
+			itemType = model.ItemDirectory
+		}
+		dpParentPath := target.repo.GetFilePath(target.dpDir, filepath.Dir(removed.PathFromRoot))
+		dpPath := target.repo.GetFilePath(dpParentPath, removed.Name)
+
+		// Directories can't be moved on DataPower, they are deleted and their
+		// content is uploaded to the new path.
+		if removal.RenamedTo != nil && !removed.Dir {
+			dpNewPath := target.repo.GetFilePath(target.dpDir, removal.RenamedTo.PathFromRoot)
+			res, err := target.repo.MoveFileByPath(ctx, target.dpDomain, dpPath, dpNewPath)
+			if err == nil && res {
+				changesMade = true
+				target.changed("Dp file '%s' moved to '%s'.", dpPath, dpNewPath)
+				continue
+			}
+			logging.LogDebug("worker/syncRemovalsToDp(), couldn't move dp file, deleting it - err: ", err)
+		}
+
+		dpParentView := model.ItemConfig{Type: model.ItemDirectory,
+			DpDomain: target.dpDomain, Path: dpParentPath}
+		res, err := target.repo.Delete(ctx, &dpParentView, itemType, dpParentPath, removed.Name)
+		if err != nil || !res {
+			logging.LogDebug("worker/syncRemovalsToDp(), couldn't delete dp file - err: ", err)
+			target.failed("Error deleting %s '%s'.", itemType.UserFriendlyString(), dpPath)
+			continue
+		}
+		changesMade = true
+		target.changed("Dp %s '%s' deleted.", itemType.UserFriendlyString(), dpPath)
+	}
+
+	return changesMade
+}
+
+// syncStateReset clears bidirectional sync state before initial sync.
+func syncStateReset() {
+	syncState.mutex.Lock()
+	defer syncState.mutex.Unlock()
+	syncState.syncedHashes = make(map[string]string)
+	syncState.treeDpOld = localfs.Tree{}
+	syncState.conflicts = make(map[string]syncConflict)
+}
+
+// syncBidirectional compares local and DataPower trees with trees from last
+// sync and uploads files changed only locally, downloads files changed only
+// on DataPower and records conflicts for files changed on both sides.
+func syncBidirectional(ctx context.Context, m *model.Model, tree, treeOld *localfs.Tree, syncIgnore ignore.Matcher) bool {
+	logging.LogDebugf("worker/syncBidirectional(%v, %v)", tree, treeOld)
+	changesMade := false
+
+	treeDp, err := dp.SyncRepo.LoadTree(ctx, m.SyncDpDomain, "", m.SyncDirDp)
+	if err != nil {
+		updateStatusf("Sync err: %s.", err)
+		return false
+	}
+	treeDp, _ = treeDp.Prune(syncIgnore.Ignored)
+
+	syncState.mutex.Lock()
+	defer syncState.mutex.Unlock()
+
+	localEntries := tree.Flatten()
+	localEntriesOld := treeOld.Flatten()
+	dpEntries := treeDp.Flatten()
+	dpEntriesOld := syncState.treeDpOld.Flatten()
+	syncState.treeDpOld = treeDp
+
+	entryPaths := make([]string, 0, len(localEntries)+len(dpEntries))
+	for pathFromRoot := range localEntries {
+		entryPaths = append(entryPaths, pathFromRoot)
+	}
+	for pathFromRoot := range dpEntries {
+		if _, ok := localEntries[pathFromRoot]; !ok {
+			entryPaths = append(entryPaths, pathFromRoot)
+		}
+	}
+	// Parent directories are sorted before their children.
+	sort.Strings(entryPaths)
+
+	conflictsPending := false
+	for _, pathFromRoot := range entryPaths {
+		localEntry, localExists := localEntries[pathFromRoot]
+		dpEntry, dpExists := dpEntries[pathFromRoot]
+		syncedHash, synced := syncState.syncedHashes[pathFromRoot]
+
+		if (localExists && localEntry.Dir) || (dpExists && dpEntry.Dir) {
+			if syncBidirectionalDir(ctx, m, pathFromRoot, localEntry, dpEntry, localExists, dpExists, synced) {
+				changesMade = true
+			}
+			continue
+		}
+
+		conflict, conflicted := syncState.conflicts[pathFromRoot]
+		localEntryOld, localExistsOld := localEntriesOld[pathFromRoot]
+		dpEntryOld, dpExistsOld := dpEntriesOld[pathFromRoot]
+		if synced && !conflicted &&
+			localExists == localExistsOld && dpExists == dpExistsOld &&
+			!localEntry.FileChanged(&localEntryOld) && !dpEntry.FileChanged(&dpEntryOld) {
+			continue
+		}
+
+		var localBytes, dpBytes []byte
+		var localHash, dpHash string
+		if localExists {
+			localBytes, err = localfs.GetFileByPath(localEntry.Path)
+			if err != nil {
+				logging.LogDebug("worker/syncBidirectional(), couldn't get local file - err: ", err)
+				continue
+			}
+			localHash = localfs.FileHash(localBytes)
+		}
+		if dpExists {
+			dpBytes, err = dp.SyncRepo.GetFileByPath(ctx, m.SyncDpDomain, dpEntry.Path)
+			if err != nil {
+				logging.LogDebug("worker/syncBidirectional(), couldn't get dp file - err: ", err)
+				continue
+			}
+			dpHash = localfs.FileHash(dpBytes)
+		}
+
+		switch localfs.GetSyncAction(syncedHash, localHash, dpHash) {
+		case localfs.SyncNone:
+			if localHash == dpHash {
+				syncState.syncedHashes[pathFromRoot] = localHash
+			}
+			delete(syncState.conflicts, pathFromRoot)
+		case localfs.SyncUpload:
+			if syncUploadFile(ctx, m, pathFromRoot, localBytes) {
+				changesMade = true
+			}
+		case localfs.SyncDownload:
+			if syncDownloadFile(m, pathFromRoot, dpBytes) {
+				changesMade = true
+			}
+		case localfs.SyncConflict:
+			if !conflicted || conflict.localHash != localHash || conflict.dpHash != dpHash {
+				conflict = syncConflict{pathFromRoot: pathFromRoot, localHash: localHash, dpHash: dpHash}
+				syncState.conflicts[pathFromRoot] = conflict
+				updateStatusf("Sync conflict, file '%s' changed both locally and on DataPower.", pathFromRoot)
+			}
+			if !conflict.skipped {
+				conflictsPending = true
+			}
+		}
+	}
+
+	// Conflict dialog is shown from the main (input event) loop.
+	if conflictsPending {
+		out.PostEvent(tcell.NewEventInterrupt(nil))
+	}
+
+	return changesMade
+}
+
+// syncBidirectionalDir creates directory missing on one side during
+// bidirectional sync, if it was not synced (and removed) before.
+func syncBidirectionalDir(ctx context.Context, m *model.Model, pathFromRoot string, localEntry, dpEntry localfs.Tree,
+	localExists, dpExists, synced bool) bool {
+	switch {
+	case localExists && dpExists:
+		if localEntry.Dir != dpEntry.Dir {
+			logging.LogDebugf("worker/syncBidirectionalDir() - Directory and file with same name: '%s'", pathFromRoot)
+			return false
+		}
+		syncState.syncedHashes[pathFromRoot] = syncDirHash
+	case synced:
+		return false
+	case localExists:
+		dpPath := dp.SyncRepo.GetFilePath(m.SyncDirDp, pathFromRoot)
+		res, err := dp.SyncRepo.CreateDirByPath(ctx, m.SyncDpDomain, dpPath, ".")
+		if err != nil || !res {
+			logging.LogDebug("worker/syncBidirectionalDir(), couldn't create dp dir - err: ", err)
+			updateStatusf("Error creating dir '%s'.", dpPath)
+			return false
+		}
+		syncState.syncedHashes[pathFromRoot] = syncDirHash
+		return true
+	case dpExists:
+		localPath := localfs.Repo.GetFilePath(m.SyncDirLocal, pathFromRoot)
+		err := os.MkdirAll(localPath, os.ModePerm)
+		if err != nil {
+			logging.LogDebug("worker/syncBidirectionalDir(), couldn't create local dir - err: ", err)
+			updateStatusf("Error creating dir '%s'.", localPath)
+			return false
+		}
+		syncState.syncedHashes[pathFromRoot] = syncDirHash
+		return true
+	}
+
+	return false
+}
+
+// syncUploadFile uploads file content to DataPower sync directory and saves
+// file hash as synced.
+func syncUploadFile(ctx context.Context, m *model.Model, pathFromRoot string, fileContent []byte) bool {
+	dpPath := dp.SyncRepo.GetFilePath(m.SyncDirDp, pathFromRoot)
+	res, err := dp.SyncRepo.UpdateFileByPath(ctx, m.SyncDpDomain, dpPath, fileContent)
+	if err != nil || !res {
+		logging.LogDebug("worker/syncUploadFile(), couldn't update dp file - err: ", err)
+		updateStatusf("Error updating file '%s'.", dpPath)
+		return false
+	}
+	syncState.syncedHashes[pathFromRoot] = localfs.FileHash(fileContent)
+	delete(syncState.conflicts, pathFromRoot)
+	updateStatusf("Dp file '%s' updated.", dpPath)
+	return true
+}
+
+// syncDownloadFile saves file content to local sync directory and saves file
+// hash as synced.
+func syncDownloadFile(m *model.Model, pathFromRoot string, fileContent []byte) bool {
+	localPath := localfs.Repo.GetFilePath(m.SyncDirLocal, pathFromRoot)
+	err := os.MkdirAll(filepath.Dir(localPath), os.ModePerm)
+	if err == nil {
+		err = ioutil.WriteFile(localPath, fileContent, os.ModePerm)
+	}
+	if err != nil {
+		logging.LogDebug("worker/syncDownloadFile(), couldn't update local file - err: ", err)
+		updateStatusf("Error updating file '%s'.", localPath)
+		return false
+	}
+	syncState.syncedHashes[pathFromRoot] = localfs.FileHash(fileContent)
+	delete(syncState.conflicts, pathFromRoot)
+	updateStatusf("Local file '%s' updated.", localPath)
+	return true
+}
+
+// resolveSyncConflicts asks user how to resolve each bidirectional sync
+// conflict (file changed both locally and on DataPower) not skipped before.
+func resolveSyncConflicts(ctx context.Context, m *model.Model) error {
+	logging.LogDebug("worker/resolveSyncConflicts()")
+	syncState.mutex.Lock()
+	conflicts := make([]syncConflict, 0)
+	for _, conflict := range syncState.conflicts {
+		if !conflict.skipped {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	syncState.mutex.Unlock()
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].pathFromRoot < conflicts[j].pathFromRoot
+	})
+
+	for _, conflict := range conflicts {
+		if !m.SyncModeOn || !m.SyncBidirectional {
+			return nil
+		}
+		err := resolveSyncConflict(ctx, m, conflict)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveSyncConflict shows dialog where user can see diff between local and
+// DataPower file and choose which version to keep.
+func resolveSyncConflict(ctx context.Context, m *model.Model, conflict syncConflict) error {
+	logging.LogDebugf("worker/resolveSyncConflict('%s')", conflict.pathFromRoot)
+	localPath := localfs.Repo.GetFilePath(m.SyncDirLocal, conflict.pathFromRoot)
+	dpPath := dp.SyncRepo.GetFilePath(m.SyncDirDp, conflict.pathFromRoot)
+
+	for {
+		question := fmt.Sprintf("Sync conflict, file '%s' changed both locally and on DataPower "+
+			"- keep (l)ocal, keep (d)ataPower, (v)iew diff or (s)kip: ", conflict.pathFromRoot)
+		dialogResult := askUserInput(question, "", false)
+		answer := dialogResult.inputAnswer
+		if dialogResult.dialogCanceled {
+			answer = "s"
+		}
+
+		switch answer {
+		case "v":
+			err := diffSyncConflict(ctx, m, localPath, dpPath)
+			if err != nil {
+				updateStatus(err.Error())
+			}
+		case "l":
+			localBytes, err := localfs.GetFileByPath(localPath)
+			if err != nil {
+				return err
+			}
+			syncState.mutex.Lock()
+			syncUploadFile(ctx, m, conflict.pathFromRoot, localBytes)
+			syncState.mutex.Unlock()
+			return refreshView(ctx, m, model.Left)
+		case "d":
+			dpBytes, err := dp.SyncRepo.GetFileByPath(ctx, m.SyncDpDomain, dpPath)
+			if err != nil {
+				return err
+			}
+			syncState.mutex.Lock()
+			syncDownloadFile(m, conflict.pathFromRoot, dpBytes)
+			syncState.mutex.Unlock()
+			return refreshView(ctx, m, model.Right)
+		case "s":
+			syncState.mutex.Lock()
+			if savedConflict, ok := syncState.conflicts[conflict.pathFromRoot]; ok {
+				savedConflict.skipped = true
+				syncState.conflicts[conflict.pathFromRoot] = savedConflict
+			}
+			syncState.mutex.Unlock()
+			updateStatusf("Sync conflict for file '%s' skipped until file changes again.", conflict.pathFromRoot)
+			return nil
+		}
+	}
+}
+
+// diffSyncConflict shows differences between DataPower and local version of
+// the file in sync conflict.
+func diffSyncConflict(ctx context.Context, m *model.Model, localPath, dpPath string) error {
+	logging.LogDebugf("worker/diffSyncConflict('%s', '%s')", localPath, dpPath)
+	dpBytes, err := dp.SyncRepo.GetFileByPath(ctx, m.SyncDpDomain, dpPath)
+	if err != nil {
+		return err
+	}
+
+	dpCopyDir := extprogs.CreateTempDir("dp")
+	dpCopyPath := localfs.Repo.GetFilePath(dpCopyDir, filepath.Base(localPath))
+	err = ioutil.WriteFile(dpCopyPath, dpBytes, os.ModePerm)
+	if err != nil {
+		extprogs.DeleteTempDir(dpCopyDir)
+		return err
+	}
+
+	return diffFilesWithCleanup(dpCopyDir, dpCopyPath, localPath)
+}
+
 // toggleObjectMode switches between (default) filestore mode, object mode and
 // status mode for the DataPower view.
-func toggleObjectMode(m *model.Model) error {
+func toggleObjectMode(ctx context.Context, m *model.Model) error {
 	logging.LogDebugf("worker/toggleObjectMode(), dp.Repo.DpViewMode: %s", dp.Repo.DpViewMode)
 
 	side := m.CurrSide()
@@ -2007,7 +3242,7 @@ func toggleObjectMode(m *model.Model) error {
 				DpAppliance: oldView.DpAppliance,
 				DpDomain:    oldView.DpDomain,
 				DpFilestore: oldView.DpFilestore}
-			return showItem(model.Left, &newView, newView.Path)
+			return showItem(ctx, model.Left, &newView, newView.Path)
 		case model.DpStatusMode:
 			newView := model.ItemConfig{
 				Parent:      oldView,
@@ -2017,7 +3252,7 @@ func toggleObjectMode(m *model.Model) error {
 				DpAppliance: oldView.DpAppliance,
 				DpDomain:    oldView.DpDomain,
 				DpFilestore: oldView.DpFilestore}
-			return showItem(model.Left, &newView, newView.Path)
+			return showItem(ctx, model.Left, &newView, newView.Path)
 		case model.DpFilestoreMode:
 			// When we switch from status mode navigate back to first filestore mode.
 			ic := m.NavCurrentViewBack(side)
@@ -2025,7 +3260,7 @@ func toggleObjectMode(m *model.Model) error {
 				ic = m.NavCurrentViewBack(side)
 			}
 			firstNonObjectView := m.ViewConfig(side)
-			return showItem(model.Left, firstNonObjectView, ".")
+			return showItem(ctx, model.Left, firstNonObjectView, ".")
 		default:
 			logging.LogDebugf("worker/toggleObjectMode(), unknown view mode %v.",
 				dp.Repo.DpViewMode)
@@ -2039,7 +3274,7 @@ func toggleObjectMode(m *model.Model) error {
 }
 
 // showItemInfo shows information about current item.
-func showItemInfo(m *model.Model) error {
+func showItemInfo(ctx context.Context, m *model.Model) error {
 	logging.LogDebugf("worker/showItemInfo(), dp.Repo.DpViewMode: %s", dp.Repo.DpViewMode)
 
 	currentItem := m.CurrItem()
@@ -2049,12 +3284,12 @@ func showItemInfo(m *model.Model) error {
 
 	switch currentItem.Config.Type {
 	case model.ItemDpObject, model.ItemFile, model.ItemDirectory:
-		infoBytes, err := repos[m.CurrSide()].GetItemInfo(currentItem.Config)
+		infoBytes, err := repos[m.CurrSide()].GetItemInfo(ctx, currentItem.Config)
 		if err != nil {
 			return err
 		}
 		if infoBytes != nil {
-			err = extprogs.View("*."+currentItem.Name, infoBytes)
+			err = viewContent("*."+currentItem.Name, infoBytes)
 			if err != nil {
 				return err
 			}
@@ -2069,9 +3304,10 @@ func showItemInfo(m *model.Model) error {
 	return nil
 }
 
-// showObjectDetails shows details (service, policy, matches, rules & actions)
-// for the current object.
-func showObjectDetails(m *model.Model) error {
+// showObjectDetails starts background job fetching details (service, policy,
+// matches, rules & actions) for the current object, details are shown from jobs
+// panel when job finishes.
+func showObjectDetails(ctx context.Context, m *model.Model) error {
 	logging.LogDebugf("worker/showObjectPolicy(), dp.Repo.ObjectConfigMode: %s",
 		dp.Repo.DpViewMode)
 
@@ -2107,27 +3343,27 @@ func showObjectDetails(m *model.Model) error {
 				currentItem.Config.Path)
 		}
 
-		updateStatusf("Fetching policy for object '%s' (%s) from domain '%s'.",
-			currentItem.Config.Name, currentItem.Config.Path,
-			currentItem.Config.DpDomain)
-		showProgressDialogf("Exporting object '%s' (%s) from domain '%s'...",
-			currentItem.Config.Name, currentItem.Config.Path,
-			currentItem.Config.DpDomain)
-		objectInfoBytes, err :=
-			dp.Repo.GetObjectDetails(currentItem.Config.DpDomain,
-				currentItem.Config.Path, currentItem.Config.Name)
-		hideProgressDialog()
-		if err != nil {
-			return err
-		}
-		if objectInfoBytes != nil {
-			err = extprogs.View("*."+currentItem.Name, objectInfoBytes)
-			if err != nil {
-				return err
-			}
-		} else {
-			return errs.Errorf("Can't show policy info for '%s' object.", currentItem.Name)
-		}
+		objectConfig := *currentItem.Config
+		objectName := currentItem.Name
+		jobDpRepo := dp.Repo.Clone()
+		startJob(fmt.Sprintf("Policy for object '%s' (%s)", objectConfig.Name, objectConfig.Path),
+			func(ctx context.Context, j *job) error {
+				j.statusf("Exporting object '%s' (%s) from domain '%s'...",
+					objectConfig.Name, objectConfig.Path, objectConfig.DpDomain)
+				objectInfoBytes, err :=
+					jobDpRepo.GetObjectDetails(ctx, objectConfig.DpDomain,
+						objectConfig.Path, objectConfig.Name)
+				if err != nil {
+					return err
+				}
+				if objectInfoBytes == nil {
+					return errs.Errorf("Can't show policy info for '%s' object.", objectName)
+				}
+				j.setOutput("*."+objectName, objectInfoBytes)
+				j.statusf("Policy for object '%s' (%s) ready, select job to view it.",
+					objectConfig.Name, objectConfig.Path)
+				return nil
+			})
 	default:
 		return errs.Error("Can't show policy info for non DataPower object.")
 	}
@@ -2135,34 +3371,35 @@ func showObjectDetails(m *model.Model) error {
 	return nil
 }
 
-func updateDpFile(m *model.Model, tree *localfs.Tree) bool {
+func updateDpFile(ctx context.Context, target *syncTarget, tree *localfs.Tree) bool {
 	changesMade := false
 	localBytes, err := localfs.GetFileByPath(tree.Path)
 	if err != nil {
 		logging.LogDebug("worker/updateDpFile(), couldn't get local file - err: ", err)
 		return false
 	}
-	dpPath := dp.SyncRepo.GetFilePath(m.SyncDirDp, tree.PathFromRoot)
-	dpBytes, err := dp.SyncRepo.GetFileByPath(m.SyncDpDomain, dpPath)
+	dpPath := target.repo.GetFilePath(target.dpDir, tree.PathFromRoot)
+	dpBytes, err := target.repo.GetFileByPath(ctx, target.dpDomain, dpPath)
 
 	if err != nil {
 		if respErr, ok := err.(errs.UnexpectedHTTPResponse); !ok || respErr.StatusCode != 404 {
 			logging.LogDebug("worker/updateDpFile(), couldn't get dp file - err: ", err)
+			target.failed("Error reading file '%s'.", dpPath)
 			return false
 		}
 	}
 
 	if bytes.Compare(localBytes, dpBytes) != 0 {
 		changesMade = true
-		res, err := dp.SyncRepo.UpdateFileByPath(m.SyncDpDomain, dpPath, localBytes)
+		res, err := target.repo.UpdateFileByPath(ctx, target.dpDomain, dpPath, localBytes)
 		if err != nil {
 			logging.LogDebug("worker/updateDpFile(), couldn't update dp file - err: ", err)
 		}
 		logging.LogDebugf("worker/updateDpFile(), file '%s' updated: %T", dpPath, res)
 		if res {
-			updateStatusf("Dp file '%s' updated.", dpPath)
+			target.changed("Dp file '%s' updated.", dpPath)
 		} else {
-			updateStatusf("Error updating file '%s'.", dpPath)
+			target.failed("Error updating file '%s'.", dpPath)
 		}
 	}
 
@@ -2174,8 +3411,13 @@ func updateStatusf(format string, v ...interface{}) {
 	updateStatus(status)
 }
 
+// statusMutex serializes status updates (sync updates status from many goroutines).
+var statusMutex sync.Mutex
+
 func updateStatus(status string) {
 	logging.LogDebugf("worker/updateStatus('%s')", status)
+	statusMutex.Lock()
+	defer statusMutex.Unlock()
 	updateView := events.UpdateViewEvent{
 		Type: events.UpdateViewShowStatus, Status: status, Model: &workingModel}
 	out.DrawEvent(updateView)
@@ -2224,6 +3466,30 @@ func updateProgressDialog() {
 	}
 }
 
+// progressDialogShown returns true if progress dialog is shown (and not hidden
+// by input dialog).
+func progressDialogShown() bool {
+	return progressDialogSession.visible && !progressDialogSession.waitUserInput
+}
+
+// setCurrentActionCancel sets cancel function of the user action currently
+// processed.
+func setCurrentActionCancel(cancel context.CancelFunc) {
+	currentActionMutex.Lock()
+	defer currentActionMutex.Unlock()
+	currentActionCancel = cancel
+}
+
+// cancelCurrentAction cancels user action currently processed (in-flight
+// DataPower requests and polling are stopped).
+func cancelCurrentAction() {
+	currentActionMutex.Lock()
+	defer currentActionMutex.Unlock()
+	if currentActionCancel != nil {
+		currentActionCancel()
+	}
+}
+
 func updateProgressDialogMessagef(format string, v ...interface{}) {
 	progressDialogSession.msg = fmt.Sprintf(format, v...)
 }
@@ -0,0 +1,538 @@
+// Package diff implements line based comparison of text files and
+// directories (Myers diff algorithm) grouping found changes to hunks which
+// can be shown in unified or side-by-side diff view.
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Op is type of change of a line.
+type Op int
+
+// Types of line changes.
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// String returns prefix used for line with given change in unified diff.
+func (op Op) String() string {
+	switch op {
+	case Delete:
+		return "-"
+	case Insert:
+		return "+"
+	default:
+		return " "
+	}
+}
+
+// Line is a single line of compared content with its change type and line
+// numbers in old and new content (0 if line doesn't exist in content).
+// NoNewline is set for last line of content which doesn't end with newline.
+type Line struct {
+	Op        Op
+	Text      string
+	OldNo     int
+	NewNo     int
+	NoNewline bool
+}
+
+// Hunk is a group of changed lines with surrounding unchanged (context) lines.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []Line
+}
+
+// Header returns hunk header in unified diff format ("@@ -1,3 +1,4 @@").
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+}
+
+// hunkRange formats start line and line count of hunk header.
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, lines)
+}
+
+// Status is status of compared file.
+type Status string
+
+// Statuses of compared files.
+const (
+	Modified = Status("modified")
+	Added    = Status("added")
+	Removed  = Status("removed")
+	Binary   = Status("binary")
+)
+
+// File contains differences found in a single compared file. OldPath is empty
+// for added files and NewPath is empty for removed files.
+type File struct {
+	OldPath string
+	NewPath string
+	Status  Status
+	Hunks   []Hunk
+}
+
+// noNewlineMark is appended to last line of content without newline at the
+// end before lines are compared (it can't be part of any line) so such line
+// is different from the same line ending with newline.
+const noNewlineMark = "\n"
+
+// binaryCheckSize is number of bytes checked for NUL byte to decide if file
+// is binary.
+const binaryCheckSize = 8000
+
+// Compare compares old and new lines and returns all lines of both contents
+// with their change types.
+func Compare(oldLines, newLines []string) []Line {
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]Op, 0, len(oldLines)+len(newLines))
+	for idx := 0; idx < prefix; idx++ {
+		ops = append(ops, Equal)
+	}
+	ops = append(ops, editScript(oldLines[prefix:len(oldLines)-suffix], newLines[prefix:len(newLines)-suffix])...)
+	for idx := 0; idx < suffix; idx++ {
+		ops = append(ops, Equal)
+	}
+
+	lines := make([]Line, 0, len(ops))
+	oldIdx, newIdx := 0, 0
+	for _, op := range ops {
+		switch op {
+		case Equal:
+			lines = append(lines, Line{Op: op, Text: newLines[newIdx], OldNo: oldIdx + 1, NewNo: newIdx + 1})
+			oldIdx++
+			newIdx++
+		case Delete:
+			lines = append(lines, Line{Op: op, Text: oldLines[oldIdx], OldNo: oldIdx + 1})
+			oldIdx++
+		case Insert:
+			lines = append(lines, Line{Op: op, Text: newLines[newIdx], NewNo: newIdx + 1})
+			newIdx++
+		}
+	}
+
+	return lines
+}
+
+// editScript finds the shortest edit script transforming a to b using linear
+// space variant of Myers algorithm, deletions are placed before insertions.
+func editScript(a, b []string) []Op {
+	maxD := (len(a) + len(b) + 1) / 2
+	es := editScripter{a: a, b: b, ops: make([]Op, 0, len(a)+len(b)),
+		vf: make([]int, 2*maxD+2), vb: make([]int, 2*maxD+2)}
+	es.compare(0, len(a), 0, len(b))
+
+	// Deletions and insertions found in different middle snake searches can
+	// be mixed, changes between equal lines are reordered.
+	for start := 0; start < len(es.ops); {
+		if es.ops[start] == Equal {
+			start++
+			continue
+		}
+		end, deletes := start, 0
+		for ; end < len(es.ops) && es.ops[end] != Equal; end++ {
+			if es.ops[end] == Delete {
+				deletes++
+			}
+		}
+		for idx := start; idx < end; idx++ {
+			if idx < start+deletes {
+				es.ops[idx] = Delete
+			} else {
+				es.ops[idx] = Insert
+			}
+		}
+		start = end
+	}
+	return es.ops
+}
+
+// editScripter finds edit script by dividing compared lines at middle snakes
+// (furthest paths searched from start and end meet there) so only furthest
+// paths of current search are kept in memory.
+type editScripter struct {
+	a, b   []string
+	ops    []Op
+	vf, vb []int
+}
+
+// compare appends edit script transforming a[aLo:aHi] to b[bLo:bHi].
+func (es *editScripter) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && es.a[aLo] == es.b[bLo] {
+		es.ops = append(es.ops, Equal)
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi && bLo < bHi && es.a[aHi-1] == es.b[bHi-1] {
+		suffix++
+		aHi--
+		bHi--
+	}
+
+	switch {
+	case aLo == aHi:
+		es.appendOps(Insert, bHi-bLo)
+	case bLo == bHi:
+		es.appendOps(Delete, aHi-aLo)
+	default:
+		x, y, found := es.middleSnake(aLo, aHi, bLo, bHi)
+		if found {
+			es.compare(aLo, x, bLo, y)
+			es.compare(x, aHi, y, bHi)
+		} else {
+			es.appendOps(Delete, aHi-aLo)
+			es.appendOps(Insert, bHi-bLo)
+		}
+	}
+	es.appendOps(Equal, suffix)
+}
+
+// appendOps appends count ops to edit script.
+func (es *editScripter) appendOps(op Op, count int) {
+	for idx := 0; idx < count; idx++ {
+		es.ops = append(es.ops, op)
+	}
+}
+
+// middleSnake searches furthest paths from start and from end of
+// a[aLo:aHi] and b[bLo:bHi] (in turns, one edit at a time) until they
+// overlap and returns point where shortest edit script can be divided. If
+// paths don't overlap lines have nothing in common.
+func (es *editScripter) middleSnake(aLo, aHi, bLo, bHi int) (x, y int, found bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	vLen := 2 * maxD
+	vf, vb := es.vf[:vLen+2], es.vb[:vLen+2]
+	for idx := range vf {
+		vf[idx], vb[idx] = -1, -1
+	}
+	vf[offset+1], vb[offset+1] = 0, 0
+	delta := n - m
+	// If delta is odd paths overlap after forward search, otherwise after
+	// reverse search.
+	forwardOverlap := delta%2 != 0
+	// Diagonals leaving a[aLo:aHi] or b[bLo:bHi] are not searched further.
+	kfStart, kfEnd, kbStart, kbEnd := 0, 0, 0, 0
+	for d := 0; d < maxD; d++ {
+		for kf := -d + kfStart; kf <= d-kfEnd; kf += 2 {
+			kfIdx := offset + kf
+			var xf int
+			if kf == -d || (kf != d && vf[kfIdx-1] < vf[kfIdx+1]) {
+				xf = vf[kfIdx+1]
+			} else {
+				xf = vf[kfIdx-1] + 1
+			}
+			yf := xf - kf
+			for xf < n && yf < m && es.a[aLo+xf] == es.b[bLo+yf] {
+				xf++
+				yf++
+			}
+			vf[kfIdx] = xf
+			switch {
+			case xf > n:
+				kfEnd += 2
+			case yf > m:
+				kfStart += 2
+			case forwardOverlap:
+				kbIdx := offset + delta - kf
+				if kbIdx >= 0 && kbIdx < vLen && vb[kbIdx] != -1 && xf >= n-vb[kbIdx] {
+					return aLo + xf, bLo + yf, true
+				}
+			}
+		}
+
+		for kb := -d + kbStart; kb <= d-kbEnd; kb += 2 {
+			kbIdx := offset + kb
+			var xb int
+			if kb == -d || (kb != d && vb[kbIdx-1] < vb[kbIdx+1]) {
+				xb = vb[kbIdx+1]
+			} else {
+				xb = vb[kbIdx-1] + 1
+			}
+			yb := xb - kb
+			for xb < n && yb < m && es.a[aHi-1-xb] == es.b[bHi-1-yb] {
+				xb++
+				yb++
+			}
+			vb[kbIdx] = xb
+			switch {
+			case xb > n:
+				kbEnd += 2
+			case yb > m:
+				kbStart += 2
+			case !forwardOverlap:
+				kfIdx := offset + delta - kb
+				if kfIdx >= 0 && kfIdx < vLen && vf[kfIdx] != -1 {
+					xf := vf[kfIdx]
+					yf := offset + xf - kfIdx
+					if xf >= n-xb {
+						return aLo + xf, bLo + yf, true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// Hunks groups changed lines to hunks with given number of context lines
+// around changes, changes separated by less than two contexts are joined.
+func Hunks(lines []Line, context int) []Hunk {
+	hunks := make([]Hunk, 0)
+	for idx := 0; idx < len(lines); {
+		if lines[idx].Op == Equal {
+			idx++
+			continue
+		}
+
+		end := idx
+		for end < len(lines) {
+			if lines[end].Op != Equal {
+				end++
+				continue
+			}
+			equalEnd := end
+			for equalEnd < len(lines) && lines[equalEnd].Op == Equal {
+				equalEnd++
+			}
+			if equalEnd == len(lines) || equalEnd-end > 2*context {
+				break
+			}
+			end = equalEnd
+		}
+
+		start := idx - context
+		if start < 0 {
+			start = 0
+		}
+		stop := end + context
+		if stop > len(lines) {
+			stop = len(lines)
+		}
+		hunks = append(hunks, newHunk(lines, start, stop))
+		idx = stop
+	}
+
+	return hunks
+}
+
+// newHunk creates hunk from lines[start:stop].
+func newHunk(lines []Line, start, stop int) Hunk {
+	hunk := Hunk{Lines: lines[start:stop]}
+	for _, line := range lines[:start] {
+		if line.Op != Insert {
+			hunk.OldStart++
+		}
+		if line.Op != Delete {
+			hunk.NewStart++
+		}
+	}
+	for _, line := range hunk.Lines {
+		if line.Op != Insert {
+			hunk.OldLines++
+		}
+		if line.Op != Delete {
+			hunk.NewLines++
+		}
+	}
+	if hunk.OldLines > 0 {
+		hunk.OldStart++
+	}
+	if hunk.NewLines > 0 {
+		hunk.NewStart++
+	}
+	return hunk
+}
+
+// SplitLines splits content to lines (without line endings).
+func SplitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// isBinary returns true if content looks like binary (non-text) content.
+func isBinary(content []byte) bool {
+	if len(content) > binaryCheckSize {
+		content = content[:binaryCheckSize]
+	}
+	return bytes.IndexByte(content, 0) != -1
+}
+
+// Contents compares old and new content and returns differences found, false
+// is returned if contents are equal.
+func Contents(oldPath, newPath string, oldContent, newContent []byte, context int) (File, bool) {
+	file := File{OldPath: oldPath, NewPath: newPath, Status: Modified}
+	switch {
+	case oldPath == "":
+		file.Status = Added
+	case newPath == "":
+		file.Status = Removed
+	case bytes.Equal(oldContent, newContent):
+		return file, false
+	}
+	if isBinary(oldContent) || isBinary(newContent) {
+		file.Status = Binary
+		return file, true
+	}
+
+	lines := Compare(splitMarkedLines(oldContent), splitMarkedLines(newContent))
+	for idx := range lines {
+		if strings.HasSuffix(lines[idx].Text, noNewlineMark) {
+			lines[idx].Text = strings.TrimSuffix(lines[idx].Text, noNewlineMark)
+			lines[idx].NoNewline = true
+		}
+	}
+	file.Hunks = Hunks(lines, context)
+	return file, true
+}
+
+// splitMarkedLines splits content to lines, last line is marked with
+// noNewlineMark if content doesn't end with newline.
+func splitMarkedLines(content []byte) []string {
+	lines := SplitLines(content)
+	if len(lines) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		lines[len(lines)-1] += noNewlineMark
+	}
+	return lines
+}
+
+// Paths compares files or (recursively) directories on given local paths and
+// returns differences found in each changed file.
+func Paths(oldPath, newPath string, context int) ([]File, error) {
+	logging.LogDebugf("utils/diff/Paths('%s', '%s')", oldPath, newPath)
+	oldInfo, err := os.Stat(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newInfo, err := os.Stat(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !oldInfo.IsDir() && !newInfo.IsDir():
+		return compareFiles(nil, oldPath, newPath, context)
+	case oldInfo.IsDir() && newInfo.IsDir():
+		return compareDirs(oldPath, newPath, context)
+	case oldInfo.IsDir():
+		return nil, errs.Errorf("Can't compare directory '%s' to file '%s'.", oldPath, newPath)
+	default:
+		return nil, errs.Errorf("Can't compare file '%s' to directory '%s'.", oldPath, newPath)
+	}
+}
+
+// compareDirs compares all files found in old and new directory trees.
+func compareDirs(oldDir, newDir string, context int) ([]File, error) {
+	oldFiles, err := dirFiles(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := dirFiles(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	relPaths := make([]string, 0, len(oldFiles)+len(newFiles))
+	for relPath := range oldFiles {
+		relPaths = append(relPaths, relPath)
+	}
+	for relPath := range newFiles {
+		if !oldFiles[relPath] {
+			relPaths = append(relPaths, relPath)
+		}
+	}
+	sort.Strings(relPaths)
+
+	files := make([]File, 0)
+	for _, relPath := range relPaths {
+		oldPath, newPath := "", ""
+		if oldFiles[relPath] {
+			oldPath = filepath.Join(oldDir, relPath)
+		}
+		if newFiles[relPath] {
+			newPath = filepath.Join(newDir, relPath)
+		}
+		files, err = compareFiles(files, oldPath, newPath, context)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// dirFiles returns paths (relative to dir) of all files in directory tree.
+func dirFiles(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			relPath, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files[relPath] = true
+		}
+		return nil
+	})
+	return files, err
+}
+
+// compareFiles compares old and new file (one of them can be missing - empty
+// path) and appends differences to files if files are different.
+func compareFiles(files []File, oldPath, newPath string, context int) ([]File, error) {
+	var oldContent, newContent []byte
+	var err error
+	if oldPath != "" {
+		oldContent, err = ioutil.ReadFile(oldPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if newPath != "" {
+		newContent, err = ioutil.ReadFile(newPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	file, changed := Contents(oldPath, newPath, oldContent, newContent, context)
+	if changed {
+		files = append(files, file)
+	}
+	return files, nil
+}
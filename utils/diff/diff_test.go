@@ -0,0 +1,244 @@
+package diff
+
+import (
+	"fmt"
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// unified returns lines with their unified diff prefixes.
+func unified(lines []Line) string {
+	result := make([]string, len(lines))
+	for idx, line := range lines {
+		result[idx] = line.Op.String() + line.Text
+	}
+	return strings.Join(result, "|")
+}
+
+func TestCompare(t *testing.T) {
+	testDataMatrix := []struct {
+		oldText string
+		newText string
+		want    string
+	}{
+		{"", "", ""},
+		{"a", "a", " a"},
+		{"", "a\nb", "+a|+b"},
+		{"a\nb", "", "-a|-b"},
+		{"a\nb\nc", "a\nc", " a|-b| c"},
+		{"a\nc", "a\nb\nc", " a|+b| c"},
+		{"a\nb\nc", "a\nx\nc", " a|-b|+x| c"},
+		{"a\nb\nc\nd", "b\nc\nd\ne", "-a| b| c| d|+e"},
+		{"a\nb\nc\na\nb\nb\na", "c\nb\na\nb\na\nc", "-a|+c| b|-c| a| b|-b| a|+c"},
+	}
+
+	for _, testCase := range testDataMatrix {
+		got := unified(Compare(SplitLines([]byte(testCase.oldText)), SplitLines([]byte(testCase.newText))))
+		assert.Equals(t, fmt.Sprintf("Compare(%q, %q)", testCase.oldText, testCase.newText), got, testCase.want)
+	}
+}
+
+func TestCompareLineNumbers(t *testing.T) {
+	lines := Compare([]string{"a", "b", "c"}, []string{"a", "x", "c"})
+	want := []Line{
+		{Equal, "a", 1, 1, false},
+		{Delete, "b", 2, 0, false},
+		{Insert, "x", 0, 2, false},
+		{Equal, "c", 3, 3, false},
+	}
+	assert.DeepEqual(t, "Compare() line numbers", lines, want)
+}
+
+// edits checks lines contain all old and new lines and returns number of
+// deleted and inserted lines.
+func edits(t *testing.T, name string, oldLines, newLines []string, lines []Line) int {
+	t.Helper()
+	gotOld, gotNew := make([]string, 0), make([]string, 0)
+	count := 0
+	for _, line := range lines {
+		if line.Op != Insert {
+			gotOld = append(gotOld, line.Text)
+		}
+		if line.Op != Delete {
+			gotNew = append(gotNew, line.Text)
+		}
+		if line.Op != Equal {
+			count++
+		}
+	}
+	assert.DeepEqual(t, name+" old lines", gotOld, append(make([]string, 0), oldLines...))
+	assert.DeepEqual(t, name+" new lines", gotNew, append(make([]string, 0), newLines...))
+	return count
+}
+
+func TestCompareLarge(t *testing.T) {
+	oldLines := make([]string, 6000)
+	for idx := range oldLines {
+		oldLines[idx] = fmt.Sprintf("old %d", idx)
+	}
+	newLines := make([]string, 5000)
+	for idx := range newLines {
+		newLines[idx] = fmt.Sprintf("new %d", idx)
+	}
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	allocated := memStats.TotalAlloc
+	lines := Compare(oldLines, newLines)
+	runtime.ReadMemStats(&memStats)
+	allocated = memStats.TotalAlloc - allocated
+
+	assert.Equals(t, "Compare() no common lines edits",
+		edits(t, "Compare() no common lines", oldLines, newLines, lines), 11000)
+	assert.Equals(t, "Compare() no common lines deletions first", lines[5999].Op, Delete)
+	assert.Equals(t, "Compare() no common lines insertions last", lines[6000].Op, Insert)
+	assert.Equals(t, "Compare() no common lines memory", allocated < 10*1024*1024, true)
+
+	// Every third line changed: 1000 lines deleted and 1000 inserted.
+	oldLines = make([]string, 3000)
+	newLines = make([]string, 3000)
+	for idx := range oldLines {
+		oldLines[idx] = fmt.Sprintf("line %d", idx)
+		newLines[idx] = oldLines[idx]
+		if idx%3 == 1 {
+			newLines[idx] = fmt.Sprintf("changed %d", idx)
+		}
+	}
+	lines = Compare(oldLines, newLines)
+	assert.Equals(t, "Compare() changed lines edits",
+		edits(t, "Compare() changed lines", oldLines, newLines, lines), 2000)
+	assert.Equals(t, "Compare() changed lines order", unified(lines[:5]),
+		" line 0|-line 1|+changed 1| line 2| line 3")
+}
+
+func TestHunks(t *testing.T) {
+	oldLines := make([]string, 20)
+	for idx := range oldLines {
+		oldLines[idx] = fmt.Sprintf("line %d", idx+1)
+	}
+	newLines := make([]string, len(oldLines))
+	copy(newLines, oldLines)
+	newLines[1] = "changed 2"
+	newLines[6] = "changed 7"
+	newLines = append(newLines[:15], newLines[16:]...)
+
+	testDataMatrix := []struct {
+		context int
+		headers []string
+	}{
+		{0, []string{"@@ -2 +2 @@", "@@ -7 +7 @@", "@@ -16 +15,0 @@"}},
+		{1, []string{"@@ -1,3 +1,3 @@", "@@ -6,3 +6,3 @@", "@@ -15,3 +15,2 @@"}},
+		{2, []string{"@@ -1,9 +1,9 @@", "@@ -14,5 +14,4 @@"}},
+		{3, []string{"@@ -1,10 +1,10 @@", "@@ -13,7 +13,6 @@"}},
+		{5, []string{"@@ -1,20 +1,19 @@"}},
+	}
+
+	lines := Compare(oldLines, newLines)
+	for _, testCase := range testDataMatrix {
+		hunks := Hunks(lines, testCase.context)
+		headers := make([]string, len(hunks))
+		for idx, hunk := range hunks {
+			headers[idx] = hunk.Header()
+		}
+		assert.DeepEqual(t, fmt.Sprintf("Hunks(.., %d)", testCase.context), headers, testCase.headers)
+	}
+
+	assert.DeepEqual(t, "Hunks() without changes", Hunks(Compare(oldLines, oldLines), 3), []Hunk{})
+	assert.Equals(t, "Hunks() added file", Hunks(Compare(nil, []string{"a", "b"}), 3)[0].Header(), "@@ -0,0 +1,2 @@")
+}
+
+func TestContents(t *testing.T) {
+	testDataMatrix := []struct {
+		oldPath    string
+		newPath    string
+		oldContent string
+		newContent string
+		status     Status
+		changed    bool
+		hunks      int
+	}{
+		{"a", "b", "x\ny\n", "x\ny\n", Modified, false, 0},
+		{"a", "b", "x\ny\n", "x\nz\n", Modified, true, 1},
+		{"", "b", "", "x\n", Added, true, 1},
+		{"a", "", "x\n", "", Removed, true, 1},
+		{"a", "b", "x\x00", "y\x00", Binary, true, 0},
+		{"a", "b", "x", "x", Modified, false, 0},
+		{"a", "b", "x", "x\n", Modified, true, 1},
+		{"a", "b", "x\ny\n", "x\ny", Modified, true, 1},
+	}
+
+	for _, testCase := range testDataMatrix {
+		file, changed := Contents(testCase.oldPath, testCase.newPath,
+			[]byte(testCase.oldContent), []byte(testCase.newContent), 3)
+		testName := fmt.Sprintf("Contents(%q, %q)", testCase.oldContent, testCase.newContent)
+		assert.Equals(t, testName+" changed", changed, testCase.changed)
+		if changed {
+			assert.Equals(t, testName+" status", file.Status, testCase.status)
+			assert.Equals(t, testName+" hunks", len(file.Hunks), testCase.hunks)
+		}
+	}
+}
+
+func TestContentsNoNewline(t *testing.T) {
+	file, changed := Contents("a", "b", []byte("x\ny"), []byte("x\ny\n"), 3)
+	assert.Equals(t, "Contents() changed", changed, true)
+	assert.DeepEqual(t, "Contents() lines", file.Hunks[0].Lines, []Line{
+		{Op: Equal, Text: "x", OldNo: 1, NewNo: 1},
+		{Op: Delete, Text: "y", OldNo: 2, NoNewline: true},
+		{Op: Insert, Text: "y", NewNo: 2}})
+}
+
+func TestPaths(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "dpcmder-diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	testFiles := map[string]string{
+		"old/same.txt":       "same\n",
+		"new/same.txt":       "same\n",
+		"old/changed.txt":    "a\nb\n",
+		"new/changed.txt":    "a\nc\n",
+		"old/sub/removed.js": "x\n",
+		"new/sub/added.xml":  "<a/>\n",
+	}
+	for filePath, content := range testFiles {
+		fullPath := filepath.Join(dirPath, filePath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldDir, newDir := filepath.Join(dirPath, "old"), filepath.Join(dirPath, "new")
+	files, err := Paths(oldDir, newDir, 3)
+	assert.Equals(t, "Paths() error", err, nil)
+	got := make([]string, len(files))
+	for idx, file := range files {
+		got[idx] = fmt.Sprintf("%s %s %s %d", file.Status,
+			strings.TrimPrefix(file.OldPath, dirPath), strings.TrimPrefix(file.NewPath, dirPath), len(file.Hunks))
+	}
+	want := []string{
+		fmt.Sprintf("modified %s %s 1", filepath.Join("/old", "changed.txt"), filepath.Join("/new", "changed.txt")),
+		fmt.Sprintf("added  %s 1", filepath.Join("/new", "sub", "added.xml")),
+		fmt.Sprintf("removed %s  1", filepath.Join("/old", "sub", "removed.js")),
+	}
+	assert.DeepEqual(t, "Paths() directories", got, want)
+
+	files, err = Paths(filepath.Join(oldDir, "same.txt"), filepath.Join(newDir, "same.txt"), 3)
+	assert.Equals(t, "Paths() same files error", err, nil)
+	assert.Equals(t, "Paths() same files", len(files), 0)
+
+	_, err = Paths(oldDir, filepath.Join(newDir, "same.txt"), 3)
+	if err == nil {
+		t.Errorf("Paths() directory to file should return error.")
+	}
+}
@@ -22,3 +22,16 @@ type UnexpectedHTTPResponse struct {
 func (uhr UnexpectedHTTPResponse) Error() string {
 	return fmt.Sprintf("UnexpectedHTTPResponse(%d '%s')", uhr.StatusCode, uhr.Status)
 }
+
+// RequestTimeout is error interface implementation for DataPower request which
+// didn't finish in time (connect or response timeout).
+type RequestTimeout struct {
+	Appliance string
+	Method    string
+	URL       string
+}
+
+func (rt RequestTimeout) Error() string {
+	return fmt.Sprintf("DataPower '%s' didn't respond in time (%s %s), check connection or timeout settings.",
+		rt.Appliance, rt.Method, rt.URL)
+}
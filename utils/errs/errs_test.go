@@ -25,3 +25,11 @@ func TestUnexpectedHTTPResponse(t *testing.T) {
 		t.Errorf("Error string is not '%s' but '%s'.", want, testErr.Error())
 	}
 }
+
+func TestRequestTimeout(t *testing.T) {
+	testErr := RequestTimeout{Appliance: "dp", Method: "GET", URL: "https://dp:5554/mgmt/"}
+	want := "DataPower 'dp' didn't respond in time (GET https://dp:5554/mgmt/), check connection or timeout settings."
+	if testErr.Error() != want {
+		t.Errorf("Error string is not '%s' but '%s'.", want, testErr.Error())
+	}
+}
@@ -0,0 +1,192 @@
+// Package ignore implements matching of paths against ignore patterns written
+// in gitignore syntax (used for .dpcmderignore file and sync configuration).
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/croz-ltd/dpcmder/utils/logging"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// FileName is name of the file containing ignore patterns.
+const FileName = ".dpcmderignore"
+
+// rule is one parsed ignore pattern.
+type rule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher matches paths against ignore patterns, last matching pattern wins.
+type Matcher struct {
+	rules []rule
+}
+
+// NewMatcher creates Matcher from list of patterns (in gitignore syntax).
+func NewMatcher(patterns ...string) Matcher {
+	m := Matcher{}
+	m.Add(patterns...)
+	return m
+}
+
+// Add adds patterns (in gitignore syntax) to Matcher.
+func (m *Matcher) Add(patterns ...string) {
+	for _, pattern := range patterns {
+		r, ok := parseRule(pattern)
+		if ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+}
+
+// AddFile adds patterns from ignore file to Matcher.
+func (m *Matcher) AddFile(filePath string) error {
+	fileBytes, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(fileBytes))
+	for scanner.Scan() {
+		m.Add(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// Empty returns true if Matcher doesn't contain any pattern.
+func (m Matcher) Empty() bool {
+	return len(m.rules) == 0
+}
+
+// Ignored checks if path (relative to ignore root, using "/" as a separator)
+// should be ignored. Path is ignored if it, or any of its parent directories,
+// matches ignore patterns.
+func (m Matcher) Ignored(path string, dir bool) bool {
+	if len(m.rules) == 0 {
+		return false
+	}
+	path = strings.Trim(path, "/")
+	if path == "" || path == "." {
+		return false
+	}
+
+	elements := strings.Split(path, "/")
+	for idx := 1; idx < len(elements); idx++ {
+		if m.matches(strings.Join(elements[:idx], "/"), true) {
+			return true
+		}
+	}
+
+	return m.matches(path, dir)
+}
+
+// matches checks if path matches ignore patterns (without checking parents).
+func (m Matcher) matches(path string, dir bool) bool {
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !dir {
+			continue
+		}
+		if r.pattern.MatchString(path) {
+			ignored = !r.negate
+		}
+	}
+
+	return ignored
+}
+
+// parseRule parses one line of ignore file.
+func parseRule(pattern string) (rule, bool) {
+	r := rule{}
+	pattern = strings.TrimRight(pattern, " \t\r")
+	switch {
+	case pattern == "", strings.HasPrefix(pattern, "#"):
+		return r, false
+	case strings.HasPrefix(pattern, "!"):
+		r.negate = true
+		pattern = pattern[1:]
+	case strings.HasPrefix(pattern, `\#`), strings.HasPrefix(pattern, `\!`):
+		pattern = pattern[1:]
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		r.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return r, false
+	}
+
+	// Pattern without slash (except trailing one) matches at any level.
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	regexpString := "^" + globToRegexp(pattern) + "$"
+	if !anchored {
+		regexpString = "^(?:.*/)?" + globToRegexp(pattern) + "$"
+	}
+	var err error
+	r.pattern, err = regexp.Compile(regexpString)
+	if err != nil {
+		logging.LogDebugf("utils/ignore/parseRule(), invalid pattern '%s': %v", pattern, err)
+		return r, false
+	}
+
+	return r, true
+}
+
+// globToRegexp converts gitignore glob pattern to regular expression.
+func globToRegexp(pattern string) string {
+	var result strings.Builder
+	for idx := 0; idx < len(pattern); idx++ {
+		c := pattern[idx]
+		switch c {
+		case '*':
+			if strings.HasPrefix(pattern[idx:], "**") {
+				rest := pattern[idx+2:]
+				atStart := idx == 0 || pattern[idx-1] == '/'
+				switch {
+				case atStart && strings.HasPrefix(rest, "/"):
+					// "**/" matches zero or more directories.
+					result.WriteString("(?:.*/)?")
+					idx += 2
+				case atStart && rest == "":
+					// Trailing "/**" matches everything inside.
+					result.WriteString(".*")
+					idx++
+				default:
+					result.WriteString("[^/]*")
+					idx++
+				}
+			} else {
+				result.WriteString("[^/]*")
+			}
+		case '?':
+			result.WriteString("[^/]")
+		case '[':
+			closeIdx := strings.Index(pattern[idx+1:], "]")
+			if closeIdx == -1 {
+				result.WriteString(`\[`)
+				continue
+			}
+			class := pattern[idx+1 : idx+1+closeIdx]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			result.WriteString("[" + strings.Replace(class, `\`, `\\`, -1) + "]")
+			idx += closeIdx + 1
+		case '\\':
+			if idx+1 < len(pattern) {
+				idx++
+				result.WriteString(regexp.QuoteMeta(string(pattern[idx])))
+			}
+		default:
+			result.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return result.String()
+}
@@ -0,0 +1,73 @@
+package ignore
+
+import (
+	"fmt"
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnored(t *testing.T) {
+	testDataMatrix := []struct {
+		patterns []string
+		path     string
+		dir      bool
+		ignored  bool
+	}{
+		{[]string{}, "a.txt", false, false},
+		{[]string{"# comment", ""}, "# comment", false, false},
+		{[]string{"*.swp"}, "a.swp", false, true},
+		{[]string{"*.swp"}, "dir/sub/.a.swp", false, true},
+		{[]string{"*.swp"}, "a.swp.txt", false, false},
+		{[]string{"node_modules/"}, "node_modules", true, true},
+		{[]string{"node_modules/"}, "node_modules", false, false},
+		{[]string{"node_modules/"}, "src/node_modules/lib/a.js", false, true},
+		{[]string{".git"}, ".git/config", false, true},
+		{[]string{"/build"}, "build", true, true},
+		{[]string{"/build"}, "src/build", true, false},
+		{[]string{"doc/*.html"}, "doc/index.html", false, true},
+		{[]string{"doc/*.html"}, "doc/api/index.html", false, false},
+		{[]string{"**/tmp"}, "tmp", true, true},
+		{[]string{"**/tmp"}, "a/b/tmp", false, true},
+		{[]string{"logs/**"}, "logs/a/b.log", false, true},
+		{[]string{"logs/**"}, "logs", true, false},
+		{[]string{"a/**/b"}, "a/b", false, true},
+		{[]string{"a/**/b"}, "a/x/y/b", false, true},
+		{[]string{"file?.txt"}, "file1.txt", false, true},
+		{[]string{"file?.txt"}, "file10.txt", false, false},
+		{[]string{"file[0-9].txt"}, "file5.txt", false, true},
+		{[]string{"file[!0-9].txt"}, "file5.txt", false, false},
+		{[]string{"file[!0-9].txt"}, "filex.txt", false, true},
+		{[]string{"*.log", "!keep.log"}, "keep.log", false, false},
+		{[]string{"*.log", "!keep.log"}, "other.log", false, true},
+		{[]string{`\#file`}, "#file", false, true},
+		{[]string{"a.txt"}, "", true, false},
+	}
+
+	for _, testCase := range testDataMatrix {
+		m := NewMatcher(testCase.patterns...)
+		methodCall := fmt.Sprintf("Ignored(%v, '%s', %t)", testCase.patterns, testCase.path, testCase.dir)
+		assert.DeepEqual(t, methodCall, m.Ignored(testCase.path, testCase.dir), testCase.ignored)
+	}
+}
+
+func TestAddFile(t *testing.T) {
+	dirPath, err := ioutil.TempDir("", "dpcmder-ignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+	filePath := filepath.Join(dirPath, FileName)
+	ioutil.WriteFile(filePath, []byte("# editor files\r\n*~\n\n.git/\n"), os.ModePerm)
+
+	m := Matcher{}
+	assert.DeepEqual(t, "Empty()", m.Empty(), true)
+	err = m.AddFile(filePath)
+	assert.DeepEqual(t, "AddFile()", err, nil)
+	assert.DeepEqual(t, "Empty()", m.Empty(), false)
+	assert.DeepEqual(t, "Ignored()", m.Ignored("src/a.js~", false), true)
+	assert.DeepEqual(t, "Ignored()", m.Ignored(".git/HEAD", false), true)
+	assert.DeepEqual(t, "Ignored()", m.Ignored("src/a.js", false), false)
+}
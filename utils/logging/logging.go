@@ -1,24 +1,67 @@
-// Package logging implements methods used for logging to dpcmder.log file and
-// adds timestamp to each log line.
+// Package logging implements methods used for logging to dpcmder log file and
+// adds timestamp to each log line. Log file is rotated when it grows over
+// configured size, passwords and basic authentication credentials are
+// redacted from log lines. Logging errors never stop dpcmder - if log file
+// can't be written log lines are dropped until log file can be opened again.
 package logging
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"strconv"
+	"sync"
 	"time"
 )
 
 var (
-	logFilePath = "./dpcmder.log"
+	// FilePath is path of log file, relative paths are relative to current folder.
+	FilePath = "./dpcmder.log"
 	// MaxEntrySize limits log line length.
 	MaxEntrySize = 1000
-	// DebugLogFile enables writing of debug messages to dpcmder.log file in current folder.
+	// MaxFileSize is size of log file (in bytes) which triggers log rotation,
+	// log file is not rotated if MaxFileSize is 0.
+	MaxFileSize int64 = 10 * 1024 * 1024
+	// MaxBackups is number of rotated log files kept (dpcmder.log.1, ...).
+	MaxBackups = 3
+	// JSONFormat enables writing of log lines as JSON objects.
+	JSONFormat = false
+	// DebugLogFile enables writing of debug messages to log file.
 	DebugLogFile = false
-	// TraceLogFile enables writing of trace messages to dpcmder.log file in current folder.
+	// TraceLogFile enables writing of trace messages to log file.
 	TraceLogFile = false
 )
 
+// reopenDelay is time logging waits before trying to open log file again
+// after log file can't be opened or written to.
+const reopenDelay = 5 * time.Second
+
+// logFile contains state of log file currently written to.
+var logFile struct {
+	mutex      sync.Mutex
+	file       *os.File
+	path       string
+	size       int64
+	failedTime time.Time
+	dropped    int
+	lastErr    error
+}
+
+// redactPatterns find passwords and basic authentication credentials in log
+// lines (HTTP headers, SOMA XML elements & attributes, JSON and Go structs).
+var redactPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(authorization\W{0,4}basic\s+)[a-z0-9+/=]+`),
+	regexp.MustCompile(`(?is)(<(?:[\w.-]+:)?[\w.-]*password\b[^>/]*>)[^<]*`),
+	regexp.MustCompile(`(?i)("?\w*password"?\s*[:=]\s*")(?:[^"\\]|\\.)*`),
+	regexp.MustCompile(`(?i)(\w*password\s*[:=]\s*')[^']*`),
+	regexp.MustCompile(`(?i)(\bpassword:)[^\s"'}\]]+`),
+}
+
+// redactedValue replaces redacted passwords and credentials.
+const redactedValue = "${1}***"
+
 // LogFatal logs fatal error message to log file and exits dpcmder.
 func LogFatal(v ...interface{}) {
 	log.Fatal(v...)
@@ -27,44 +70,158 @@ func LogFatal(v ...interface{}) {
 // LogDebug logs debug message to log file.
 func LogDebug(v ...interface{}) {
 	if DebugLogFile || TraceLogFile {
-		logInternal(fmt.Sprint(v...))
+		logInternal("debug", fmt.Sprint(v...))
 	}
 }
 
 // LogDebugf logs formatted debug message to log file.
 func LogDebugf(format string, params ...interface{}) {
 	if DebugLogFile || TraceLogFile {
-		logInternal(fmt.Sprintf(format, params...))
+		logInternal("debug", fmt.Sprintf(format, params...))
 	}
 }
 
 // LogTrace logs trace message to log file.
 func LogTrace(v ...interface{}) {
 	if TraceLogFile {
-		logInternal(fmt.Sprint(v...))
+		logInternal("trace", fmt.Sprint(v...))
 	}
 }
 
 // LogTracef logs trace message to log file.
 func LogTracef(format string, params ...interface{}) {
 	if TraceLogFile {
-		logInternal(fmt.Sprintf(format, params...))
+		logInternal("trace", fmt.Sprintf(format, params...))
+	}
+}
+
+// Redact removes passwords and basic authentication credentials from message.
+func Redact(msg string) string {
+	for _, pattern := range redactPatterns {
+		msg = pattern.ReplaceAllString(msg, redactedValue)
 	}
+	return msg
 }
 
-func logInternal(logMsg string) {
-	timedMsg := fmt.Sprintf("%s: %s\n", time.Now().Format("2006-01-02T15:04:05.999"), logMsg)
-	lineLen := len(timedMsg)
-	if lineLen > MaxEntrySize {
-		timedMsg = timedMsg[:MaxEntrySize] + "\n"
+func logInternal(level, logMsg string) {
+	line := formatLine(time.Now(), level, Redact(logMsg))
+
+	logFile.mutex.Lock()
+	defer logFile.mutex.Unlock()
+	writeLine(line)
+}
+
+// formatLine formats log line as text or JSON line, message is truncated to
+// MaxEntrySize bytes.
+func formatLine(t time.Time, level, logMsg string) string {
+	if MaxEntrySize > 0 && len(logMsg) > MaxEntrySize {
+		logMsg = logMsg[:MaxEntrySize]
+	}
+	timestamp := t.Format("2006-01-02T15:04:05.999")
+	if JSONFormat {
+		jsonLine, err := json.Marshal(struct {
+			Time    string `json:"time"`
+			Level   string `json:"level"`
+			Message string `json:"msg"`
+		}{timestamp, level, logMsg})
+		if err == nil {
+			return string(jsonLine) + "\n"
+		}
+	}
+	return fmt.Sprintf("%s: %s\n", timestamp, logMsg)
+}
+
+// writeLine writes line to log file (opening or rotating log file if needed).
+// Errors are not returned - line is dropped and log file is closed so it is
+// opened again after reopenDelay (logFile.mutex must be locked).
+func writeLine(line string) {
+	if logFile.file == nil || logFile.path != FilePath {
+		if time.Since(logFile.failedTime) < reopenDelay {
+			logFile.dropped++
+			return
+		}
+		if err := openLogFile(); err != nil {
+			logFailure(err)
+			return
+		}
+	}
+	if MaxFileSize > 0 && logFile.size > 0 && logFile.size+int64(len(line)) > MaxFileSize {
+		if err := rotateLogFile(); err != nil {
+			logFailure(err)
+			return
+		}
+	}
+
+	n, err := logFile.file.WriteString(line)
+	logFile.size += int64(n)
+	if err != nil {
+		logFailure(err)
 	}
+}
 
-	f, err := os.OpenFile(logFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// openLogFile opens log file for appending (logFile.mutex must be locked).
+func openLogFile() error {
+	closeLogFile()
+	f, err := os.OpenFile(FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	logFile.file = f
+	logFile.path = FilePath
+	logFile.size = 0
+	if info, err := f.Stat(); err == nil {
+		logFile.size = info.Size()
+	}
+	if logFile.dropped > 0 {
+		dropped := logFile.dropped
+		logFile.dropped = 0
+		writeLine(formatLine(time.Now(), "debug",
+			fmt.Sprintf("logging - %d log line(s) dropped while log file couldn't be written (%v).",
+				dropped, logFile.lastErr)))
+	}
+	return nil
+}
+
+// rotateLogFile renames log file to first backup (shifting older backups and
+// removing the oldest one) and opens new log file (logFile.mutex must be
+// locked).
+func rotateLogFile() error {
+	closeLogFile()
+	if MaxBackups < 1 {
+		if err := os.Remove(FilePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return openLogFile()
 	}
-	defer f.Close()
-	if _, err := f.Write([]byte(timedMsg)); err != nil {
-		log.Fatal(err)
+	os.Remove(backupPath(MaxBackups))
+	for idx := MaxBackups - 1; idx > 0; idx-- {
+		os.Rename(backupPath(idx), backupPath(idx+1))
+	}
+	if err := os.Rename(FilePath, backupPath(1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return openLogFile()
+}
+
+// backupPath returns path of rotated log file with given index.
+func backupPath(idx int) string {
+	return FilePath + "." + strconv.Itoa(idx)
+}
+
+// logFailure drops log file after it can't be written to - error can't be
+// shown since screen is used by dpcmder UI, number of dropped log lines is
+// logged after log file is opened again (logFile.mutex must be locked).
+func logFailure(err error) {
+	closeLogFile()
+	logFile.failedTime = time.Now()
+	logFile.dropped++
+	logFile.lastErr = err
+}
+
+// closeLogFile closes log file if it is open (logFile.mutex must be locked).
+func closeLogFile() {
+	if logFile.file != nil {
+		logFile.file.Close()
+		logFile.file = nil
 	}
 }
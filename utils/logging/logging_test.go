@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedact(t *testing.T) {
+	testDataMatrix := [][]string{
+		{"no secrets here", "no secrets here"},
+		{"Basic settings changed", "Basic settings changed"},
+		{"Authorization: Basic dXNlcjpwYXNz", "Authorization: Basic ***"},
+		{"header: map[Authorization:[Basic dXNlcjpwYXNz]]", "header: map[Authorization:[Basic ***]]"},
+		{"<dp:password>secret</dp:password>", "<dp:password>***</dp:password>"},
+		{`<man:user-password xmlns:man="x">secret</man:user-password>`,
+			`<man:user-password xmlns:man="x">***</man:user-password>`},
+		{"<PasswordAlias>myAlias</PasswordAlias>", "<PasswordAlias>myAlias</PasswordAlias>"},
+		{`{"Username":"admin","Password":"secret"}`, `{"Username":"admin","Password":"***"}`},
+		{`config.DataPowerAppliance{Username:"admin", Password:"se\"cret"}`,
+			`config.DataPowerAppliance{Username:"admin", Password:"***"}`},
+		{"{admin secret} {Username:admin Password:secret}", "{admin secret} {Username:admin Password:***}"},
+		{"password='secret' user='admin'", "password='***' user='admin'"},
+		{"password: can't be empty", "password: can't be empty"},
+	}
+	for _, testCase := range testDataMatrix {
+		assert.Equals(t, "Redact("+testCase[0]+")", Redact(testCase[0]), testCase[1])
+	}
+}
+
+func TestFormatLine(t *testing.T) {
+	defer func(maxEntrySize int, jsonFormat bool) {
+		MaxEntrySize, JSONFormat = maxEntrySize, jsonFormat
+	}(MaxEntrySize, JSONFormat)
+	lineTime := time.Date(2020, 1, 2, 3, 4, 5, 600000000, time.UTC)
+
+	MaxEntrySize, JSONFormat = 5, false
+	assert.Equals(t, "formatLine() text", formatLine(lineTime, "debug", "message"),
+		"2020-01-02T03:04:05.6: messa\n")
+	JSONFormat = true
+	assert.Equals(t, "formatLine() JSON", formatLine(lineTime, "trace", `"q"`),
+		`{"time":"2020-01-02T03:04:05.6","level":"trace","msg":"\"q\""}`+"\n")
+}
+
+func TestRotation(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "dpcmder-logging")
+	assert.Equals(t, "TempDir()", err, nil)
+	defer os.RemoveAll(tmpDir)
+	defer func(filePath string, maxFileSize int64, maxBackups int, debug bool) {
+		logFile.mutex.Lock()
+		closeLogFile()
+		logFile.mutex.Unlock()
+		FilePath, MaxFileSize, MaxBackups, DebugLogFile = filePath, maxFileSize, maxBackups, debug
+	}(FilePath, MaxFileSize, MaxBackups, DebugLogFile)
+
+	FilePath = filepath.Join(tmpDir, "dpcmder.log")
+	MaxFileSize, MaxBackups, DebugLogFile = 100, 2, true
+	for idx := 0; idx < 10; idx++ {
+		LogDebugf("log line %d with some text", idx)
+	}
+
+	for _, path := range []string{FilePath, backupPath(1), backupPath(2)} {
+		content, err := ioutil.ReadFile(path)
+		assert.Equals(t, "ReadFile("+path+")", err, nil)
+		assert.Equals(t, "log file size <= MaxFileSize", len(content) <= 100, true)
+	}
+	_, err = os.Stat(backupPath(3))
+	assert.Equals(t, "only MaxBackups rotated files kept", os.IsNotExist(err), true)
+	content, _ := ioutil.ReadFile(FilePath)
+	assert.Equals(t, "last line logged", strings.HasSuffix(string(content), "log line 9 with some text\n"), true)
+}
+
+func TestLogFileFailure(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "dpcmder-logging")
+	assert.Equals(t, "TempDir()", err, nil)
+	defer os.RemoveAll(tmpDir)
+	defer func(filePath string, debug bool) {
+		logFile.mutex.Lock()
+		closeLogFile()
+		logFile.failedTime = time.Time{}
+		logFile.dropped = 0
+		logFile.mutex.Unlock()
+		FilePath, DebugLogFile = filePath, debug
+	}(FilePath, DebugLogFile)
+
+	FilePath = filepath.Join(tmpDir, "missing-dir", "dpcmder.log")
+	DebugLogFile = true
+	LogDebug("dropped line 1")
+	LogDebug("dropped line 2")
+	assert.Equals(t, "dropped lines", logFile.dropped, 2)
+
+	FilePath = filepath.Join(tmpDir, "dpcmder.log")
+	logFile.failedTime = time.Time{}
+	LogDebug("logged line")
+	content, err := ioutil.ReadFile(FilePath)
+	assert.Equals(t, "ReadFile()", err, nil)
+	assert.Equals(t, "dropped lines logged", strings.Contains(string(content), "2 log line(s) dropped"), true)
+	assert.Equals(t, "line logged", strings.HasSuffix(string(content), "logged line\n"), true)
+}
@@ -0,0 +1,84 @@
+// Package secret implements encryption of secrets (like saved DataPower
+// passwords) with key derived from passphrase (scrypt key derivation and
+// AES-GCM authenticated encryption).
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"github.com/croz-ltd/dpcmder/utils/errs"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Parameters of scrypt key derivation (recommended for interactive logins).
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	keySize = 32
+	// SaltSize is size of random salt (in bytes) created by NewSalt.
+	SaltSize = 16
+)
+
+// ErrDecrypt is returned when secret can't be decrypted (wrong key or
+// corrupted secret).
+const ErrDecrypt = errs.Error("Can't decrypt secret, wrong passphrase or corrupted data.")
+
+// NewSalt creates new random salt used for key derivation.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// DeriveKey derives AES-256 key from passphrase and salt.
+func DeriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+}
+
+// Encrypt encrypts plaintext using key and returns base64 encoded nonce with
+// ciphertext.
+func Encrypt(key, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts secret created by Encrypt using key.
+func Decrypt(key []byte, secret string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// newGCM creates AES-GCM cipher using key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,38 @@
+package secret
+
+import (
+	"github.com/croz-ltd/dpcmder/utils/assert"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	salt, err := NewSalt()
+	assert.DeepEqual(t, "NewSalt()", err, nil)
+	assert.DeepEqual(t, "NewSalt()", len(salt), SaltSize)
+
+	key, err := DeriveKey("passphrase", salt)
+	assert.DeepEqual(t, "DeriveKey()", err, nil)
+	sameKey, _ := DeriveKey("passphrase", salt)
+	assert.DeepEqual(t, "DeriveKey()", sameKey, key)
+	otherKey, _ := DeriveKey("other passphrase", salt)
+
+	testDataMatrix := []string{"", "password", "pässwörd with unicode & spaces"}
+	for _, plaintext := range testDataMatrix {
+		encrypted, err := Encrypt(key, []byte(plaintext))
+		assert.DeepEqual(t, "Encrypt()", err, nil)
+		encryptedAgain, _ := Encrypt(key, []byte(plaintext))
+		assert.DeepEqual(t, "Encrypt() uses random nonce", encrypted != encryptedAgain, true)
+
+		decrypted, err := Decrypt(key, encrypted)
+		assert.DeepEqual(t, "Decrypt()", err, nil)
+		assert.DeepEqual(t, "Decrypt()", string(decrypted), plaintext)
+
+		_, err = Decrypt(otherKey, encrypted)
+		assert.DeepEqual(t, "Decrypt() with wrong key", err, ErrDecrypt)
+	}
+
+	_, err = Decrypt(key, "bm90IGVuY3J5cHRlZA==")
+	assert.DeepEqual(t, "Decrypt() of invalid secret", err, ErrDecrypt)
+	_, err = Decrypt(key, "not base64!")
+	assert.DeepEqual(t, "Decrypt() of invalid base64", err, ErrDecrypt)
+}